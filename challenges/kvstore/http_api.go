@@ -3,6 +3,8 @@ package kvstore
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	. "github.com/littleclusters/lc/internal/attest"
 )
@@ -254,7 +256,7 @@ func HTTPAPI() *Suite {
 		}).
 
 		// 8
-		Test("Concurrent Operations - Different Keys", func(do *Do) {
+		TestParallel("Concurrent Operations - Different Keys", func(do *Do) {
 			// Test concurrent writes to different keys
 			putFn := func(key, value string) func() {
 				return func() {
@@ -283,7 +285,7 @@ func HTTPAPI() *Suite {
 		}).
 
 		// 9
-		Test("Concurrent Operations - Same Key", func(do *Do) {
+		TestParallel("Concurrent Operations - Same Key", func(do *Do) {
 			// Test concurrent writes to the SAME key
 			// Last write should win, but no crashes or data corruption
 			putFn := func(key, value string) func() {
@@ -314,7 +316,7 @@ func HTTPAPI() *Suite {
 		}).
 
 		// 10
-		Test("Check Allowed HTTP Methods", func(do *Do) {
+		TestParallel("Check Allowed HTTP Methods", func(do *Do) {
 			// POST & PATCH /kv/{key} not allowed
 			for _, method := range []string{"POST", "PATCH"} {
 				do.HTTP("node", method, "/kv/test:key").T().
@@ -334,3 +336,50 @@ func HTTPAPI() *Suite {
 			}
 		})
 }
+
+func HTTPAPIBench() *Suite {
+	return New().
+		// 0
+		Setup(func(do *Do) {
+			do.Start("node")
+
+			for i := range 1_000 {
+				do.HTTP("node", "PUT", fmt.Sprintf("/kv/bench:%d", i), fmt.Sprintf("value%d", i)).T().
+					Status(Is(200)).
+					Assert("Your server should accept PUT requests.\n" +
+						"Ensure your HTTP handler processes PUT requests to /kv/{key}.")
+			}
+		}).
+
+		// 1
+		Test("GET Throughput and Latency", func(do *Do) {
+			var n atomic.Int64
+			do.Bench(func() {
+				key := fmt.Sprintf("bench:%d", n.Add(1)%1_000)
+				do.HTTP("node", "GET", "/kv/"+key).T().
+					Status(Is(200)).
+					Assert("")
+			}).Workers(16).Duration(5*time.Second).T().
+				ErrorRate(Is(0.0)).
+				Throughput(AtLeast(1_000.0)).
+				Latency(99, AtMost(50*time.Millisecond)).
+				Assert("Your server should serve reads quickly under concurrent load.\n" +
+					"Avoid holding a global write lock while serving reads (consider a RWMutex).")
+		}).
+
+		// 2
+		Test("PUT Throughput and Latency", func(do *Do) {
+			var n atomic.Int64
+			do.Bench(func() {
+				i := n.Add(1)
+				do.HTTP("node", "PUT", fmt.Sprintf("/kv/bench:put%d", i), fmt.Sprintf("value%d", i)).T().
+					Status(Is(200)).
+					Assert("")
+			}).Workers(16).Duration(5*time.Second).T().
+				ErrorRate(Is(0.0)).
+				Throughput(AtLeast(500.0)).
+				Latency(99, AtMost(100*time.Millisecond)).
+				Assert("Your server should handle writes quickly under concurrent load.\n" +
+					"Keep critical sections short and avoid unnecessary copying of the store.")
+		})
+}
@@ -1,11 +1,21 @@
 package kvstore
 
-import "github.com/littleclusters/lc/internal/registry"
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/littleclusters/lc/internal/registry"
+)
+
+//go:embed guides/*.md
+var guides embed.FS
 
 func init() {
 	challenge := &registry.Challenge{
-		Name:    "Distributed Key-Value Store",
-		Summary: "Build a distributed key-value store from scratch using the Raft consensus algorithm.",
+		Name:       "Distributed Key-Value Store",
+		Summary:    "Build a distributed key-value store from scratch using the Raft consensus algorithm.",
+		Difficulty: "advanced",
+		Tags:       []string{"distributed-systems", "consensus", "raft", "storage", "http"},
 	}
 
 	challenge.AddStage("http-api", "Store and Retrieve Data", HTTPAPI)
@@ -17,5 +27,33 @@ func init() {
 	challenge.AddStage("fault-tolerance", "Cluster Survives Failures and Partitions", FaultTolerance)
 	challenge.AddStage("log-compaction", "System Manages Log Growth", LogCompaction)
 
+	challenge.AddBenchmark("http-api", HTTPAPIBench)
+
+	challenge.AddChange(1, "Concurrency and HTTP method tests can run in parallel with lc test --parallel.", "http-api")
+
+	guideFS, _ := fs.Sub(guides, "guides")
+	challenge.AddGuides(guideFS)
+
+	challenge.AddHints("http-api",
+		"Start with an in-memory map guarded by a mutex; every handler reads or writes it.",
+		"Route on the path prefix: /kv/{key} handles GET, PUT, and DELETE, and /clear only DELETE.",
+		"Empty keys and values get 400, GET of a missing key 404, and other methods 405 with the body \"method not allowed\\n\".",
+	)
+	challenge.AddHints("persistence",
+		"Write your data under the directory passed in --working-dir, not the current directory.",
+		"Handle SIGTERM: save the map to disk before exiting, and load it again on startup.",
+		"Write to a temporary file and rename it over the old one so a half-written file never replaces good data.",
+	)
+	challenge.AddHints("crash-recovery",
+		"SIGKILL gives you no chance to save, so every acknowledged write must already be on disk.",
+		"Append each PUT and DELETE to a write-ahead log and fsync it before responding.",
+		"On startup, load the latest snapshot if any, then replay the log entries written after it.",
+	)
+	challenge.AddHints("leader-election",
+		"Every node starts as a follower and becomes a candidate when it hears no heartbeat within a randomized timeout.",
+		"Grant at most one vote per term, and step down whenever you see a higher term.",
+		"Persist currentTerm and votedFor before answering a vote request so restarts can't vote twice.",
+	)
+
 	registry.RegisterChallenge("kv-store", challenge)
 }
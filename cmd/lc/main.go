@@ -15,46 +15,334 @@ func main() {
 	log.SetFlags(0)
 
 	cmd := &commands.Command{
-		Name:  "lc",
-		Usage: "Learn distributed systems by building them from scratch",
+		Name:        "lc",
+		Usage:       "Learn distributed systems by building them from scratch",
+		Description: cli.ExitCodes,
+		Before:      cli.Setup,
+		// Errors are reported below so every exit code goes through cli.ExitCode
+		ExitErrHandler: func(context.Context, *commands.Command, error) {},
+		Flags: []commands.Flag{
+			&commands.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable colors, emoji, and hyperlinks (also set by NO_COLOR)",
+			},
+		},
 		Commands: []*commands.Command{
 			{
 				Name:      "init",
 				Aliases:   []string{"i"},
 				Usage:     "Initialize a challenge",
 				ArgsUsage: "<challenge> [path]",
-				Action:    cli.InitChallenge,
+				Flags: []commands.Flag{
+					&commands.BoolFlag{
+						Name:  "git",
+						Usage: "Initialize a git repository and commit the challenge files",
+					},
+				},
+				Action: cli.InitChallenge,
 			},
 			{
 				Name:      "test",
 				Aliases:   []string{"t"},
 				Usage:     "Test your implementation",
-				ArgsUsage: "[stage]",
+				ArgsUsage: "[[challenge:]stage]",
+				Before:    cli.SelectProject,
+				Flags:     cli.TestFlags(),
+				Action:    cli.Test,
+			},
+			{
+				Name:  "validate",
+				Usage: "Check that run.sh is set up and starts your implementation",
 				Flags: []commands.Flag{
-					&commands.BoolFlag{
-						Name:  "so-far",
-						Usage: "Test all stages up to the specified stage",
+					&commands.StringFlag{
+						Name:  "path",
+						Usage: "Validate the project in `dir` instead of the current directory",
 					},
 				},
-				Action: cli.Test,
+				Action: cli.Validate,
+			},
+			{
+				Name:      "bench",
+				Aliases:   []string{"b"},
+				Usage:     "Benchmark your implementation against performance targets",
+				ArgsUsage: "[stage]",
+				Action:    cli.Bench,
 			},
 			{
 				Name:    "next",
 				Aliases: []string{"n"},
 				Usage:   "Advance to the next stage",
-				Action:  cli.NextStage,
+				Before:  cli.SelectProject,
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:  "project",
+						Usage: "Run in the `name`d project of lc.workspace",
+					},
+					&commands.BoolFlag{
+						Name:  "tag",
+						Usage: "Tag the current git commit with the completed stage",
+					},
+				},
+				Action: cli.NextStage,
+			},
+			{
+				Name:   "skip",
+				Usage:  "Advance to the next stage, leaving the current one incomplete",
+				Action: cli.SkipStage,
+			},
+			{
+				Name:  "diff",
+				Usage: "Replay the last failed request and diff expected and actual responses",
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:  "addr",
+						Usage: "Replay against an implementation already running at `host:port`",
+					},
+					&commands.BoolFlag{
+						Name:  "recorded",
+						Usage: "Show the response recorded during the test run without replaying",
+					},
+				},
+				Action: cli.Diff,
+			},
+			{
+				Name:      "record",
+				Usage:     "Run a stage and save the requests it sends for replay",
+				ArgsUsage: "[stage]",
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write the recording to `file`",
+					},
+					&commands.Uint64Flag{
+						Name:  "seed",
+						Usage: "Seed randomized test inputs to reproduce a run",
+					},
+				},
+				Action: cli.Record,
+			},
+			{
+				Name:      "replay",
+				Usage:     "Send a recording's requests to your implementation without assertions",
+				ArgsUsage: "<file>",
+				Flags: []commands.Flag{
+					&commands.BoolFlag{
+						Name:  "realtime",
+						Usage: "Keep the original timing between requests",
+					},
+				},
+				Action: cli.Replay,
+			},
+			{
+				Name:      "guide",
+				Usage:     "Show the stage guide offline",
+				ArgsUsage: "[stage]",
+				Action:    cli.Guide,
+			},
+			{
+				Name:   "whatsnew",
+				Usage:  "Show test suite changes since your last test run",
+				Action: cli.WhatsNew,
+			},
+			{
+				Name:      "hint",
+				Usage:     "Reveal the next hint for a stage",
+				ArgsUsage: "[stage]",
+				Action:    cli.Hint,
+			},
+			{
+				Name:   "tui",
+				Usage:  "Open the interactive full-screen mode",
+				Action: cli.TUI,
+			},
+			{
+				Name:        "status",
+				Aliases:     []string{"s"},
+				Usage:       "Show current progress",
+				Description: "Exit status is 2 when not in a challenge directory or lc.state is invalid.",
+				Before:      cli.SelectProject,
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:  "project",
+						Usage: "Run in the `name`d project of lc.workspace",
+					},
+					&commands.BoolFlag{
+						Name:  "json",
+						Usage: "Print progress as JSON",
+					},
+				},
+				Action: cli.ShowStatus,
+			},
+			{
+				Name:  "progress",
+				Usage: "Show challenge progress, or with --all, progress across a workspace",
+				Description: "With --all, lc progress lists the directories in lc.workspace, one per line,\n" +
+					"or scans subdirectories for lc.state files.",
+				Flags: []commands.Flag{
+					&commands.BoolFlag{
+						Name:  "all",
+						Usage: "Show every challenge directory in the workspace",
+					},
+				},
+				Action: cli.ShowProgress,
+			},
+			{
+				Name:   "stats",
+				Usage:  "Show time spent and test runs per stage",
+				Action: cli.ShowStats,
+			},
+			{
+				Name:  "submit",
+				Usage: "Upload your solution with its verified test results",
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write the submission archive to a file instead of uploading",
+					},
+				},
+				Action: cli.Submit,
+			},
+			{
+				Name:  "login",
+				Usage: "Log in to littleclusters.com",
+				Flags: []commands.Flag{
+					&commands.BoolFlag{
+						Name:  "with-token",
+						Usage: "Read an API token from standard input instead of the browser flow (or set LC_TOKEN)",
+					},
+				},
+				Action: cli.Login,
 			},
 			{
-				Name:    "status",
-				Aliases: []string{"s"},
-				Usage:   "Show current progress",
-				Action:  cli.ShowStatus,
+				Name:   "logout",
+				Usage:  "Log out of littleclusters.com",
+				Action: cli.Logout,
+			},
+			{
+				Name:   "purge",
+				Usage:  "Remove global lc data such as credentials and preferences",
+				Flags:  cli.PurgeFlags(),
+				Action: cli.Purge,
+			},
+			{
+				Name:  "telemetry",
+				Usage: "Control anonymous usage statistics (off by default)",
+				Commands: []*commands.Command{
+					{
+						Name:   "on",
+						Usage:  "Share anonymous usage statistics",
+						Action: cli.TelemetryOn,
+					},
+					{
+						Name:   "off",
+						Usage:  "Stop sharing and delete unsent statistics",
+						Action: cli.TelemetryOff,
+					},
+					{
+						Name:   "status",
+						Usage:  "Show whether telemetry is on and what it collects",
+						Action: cli.TelemetryStatus,
+					},
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "Export a progress report as Markdown or HTML",
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Report format: markdown or html (default: from output extension)",
+					},
+					&commands.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write the report to a file instead of stdout",
+					},
+				},
+				Action: cli.ExportReport,
+			},
+			{
+				Name:  "badge",
+				Usage: "Generate a progress badge for your README",
+				Flags: []commands.Flag{
+					&commands.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Badge format: svg or json (default: from output extension)",
+					},
+					&commands.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write the badge to a file instead of stdout",
+					},
+				},
+				Action: cli.Badge,
+			},
+			{
+				Name:      "open",
+				Aliases:   []string{"o"},
+				Usage:     "Open the stage guide in a browser",
+				ArgsUsage: "[stage]",
+				Action:    cli.OpenGuide,
 			},
 			{
 				Name:    "list",
 				Aliases: []string{"l", "ls"},
 				Usage:   "List available challenges",
-				Action:  cli.ListChallenges,
+				Flags: []commands.Flag{
+					&commands.StringSliceFlag{
+						Name:  "tag",
+						Usage: "Only list challenges with this `tag` (repeatable)",
+					},
+					&commands.StringFlag{
+						Name:  "difficulty",
+						Usage: "Only list challenges of this `level` (beginner, intermediate, advanced)",
+					},
+					&commands.BoolFlag{
+						Name:  "json",
+						Usage: "Print the challenge catalog as JSON",
+					},
+				},
+				Action: cli.ListChallenges,
+			},
+			{
+				Name:      "search",
+				Usage:     "Search challenges and stages by keyword",
+				ArgsUsage: "<query>",
+				Action:    cli.Search,
+			},
+			{
+				Name:  "config",
+				Usage: "View and change global preferences",
+				Commands: []*commands.Command{
+					{
+						Name:      "get",
+						Usage:     "Print the value of a config key",
+						ArgsUsage: "<key>",
+						Action:    cli.ConfigGet,
+					},
+					{
+						Name:      "set",
+						Usage:     "Set the value of a config key",
+						ArgsUsage: "<key> <value>",
+						Action:    cli.ConfigSet,
+					},
+					{
+						Name:      "unset",
+						Usage:     "Restore the default value of a config key",
+						ArgsUsage: "<key>",
+						Action:    cli.ConfigUnset,
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List all config keys and their values",
+						Action:  cli.ConfigList,
+					},
+				},
 			},
 		},
 	}
@@ -70,12 +358,36 @@ func main() {
 		cancel()
 	}()
 
+	setUsageErrorHandler(cmd)
+	cli.BindEnv(cmd)
+
+	// Unknown subcommands run lc-<name> from PATH, like git
+	if path, args, ok := cli.FindPlugin(cmd, os.Args[1:]); ok {
+		code, err := cli.RunPlugin(path, args)
+		if err != nil {
+			log.Print(err)
+			os.Exit(cli.ExitCode(err))
+		}
+
+		os.Exit(code)
+	}
+
 	err := cmd.Run(ctx, os.Args)
 	if err != nil {
 		if ctx.Err() == context.Canceled {
 			os.Exit(0)
 		}
 
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(cli.ExitCode(err))
+	}
+}
+
+// setUsageErrorHandler reports flag parsing errors of cmd and its
+// subcommands as usage errors.
+func setUsageErrorHandler(cmd *commands.Command) {
+	cmd.OnUsageError = cli.UsageError
+	for _, sub := range cmd.Commands {
+		setUsageErrorHandler(sub)
 	}
 }
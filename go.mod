@@ -3,15 +3,27 @@ module github.com/littleclusters/lc
 go 1.25
 
 require (
+	github.com/coder/websocket v1.8.15
 	github.com/fatih/color v1.18.0
+	github.com/quic-go/quic-go v0.59.1
+	github.com/rabbitmq/amqp091-go v1.15.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.3
 	github.com/tidwall/gjson v1.18.0
 	github.com/urfave/cli/v3 v3.6.2
+	golang.org/x/net v0.49.0
+	golang.org/x/term v0.39.0
+	google.golang.org/grpc v1.80.0
+	google.golang.org/protobuf v1.36.11
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/quic-go/qpack v0.6.0 // indirect
 	github.com/tidwall/match v1.2.0 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
+	golang.org/x/crypto v0.47.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260120221211-b8f7ae30c516 // indirect
 )
@@ -0,0 +1,452 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var _ Plan[*AMQPPlan, *AMQPAssert] = (*AMQPPlan)(nil)
+var _ Assert = (*AMQPAssert)(nil)
+
+// defaultDeliveryTimeout is how long an AMQP plan waits for each delivery.
+const defaultDeliveryTimeout = time.Second
+
+// amqpPublishing is a message to publish.
+type amqpPublishing struct {
+	exchange string
+	key      string
+	body     string
+}
+
+// AMQPPlan represents a test plan for an AMQP 0.9.1 broker: open a
+// channel, declare queues, publish messages, then consume from a queue.
+type AMQPPlan struct {
+	PlanBase
+
+	process   string
+	addr      string
+	username  string
+	password  string
+	vhost     string
+	queues    []string
+	publishes []amqpPublishing
+	confirm   bool
+	consume   string
+	wait      time.Duration
+}
+
+// Credentials sets the PLAIN login, guest/guest by default.
+func (p *AMQPPlan) Credentials(username, password string) *AMQPPlan {
+	p.username, p.password = username, password
+	return p
+}
+
+// VHost sets the virtual host, "/" by default.
+func (p *AMQPPlan) VHost(vhost string) *AMQPPlan {
+	p.vhost = vhost
+	return p
+}
+
+// DeclareQueue declares a non-durable queue before publishing.
+func (p *AMQPPlan) DeclareQueue(name string) *AMQPPlan {
+	p.queues = append(p.queues, name)
+	return p
+}
+
+// Publish publishes a message to an exchange with a routing key. Use the
+// exchange "" to route directly to the queue named by the key.
+func (p *AMQPPlan) Publish(exchange, key, body string) *AMQPPlan {
+	p.publishes = append(p.publishes, amqpPublishing{exchange: exchange, key: key, body: body})
+	return p
+}
+
+// Confirm puts the channel in confirm mode so the broker acknowledges
+// each published message.
+func (p *AMQPPlan) Confirm() *AMQPPlan {
+	p.confirm = true
+	return p
+}
+
+// Consume consumes from a queue, with manual acknowledgements, after
+// publishing.
+func (p *AMQPPlan) Consume(queue string) *AMQPPlan {
+	p.consume = queue
+	return p
+}
+
+// DeliveryTimeout sets how long to wait for each delivery and publisher
+// confirm, one second by default.
+func (p *AMQPPlan) DeliveryTimeout(d time.Duration) *AMQPPlan {
+	p.wait = d
+	return p
+}
+
+func (p *AMQPPlan) Eventually() *AMQPPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *AMQPPlan) Within(timeout time.Duration) *AMQPPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *AMQPPlan) Retry(policy RetryPolicy) *AMQPPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *AMQPPlan) Consistently() *AMQPPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *AMQPPlan) For(timeout time.Duration) *AMQPPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *AMQPPlan) Every(interval time.Duration) *AMQPPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *AMQPPlan) Deadline(d time.Duration) *AMQPPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *AMQPPlan) T() *AMQPAssert {
+	return &AMQPAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// deliveryTimeout returns how long to wait for each delivery.
+func (p *AMQPPlan) deliveryTimeout() time.Duration {
+	if p.wait == 0 {
+		return defaultDeliveryTimeout
+	}
+
+	return p.wait
+}
+
+// url returns the AMQP URI of the broker.
+func (p *AMQPPlan) url() string {
+	u := url.URL{
+		Scheme: "amqp",
+		User:   url.UserPassword(p.username, p.password),
+		Host:   p.addr,
+		Path:   "/" + url.PathEscape(p.vhost),
+	}
+
+	return u.String()
+}
+
+// amqpAction is what the consumer does with a delivery.
+type amqpAction int
+
+const (
+	amqpAck amqpAction = iota
+	amqpNack
+	amqpRedelivered
+)
+
+// amqpExpectation is an expected delivery, in order.
+type amqpExpectation struct {
+	action   amqpAction
+	checkers []Checker[string]
+}
+
+// amqpDelivery is a received message.
+type amqpDelivery struct {
+	body        string
+	redelivered bool
+}
+
+func (d amqpDelivery) String() string {
+	if d.redelivered {
+		return fmt.Sprintf("%q (redelivered)", d.body)
+	}
+
+	return fmt.Sprintf("%q", d.body)
+}
+
+// AMQPAssert provides assertions on publisher confirms and on the
+// messages delivered to the consumer.
+type AMQPAssert struct {
+	AssertBase
+
+	plan      *AMQPPlan
+	confirmed int
+	nacked    int
+	received  []amqpDelivery
+	// failed is the index of the expectation that was not met.
+	failed int
+	err    error
+
+	expectConfirms bool
+	expected       []amqpExpectation
+	noDelivery     bool
+}
+
+// Confirmed expects the broker to ack every published message. Requires
+// Confirm on the plan.
+func (a *AMQPAssert) Confirmed() *AMQPAssert {
+	a.expectConfirms = true
+	return a
+}
+
+// Delivery expects the next delivery to have a body passing all checkers,
+// then acks it.
+func (a *AMQPAssert) Delivery(checkers ...Checker[string]) *AMQPAssert {
+	a.expected = append(a.expected, amqpExpectation{action: amqpAck, checkers: checkers})
+	return a
+}
+
+// Nack expects the next delivery to have a body passing all checkers, then
+// nacks it with requeue so the broker redelivers it.
+func (a *AMQPAssert) Nack(checkers ...Checker[string]) *AMQPAssert {
+	a.expected = append(a.expected, amqpExpectation{action: amqpNack, checkers: checkers})
+	return a
+}
+
+// Redelivery expects the next delivery to be flagged as redelivered, with a
+// body passing all checkers, then acks it.
+func (a *AMQPAssert) Redelivery(checkers ...Checker[string]) *AMQPAssert {
+	a.expected = append(a.expected, amqpExpectation{action: amqpRedelivered, checkers: checkers})
+	return a
+}
+
+// NoDelivery expects no further delivery within the delivery timeout.
+func (a *AMQPAssert) NoDelivery() *AMQPAssert {
+	a.noDelivery = true
+	return a
+}
+
+func (a *AMQPAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *AMQPAssert) execute() bool {
+	p := a.plan
+	a.confirmed, a.nacked, a.received, a.failed, a.err = 0, 0, nil, -1, nil
+
+	if a.expectConfirms && !p.confirm {
+		panic("Confirmed requires Confirm on the plan")
+	}
+	if (len(a.expected) > 0 || a.noDelivery) && p.consume == "" {
+		panic("delivery assertions require Consume on the plan")
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
+	defer cancel()
+
+	var raw net.Conn
+	dial := func(network, addr string) (net.Conn, error) {
+		var err error
+		raw, err = net.DialTimeout(network, addr, a.config.ExecuteTimeout)
+		return raw, err
+	}
+
+	conn, err := amqp.DialConfig(p.url(), amqp.Config{Dial: dial})
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.Close()
+	defer closeWhenDone(ctx, raw)()
+
+	// The client waits forever for replies, so bound the whole session
+	waits := len(p.publishes) + len(a.expected) + 1
+	raw.SetDeadline(time.Now().Add(a.config.ExecuteTimeout + time.Duration(waits)*p.deliveryTimeout()))
+
+	ch, err := conn.Channel()
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer ch.Close()
+
+	for _, queue := range p.queues {
+		_, err := ch.QueueDeclare(queue, false, false, false, false, nil)
+		if err != nil {
+			a.err = fmt.Errorf("failed to declare queue %s: %w", queue, err)
+			return false
+		}
+	}
+
+	var confirms chan amqp.Confirmation
+	if p.confirm {
+		err := ch.Confirm(false)
+		if err != nil {
+			a.err = fmt.Errorf("failed to enable confirms: %w", err)
+			return false
+		}
+		confirms = ch.NotifyPublish(make(chan amqp.Confirmation, len(p.publishes)))
+	}
+
+	for _, pub := range p.publishes {
+		err := ch.PublishWithContext(ctx, pub.exchange, pub.key, false, false, amqp.Publishing{Body: []byte(pub.body)})
+		if err != nil {
+			a.err = fmt.Errorf("failed to publish: %w", err)
+			return false
+		}
+	}
+
+	if p.confirm {
+		for range p.publishes {
+			select {
+			case c := <-confirms:
+				if c.Ack {
+					a.confirmed++
+				} else {
+					a.nacked++
+				}
+			case <-time.After(p.deliveryTimeout()):
+			}
+		}
+	}
+
+	if p.consume != "" {
+		deliveries, err := ch.Consume(p.consume, "", false, false, false, false, nil)
+		if err != nil {
+			a.err = fmt.Errorf("failed to consume from %s: %w", p.consume, err)
+			return false
+		}
+
+		for i, want := range a.expected {
+			d, ok := nextDelivery(deliveries, p.deliveryTimeout())
+			if !ok {
+				a.failed = i
+				return false
+			}
+
+			a.received = append(a.received, amqpDelivery{body: string(d.Body), redelivered: d.Redelivered})
+			if !checkAll(string(d.Body), want.checkers, nil) || (want.action == amqpRedelivered && !d.Redelivered) {
+				d.Nack(false, true)
+				a.failed = i
+				return false
+			}
+
+			if want.action == amqpNack {
+				err = d.Nack(false, true)
+			} else {
+				err = d.Ack(false)
+			}
+			if err != nil {
+				a.err = err
+				return false
+			}
+		}
+
+		if a.noDelivery {
+			if d, ok := nextDelivery(deliveries, p.deliveryTimeout()); ok {
+				d.Nack(false, true)
+				a.received = append(a.received, amqpDelivery{body: string(d.Body), redelivered: d.Redelivered})
+				return false
+			}
+		}
+	}
+
+	return !a.expectConfirms || a.confirmed == len(p.publishes)
+}
+
+// nextDelivery waits up to timeout for a delivery.
+func nextDelivery(deliveries <-chan amqp.Delivery, timeout time.Duration) (amqp.Delivery, bool) {
+	select {
+	case d, ok := <-deliveries:
+		return d, ok
+	case <-time.After(timeout):
+		return amqp.Delivery{}, false
+	}
+}
+
+// formatReceived lists the deliveries received.
+func (a *AMQPAssert) formatReceived() string {
+	if len(a.received) == 0 {
+		return " (none)"
+	}
+
+	var lines []string
+	for _, d := range a.received {
+		lines = append(lines, "\n    "+d.String())
+	}
+
+	return strings.Join(lines, "")
+}
+
+func (a *AMQPAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("AMQP %s", p.addr)
+	for _, pub := range p.publishes {
+		title += fmt.Sprintf("\n  Published: %q to exchange %q with key %q", pub.body, pub.exchange, pub.key)
+	}
+	if p.consume != "" {
+		title += fmt.Sprintf("\n  Consumed: %s", p.consume)
+	}
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	if a.expectConfirms && a.confirmed != len(p.publishes) {
+		msg := fmt.Sprintf("%s\n  Expected confirms: %d acks\n  Actual confirms: %d acks, %d nacks%s",
+			title, len(p.publishes), a.confirmed, a.nacked, a.formatHelp())
+		panic(msg)
+	}
+
+	if a.failed >= 0 {
+		want := a.expected[a.failed]
+		kind := "delivery"
+		if want.action == amqpRedelivered {
+			kind = "redelivery"
+		}
+
+		if a.failed >= len(a.received) {
+			msg := fmt.Sprintf("%s\n  Expected %s %d within %s\n  Actual deliveries:%s%s",
+				title, kind, a.failed+1, p.deliveryTimeout(), a.formatReceived(), a.formatHelp())
+			panic(msg)
+		}
+
+		actual := a.received[a.failed]
+		if want.action == amqpRedelivered && !actual.redelivered {
+			msg := fmt.Sprintf("%s\n  Expected %s %d to be redelivered\n  Actual delivery %d: %s%s",
+				title, kind, a.failed+1, a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(actual.body, want.checkers, func(m Checker[string], body string) {
+			msg := fmt.Sprintf("%s\n  Expected %s %d: %s\n  Actual delivery %d: %s%s",
+				title, kind, a.failed+1, m.Expected(), a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	if a.noDelivery && len(a.received) > len(a.expected) {
+		msg := fmt.Sprintf("%s\n  Expected no further delivery within %s\n  Actual delivery: %s%s",
+			title, p.deliveryTimeout(), a.received[len(a.received)-1], a.formatHelp())
+		panic(msg)
+	}
+}
@@ -7,20 +7,29 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 )
 
-// eventually checks that the condition becomes true within the given period.
-func eventually(ctx context.Context, condition func() bool, timeout, pollInterval time.Duration) bool {
+// eventually checks that the condition becomes true within the given period,
+// waiting between attempts as the policy says. The last wait is cut short
+// so the final attempt happens at the deadline.
+func eventually(ctx context.Context, condition func() bool, timeout time.Duration, policy RetryPolicy) bool {
 	deadline := time.Now().Add(timeout)
 
-	for time.Now().Before(deadline) {
+	for attempt := 0; time.Now().Before(deadline); attempt++ {
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return false
+		}
+
 		select {
 		case <-ctx.Done():
 			return false
-		case <-time.After(pollInterval):
+		case <-time.After(min(policy.delay(attempt), time.Until(deadline))):
 			if condition() {
 				return true
 			}
@@ -30,16 +39,20 @@ func eventually(ctx context.Context, condition func() bool, timeout, pollInterva
 	return false
 }
 
-// consistently checks that the condition is always true for the given period.
-func consistently(ctx context.Context, condition func() bool, timeout, pollInterval time.Duration) bool {
+// consistently checks that the condition is always true for the given
+// period, sampling it every interval. It records the sample that failed for
+// the failure message.
+func (a *AssertBase) consistently(ctx context.Context, condition func() bool, timeout, interval time.Duration) bool {
 	deadline := time.Now().Add(timeout)
+	a.failedSample, a.interval = 0, interval
 
-	for time.Now().Before(deadline) {
+	for sample := 1; time.Now().Before(deadline); sample++ {
 		select {
 		case <-ctx.Done():
 			return false
-		case <-time.After(pollInterval):
+		case <-time.After(interval):
 			if !condition() {
+				a.failedSample = sample
 				return false
 			}
 		}
@@ -58,20 +71,51 @@ type Assert interface {
 	check()
 	// formatHelp formats help text with proper indentation for error messages.
 	formatHelp() string
+	// base returns the common assertion state.
+	base() *AssertBase
 }
 
 var _ Assert = (*HTTPAssert)(nil)
 var _ Assert = (*CLIAssert)(nil)
+var _ Assert = (*BenchAssert)(nil)
 
 // AssertBase provides common assertion functionality.
 type AssertBase struct {
 	help string
+	// because says why the assertion matters, and hint what to try when it
+	// fails.
+	because string
+	hint    string
+	// strict fails by panicking even in a soft test.
+	strict bool
+	// failedSample is the Consistently sample that failed, counting from
+	// one, taken every interval.
+	failedSample int
+	interval     time.Duration
 
 	config *Config
 }
 
+// formatHelp formats the help text that ends a failure message, after the
+// Consistently sample that failed, if any, and the Because and Hint lines.
 func (a *AssertBase) formatHelp() string {
-	return "\n\n  " + strings.ReplaceAll(a.help, "\n", "\n  ")
+	help := a.formatReasons() + "\n\n  " + strings.ReplaceAll(a.help, "\n", "\n  ")
+	if a.failedSample == 0 {
+		return help
+	}
+
+	return fmt.Sprintf("\n  Failed on sample %d, checking every %s%s", a.failedSample, a.interval, help)
+}
+
+// formatText follows a label with single-line text quoted on the same line,
+// or multi-line text as an indented block so each line reads as sent.
+func formatText(text string) string {
+	if !strings.Contains(strings.TrimSuffix(text, "\n"), "\n") {
+		return fmt.Sprintf(" %q", text)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return "\n    | " + strings.Join(lines, "\n    | ")
 }
 
 // HTTPAssert provides assertions for HTTP response validation.
@@ -81,10 +125,43 @@ type HTTPAssert struct {
 	plan           *HTTPPlan
 	responseBody   string
 	responseStatus int
-
-	statusCheckers []Checker[int]
-	bodyCheckers   []Checker[string]
-	jsonCheckers   []Checker[string]
+	responseHeader http.Header
+	protocol       string
+	latency        time.Duration
+	connections    int
+	errorCode      string
+	received       []sseEvent
+	// eventFailed is the index of the event expectation that was not met.
+	eventFailed int
+	eventErr    error
+	tls         tlsAssert
+	order       orderAssert
+
+	statusCheckers      []Checker[int]
+	bodyCheckers        []Checker[string]
+	jsonCheckers        []Checker[string]
+	etagCheckers        []Checker[string]
+	objects             []string
+	events              []sseExpectation
+	protocolCheckers    []Checker[string]
+	connectionsCheckers []Checker[int]
+	errorCodeCheckers   []Checker[string]
+	altSvcCheckers      []Checker[string]
+	latencyCheckers     []Checker[time.Duration]
+	headers             []headerExpectation
+	cookies             []cookieExpectation
+	parts               []partExpectation
+	captures            []responseCapture
+	// bodyFile is the fixture the body is streamed against, if any.
+	bodyFile string
+	bodyDiff streamDiff
+	// encoding is the expected Content-Encoding, "" for none, if checked.
+	encoding          *string
+	contentEncoding   string
+	redirectsCheckers []Checker[int]
+	chainCheckers     []Checker[string]
+	redirects         []redirectHop
+	redirectErr       error
 }
 
 // Status adds expected HTTP response status code checkers.
@@ -101,7 +178,31 @@ func (a *HTTPAssert) Body(checkers ...Checker[string]) *HTTPAssert {
 	return a
 }
 
-// JSON adds expected checkers for a JSON field at the given gjson path.
+// BodyMatches expects the response body to match a regular expression, with
+// ^ and $ matching at line boundaries.
+func (a *HTTPAssert) BodyMatches(pattern string) *HTTPAssert {
+	a.bodyCheckers = append(a.bodyCheckers, matchesLines(pattern))
+	return a
+}
+
+// BodyContains expects the response body to contain every substring.
+func (a *HTTPAssert) BodyContains(substrings ...string) *HTTPAssert {
+	for _, substring := range substrings {
+		a.bodyCheckers = append(a.bodyCheckers, Contains(substring))
+	}
+
+	return a
+}
+
+// Protocol adds checkers for the response protocol, e.g. "HTTP/1.1" or
+// "HTTP/2.0". All checkers must pass.
+func (a *HTTPAssert) Protocol(checkers ...Checker[string]) *HTTPAssert {
+	a.protocolCheckers = append(a.protocolCheckers, checkers...)
+	return a
+}
+
+// JSON adds expected checkers for a JSON field at the given gjson path or
+// JSONPath. Exists, IsType and HasLen check presence, type and length.
 // All checkers must pass.
 func (a *HTTPAssert) JSON(path string, checkers ...Checker[string]) *HTTPAssert {
 	for _, checker := range checkers {
@@ -114,72 +215,285 @@ func (a *HTTPAssert) JSON(path string, checkers ...Checker[string]) *HTTPAssert
 func (a *HTTPAssert) Assert(help string) {
 	a.help = help
 
+	execute := func() bool { return a.order.observe(a.execute()) }
+
 	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
 	switch p.timing {
 	case TimingEventually:
-		eventually(p.ctx, a.execute, p.timeout, a.config.RetryPollInterval)
+		eventually(p.ctx, execute, p.timeout, p.retryPolicy())
 	case TimingConsistently:
-		consistently(p.ctx, a.execute, p.timeout, a.config.RetryPollInterval)
+		a.consistently(p.ctx, execute, p.timeout, p.pollInterval())
 	default:
-		a.execute()
+		execute()
 	}
 
-	a.check()
+	a.verify(p.ctx, func() {
+		a.check()
+		a.checkOrder()
+		a.capture()
+	})
 }
 
 func (a *HTTPAssert) execute() bool {
-	client := &http.Client{Timeout: a.config.ExecuteTimeout}
 	p := a.plan
+	p.record()
+	a.received, a.eventFailed, a.eventErr = nil, -1, nil
+	a.connections, a.errorCode = 0, ""
+	a.tls.state, a.tls.err = nil, nil
+	a.redirects, a.redirectErr = nil, nil
+
+	if p.http2 {
+		return a.executeHTTP2()
+	}
 
-	req, err := http.NewRequestWithContext(p.ctx, p.method, p.url, bytes.NewReader(p.body))
-	if err != nil {
-		panic(fmt.Sprintf("An error occurred: %v", err))
+	client := &http.Client{Timeout: a.config.ExecuteTimeout}
+	if p.session != nil {
+		client = p.session.client
 	}
 
-	for key, value := range p.headers {
-		req.Header.Set(key, value)
+	ctx := p.ctx
+	if p.sse {
+		// The stream stays open, so only the response headers are timed
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(p.ctx)
+		defer cancel()
+
+		client = &http.Client{Transport: &http.Transport{
+			ResponseHeaderTimeout: a.config.ExecuteTimeout,
+			DisableKeepAlives:     true,
+			TLSClientConfig:       p.tls.client(""),
+		}}
+	} else if p.tls != nil {
+		client.Transport = &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   p.tls.client(""),
+		}
+	}
+
+	if p.http3 {
+		var closeClient func()
+		client, closeClient = a.http3Client()
+		defer closeClient()
 	}
+	client = a.followRedirects(client)
 
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := client.Do(p.newRequest(ctx))
+	a.latency = time.Since(start)
 	if err != nil {
-		panic(fmt.Sprintf("An error occurred: %v", err))
+		if p.tls == nil {
+			panic(fmt.Sprintf("An error occurred: %v", err))
+		}
+		a.tls.err = err
+		return a.tls.passes()
 	}
 	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
+	a.responseStatus = resp.StatusCode
+	a.responseHeader = resp.Header
+	a.protocol = resp.Proto
+	a.tls.state = resp.TLS
+
+	if p.sse {
+		a.responseBody = a.readEvents(resp.Body)
+		if a.eventFailed >= 0 {
+			return false
+		}
+	} else {
+		a.responseBody = a.readResponse(resp, resp.Body)
+		a.latency = time.Since(start)
+	}
+
+	return a.passes()
+}
+
+// newRequest builds the request the plan sends.
+func (p *HTTPPlan) newRequest(ctx context.Context) *http.Request {
+	var body io.Reader = bytes.NewReader(p.body)
+	var size int64
+	if p.bodyFile != "" {
+		body, size = p.config.openFixture(p.bodyFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.method, p.url, body)
 	if err != nil {
 		panic(fmt.Sprintf("An error occurred: %v", err))
 	}
+	if p.bodyFile != "" {
+		req.ContentLength = size
+	}
+	if p.bodyEncoding != "" {
+		req.Body = compress(p.bodyEncoding, body)
+		req.ContentLength = -1
+		req.Header.Set("Content-Encoding", p.bodyEncoding)
+	}
 
-	a.responseBody = string(responseBody)
-	a.responseStatus = resp.StatusCode
+	if p.tls != nil || p.http3 {
+		req.URL.Scheme = "https"
+	}
+	if p.sse {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	if p.signer != nil {
+		payload := p.body
+		if p.bodyFile != "" {
+			payload = []byte(p.config.readFixture(p.bodyFile))
+		}
+		p.signer.sign(req, payload, time.Now())
+	}
+
+	return req
+}
 
-	return checkAll(a.responseStatus, a.statusCheckers, nil) &&
+// passes reports whether the response meets every expectation.
+func (a *HTTPAssert) passes() bool {
+	if a.tls.failed() {
+		return a.tls.passes()
+	}
+
+	if len(a.errorCodeCheckers) > 0 || a.errorCode != "" {
+		return a.errorCode != "" && len(a.errorCodeCheckers) > 0 &&
+			checkAll(a.errorCode, a.errorCodeCheckers, nil) &&
+			checkAll(a.connections, a.connectionsCheckers, nil)
+	}
+
+	if a.objects != nil {
+		keys, err := listObjects(a.responseBody)
+		if err != nil || !slices.Equal(keys, a.objects) {
+			return false
+		}
+	}
+
+	return a.tls.passes() &&
+		a.redirectsPass() &&
+		checkAll(a.responseStatus, a.statusCheckers, nil) &&
+		checkAll(a.protocol, a.protocolCheckers, nil) &&
+		checkAll(a.latency, a.latencyCheckers, nil) &&
+		checkAll(a.connections, a.connectionsCheckers, nil) &&
+		(a.encoding == nil || *a.encoding == a.contentEncoding) &&
 		checkAll(a.responseBody, a.bodyCheckers, nil) &&
-		checkAll(a.responseBody, a.jsonCheckers, nil)
+		checkAll(a.responseBody, a.jsonCheckers, nil) &&
+		(a.bodyFile == "" || a.bodyDiff.equal()) &&
+		checkAll(unquoteETag(a.responseHeader.Get("ETag")), a.etagCheckers, nil) &&
+		checkAll(a.responseHeader.Get("Alt-Svc"), a.altSvcCheckers, nil) &&
+		a.headersPass() &&
+		a.partsPass()
 }
 
 func (a *HTTPAssert) check() {
 	p := a.plan
 
+	done := a.tls.check(func(detail, field, expected string) {
+		msg := fmt.Sprintf("%s %s\n%s%s", p.method, p.url, detail, a.formatHelp())
+		panic(a.failure(msg, field, expected, ""))
+	})
+	if done || a.checkHTTP2() {
+		return
+	}
+
+	a.checkRedirects()
+
 	checkAll(a.responseStatus, a.statusCheckers, func(m Checker[int], actual int) {
-		msg := fmt.Sprintf("%s %s\n  Expected status: %s\n  Actual status: %d %s%s",
+		msg := fmt.Sprintf("%s %s\n  Expected status: %s\n  Actual status: %d %s%s%s",
 			p.method, p.url, m.Expected(), actual,
-			http.StatusText(actual), a.formatHelp())
-		panic(msg)
+			http.StatusText(actual), explain(m, actual), a.formatHelp())
+		panic(a.failure(msg, "status", m.Expected(), ""))
 	})
 
-	checkAll(a.responseBody, a.bodyCheckers, func(m Checker[string], actual string) {
-		msg := fmt.Sprintf("%s %s\n  Expected response: %s\n  Actual response: %q%s",
+	a.checkEncoding()
+
+	checkAll(a.protocol, a.protocolCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected protocol: %s\n  Actual protocol: %s%s",
 			p.method, p.url, m.Expected(), actual, a.formatHelp())
-		panic(msg)
+		panic(a.failure(msg, "protocol", m.Expected(), ""))
+	})
+
+	checkAll(a.latency, a.latencyCheckers, func(m Checker[time.Duration], actual time.Duration) {
+		msg := fmt.Sprintf("%s %s\n  Expected latency: %s\n  Actual latency: %s%s",
+			p.method, p.url, m.Expected(), actual.Round(time.Microsecond), a.formatHelp())
+		panic(a.failure(msg, "latency", m.Expected(), ""))
 	})
 
+	a.checkEvents()
+
+	checkAll(a.responseBody, a.bodyCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected response: %s\n  Actual response:%s%s%s",
+			p.method, p.url, m.Expected(), formatText(actual), explain(m, actual), a.formatHelp())
+		panic(a.failure(msg, "body", m.Expected(), ""))
+	})
+
+	if a.bodyFile != "" && !a.bodyDiff.equal() {
+		msg := fmt.Sprintf("%s %s\n%s%s", p.method, p.url, a.bodyDiff.describe("response"), a.formatHelp())
+		panic(a.failure(msg, "body", "fixture "+a.bodyFile, ""))
+	}
+
 	checkAll(a.responseBody, a.jsonCheckers, func(m Checker[string], actual string) {
-		msg := fmt.Sprintf("%s %s\n  Expected JSON: %s\n  Actual value: %v%s",
+		reason := explain(m, actual)
+
+		var jsonPath string
+		if field, ok := m.(JSONFieldChecker); ok {
+			jsonPath = field.path
+			actual = field.actual(actual)
+		}
+
+		msg := fmt.Sprintf("%s %s\n  Expected JSON: %s\n  Actual value: %v%s%s",
+			p.method, p.url, m.Expected(), actual, reason, a.formatHelp())
+		panic(a.failure(msg, "json", m.Expected(), jsonPath))
+	})
+
+	checkAll(unquoteETag(a.responseHeader.Get("ETag")), a.etagCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected ETag: %s\n  Actual ETag: %q%s",
 			p.method, p.url, m.Expected(), actual, a.formatHelp())
-		panic(msg)
+		panic(a.failure(msg, "etag", m.Expected(), ""))
 	})
+
+	checkAll(a.responseHeader.Get("Alt-Svc"), a.altSvcCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected Alt-Svc: %s\n  Actual Alt-Svc: %q%s",
+			p.method, p.url, m.Expected(), actual, a.formatHelp())
+		panic(a.failure(msg, "altsvc", m.Expected(), ""))
+	})
+
+	a.checkHeaders()
+	a.checkParts()
+
+	if a.objects != nil {
+		keys, err := listObjects(a.responseBody)
+		if err != nil {
+			msg := fmt.Sprintf("%s %s\n  Expected objects: %q\n  Actual response: %q (%v)%s",
+				p.method, p.url, a.objects, a.responseBody, err, a.formatHelp())
+			panic(a.failure(msg, "objects", fmt.Sprintf("%q", a.objects), ""))
+		}
+		if !slices.Equal(keys, a.objects) {
+			msg := fmt.Sprintf("%s %s\n  Expected objects: %q\n  Actual objects: %q%s",
+				p.method, p.url, a.objects, keys, a.formatHelp())
+			panic(a.failure(msg, "objects", fmt.Sprintf("%q", a.objects), ""))
+		}
+	}
+}
+
+// failure describes the failed expectation and the response that broke it.
+func (a *HTTPAssert) failure(msg, field, expected, jsonPath string) *HTTPFailure {
+	p := a.plan
+
+	return &HTTPFailure{
+		Message:  msg,
+		Process:  p.process,
+		Method:   p.method,
+		Path:     p.path,
+		Headers:  p.headers,
+		Body:     string(p.body),
+		BodyFile: p.bodyFile,
+		Field:    field,
+		JSONPath: jsonPath,
+		Expected: expected,
+		Status:   a.responseStatus,
+		Response: a.responseBody,
+	}
 }
 
 // CLIAssert provides CLI command output and exit code assertions.
@@ -188,40 +502,86 @@ type CLIAssert struct {
 
 	plan     *CLIPlan
 	output   string
+	stdout   string
+	stderr   string
+	combined string
 	exitCode int
+	state    string
+	signal   syscall.Signal
+	runtime  time.Duration
 
 	exitCheckers   []Checker[int]
 	outputCheckers []Checker[string]
+	stdoutCheckers []Checker[string]
+	stderrCheckers []Checker[string]
+	jsonCheckers   []Checker[string]
+	// jsonLines is whether stdout is JSON Lines rather than one document.
+	jsonLines  bool
+	killedBy   syscall.Signal
+	maxRuntime time.Duration
 }
 
-// ExitCode adds expected exit code checkers.
-// All checkers must pass.
+// ExitCode adds expected exit code checkers. The exit code is -1 if the
+// command was killed by a signal. All checkers must pass.
 func (a *CLIAssert) ExitCode(checkers ...Checker[int]) *CLIAssert {
 	a.exitCheckers = append(a.exitCheckers, checkers...)
 	return a
 }
 
-// Output adds expected command output checkers.
+// KilledBy expects the command to be terminated by sig, e.g. SIGPIPE when
+// its output is closed early. Being killed for running past the execute
+// timeout does not count.
+func (a *CLIAssert) KilledBy(sig syscall.Signal) *CLIAssert {
+	a.killedBy = sig
+	return a
+}
+
+// MaxRuntime expects the command to exit within d of starting.
+func (a *CLIAssert) MaxRuntime(d time.Duration) *CLIAssert {
+	a.maxRuntime = d
+	return a
+}
+
+// Output adds expected command output checkers. The output is stdout, or
+// stderr if the command failed; Stdout and Stderr check either one.
 // All checkers must pass.
 func (a *CLIAssert) Output(checkers ...Checker[string]) *CLIAssert {
 	a.outputCheckers = append(a.outputCheckers, checkers...)
 	return a
 }
 
+// OutputMatches expects the command output to match a regular expression,
+// with ^ and $ matching at line boundaries.
+func (a *CLIAssert) OutputMatches(pattern string) *CLIAssert {
+	a.outputCheckers = append(a.outputCheckers, matchesLines(pattern))
+	return a
+}
+
+// OutputContains expects the command output to contain every substring.
+func (a *CLIAssert) OutputContains(substrings ...string) *CLIAssert {
+	for _, substring := range substrings {
+		a.outputCheckers = append(a.outputCheckers, Contains(substring))
+	}
+
+	return a
+}
+
 func (a *CLIAssert) Assert(help string) {
 	a.help = help
 
 	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
 	switch p.timing {
 	case TimingEventually:
-		eventually(p.ctx, a.execute, p.timeout, a.config.RetryPollInterval)
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
 	case TimingConsistently:
-		consistently(p.ctx, a.execute, p.timeout, a.config.RetryPollInterval)
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
 	default:
 		a.execute()
 	}
 
-	a.check()
+	a.verify(p.ctx, a.check)
 }
 
 func (a *CLIAssert) execute() bool {
@@ -230,9 +590,30 @@ func (a *CLIAssert) execute() bool {
 	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	stdin, err := p.readStdin()
+	if err != nil {
+		panic(fmt.Sprintf("%s %s\n  Could not read stdin: %v", p.command, strings.Join(p.args, " "), err))
+	}
+
+	command, dir := commandIn(p.command, a.config.Dir, p.dir)
+	cmd := exec.CommandContext(ctx, command, p.args...)
+	cmd.Dir = dir
+	cmd.Env = a.config.environ(slices.Concat(p.harnessEnv, p.env))
+	cmd.Stdin = bytes.NewReader(stdin)
+	a.config.Recorder.record(Entry{Kind: EntryExec, Args: p.args, Env: p.env, Body: string(stdin)})
+
+	var streams outputStreams
+	cmd.Stdout, cmd.Stderr = streams.writer(&streams.stdout), streams.writer(&streams.stderr)
+
+	start := time.Now()
+	err = cmd.Run()
+	a.runtime = time.Since(start)
+	a.stdout, a.stderr, a.combined = streams.stdout.String(), streams.stderr.String(), streams.combined.String()
+	a.signal, a.state = 0, ""
+	if cmd.ProcessState != nil {
+		a.state = cmd.ProcessState.String()
+	}
 
-	stdout, err := cmd.Output()
 	if err != nil {
 		var exitError *exec.ExitError
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
@@ -242,34 +623,71 @@ func (a *CLIAssert) execute() bool {
 			a.output = fmt.Sprintf("%s was cancelled", p.command)
 			a.exitCode = -1
 		} else if errors.As(err, &exitError) {
-			a.output = string(exitError.Stderr)
+			a.output = a.stderr
 			a.exitCode = exitError.ExitCode()
+			if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				a.signal = status.Signal()
+			}
 		} else {
 			panic(err.Error())
 		}
 	} else {
-		a.output = string(stdout)
+		a.output = a.stdout
 		a.exitCode = 0
 	}
 
 	return checkAll(a.exitCode, a.exitCheckers, nil) &&
-		checkAll(a.output, a.outputCheckers, nil)
+		(a.killedBy == 0 || a.signal == a.killedBy) &&
+		(a.maxRuntime == 0 || a.runtime <= a.maxRuntime) &&
+		checkAll(a.output, a.outputCheckers, nil) &&
+		checkAll(a.stdout, a.stdoutCheckers, nil) &&
+		checkAll(a.stderr, a.stderrCheckers, nil) &&
+		(len(a.jsonCheckers) == 0 || a.invalidJSON() == "") &&
+		checkAll(a.stdout, a.jsonCheckers, nil)
+}
+
+// readStdin returns what to feed the command on standard input.
+func (p *CLIPlan) readStdin() ([]byte, error) {
+	if p.stdin != nil || p.stdinFile == "" {
+		return p.stdin, nil
+	}
+
+	return os.ReadFile(p.stdinFile)
 }
 
 func (a *CLIAssert) check() {
 	p := a.plan
 
+	if a.killedBy != 0 && a.signal != a.killedBy {
+		actual := a.state
+		if a.state == "" {
+			actual = a.output
+		}
+
+		msg := fmt.Sprintf("%s %s\n  Expected termination: %s\n  Actual: %s%s",
+			p.command, strings.Join(p.args, " "), signalName(a.killedBy), actual, a.formatHelp())
+		panic(msg)
+	}
+
+	if a.maxRuntime > 0 && a.runtime > a.maxRuntime {
+		msg := fmt.Sprintf("%s %s\n  Expected runtime: at most %s\n  Actual runtime: %s%s",
+			p.command, strings.Join(p.args, " "), a.maxRuntime, a.runtime.Round(time.Millisecond), a.formatHelp())
+		panic(msg)
+	}
+
 	checkAll(a.exitCode, a.exitCheckers, func(m Checker[int], actual int) {
-		msg := fmt.Sprintf("%s %s\n  Expected exit code: %s\n  Actual exit code: %d%s",
+		msg := fmt.Sprintf("%s %s\n  Expected exit code: %s\n  Actual exit code: %d%s%s%s",
 			p.command, strings.Join(p.args, " "), m.Expected(), actual,
-			a.formatHelp())
+			explain(m, actual), a.formatCombined(""), a.formatHelp())
 		panic(msg)
 	})
 
 	checkAll(a.output, a.outputCheckers, func(m Checker[string], actual string) {
-		msg := fmt.Sprintf("%s %s\n  Expected output: %s\n  Actual output: %q%s",
-			p.command, strings.Join(p.args, " "), m.Expected(), actual,
-			a.formatHelp())
+		msg := fmt.Sprintf("%s %s\n  Expected output: %s\n  Actual output:%s%s%s",
+			p.command, strings.Join(p.args, " "), m.Expected(), formatText(actual),
+			explain(m, actual), a.formatHelp())
 		panic(msg)
 	})
+
+	a.checkStreams()
 }
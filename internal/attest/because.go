@@ -0,0 +1,230 @@
+package attest
+
+import "strings"
+
+// setBecause records why the assertion matters.
+func (a *AssertBase) setBecause(reason string) {
+	a.because = reason
+}
+
+// setHint records what to try when the assertion fails.
+func (a *AssertBase) setHint(hint string) {
+	a.hint = hint
+}
+
+// formatReasons formats the Because and Hint lines of a failure message.
+func (a *AssertBase) formatReasons() string {
+	var b strings.Builder
+	if a.because != "" {
+		b.WriteString("\n  Because " + strings.ReplaceAll(a.because, "\n", "\n    "))
+	}
+	if a.hint != "" {
+		b.WriteString("\n  Hint: " + strings.ReplaceAll(a.hint, "\n", "\n    "))
+	}
+
+	return b.String()
+}
+
+// Because explains why the assertion matters, e.g. "the server must echo
+// the request ID header". It is shown on failure after expected and actual,
+// as are Because and Hint on every other assert.
+func (a *HTTPAssert) Because(reason string) *HTTPAssert {
+	a.setBecause(reason)
+	return a
+}
+
+// Hint suggests what to try when the assertion fails.
+func (a *HTTPAssert) Hint(hint string) *HTTPAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *CLIAssert) Because(reason string) *CLIAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *CLIAssert) Hint(hint string) *CLIAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *TCPAssert) Because(reason string) *TCPAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *TCPAssert) Hint(hint string) *TCPAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *UDPAssert) Because(reason string) *UDPAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *UDPAssert) Hint(hint string) *UDPAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *DNSAssert) Because(reason string) *DNSAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *DNSAssert) Hint(hint string) *DNSAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *WSAssert) Because(reason string) *WSAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *WSAssert) Hint(hint string) *WSAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *GRPCAssert) Because(reason string) *GRPCAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *GRPCAssert) Hint(hint string) *GRPCAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *GRPCStreamAssert) Because(reason string) *GRPCStreamAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *GRPCStreamAssert) Hint(hint string) *GRPCStreamAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *GraphQLAssert) Because(reason string) *GraphQLAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *GraphQLAssert) Hint(hint string) *GraphQLAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *PostgresAssert) Because(reason string) *PostgresAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *PostgresAssert) Hint(hint string) *PostgresAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *MQTTAssert) Because(reason string) *MQTTAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *MQTTAssert) Hint(hint string) *MQTTAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *AMQPAssert) Because(reason string) *AMQPAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *AMQPAssert) Hint(hint string) *AMQPAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *MemcachedAssert) Because(reason string) *MemcachedAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *MemcachedAssert) Hint(hint string) *MemcachedAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *S3MultipartAssert) Because(reason string) *S3MultipartAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *S3MultipartAssert) Hint(hint string) *S3MultipartAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *BenchAssert) Because(reason string) *BenchAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *BenchAssert) Hint(hint string) *BenchAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *GroupAssert) Because(reason string) *GroupAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *GroupAssert) Hint(hint string) *GroupAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *FileAssert) Because(reason string) *FileAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *FileAssert) Hint(hint string) *FileAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *DirAssert) Because(reason string) *DirAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *DirAssert) Hint(hint string) *DirAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *ProcessAssert) Because(reason string) *ProcessAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *ProcessAssert) Hint(hint string) *ProcessAssert {
+	a.setHint(hint)
+	return a
+}
+
+func (a *PTYAssert) Because(reason string) *PTYAssert {
+	a.setBecause(reason)
+	return a
+}
+
+func (a *PTYAssert) Hint(hint string) *PTYAssert {
+	a.setHint(hint)
+	return a
+}
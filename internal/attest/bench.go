@@ -0,0 +1,253 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// BenchPlan represents a benchmark that repeatedly runs an operation from
+// concurrent workers and measures throughput and latency.
+type BenchPlan struct {
+	ctx    context.Context
+	config *Config
+
+	fn       func()
+	workers  int
+	duration time.Duration
+	// title names the run in failures; empty uses "Benchmark".
+	title string
+	// cleanup runs after the workers stop, if set.
+	cleanup func()
+}
+
+// Workers sets the number of concurrent workers.
+func (p *BenchPlan) Workers(n int) *BenchPlan {
+	if n < 1 {
+		panic("Workers() requires at least one worker")
+	}
+
+	p.workers = n
+	return p
+}
+
+// Duration sets how long the benchmark runs.
+func (p *BenchPlan) Duration(d time.Duration) *BenchPlan {
+	p.duration = d
+	return p
+}
+
+func (p *BenchPlan) T() *BenchAssert {
+	return &BenchAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// BenchResult holds the measurements of a benchmark run.
+type BenchResult struct {
+	Requests int
+	Errors   int
+	Elapsed  time.Duration
+
+	latencies []time.Duration
+}
+
+// Throughput returns the number of successful operations per second.
+func (r *BenchResult) Throughput() float64 {
+	if r.Elapsed == 0 {
+		return 0
+	}
+
+	return float64(r.Requests-r.Errors) / r.Elapsed.Seconds()
+}
+
+// ErrorRate returns the fraction of operations that failed.
+func (r *BenchResult) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Percentile returns the latency at the given percentile (0-100).
+func (r *BenchResult) Percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(p/100*float64(len(r.latencies)))) - 1
+	i = max(0, min(i, len(r.latencies)-1))
+
+	return r.latencies[i]
+}
+
+func (r *BenchResult) String() string {
+	return fmt.Sprintf("%.0f ops/s, p50 %s, p95 %s, p99 %s, %.2f%% errors (%d ops in %s)",
+		r.Throughput(), r.Percentile(50), r.Percentile(95), r.Percentile(99), r.ErrorRate()*100,
+		r.Requests, r.Elapsed.Round(time.Millisecond))
+}
+
+// percentileChecker pairs a latency percentile with a checker for it.
+type percentileChecker struct {
+	percentile float64
+	checker    Checker[time.Duration]
+}
+
+// BenchAssert provides throughput, latency, and error rate assertions.
+type BenchAssert struct {
+	AssertBase
+
+	plan   *BenchPlan
+	result *BenchResult
+
+	throughputCheckers []Checker[float64]
+	errorRateCheckers  []Checker[float64]
+	latencyCheckers    []percentileChecker
+}
+
+// Throughput adds expected checkers for successful operations per second.
+// All checkers must pass.
+func (a *BenchAssert) Throughput(checkers ...Checker[float64]) *BenchAssert {
+	a.throughputCheckers = append(a.throughputCheckers, checkers...)
+	return a
+}
+
+// ErrorRate adds expected checkers for the fraction (0-1) of failed operations.
+// All checkers must pass.
+func (a *BenchAssert) ErrorRate(checkers ...Checker[float64]) *BenchAssert {
+	a.errorRateCheckers = append(a.errorRateCheckers, checkers...)
+	return a
+}
+
+// Latency adds expected checkers for the latency at the given percentile (0-100).
+// All checkers must pass.
+func (a *BenchAssert) Latency(percentile float64, checkers ...Checker[time.Duration]) *BenchAssert {
+	for _, checker := range checkers {
+		a.latencyCheckers = append(a.latencyCheckers, percentileChecker{percentile, checker})
+	}
+
+	return a
+}
+
+// Result returns the measurements of the last run.
+func (a *BenchAssert) Result() *BenchResult {
+	return a.result
+}
+
+func (a *BenchAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+
+	if !a.plan.config.Quiet {
+		fmt.Printf("  %s\n", a.result)
+	}
+}
+
+func (a *BenchAssert) execute() bool {
+	p := a.plan
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := &BenchResult{}
+
+	start := time.Now()
+	for range p.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				began := time.Now()
+				failed := benchCall(p.fn)
+				latency := time.Since(began)
+
+				mu.Lock()
+				result.Requests++
+				if failed {
+					result.Errors++
+				} else {
+					result.latencies = append(result.latencies, latency)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	if p.cleanup != nil {
+		p.cleanup()
+	}
+	slices.Sort(result.latencies)
+
+	a.result = result
+
+	if p.ctx.Err() != nil {
+		return false
+	}
+
+	for _, c := range a.latencyCheckers {
+		if !c.checker.Check(result.Percentile(c.percentile)) {
+			return false
+		}
+	}
+
+	return checkAll(result.Throughput(), a.throughputCheckers, nil) &&
+		checkAll(result.ErrorRate(), a.errorRateCheckers, nil)
+}
+
+// benchCall runs a single benchmark operation, reporting whether it panicked.
+func benchCall(fn func()) (failed bool) {
+	defer func() {
+		if recover() != nil {
+			failed = true
+		}
+	}()
+
+	fn()
+	return false
+}
+
+func (a *BenchAssert) check() {
+	p := a.plan
+	r := a.result
+	title := p.title
+	if title == "" {
+		title = "Benchmark"
+	}
+	title = fmt.Sprintf("%s (%d workers, %s)", title, p.workers, p.duration)
+
+	checkAll(r.ErrorRate(), a.errorRateCheckers, func(m Checker[float64], actual float64) {
+		msg := fmt.Sprintf("%s\n  Expected error rate: %s\n  Actual error rate: %.4f (%d of %d failed)%s",
+			title, m.Expected(), actual, r.Errors, r.Requests, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(r.Throughput(), a.throughputCheckers, func(m Checker[float64], actual float64) {
+		msg := fmt.Sprintf("%s\n  Expected throughput: %s ops/s\n  Actual throughput: %.1f ops/s%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	for _, c := range a.latencyCheckers {
+		actual := r.Percentile(c.percentile)
+		if !c.checker.Check(actual) {
+			msg := fmt.Sprintf("%s\n  Expected p%g latency: %s\n  Actual p%g latency: %s%s",
+				title, c.percentile, c.checker.Expected(), c.percentile, actual, a.formatHelp())
+			panic(msg)
+		}
+	}
+}
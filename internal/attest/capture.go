@@ -0,0 +1,124 @@
+package attest
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// variables holds values captured from responses, looked up by name. A
+// session's variables fall back to the test's.
+type variables struct {
+	mu     sync.Mutex
+	values map[string]string
+	parent *variables
+}
+
+// variablePattern matches a {{name}} reference to a variable.
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+func newVariables(parent *variables) *variables {
+	return &variables{values: make(map[string]string), parent: parent}
+}
+
+func (v *variables) set(name, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+}
+
+func (v *variables) get(name string) (string, bool) {
+	v.mu.Lock()
+	value, ok := v.values[name]
+	v.mu.Unlock()
+
+	if !ok && v.parent != nil {
+		return v.parent.get(name)
+	}
+
+	return value, ok
+}
+
+// value returns the value of a variable, panicking if it is not set.
+func (v *variables) value(name string) string {
+	value, ok := v.get(name)
+	if !ok {
+		panic(fmt.Sprintf("Variable %q is not set", name))
+	}
+
+	return value
+}
+
+// expand replaces references to variables in text with their values.
+// References to variables that are not set are left as they are, so text
+// can contain a literal {{...}}.
+func (v *variables) expand(text string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		if value, ok := v.get(variablePattern.FindStringSubmatch(ref)[1]); ok {
+			return value
+		}
+
+		return ref
+	})
+}
+
+// responseCapture saves part of a response into a variable.
+type responseCapture struct {
+	name string
+	// source is shown in a failure, e.g. "JSON field $.token".
+	source string
+	// extract returns the captured value, and whether it was found.
+	extract func(a *HTTPAssert) (string, bool)
+}
+
+// CaptureJSON saves the JSON field at the given gjson path or JSONPath into
+// the variable name once the assertion passed, e.g. an auth token. Later
+// plans of the test, or of the session, refer to it as {{name}}. Strings
+// are saved without quotes.
+func (a *HTTPAssert) CaptureJSON(name, path string) *HTTPAssert {
+	a.captures = append(a.captures, responseCapture{
+		name:   name,
+		source: "JSON field " + path,
+		extract: func(a *HTTPAssert) (string, bool) {
+			result := gjson.Get(a.responseBody, gjsonPath(path))
+			return result.String(), result.Exists()
+		},
+	})
+
+	return a
+}
+
+// CaptureHeader saves the response header key into the variable name once
+// the assertion passed, e.g. the Location of a created resource.
+func (a *HTTPAssert) CaptureHeader(name, key string) *HTTPAssert {
+	a.captures = append(a.captures, responseCapture{
+		name:   name,
+		source: "header " + key,
+		extract: func(a *HTTPAssert) (string, bool) {
+			values := a.responseHeader.Values(key)
+			if len(values) == 0 {
+				return "", false
+			}
+			return values[0], true
+		},
+	})
+
+	return a
+}
+
+// capture saves the captured values, failing if one is missing.
+func (a *HTTPAssert) capture() {
+	p := a.plan
+	for _, c := range a.captures {
+		value, ok := c.extract(a)
+		if !ok {
+			msg := fmt.Sprintf("%s %s\n  Could not capture %s\n  Expected: %s\n  Actual response:%s%s",
+				p.method, p.url, c.name, c.source, formatText(a.responseBody), a.formatHelp())
+			panic(msg)
+		}
+
+		p.vars.set(c.name, value)
+	}
+}
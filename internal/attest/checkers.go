@@ -1,6 +1,7 @@
 package attest
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -80,6 +81,24 @@ func (m containsChecker) Expected() string {
 	return fmt.Sprintf("containing %q", m.substring)
 }
 
+// hasPrefixChecker validates that a string starts with a prefix.
+type hasPrefixChecker struct {
+	prefix string
+}
+
+// HasPrefix creates a checker that checks if actual starts with prefix.
+func HasPrefix(prefix string) hasPrefixChecker {
+	return hasPrefixChecker{prefix: prefix}
+}
+
+func (m hasPrefixChecker) Check(actual string) bool {
+	return strings.HasPrefix(actual, m.prefix)
+}
+
+func (m hasPrefixChecker) Expected() string {
+	return fmt.Sprintf("starting with %q", m.prefix)
+}
+
 // matchesChecker validates that a string matches a regex pattern.
 type matchesChecker struct {
 	pattern *regexp.Regexp
@@ -96,6 +115,13 @@ func Matches(pattern string) matchesChecker {
 	return matchesChecker{pattern: compiled, raw: pattern}
 }
 
+// matchesLines is Matches with ^ and $ matching at line boundaries.
+func matchesLines(pattern string) matchesChecker {
+	m := Matches("(?m)" + pattern)
+	m.raw = pattern
+	return m
+}
+
 func (m matchesChecker) Check(actual string) bool {
 	return m.pattern.MatchString(actual)
 }
@@ -162,6 +188,42 @@ func (m oneOfChecker[T]) Expected() string {
 	return fmt.Sprintf("one of [%v, %v, %v, ... and %d more]", m.values[0], m.values[1], m.values[2], len(m.values)-3)
 }
 
+// atLeastChecker validates that a value is greater than or equal to a bound.
+type atLeastChecker[T cmp.Ordered] struct {
+	bound T
+}
+
+// AtLeast creates a checker that accepts values greater than or equal to bound.
+func AtLeast[T cmp.Ordered](bound T) atLeastChecker[T] {
+	return atLeastChecker[T]{bound: bound}
+}
+
+func (m atLeastChecker[T]) Check(actual T) bool {
+	return actual >= m.bound
+}
+
+func (m atLeastChecker[T]) Expected() string {
+	return fmt.Sprintf("at least %v", m.bound)
+}
+
+// atMostChecker validates that a value is less than or equal to a bound.
+type atMostChecker[T cmp.Ordered] struct {
+	bound T
+}
+
+// AtMost creates a checker that accepts values less than or equal to bound.
+func AtMost[T cmp.Ordered](bound T) atMostChecker[T] {
+	return atMostChecker[T]{bound: bound}
+}
+
+func (m atMostChecker[T]) Check(actual T) bool {
+	return actual <= m.bound
+}
+
+func (m atMostChecker[T]) Expected() string {
+	return fmt.Sprintf("at most %v", m.bound)
+}
+
 // notChecker negates another checker.
 type notChecker[T any] struct {
 	checker Checker[T]
@@ -180,40 +242,234 @@ func (m notChecker[T]) Expected() string {
 	return fmt.Sprintf("not %s", m.checker.Expected())
 }
 
+// existsChecker validates that a JSON value is present.
+type existsChecker struct{}
+
+// Exists creates a checker that accepts any JSON value, including null.
+// Not(Exists()) checks that a JSON field is absent.
+func Exists() existsChecker {
+	return existsChecker{}
+}
+
+func (m existsChecker) Check(actual string) bool {
+	return m.checkResult(gjson.Parse(actual))
+}
+
+func (m existsChecker) checkResult(result gjson.Result) bool {
+	return result.Exists()
+}
+
+func (m existsChecker) Expected() string {
+	return "present"
+}
+
+// isTypeChecker validates the type of a JSON value.
+type isTypeChecker struct {
+	kind string
+}
+
+// IsType creates a checker that validates the type of a JSON value: one of
+// "string", "number", "boolean", "null", "array" or "object".
+func IsType(kind string) isTypeChecker {
+	switch kind {
+	case "string", "number", "boolean", "null", "array", "object":
+	default:
+		panic(fmt.Sprintf("invalid JSON type %q", kind))
+	}
+
+	return isTypeChecker{kind: kind}
+}
+
+func (m isTypeChecker) Check(actual string) bool {
+	return m.checkResult(gjson.Parse(actual))
+}
+
+func (m isTypeChecker) checkResult(result gjson.Result) bool {
+	return result.Exists() && jsonType(result) == m.kind
+}
+
+func (m isTypeChecker) Expected() string {
+	return "of type " + m.kind
+}
+
+// jsonType returns the JSON type name of a value.
+func jsonType(result gjson.Result) string {
+	switch {
+	case result.IsArray():
+		return "array"
+	case result.IsObject():
+		return "object"
+	case result.IsBool():
+		return "boolean"
+	case result.Type == gjson.String:
+		return "string"
+	case result.Type == gjson.Number:
+		return "number"
+	default:
+		return "null"
+	}
+}
+
 // JSONFieldChecker pairs a gjson path with a checker for that field.
 type JSONFieldChecker struct {
 	path    string
+	display string
 	checker Checker[string]
 }
 
 // JSON creates a checker that extracts a JSON field at the given path and validates it.
+// The path uses gjson syntax, e.g. items.0.id, or JSONPath, e.g. $.items[0].id.
 func JSON(path string, checker Checker[string]) JSONFieldChecker {
-	return JSONFieldChecker{path: path, checker: checker}
+	return JSONFieldChecker{path: gjsonPath(path), display: path, checker: checker}
 }
 
 func (m JSONFieldChecker) Check(actual string) bool {
-	result := gjson.Get(actual, m.path)
-
-	switch m.checker.(type) {
+	return checkJSONResult(m.checker, gjson.Get(actual, m.path))
+}
+
+// checkJSONResult validates the value found at a JSON path.
+func checkJSONResult(checker Checker[string], result gjson.Result) bool {
+	switch checker := checker.(type) {
+	case existsChecker:
+		return checker.checkResult(result)
+	case isTypeChecker:
+		return checker.checkResult(result)
+	case notChecker[string]:
+		switch checker.checker.(type) {
+		case existsChecker, isTypeChecker:
+			return !checkJSONResult(checker.checker, result)
+		}
 	case isNullChecker[string]:
 		return result.Type == gjson.Null
 	case hasLenChecker[string]:
-		// For length checks, we need the actual Go value
-		checker := m.checker.(hasLenChecker[string])
-		value := result.Value()
-		return reflect.ValueOf(value).Len() == checker.length
-	default:
-		// Most checkers work on the string representation
-		if result.Type == gjson.Null {
+		// Length counts array elements, object fields, or string characters
+		switch {
+		case result.IsArray():
+			return len(result.Array()) == checker.length
+		case result.IsObject():
+			return len(result.Map()) == checker.length
+		case result.Type == gjson.String:
+			return len(result.Str) == checker.length
+		default:
 			return false
 		}
+	}
 
-		return m.checker.Check(result.String())
+	// Most checkers work on the string representation
+	if result.Type == gjson.Null {
+		return false
 	}
+
+	return checker.Check(result.String())
 }
 
 func (m JSONFieldChecker) Expected() string {
-	return fmt.Sprintf("field %s: %s", m.path, m.checker.Expected())
+	return fmt.Sprintf("field %s: %s", m.display, m.checker.Expected())
+}
+
+func (m JSONFieldChecker) explain(actual string) string {
+	e, ok := m.checker.(explainer[string])
+	if !ok {
+		return ""
+	}
+
+	return e.explain(gjson.Get(actual, m.path).Raw)
+}
+
+// actual returns the raw JSON at the checker's path in body.
+func (m JSONFieldChecker) actual(body string) string {
+	result := gjson.Get(body, m.path)
+	if !result.Exists() {
+		return "(missing)"
+	}
+
+	return result.Raw
+}
+
+// gjsonPath converts a JSONPath such as $.items[0].id or $['a.b'][*] to
+// gjson syntax. Other paths are returned unchanged.
+func gjsonPath(path string) string {
+	if !strings.HasPrefix(path, "$") {
+		return path
+	}
+
+	var parts []string
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				panic(fmt.Sprintf("invalid JSONPath %q", path))
+			}
+
+			index := rest[1:end]
+			switch {
+			case index == "*":
+				parts = append(parts, "#")
+			case len(index) >= 2 && (index[0] == '\'' || index[0] == '"') && index[len(index)-1] == index[0]:
+				parts = append(parts, escapeGJSON(index[1:len(index)-1]))
+			default:
+				parts = append(parts, index)
+			}
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if name := rest[:end]; name == "*" {
+				parts = append(parts, "#")
+			} else {
+				parts = append(parts, escapeGJSON(name))
+			}
+			rest = rest[end:]
+		default:
+			panic(fmt.Sprintf("invalid JSONPath %q", path))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "@this"
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// escapeGJSON escapes the characters gjson treats as path syntax.
+func escapeGJSON(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(".*?|#@\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// explainer is implemented by checkers that can say why a value failed.
+type explainer[T any] interface {
+	explain(actual T) string
+}
+
+// explain returns the checker's reason that actual failed, as an indented
+// line for a failure message, or "" if it gives none.
+func explain[T any](checker Checker[T], actual T) string {
+	e, ok := checker.(explainer[T])
+	if !ok {
+		return ""
+	}
+
+	reason := e.explain(actual)
+	if reason == "" {
+		return ""
+	}
+
+	return "\n  " + reason
 }
 
 // checkAll returns true if all checkers pass for the given value.
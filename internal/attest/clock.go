@@ -0,0 +1,88 @@
+package attest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ClockEnv is the environment variable naming the virtual clock file when
+// Config.VirtualClock is set. The file holds the current time in Unix
+// milliseconds; an implementation reads it instead of the system clock,
+// e.g. to expire keys, so a test can Advance minutes without waiting.
+const ClockEnv = "LC_CLOCK_FILE"
+
+// virtualClock is the time the implementation is told it is.
+type virtualClock struct {
+	mu   sync.Mutex
+	path string
+	now  time.Time
+}
+
+// newVirtualClock creates a clock file in dir set to the current time.
+func newVirtualClock(dir string) *virtualClock {
+	c := &virtualClock{
+		path: filepath.Join(dir, "clock"),
+		now:  time.Now().Truncate(time.Millisecond),
+	}
+	c.write()
+
+	return c
+}
+
+// write replaces the clock file, so readers never see a partial time.
+func (c *virtualClock) write() {
+	tmp := c.path + ".tmp"
+	err := os.WriteFile(tmp, []byte(strconv.FormatInt(c.now.UnixMilli(), 10)), 0644)
+	if err == nil {
+		err = os.Rename(tmp, c.path)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("failed to write clock file: %v", err))
+	}
+}
+
+// Advance moves the virtual clock forward by d, e.g. past a key's TTL.
+// Tests that advance it should not run in parallel.
+func (do *Do) Advance(d time.Duration) {
+	c := do.virtualClock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.write()
+}
+
+// Now returns the virtual clock's time, e.g. to check an expiry timestamp.
+func (do *Do) Now() time.Time {
+	c := do.virtualClock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// virtualClock returns the run's clock or panics if it has none.
+func (do *Do) virtualClock() *virtualClock {
+	if do.clock == nil {
+		panic("the virtual clock needs Config.VirtualClock")
+	}
+
+	return do.clock
+}
+
+// harnessEnv returns the environment the harness gives every process and
+// command: the upstream addresses and the clock file.
+func (do *Do) harnessEnv() []string {
+	env := do.upstreamEnv()
+	if do.clock != nil {
+		env = append(env, ClockEnv+"="+do.clock.path)
+	}
+
+	return env
+}
@@ -0,0 +1,306 @@
+package attest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+var _ Plan[*ClusterPlan, *ClusterAssert] = (*ClusterPlan)(nil)
+var _ Assert = (*ClusterAssert)(nil)
+
+// ClusterPlan sends the same HTTP request to every node of a cluster at
+// once and checks their answers as a whole, e.g. that a quorum agrees on a
+// value or that exactly one node is the leader.
+type ClusterPlan struct {
+	PlanBase
+
+	do     *Do
+	nodes  []string
+	method string
+	path   string
+	args   []any
+	// valuePath is the JSON field compared instead of the body, if set.
+	valuePath string
+}
+
+// Cluster creates a test plan for the nodes, by process name.
+func (do *Do) Cluster(nodes ...string) *ClusterPlan {
+	if len(nodes) == 0 {
+		panic("Cluster() requires at least one node")
+	}
+
+	return &ClusterPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		do:     do,
+		nodes:  nodes,
+		method: "GET",
+		path:   "/",
+	}
+}
+
+// HTTP sets the request sent to each node, with a body and headers as for
+// Do.HTTP. It is GET / by default.
+func (p *ClusterPlan) HTTP(method, path string, args ...any) *ClusterPlan {
+	p.method, p.path, p.args = method, path, args
+	return p
+}
+
+// Value compares the JSON field at path, in gjson syntax or JSONPath,
+// instead of the whole body, e.g. "role" or "$.term".
+func (p *ClusterPlan) Value(path string) *ClusterPlan {
+	p.valuePath = gjsonPath(path)
+	return p
+}
+
+func (p *ClusterPlan) Eventually() *ClusterPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *ClusterPlan) Within(timeout time.Duration) *ClusterPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *ClusterPlan) Retry(policy RetryPolicy) *ClusterPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *ClusterPlan) Consistently() *ClusterPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *ClusterPlan) For(timeout time.Duration) *ClusterPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *ClusterPlan) Every(interval time.Duration) *ClusterPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *ClusterPlan) Deadline(d time.Duration) *ClusterPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *ClusterPlan) T() *ClusterAssert {
+	return &ClusterAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// nodeAnswer is what a node answered, or why it did not.
+type nodeAnswer struct {
+	value string
+	err   string
+}
+
+// ClusterAssert provides assertions on the answers of a cluster's nodes.
+// A node answers with a 2xx response; others, and nodes that cannot be
+// reached, count as not answering.
+type ClusterAssert struct {
+	AssertBase
+
+	plan    *ClusterPlan
+	answers []nodeAnswer
+
+	quorumCheckers [][]Checker[string]
+	oneCheckers    []Checker[string]
+	// leader is the node that passed ExactlyOne, if one did.
+	leader string
+}
+
+// Quorum expects a majority of the nodes, down ones included, to answer
+// with the same value, passing all checkers.
+func (a *ClusterAssert) Quorum(checkers ...Checker[string]) *ClusterAssert {
+	a.quorumCheckers = append(a.quorumCheckers, checkers)
+	return a
+}
+
+// ExactlyOne expects exactly one node to answer with a value passing
+// checker, e.g. Is("leader"), which Leader then returns.
+func (a *ClusterAssert) ExactlyOne(checker Checker[string]) *ClusterAssert {
+	a.oneCheckers = append(a.oneCheckers, checker)
+	return a
+}
+
+// Leader returns the node that passed ExactlyOne in the last execution,
+// e.g. to send it a write or kill it.
+func (a *ClusterAssert) Leader() string {
+	return a.leader
+}
+
+func (a *ClusterAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *ClusterAssert) execute() bool {
+	p := a.plan
+	a.answers = make([]nodeAnswer, len(p.nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range p.nodes {
+		wg.Go(func() {
+			a.answers[i] = p.ask(node)
+		})
+	}
+	wg.Wait()
+
+	return a.passes()
+}
+
+// ask sends the plan's request to node.
+func (p *ClusterPlan) ask(node string) (answer nodeAnswer) {
+	defer func() {
+		if err := recover(); err != nil {
+			answer = nodeAnswer{err: strings.TrimPrefix(firstLine(fmt.Sprint(err)), "An error occurred: ")}
+		}
+	}()
+
+	hp := p.do.http(p.do.vars, node, p.method, p.path, p.args...)
+	hp.ctx = p.ctx
+	ha := hp.T()
+	ha.execute()
+
+	if ha.responseStatus < 200 || ha.responseStatus > 299 {
+		return nodeAnswer{err: fmt.Sprintf("status %d", ha.responseStatus)}
+	}
+	if p.valuePath == "" {
+		return nodeAnswer{value: ha.responseBody}
+	}
+
+	result := gjson.Get(ha.responseBody, p.valuePath)
+	if !result.Exists() {
+		return nodeAnswer{err: "no field " + p.valuePath}
+	}
+
+	return nodeAnswer{value: result.String()}
+}
+
+// majority returns the value most nodes answered with and how many did.
+func (a *ClusterAssert) majority() (string, int) {
+	counts := make(map[string]int)
+	var value string
+	for _, answer := range a.answers {
+		if answer.err != "" {
+			continue
+		}
+
+		counts[answer.value]++
+		if counts[answer.value] > counts[value] {
+			value = answer.value
+		}
+	}
+
+	return value, counts[value]
+}
+
+// quorum returns how many nodes make a majority.
+func (a *ClusterAssert) quorum() int {
+	return len(a.plan.nodes)/2 + 1
+}
+
+// matching returns the nodes whose answer passes checker.
+func (a *ClusterAssert) matching(checker Checker[string]) []string {
+	var nodes []string
+	for i, answer := range a.answers {
+		if answer.err == "" && checker.Check(answer.value) {
+			nodes = append(nodes, a.plan.nodes[i])
+		}
+	}
+
+	return nodes
+}
+
+// passes reports whether the answers meet every expectation.
+func (a *ClusterAssert) passes() bool {
+	a.leader = ""
+
+	value, count := a.majority()
+	for _, checkers := range a.quorumCheckers {
+		if count < a.quorum() || !checkAll(value, checkers, nil) {
+			return false
+		}
+	}
+
+	for _, checker := range a.oneCheckers {
+		nodes := a.matching(checker)
+		if len(nodes) != 1 {
+			return false
+		}
+		a.leader = nodes[0]
+	}
+
+	return true
+}
+
+// formatAnswers lists what each node answered.
+func (a *ClusterAssert) formatAnswers() string {
+	var b strings.Builder
+	for i, answer := range a.answers {
+		if answer.err != "" {
+			fmt.Fprintf(&b, "\n    %s: no answer (%s)", a.plan.nodes[i], answer.err)
+			continue
+		}
+		fmt.Fprintf(&b, "\n    %s: %q", a.plan.nodes[i], answer.value)
+	}
+
+	return b.String()
+}
+
+func (a *ClusterAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("Cluster %s %s on %s", p.method, p.path, strings.Join(p.nodes, ", "))
+
+	value, count := a.majority()
+	for _, checkers := range a.quorumCheckers {
+		if count < a.quorum() {
+			msg := fmt.Sprintf("%s\n  Expected a quorum of %d nodes to agree\n  Actual answers:%s%s",
+				title, a.quorum(), a.formatAnswers(), a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(value, checkers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected quorum value: %s\n  Actual quorum value: %q%s\n  Answers:%s%s",
+				title, m.Expected(), actual, explain(m, actual), a.formatAnswers(), a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	for _, checker := range a.oneCheckers {
+		nodes := a.matching(checker)
+		if len(nodes) != 1 {
+			msg := fmt.Sprintf("%s\n  Expected exactly one node answering: %s\n  Actual nodes answering: %d\n  Answers:%s%s",
+				title, checker.Expected(), len(nodes), a.formatAnswers(), a.formatHelp())
+			panic(msg)
+		}
+	}
+}
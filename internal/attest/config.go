@@ -1,6 +1,10 @@
 package attest
 
-import "time"
+import (
+	"io/fs"
+	"os"
+	"time"
+)
 
 // Config holds configuration options for the test framework.
 type Config struct {
@@ -8,8 +12,17 @@ type Config struct {
 	Command string
 
 	// WorkingDir is the base directory for test runs.
+	// When Dir is set, a relative WorkingDir is inside Dir.
 	WorkingDir string
 
+	// Dir is the project directory processes run in.
+	// Empty uses the current directory.
+	Dir string
+
+	// Env holds KEY=VALUE pairs added to the environment of every process
+	// and command, e.g. LOG_LEVEL=debug.
+	Env []string
+
 	// ProcessStartTimeout for process startup.
 	ProcessStartTimeout time.Duration
 	// ProcessShutdownTimeout for process shutdown.
@@ -21,9 +34,70 @@ type Config struct {
 	DefaultRetryTimeout time.Duration
 	// RetryPollInterval for Eventually and Consistently operations.
 	RetryPollInterval time.Duration
+	// RetryPolicy is the default retry policy for Eventually, e.g. to back
+	// off exponentially. The zero value polls every RetryPollInterval.
+	RetryPolicy RetryPolicy
+	// TimeoutScale multiplies DefaultRetryTimeout and the timeouts set with
+	// Within and For. Zero leaves them unchanged.
+	TimeoutScale float64
 
 	// ExecuteTimeout for HTTP client requests.
 	ExecuteTimeout time.Duration
+	// PlanDeadline bounds every assertion, retries included, unless the
+	// plan sets its own Deadline. Zero leaves assertions unbounded.
+	PlanDeadline time.Duration
+
+	// Verbose prints additional details such as the log directory.
+	Verbose bool
+	// Quiet prints a single line per test and a one-line summary.
+	Quiet bool
+
+	// KeepGoing runs the remaining tests after a failing one instead of
+	// stopping the run there.
+	KeepGoing bool
+
+	// Parallel is the maximum number of parallel tests run at once.
+	// Values below two run every test sequentially.
+	Parallel int
+
+	// Recorder captures the traffic sent during the run, if set.
+	Recorder *Recorder
+
+	// Seed for the run's random generator. Zero picks a random seed.
+	Seed uint64
+
+	// Golden holds the golden files compared by Golden assertions, e.g. a
+	// challenge's embedded testdata.
+	Golden fs.FS
+	// GoldenDir is a directory of golden files read instead of Golden.
+	GoldenDir string
+	// UpdateGolden writes actual values to the golden files in GoldenDir
+	// instead of comparing them.
+	UpdateGolden bool
+
+	// VirtualClock gives every process and command a clock file, named in
+	// LC_CLOCK_FILE, that tests move forward with Advance.
+	VirtualClock bool
+
+	// Fixtures holds the files that BodyFile, SendFile and ReceivedFile
+	// stream, e.g. a challenge's embedded testdata.
+	Fixtures fs.FS
+}
+
+// environ returns the environment for a process or command: the harness's
+// own, then Env, then extra. Later values win.
+func (c *Config) environ(extra []string) []string {
+	env := append(os.Environ(), c.Env...)
+	return append(env, extra...)
+}
+
+// scaled applies TimeoutScale to a retry timeout.
+func (c *Config) scaled(timeout time.Duration) time.Duration {
+	if c.TimeoutScale == 0 {
+		return timeout
+	}
+
+	return time.Duration(float64(timeout) * c.TimeoutScale)
 }
 
 // DefaultConfig returns the default configuration.
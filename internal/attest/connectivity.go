@@ -0,0 +1,80 @@
+package attest
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// Refused expects the connection to be refused, e.g. after the server shut
+// down. Use it with Consistently to check the port stays closed.
+func (a *TCPAssert) Refused() *TCPAssert {
+	a.refused = true
+	return a
+}
+
+// NotListening expects nothing to accept the connection, whether it is
+// refused or the connect times out, e.g. a port the server must not bind.
+func (a *TCPAssert) NotListening() *TCPAssert {
+	a.notListening = true
+	return a
+}
+
+// ClosedWithin expects the server to close the connection within d of the
+// last write, whatever it sent first, e.g. an idle timeout or a rejected
+// handshake. A reset counts as closed.
+func (a *TCPAssert) ClosedWithin(d time.Duration) *TCPAssert {
+	a.closed = true
+	a.closedWithin = d
+	return a
+}
+
+// expectsNoConnection reports whether the connection is expected to fail.
+func (a *TCPAssert) expectsNoConnection() bool {
+	return a.refused || a.notListening
+}
+
+// dialPasses reports whether the outcome of connecting meets a Refused or
+// NotListening expectation.
+func (a *TCPAssert) dialPasses(err error) bool {
+	if a.refused {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+
+	return err != nil
+}
+
+// checkDial fails unless connecting failed as expected.
+func (a *TCPAssert) checkDial(title string) {
+	expected := "nothing listening"
+	if a.refused {
+		expected = "connection refused"
+	}
+
+	if !a.connected && a.dialPasses(a.err) {
+		return
+	}
+
+	actual := "connected"
+	if !a.connected {
+		actual = a.err.Error()
+	}
+
+	panic(fmt.Sprintf("%s\n  Expected: %s\n  Actual: %s%s", title, expected, actual, a.formatHelp()))
+}
+
+// TCPPort creates a test plan for a raw TCP exchange with a local port that
+// is not a process's, e.g. to check a server does not bind it.
+func (do *Do) TCPPort(port int) *TCPPlan {
+	return &TCPPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		addr:  fmt.Sprintf("127.0.0.1:%d", port),
+		marks: do.marks,
+	}
+}
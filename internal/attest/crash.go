@@ -0,0 +1,282 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/tidwall/gjson"
+)
+
+var _ Assert = (*CrashAssert)(nil)
+
+// defaultCrashWrites is how many writes a crash plan sends by default.
+const defaultCrashWrites = 100
+
+// maxLostShown bounds the lost writes listed in a failure.
+const maxLostShown = 10
+
+// CrashPlan sends writes to a process, kills it with SIGKILL while they are
+// still being sent, restarts it, and checks that every write it
+// acknowledged can be read back, e.g. that a write-ahead log is replayed.
+type CrashPlan struct {
+	ctx    context.Context
+	config *Config
+
+	do        *Do
+	process   string
+	writes    int
+	killAfter int
+	write     crashRequest
+	read      crashRequest
+	// valuePath is the JSON field read back instead of the body, if set.
+	valuePath string
+}
+
+// crashRequest is a request a crash plan sends for each write.
+type crashRequest struct {
+	method string
+	path   string
+	args   []any
+}
+
+// Crash creates a crash plan for a process, which must have been started.
+func (do *Do) Crash(name string) *CrashPlan {
+	return &CrashPlan{
+		ctx:     do.ctx,
+		config:  do.config,
+		do:      do,
+		process: name,
+		writes:  defaultCrashWrites,
+	}
+}
+
+// Write sets the request sending each write, with a body and headers as
+// for Do.HTTP. {{i}} refers to the write's number, from 1, e.g.
+// Write("PUT", "/kv/key-{{i}}", "value-{{i}}"). A 2xx response
+// acknowledges the write.
+func (p *CrashPlan) Write(method, path string, args ...any) *CrashPlan {
+	if len(args) == 0 {
+		panic("Write() requires a body to read back")
+	}
+
+	p.write = crashRequest{method: method, path: path, args: args}
+	return p
+}
+
+// Read sets the request reading each write back, e.g. GET /kv/key-{{i}}.
+// It must answer with the write's body.
+func (p *CrashPlan) Read(method, path string, args ...any) *CrashPlan {
+	p.read = crashRequest{method: method, path: path, args: args}
+	return p
+}
+
+// Value compares the JSON field at path, in gjson syntax or JSONPath,
+// with the write's body instead of the whole response.
+func (p *CrashPlan) Value(path string) *CrashPlan {
+	p.valuePath = gjsonPath(path)
+	return p
+}
+
+// Writes sets how many writes to send, 100 by default.
+func (p *CrashPlan) Writes(n int) *CrashPlan {
+	if n < 1 {
+		panic("Writes() requires at least one write")
+	}
+
+	p.writes = n
+	return p
+}
+
+// KillAfter kills the process once n writes were acknowledged, half of
+// them by default.
+func (p *CrashPlan) KillAfter(n int) *CrashPlan {
+	if n < 1 {
+		panic("KillAfter() requires at least one write")
+	}
+
+	p.killAfter = n
+	return p
+}
+
+func (p *CrashPlan) T() *CrashAssert {
+	return &CrashAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// lostWrite is an acknowledged write that could not be read back.
+type lostWrite struct {
+	i        int
+	expected string
+	actual   string
+}
+
+// CrashAssert checks that acknowledged writes survive a crash.
+type CrashAssert struct {
+	AssertBase
+
+	plan  *CrashPlan
+	acked []int
+	lost  []lostWrite
+}
+
+func (a *CrashAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+}
+
+func (a *CrashAssert) execute() bool {
+	p := a.plan
+	if p.write.method == "" || p.read.method == "" {
+		panic("Crash() requires Write() and Read()")
+	}
+
+	proc := p.do.getProcess(p.process)
+	if proc.cmd == nil {
+		panic(fmt.Sprintf("process %q was not started, so it cannot be killed", p.process))
+	}
+
+	a.acked, a.lost = nil, nil
+
+	var mu sync.Mutex
+	var stopped atomic.Bool
+	reached := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 1; i <= p.writes && !stopped.Load() && p.ctx.Err() == nil; i++ {
+			if _, err := p.send(p.write, i); err != "" {
+				continue
+			}
+
+			mu.Lock()
+			a.acked = append(a.acked, i)
+			if len(a.acked) == p.killLimit() {
+				close(reached)
+			}
+			mu.Unlock()
+		}
+	}()
+
+	select {
+	case <-reached:
+	case <-done:
+	case <-p.ctx.Done():
+	}
+
+	// Writes still in flight are cut off by the kill, as in a crash
+	stopped.Store(true)
+	p.do.Restart(p.process, syscall.SIGKILL)
+	<-done
+
+	if len(a.acked) < p.killLimit() {
+		return false
+	}
+
+	for _, i := range a.acked {
+		expected := p.expected(i)
+		actual, err := p.send(p.read, i)
+		if err == "" && p.valuePath != "" {
+			actual, err = p.value(actual)
+		}
+		if err != "" {
+			a.lost = append(a.lost, lostWrite{i: i, expected: expected, actual: "no answer (" + err + ")"})
+			continue
+		}
+		if actual != expected {
+			a.lost = append(a.lost, lostWrite{i: i, expected: expected, actual: fmt.Sprintf("%q", actual)})
+		}
+	}
+
+	return len(a.lost) == 0
+}
+
+// killLimit returns how many acknowledged writes to kill the process after.
+func (p *CrashPlan) killLimit() int {
+	if p.killAfter > 0 {
+		return min(p.killAfter, p.writes)
+	}
+
+	return max(p.writes/2, 1)
+}
+
+// vars returns the variables of the i'th write.
+func (p *CrashPlan) vars(i int) *variables {
+	vars := newVariables(p.do.vars)
+	vars.set("i", strconv.Itoa(i))
+	return vars
+}
+
+// expected returns the body of the i'th write.
+func (p *CrashPlan) expected(i int) string {
+	return p.vars(i).expand(p.write.args[0].(string))
+}
+
+// send sends the request of the i'th write and returns the response's
+// body, or why it was not answered with a 2xx.
+func (p *CrashPlan) send(req crashRequest, i int) (body, failure string) {
+	defer func() {
+		if err := recover(); err != nil {
+			failure = strings.TrimPrefix(firstLine(fmt.Sprint(err)), "An error occurred: ")
+		}
+	}()
+
+	hp := p.do.http(p.vars(i), p.process, req.method, req.path, req.args...)
+	hp.ctx = p.ctx
+	ha := hp.T()
+	ha.execute()
+
+	if ha.responseStatus < 200 || ha.responseStatus > 299 {
+		return "", fmt.Sprintf("status %d", ha.responseStatus)
+	}
+
+	return ha.responseBody, ""
+}
+
+// value returns the JSON field Value compares in body.
+func (p *CrashPlan) value(body string) (value, failure string) {
+	result := gjson.Get(body, p.valuePath)
+	if !result.Exists() {
+		return "", "no field " + p.valuePath
+	}
+
+	return result.String(), ""
+}
+
+func (a *CrashAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("Crash %s after %d of %d writes", p.process, p.killLimit(), p.writes)
+
+	if len(a.acked) < p.killLimit() {
+		panic(fmt.Sprintf("%s\n  Expected: %d acknowledged writes before the kill\n  Actual: %d acknowledged%s",
+			title, p.killLimit(), len(a.acked), a.formatHelp()))
+	}
+
+	if len(a.lost) == 0 {
+		return
+	}
+
+	var lost strings.Builder
+	for _, w := range a.lost[:min(len(a.lost), maxLostShown)] {
+		fmt.Fprintf(&lost, "\n    write %d: expected %q, actual %s", w.i, w.expected, w.actual)
+	}
+	if len(a.lost) > maxLostShown {
+		fmt.Fprintf(&lost, "\n    ... and %d more", len(a.lost)-maxLostShown)
+	}
+
+	panic(fmt.Sprintf("%s\n  Expected: all %d acknowledged writes readable after restart\n  Actual: %d lost:%s%s",
+		title, len(a.acked), len(a.lost), lost.String(), a.formatHelp()))
+}
@@ -0,0 +1,65 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// setDeadline bounds the whole assertion, retries included.
+func (b *PlanBase) setDeadline(d time.Duration) {
+	if d <= 0 {
+		panic("Deadline() requires a positive duration")
+	}
+
+	b.deadline = d
+}
+
+// startDeadline applies the plan's deadline, or Config.PlanDeadline, from
+// now. The returned function must be deferred directly: it lifts the
+// deadline and turns a failure caused by it into a deadline failure.
+func (b *PlanBase) startDeadline(a *AssertBase) func() {
+	return startDeadline(&b.ctx, b.config, b.deadline, a)
+}
+
+// startDeadline applies deadline, or Config.PlanDeadline if it is zero, to
+// *ctx from now, for plans without a PlanBase. The returned function is
+// deferred as for PlanBase.startDeadline.
+func startDeadline(ctx *context.Context, config *Config, deadline time.Duration, a *AssertBase) func() {
+	if deadline == 0 {
+		deadline = config.PlanDeadline
+	}
+	if deadline == 0 {
+		return func() {}
+	}
+
+	parent := *ctx
+	bounded, cancel := context.WithTimeout(parent, config.scaled(deadline))
+	*ctx = bounded
+
+	return func() {
+		err := recover()
+		*ctx = parent
+		cancel()
+
+		// Without an error, the deadline cut Consistently short
+		if errors.Is(bounded.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+			last := ""
+			if err != nil {
+				last = "\n  Last error: " + firstLine(fmt.Sprint(err))
+			}
+			panic(fmt.Sprintf("Plan did not finish within its %s deadline%s%s", deadline, last, a.formatHelp()))
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// closeWhenDone closes conn once ctx is done, so a blocked read or write
+// returns when the plan is cancelled. The returned function stops it.
+func closeWhenDone(ctx context.Context, conn io.Closer) func() bool {
+	return context.AfterFunc(ctx, func() { conn.Close() })
+}
@@ -0,0 +1,245 @@
+package attest
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+var _ Plan[*DirPlan, *DirAssert] = (*DirPlan)(nil)
+var _ Assert = (*DirAssert)(nil)
+
+// DirPlan represents a test plan that walks a directory the implementation
+// wrote, e.g. a directory of log segments. Each execution walks it anew.
+type DirPlan struct {
+	PlanBase
+
+	path string
+	glob string
+}
+
+// Glob only considers files whose path relative to the directory matches
+// pattern, e.g. "segment-*.log". Without it, every file in the tree counts.
+func (p *DirPlan) Glob(pattern string) *DirPlan {
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		panic(fmt.Sprintf("Glob() pattern %q is malformed: %v", pattern, err))
+	}
+
+	p.glob = pattern
+	return p
+}
+
+func (p *DirPlan) Eventually() *DirPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *DirPlan) Within(timeout time.Duration) *DirPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *DirPlan) Retry(policy RetryPolicy) *DirPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *DirPlan) Consistently() *DirPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *DirPlan) For(timeout time.Duration) *DirPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *DirPlan) Every(interval time.Duration) *DirPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *DirPlan) Deadline(d time.Duration) *DirPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *DirPlan) T() *DirAssert {
+	return &DirAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// DirAssert provides assertions on the files in a directory tree.
+type DirAssert struct {
+	AssertBase
+
+	plan  *DirPlan
+	files []string
+	sizes map[string]int
+	err   error
+
+	countCheckers     []Checker[int]
+	filesCheckers     []Checker[[]string]
+	eachSizeCheckers  []Checker[int]
+	totalSizeCheckers []Checker[int]
+}
+
+// Count adds checkers for the number of matching files, e.g. AtMost(3)
+// segments after rotation. All checkers must pass.
+func (a *DirAssert) Count(checkers ...Checker[int]) *DirAssert {
+	a.countCheckers = append(a.countCheckers, checkers...)
+	return a
+}
+
+// Files adds checkers for the sorted paths of the matching files, relative
+// to the directory and separated by slashes. All checkers must pass.
+func (a *DirAssert) Files(checkers ...Checker[[]string]) *DirAssert {
+	a.filesCheckers = append(a.filesCheckers, checkers...)
+	return a
+}
+
+// EachSize adds checkers that every matching file's size in bytes must
+// pass, e.g. AtMost(1 << 20) for segments rotated at 1 MiB.
+func (a *DirAssert) EachSize(checkers ...Checker[int]) *DirAssert {
+	a.eachSizeCheckers = append(a.eachSizeCheckers, checkers...)
+	return a
+}
+
+// TotalSize adds checkers for the combined size of the matching files in
+// bytes, e.g. that compaction keeps the data directory bounded.
+// All checkers must pass.
+func (a *DirAssert) TotalSize(checkers ...Checker[int]) *DirAssert {
+	a.totalSizeCheckers = append(a.totalSizeCheckers, checkers...)
+	return a
+}
+
+func (a *DirAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *DirAssert) execute() bool {
+	a.files, a.sizes, a.err = walkFiles(a.plan.path, a.plan.glob)
+	if a.err != nil {
+		return false
+	}
+
+	return checkAll(len(a.files), a.countCheckers, nil) &&
+		checkAll(a.files, a.filesCheckers, nil) &&
+		a.oversized() == "" &&
+		checkAll(a.totalSize(), a.totalSizeCheckers, nil)
+}
+
+// walkFiles returns the sorted relative paths of the regular files under
+// dir that match glob, with their sizes.
+func walkFiles(dir, glob string) ([]string, map[string]int, error) {
+	var files []string
+	sizes := make(map[string]int)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if glob != "" {
+			if ok, _ := path.Match(glob, rel); !ok {
+				return nil
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		sizes[rel] = int(info.Size())
+		return nil
+	})
+
+	return files, sizes, err
+}
+
+// oversized returns the first file whose size fails an EachSize checker.
+func (a *DirAssert) oversized() string {
+	for _, file := range a.files {
+		if !checkAll(a.sizes[file], a.eachSizeCheckers, nil) {
+			return file
+		}
+	}
+
+	return ""
+}
+
+// totalSize returns the combined size of the matching files.
+func (a *DirAssert) totalSize() int {
+	total := 0
+	for _, size := range a.sizes {
+		total += size
+	}
+
+	return total
+}
+
+func (a *DirAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("Directory %s", p.path)
+	if p.glob != "" {
+		title += fmt.Sprintf(" (%s)", p.glob)
+	}
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Expected: a directory\n  Actual: %s%s", title, a.err, a.formatHelp()))
+	}
+
+	checkAll(len(a.files), a.countCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected count: %s\n  Actual count: %d\n  Files: %q%s",
+			title, m.Expected(), actual, a.files, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.files, a.filesCheckers, func(m Checker[[]string], actual []string) {
+		msg := fmt.Sprintf("%s\n  Expected files: %s\n  Actual files: %q%s%s",
+			title, m.Expected(), actual, explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+
+	if file := a.oversized(); file != "" {
+		checkAll(a.sizes[file], a.eachSizeCheckers, func(m Checker[int], actual int) {
+			msg := fmt.Sprintf("%s\n  Expected size of each file: %s\n  Actual size of %s: %d bytes%s",
+				title, m.Expected(), file, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	checkAll(a.totalSize(), a.totalSizeCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected total size: %s\n  Actual total size: %d bytes in %d files%s",
+			title, m.Expected(), actual, len(a.files), a.formatHelp())
+		panic(msg)
+	})
+}
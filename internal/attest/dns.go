@@ -0,0 +1,467 @@
+package attest
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var _ Plan[*DNSPlan, *DNSAssert] = (*DNSPlan)(nil)
+var _ Assert = (*DNSAssert)(nil)
+
+// dnsTypes are the query types a DNSPlan can send.
+var dnsTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+	"MX":    dnsmessage.TypeMX,
+	"SOA":   dnsmessage.TypeSOA,
+}
+
+// DNSPlan represents a test plan that sends a single DNS query over UDP,
+// or TCP, and waits for the response.
+type DNSPlan struct {
+	PlanBase
+
+	process string
+	addr    string
+	domain  string
+	qtype   string
+	tcp     bool
+	noRD    bool
+	wait    time.Duration
+}
+
+// TCP sends the query over TCP instead of UDP.
+func (p *DNSPlan) TCP() *DNSPlan {
+	p.tcp = true
+	return p
+}
+
+// NoRecursion clears the recursion desired flag.
+func (p *DNSPlan) NoRecursion() *DNSPlan {
+	p.noRD = true
+	return p
+}
+
+// Timeout sets how long to wait for the response, one second by default.
+func (p *DNSPlan) Timeout(d time.Duration) *DNSPlan {
+	p.wait = d
+	return p
+}
+
+func (p *DNSPlan) Eventually() *DNSPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *DNSPlan) Within(timeout time.Duration) *DNSPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *DNSPlan) Retry(policy RetryPolicy) *DNSPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *DNSPlan) Consistently() *DNSPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *DNSPlan) For(timeout time.Duration) *DNSPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *DNSPlan) Every(interval time.Duration) *DNSPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *DNSPlan) Deadline(d time.Duration) *DNSPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *DNSPlan) T() *DNSAssert {
+	return &DNSAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// replyTimeout returns how long to wait for a response.
+func (p *DNSPlan) replyTimeout() time.Duration {
+	if p.wait == 0 {
+		return defaultDatagramTimeout
+	}
+
+	return p.wait
+}
+
+// transport returns the protocol the query is sent over.
+func (p *DNSPlan) transport() string {
+	if p.tcp {
+		return "tcp"
+	}
+
+	return "udp"
+}
+
+// query builds the query message with the given ID.
+func (p *DNSPlan) query(id uint16) ([]byte, error) {
+	name, err := dnsmessage.NewName(dnsName(p.domain))
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", p.domain, err)
+	}
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: !p.noRD},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: dnsTypes[p.qtype], Class: dnsmessage.ClassINET},
+		},
+	}
+
+	return msg.Pack()
+}
+
+// dnsName returns domain as a fully qualified name.
+func dnsName(domain string) string {
+	if strings.HasSuffix(domain, ".") {
+		return domain
+	}
+
+	return domain + "."
+}
+
+// DNSAssert provides assertions on a DNS response.
+type DNSAssert struct {
+	AssertBase
+
+	plan     *DNSPlan
+	response *dnsmessage.Message
+	err      error
+
+	rcodeCheckers  []Checker[string]
+	flagsCheckers  []Checker[string]
+	answerCheckers [][]Checker[string]
+	countCheckers  []Checker[int]
+	ttlCheckers    []Checker[int]
+}
+
+// RCode adds checkers for the response code, e.g. "NOERROR" or "NXDOMAIN".
+// All checkers must pass.
+func (a *DNSAssert) RCode(checkers ...Checker[string]) *DNSAssert {
+	a.rcodeCheckers = append(a.rcodeCheckers, checkers...)
+	return a
+}
+
+// Flags adds checkers for the header flags, written like dig as e.g.
+// "qr aa rd". All checkers must pass.
+func (a *DNSAssert) Flags(checkers ...Checker[string]) *DNSAssert {
+	a.flagsCheckers = append(a.flagsCheckers, checkers...)
+	return a
+}
+
+// Answer expects an answer record whose data passes all checkers. Data is
+// the address for A and AAAA, the target name for CNAME and NS, and the
+// joined strings for TXT.
+func (a *DNSAssert) Answer(checkers ...Checker[string]) *DNSAssert {
+	a.answerCheckers = append(a.answerCheckers, checkers)
+	return a
+}
+
+// AnswerCount adds checkers for the number of answer records.
+// All checkers must pass.
+func (a *DNSAssert) AnswerCount(checkers ...Checker[int]) *DNSAssert {
+	a.countCheckers = append(a.countCheckers, checkers...)
+	return a
+}
+
+// TTL adds checkers for the TTL, in seconds, of every answer record.
+// All checkers must pass.
+func (a *DNSAssert) TTL(checkers ...Checker[int]) *DNSAssert {
+	a.ttlCheckers = append(a.ttlCheckers, checkers...)
+	return a
+}
+
+func (a *DNSAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *DNSAssert) execute() bool {
+	p := a.plan
+	a.response, a.err = nil, nil
+
+	if _, ok := dnsTypes[p.qtype]; !ok {
+		panic(fmt.Sprintf("unsupported DNS query type %q", p.qtype))
+	}
+
+	id := uint16(rand.N(1 << 16))
+	query, err := p.query(id)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	var raw []byte
+	if p.tcp {
+		raw, err = exchangeDNSTCP(p.ctx, p.addr, query, p.replyTimeout())
+	} else {
+		var reply string
+		var replied bool
+		reply, replied, err = exchangeDatagram(p.ctx, p.addr, query, p.replyTimeout())
+		if err == nil && !replied {
+			err = fmt.Errorf("no response within %s", p.replyTimeout())
+		}
+		raw = []byte(reply)
+	}
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	var msg dnsmessage.Message
+	err = msg.Unpack(raw)
+	if err != nil {
+		a.err = fmt.Errorf("malformed response: %w", err)
+		return false
+	}
+
+	if msg.ID != id || !msg.Response {
+		a.err = fmt.Errorf("response does not answer the query (ID %d, expected %d)", msg.ID, id)
+		return false
+	}
+
+	a.response = &msg
+	return a.passes()
+}
+
+// exchangeDNSTCP sends a length-prefixed query and reads the response.
+func exchangeDNSTCP(ctx context.Context, addr string, query []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer closeWhenDone(ctx, conn)()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	_, err = conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(query))))
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.Write(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	_, err = io.ReadFull(conn, length[:])
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, binary.BigEndian.Uint16(length[:]))
+	_, err = io.ReadFull(conn, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// passes reports whether the response meets every expectation.
+func (a *DNSAssert) passes() bool {
+	answers := a.answers()
+	for _, checkers := range a.answerCheckers {
+		if !slices.ContainsFunc(answers, func(data string) bool { return checkAll(data, checkers, nil) }) {
+			return false
+		}
+	}
+
+	for _, ttl := range a.ttls() {
+		if !checkAll(ttl, a.ttlCheckers, nil) {
+			return false
+		}
+	}
+
+	return checkAll(a.rcode(), a.rcodeCheckers, nil) &&
+		checkAll(a.flags(), a.flagsCheckers, nil) &&
+		checkAll(len(a.response.Answers), a.countCheckers, nil)
+}
+
+// rcode returns the response code as written by dig.
+func (a *DNSAssert) rcode() string {
+	switch a.response.RCode {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+
+	return fmt.Sprintf("RCODE%d", a.response.RCode)
+}
+
+// flags returns the set header flags as written by dig.
+func (a *DNSAssert) flags() string {
+	h := a.response.Header
+	var flags []string
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{
+		{h.Response, "qr"},
+		{h.Authoritative, "aa"},
+		{h.Truncated, "tc"},
+		{h.RecursionDesired, "rd"},
+		{h.RecursionAvailable, "ra"},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+
+	return strings.Join(flags, " ")
+}
+
+// answers returns the data of each answer record.
+func (a *DNSAssert) answers() []string {
+	var answers []string
+	for _, r := range a.response.Answers {
+		answers = append(answers, recordData(r.Body))
+	}
+
+	return answers
+}
+
+// ttls returns the TTL of each answer record.
+func (a *DNSAssert) ttls() []int {
+	var ttls []int
+	for _, r := range a.response.Answers {
+		ttls = append(ttls, int(r.Header.TTL))
+	}
+
+	return ttls
+}
+
+// recordData formats the data of a resource record.
+func recordData(body dnsmessage.ResourceBody) string {
+	switch b := body.(type) {
+	case *dnsmessage.AResource:
+		return netip.AddrFrom4(b.A).String()
+	case *dnsmessage.AAAAResource:
+		return netip.AddrFrom16(b.AAAA).String()
+	case *dnsmessage.CNAMEResource:
+		return b.CNAME.String()
+	case *dnsmessage.NSResource:
+		return b.NS.String()
+	case *dnsmessage.TXTResource:
+		return strings.Join(b.TXT, "")
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", b.Pref, b.MX)
+	case *dnsmessage.SOAResource:
+		return fmt.Sprintf("%s %s %d", b.NS, b.MBox, b.Serial)
+	}
+
+	return fmt.Sprintf("%v", body)
+}
+
+// formatAnswers lists the answer records like dig's answer section.
+func (a *DNSAssert) formatAnswers() string {
+	if len(a.response.Answers) == 0 {
+		return " (none)"
+	}
+
+	var lines []string
+	for _, r := range a.response.Answers {
+		typ := strings.TrimPrefix(r.Header.Type.String(), "Type")
+		lines = append(lines, fmt.Sprintf("\n    %s %d %s %s", r.Header.Name, r.Header.TTL, typ, recordData(r.Body)))
+	}
+
+	return strings.Join(lines, "")
+}
+
+func (a *DNSAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("DNS %s %s over %s to %s", p.qtype, dnsName(p.domain), strings.ToUpper(p.transport()), p.addr)
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	checkAll(a.rcode(), a.rcodeCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected rcode: %s\n  Actual rcode: %s%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.flags(), a.flagsCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected flags: %s\n  Actual flags: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(len(a.response.Answers), a.countCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected answers: %s\n  Actual answers: %d%s%s",
+			title, m.Expected(), actual, a.formatAnswers(), a.formatHelp())
+		panic(msg)
+	})
+
+	answers := a.answers()
+	for _, checkers := range a.answerCheckers {
+		if !slices.ContainsFunc(answers, func(data string) bool { return checkAll(data, checkers, nil) }) {
+			expected := make([]string, 0, len(checkers))
+			for _, m := range checkers {
+				expected = append(expected, m.Expected())
+			}
+
+			msg := fmt.Sprintf("%s\n  Expected an answer: %s\n  Actual answers:%s%s",
+				title, strings.Join(expected, ", "), a.formatAnswers(), a.formatHelp())
+			panic(msg)
+		}
+	}
+
+	for _, ttl := range a.ttls() {
+		checkAll(ttl, a.ttlCheckers, func(m Checker[int], actual int) {
+			msg := fmt.Sprintf("%s\n  Expected TTL: %s\n  Actual TTL: %d%s%s",
+				title, m.Expected(), actual, a.formatAnswers(), a.formatHelp())
+			panic(msg)
+		})
+	}
+}
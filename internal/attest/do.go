@@ -4,23 +4,42 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"net"
+	"net/http"
+	"net/http/cookiejar"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/littleclusters/lc/internal/style"
 	"github.com/littleclusters/lc/pkg/threadsafe"
 )
 
 // Do provides the test harness and acts as the test runner.
 type Do struct {
-	processes  *threadsafe.Map[string, *Process]
+	processes *threadsafe.Map[string, *Process]
+	// interceptors hold the implementation's outbound requests.
+	interceptors *threadsafe.Map[string, *Interceptor]
+	// upstreams hold the upstreams the suite runs.
+	upstreams *threadsafe.Map[string, *runningUpstream]
+	// network holds the links between nodes, which partitions cut.
+	network *network
+	// clock is the virtual clock, if Config.VirtualClock is set.
+	clock      *virtualClock
 	config     *Config
 	workingDir string
 
+	seed  uint64
+	rand  *rand.Rand
+	marks *marks
+	// vars holds the values the current test captured.
+	vars *variables
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -33,17 +52,48 @@ func newDo(ctx context.Context, config *Config) *Do {
 	timestamp := time.Now().Format("20060102-150405")
 	workingDir := filepath.Join(config.WorkingDir, fmt.Sprintf("run-%s", timestamp))
 
+	// Processes run in config.Dir, so they need a path that resolves from there
+	if config.Dir != "" {
+		if !filepath.IsAbs(workingDir) {
+			workingDir = filepath.Join(config.Dir, workingDir)
+		}
+
+		abs, err := filepath.Abs(workingDir)
+		if err != nil {
+			panic(fmt.Sprintf("failed to resolve working directory: %v", err))
+		}
+		workingDir = abs
+	}
+
 	err := os.MkdirAll(workingDir, 0755)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create working directory: %v", err))
 	}
 
+	var clock *virtualClock
+	if config.VirtualClock {
+		clock = newVirtualClock(workingDir)
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
 	return &Do{
-		processes:  threadsafe.NewMap[string, *Process](),
-		config:     config,
-		workingDir: workingDir,
-		ctx:        doCtx,
-		cancel:     cancel,
+		processes:    threadsafe.NewMap[string, *Process](),
+		interceptors: threadsafe.NewMap[string, *Interceptor](),
+		upstreams:    threadsafe.NewMap[string, *runningUpstream](),
+		network:      newNetwork(),
+		config:       config,
+		workingDir:   workingDir,
+		seed:         seed,
+		rand:         newRand(seed),
+		marks:        threadsafe.NewMap[string, time.Time](),
+		vars:         newVariables(nil),
+		clock:        clock,
+		ctx:          doCtx,
+		cancel:       cancel,
 	}
 }
 
@@ -51,12 +101,42 @@ func newDo(ctx context.Context, config *Config) *Do {
 type Process struct {
 	cmd     *exec.Cmd
 	args    []string
+	env     []string
 	logFile *os.File
+	// exited is closed once the process exited, with its state in state.
+	exited chan struct{}
+	state  *os.ProcessState
 
 	realPort int
 	fauxPort int
 }
 
+// hasExited reports whether the process already exited.
+func (proc *Process) hasExited() bool {
+	select {
+	case <-proc.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// markLogs appends a marker line to the log of every started process.
+func (do *Do) markLogs(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+
+	do.processes.Range(func(name string, _ *Process) bool {
+		logPath := filepath.Join(do.workingDir, fmt.Sprintf("%s.log", name))
+		logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintln(logFile, line)
+			logFile.Close()
+		}
+
+		return true
+	})
+}
+
 // getProcess retrieves a process by name or panics if not found.
 func (do *Do) getProcess(name string) *Process {
 	if proc, exists := do.processes.Get(name); exists {
@@ -68,11 +148,18 @@ func (do *Do) getProcess(name string) *Process {
 
 // Start starts the process with an OS-assigned port.
 func (do *Do) Start(name string, args ...string) {
-	do.startWithPort(name, 0, args...)
+	do.StartWithEnv(name, nil, args...)
+}
+
+// StartWithEnv starts the process with KEY=VALUE pairs added to its
+// environment, e.g. to check it honors LOG_LEVEL.
+func (do *Do) StartWithEnv(name string, env []string, args ...string) {
+	do.config.Recorder.record(Entry{Kind: EntryStart, Process: name, Args: args, Env: env})
+	do.startWithPort(name, 0, env, args...)
 }
 
 // startWithPort starts the process on the specified port.
-func (do *Do) startWithPort(name string, port int, args ...string) {
+func (do *Do) startWithPort(name string, port int, env []string, args ...string) {
 	select {
 	case <-do.ctx.Done():
 		return
@@ -95,6 +182,8 @@ func (do *Do) startWithPort(name string, port int, args ...string) {
 	newArgs := append([]string{portArg, workingDirArg}, args...)
 
 	cmd := exec.CommandContext(do.ctx, do.config.Command, newArgs...)
+	cmd.Dir = do.config.Dir
+	cmd.Env = do.config.environ(append(do.harnessEnv(), env...))
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	// Redirect stdout/stderr to log file
@@ -112,7 +201,12 @@ func (do *Do) startWithPort(name string, port int, args ...string) {
 		panic(err.Error())
 	}
 
-	proc := &Process{realPort: port, cmd: cmd, args: args, logFile: logFile}
+	proc := &Process{realPort: port, cmd: cmd, args: args, env: env, logFile: logFile, exited: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		proc.state = cmd.ProcessState
+		close(proc.exited)
+	}()
 	do.waitForPort(proc)
 
 	do.processes.Set(name, proc)
@@ -130,7 +224,7 @@ func (do *Do) waitForPort(proc *Process) {
 
 		conn.Close()
 		return true
-	}, do.config.ProcessStartTimeout, do.config.RetryPollInterval)
+	}, do.config.ProcessStartTimeout, RetryPolicy{Interval: do.config.RetryPollInterval})
 
 	if !succeeded {
 		select {
@@ -151,31 +245,33 @@ func (do *Do) waitForPort(proc *Process) {
 
 // Stop sends SIGTERM to the process, then SIGKILL after timeout.
 func (do *Do) Stop(name string) {
+	do.config.Recorder.record(Entry{Kind: EntryStop, Process: name})
+	do.stop(name)
+}
+
+// stop stops the process without recording it.
+func (do *Do) stop(name string) {
 	proc := do.getProcess(name)
 	if proc.cmd == nil || proc.cmd.Process == nil {
 		return
 	}
 
-	pgid := proc.cmd.Process.Pid
-	err := syscall.Kill(-pgid, syscall.SIGTERM)
-	if err != nil {
-		fmt.Println(red("Error stopping process running @"), red(proc.realPort))
-		return
-	}
-
-	// Wait for graceful exit, force kill if timeout
-	done := make(chan bool, 1)
-	go func() {
-		proc.cmd.Wait()
-		done <- true
-	}()
+	if !proc.hasExited() {
+		pgid := proc.cmd.Process.Pid
+		err := syscall.Kill(-pgid, syscall.SIGTERM)
+		if err != nil {
+			fmt.Println(style.Red("Error stopping process running @"), style.Red(proc.realPort))
+			return
+		}
 
-	select {
-	case <-done:
-		// Process exited gracefully
-	case <-time.After(do.config.ProcessShutdownTimeout):
-		do.Kill(name)
-		<-done
+		// Wait for graceful exit, force kill if timeout
+		select {
+		case <-proc.exited:
+			// Process exited gracefully
+		case <-time.After(do.config.ProcessShutdownTimeout):
+			do.kill(name)
+			<-proc.exited
+		}
 	}
 
 	// Close log file after process exits
@@ -187,15 +283,23 @@ func (do *Do) Stop(name string) {
 
 // Kill sends SIGKILL to kill the process immediately.
 func (do *Do) Kill(name string) {
+	do.config.Recorder.record(Entry{Kind: EntryKill, Process: name})
+	do.kill(name)
+}
+
+// kill kills the process without recording it.
+func (do *Do) kill(name string) {
 	proc := do.getProcess(name)
 	if proc.cmd == nil || proc.cmd.Process == nil {
 		return
 	}
 
-	pgid := proc.cmd.Process.Pid
-	err := syscall.Kill(-pgid, syscall.SIGKILL)
-	if err != nil {
-		fmt.Println(red("Error killing process running @"), red(proc.realPort))
+	if !proc.hasExited() {
+		pgid := proc.cmd.Process.Pid
+		err := syscall.Kill(-pgid, syscall.SIGKILL)
+		if err != nil {
+			fmt.Println(style.Red("Error killing process running @"), style.Red(proc.realPort))
+		}
 	}
 
 	// Close log file if not already closed (e.g., when called directly, not via Stop)
@@ -216,24 +320,31 @@ func (do *Do) Restart(name string, sig ...syscall.Signal) {
 	if len(sig) > 0 {
 		signal = sig[0]
 	}
+	do.config.Recorder.record(Entry{Kind: EntryRestart, Process: name, Signal: int(signal)})
 
 	switch signal {
 	case syscall.SIGTERM:
-		do.Stop(name)
+		do.stop(name)
 	case syscall.SIGKILL:
-		do.Kill(name)
+		do.kill(name)
 	default:
-		do.Stop(name)
+		do.stop(name)
 	}
 
 	time.Sleep(do.config.ProcessRestartDelay)
 
-	do.startWithPort(name, proc.realPort, proc.args...)
+	do.startWithPort(name, proc.realPort, proc.env, proc.args...)
 }
 
-// Done cleans up all running processes.
+// Done cleans up all running processes, interceptors and links.
 func (do *Do) Done() {
 	do.cancel()
+	do.network.close()
+
+	do.interceptors.Range(func(_ string, ic *Interceptor) bool {
+		ic.close()
+		return true
+	})
 
 	var processNames []string
 	do.processes.Range(func(name string, _ *Process) bool {
@@ -242,7 +353,19 @@ func (do *Do) Done() {
 	})
 
 	for _, name := range processNames {
-		do.Stop(name)
+		do.stop(name)
+	}
+}
+
+// Signal creates a plan that sends sig to a running process and checks how
+// it shuts down. Check what it flushed to disk with File once it passed.
+func (do *Do) Signal(name string, sig syscall.Signal) *ProcessPlan {
+	return &ProcessPlan{
+		ctx:     do.ctx,
+		config:  do.config,
+		do:      do,
+		process: name,
+		signal:  sig,
 	}
 }
 
@@ -278,19 +401,32 @@ func (do *Do) Concurrently(fns ...func()) {
 	}
 }
 
-// HTTP creates a test plan for an HTTP request.
+// HTTP creates a test plan for an HTTP request. References to variables
+// such as {{token}} in the path, body and header values are replaced by
+// their values when the plan is created. A reference to a variable the test
+// has not set or captured is sent as it is, so a body can contain a literal
+// {{...}}.
 func (do *Do) HTTP(name, method, path string, args ...any) *HTTPPlan {
+	return do.http(do.vars, name, method, path, args...)
+}
+
+// http creates a test plan for an HTTP request whose variables are vars.
+func (do *Do) http(vars *variables, name, method, path string, args ...any) *HTTPPlan {
 	proc := do.getProcess(name)
+	path = vars.expand(path)
 	url := fmt.Sprintf("http://127.0.0.1:%d%s", proc.realPort, path)
 
 	var body []byte
 	if len(args) >= 1 {
-		body = []byte(args[0].(string))
+		body = []byte(vars.expand(args[0].(string)))
 	}
 
 	var headers H
 	if len(args) >= 2 {
-		headers = args[1].(H)
+		headers = make(H, len(args[1].(H)))
+		for key, value := range args[1].(H) {
+			headers[key] = vars.expand(value)
+		}
 	}
 
 	return &HTTPPlan{
@@ -300,13 +436,255 @@ func (do *Do) HTTP(name, method, path string, args ...any) *HTTPPlan {
 			config: do.config,
 		},
 
+		process: name,
 		method:  method,
+		path:    path,
 		url:     url,
 		headers: headers,
 		body:    body,
+		marks:   do.marks,
+		vars:    vars,
+	}
+}
+
+// Session starts an HTTP session with a process. Its requests share
+// connections, cookies and variables.
+func (do *Do) Session(name string) *Session {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		Jar:       jar,
+		Timeout:   do.config.ExecuteTimeout,
+	}
+
+	return &Session{
+		do:      do,
+		process: name,
+		client:  client,
+		vars:    newVariables(do.vars),
+	}
+}
+
+// TCP creates a test plan for a raw TCP exchange with a process.
+func (do *Do) TCP(name string) *TCPPlan {
+	return &TCPPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+		marks:   do.marks,
+	}
+}
+
+// UDP creates a test plan that sends a datagram to a process, which must
+// listen for UDP on the same port number it was given for TCP.
+func (do *Do) UDP(name, payload string) *UDPPlan {
+	return &UDPPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+		payload: []byte(payload),
+	}
+}
+
+// DNS creates a test plan for a DNS query to a process. The query type is
+// one of A, AAAA, CNAME, NS, TXT, MX or SOA.
+func (do *Do) DNS(name, domain, qtype string) *DNSPlan {
+	return &DNSPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+		domain:  domain,
+		qtype:   strings.ToUpper(qtype),
+	}
+}
+
+// Postgres creates a test plan for a simple query to a process speaking the
+// PostgreSQL wire protocol.
+func (do *Do) Postgres(name, query string) *PostgresPlan {
+	return &PostgresPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+		query:   query,
+		user:    "postgres",
+	}
+}
+
+// MQTT creates a test plan for an MQTT broker process.
+func (do *Do) MQTT(name string) *MQTTPlan {
+	return &MQTTPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+	}
+}
+
+// AMQP creates a test plan for an AMQP 0.9.1 broker process.
+func (do *Do) AMQP(name string) *AMQPPlan {
+	return &AMQPPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process:  name,
+		addr:     do.addr(name),
+		username: "guest",
+		password: "guest",
+		vhost:    "/",
+	}
+}
+
+// Memcached creates a test plan for a memcached text protocol command to a
+// process. The command is chosen with Set, Add, Get, Delete, Incr or Decr.
+func (do *Do) Memcached(name string) *MemcachedPlan {
+	return &MemcachedPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+	}
+}
+
+// S3Multipart creates a test plan for a multipart upload of key to bucket
+// on a process serving the S3 API. Parts are added with Part.
+func (do *Do) S3Multipart(name, bucket, key string) *S3MultipartPlan {
+	return &S3MultipartPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		baseURL: "http://" + do.addr(name),
+		path:    "/" + bucket + "/" + key,
 	}
 }
 
+// GraphQL creates a test plan for a GraphQL operation on a process, sent
+// to /graphql.
+func (do *Do) GraphQL(name, query string) *GraphQLPlan {
+	return &GraphQLPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		addr:    do.addr(name),
+		path:    "/graphql",
+		query:   query,
+	}
+}
+
+// Load creates a load test plan that sends a mix of HTTP requests to a
+// process from concurrent workers. Add requests with Request.
+func (do *Do) Load(name string) *LoadPlan {
+	return &LoadPlan{
+		ctx:      do.ctx,
+		config:   do.config,
+		rand:     do.rand,
+		process:  name,
+		baseURL:  "http://" + do.addr(name),
+		workers:  10,
+		duration: 5 * time.Second,
+	}
+}
+
+// WS creates a test plan for a WebSocket session with a process.
+// Optional headers are sent with the upgrade request.
+func (do *Do) WS(name, path string, headers ...H) *WSPlan {
+	var merged H
+	if len(headers) > 0 {
+		merged = headers[0]
+	}
+
+	return &WSPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		process: name,
+		path:    path,
+		url:     "ws://" + do.addr(name) + path,
+		headers: merged,
+	}
+}
+
+// GRPC creates a test plan for a unary gRPC call to a process.
+// The method is "pkg.Service/Method" and the request is a JSON message.
+func (do *Do) GRPC(name, method, request string) *GRPCPlan {
+	service, rpc := splitMethod(method)
+
+	return &GRPCPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		grpcTarget: grpcTarget{addr: do.addr(name), service: service, method: rpc},
+		process:    name,
+		request:    request,
+	}
+}
+
+// GRPCStream creates a test plan for a streaming gRPC call to a process.
+// The method is "pkg.Service/Method"; messages are added with Send.
+func (do *Do) GRPCStream(name, method string) *GRPCStreamPlan {
+	service, rpc := splitMethod(method)
+
+	return &GRPCStreamPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		grpcTarget: grpcTarget{addr: do.addr(name), service: service, method: rpc},
+		process:    name,
+	}
+}
+
+// addr returns the host:port a process listens on.
+func (do *Do) addr(name string) string {
+	return fmt.Sprintf("127.0.0.1:%d", do.getProcess(name).realPort)
+}
+
 // Exec creates a test plan for a CLI command execution.
 func (do *Do) Exec(args ...string) *CLIPlan {
 	return &CLIPlan{
@@ -316,7 +694,93 @@ func (do *Do) Exec(args ...string) *CLIPlan {
 			config: do.config,
 		},
 
-		command: do.config.Command,
-		args:    args,
+		command:    do.config.Command,
+		args:       args,
+		harnessEnv: do.harnessEnv(),
+		workingDir: do.workingDir,
+	}
+}
+
+// File creates a test plan for a file the implementation wrote. A relative
+// path is inside the working directory passed to processes.
+func (do *Do) File(path string) *FilePlan {
+	return &FilePlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		path: resolvePath(do.workingDir, path),
+	}
+}
+
+// Dir creates a test plan for a directory tree the implementation wrote.
+// A relative path is inside the working directory passed to processes.
+func (do *Do) Dir(path string) *DirPlan {
+	return &DirPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		path: resolvePath(do.workingDir, path),
+	}
+}
+
+// Set sets a variable for the rest of the test, e.g. a username the later
+// requests refer to as {{user}}.
+func (do *Do) Set(name, value string) {
+	do.vars.set(name, value)
+}
+
+// Var returns the value of a variable the test set or captured, e.g. to
+// check a later response against it. It panics if the variable is not set.
+func (do *Do) Var(name string) string {
+	return do.vars.value(name)
+}
+
+// WorkingDir returns the directory passed to processes as --working-dir.
+func (do *Do) WorkingDir() string {
+	return do.workingDir
+}
+
+// Interactive creates a test plan for a CLI command run under a
+// pseudo-terminal, for shells and REPLs that need a terminal to talk to.
+func (do *Do) Interactive(args ...string) *PTYPlan {
+	return &PTYPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		command:    do.config.Command,
+		args:       args,
+		harnessEnv: do.harnessEnv(),
+		workingDir: do.workingDir,
+	}
+}
+
+// Group creates a plan that runs the assertions concurrently, each with its
+// own timing, and checks how many passed and what they received.
+func (do *Do) Group(members ...Assert) *GroupPlan {
+	return &GroupPlan{
+		ctx:     do.ctx,
+		config:  do.config,
+		members: members,
+	}
+}
+
+// Bench creates a benchmark plan that repeatedly calls fn from concurrent workers.
+// A call that panics, such as a failed assertion, counts as an error.
+func (do *Do) Bench(fn func()) *BenchPlan {
+	return &BenchPlan{
+		ctx:      do.ctx,
+		config:   do.config,
+		fn:       fn,
+		workers:  10,
+		duration: 5 * time.Second,
 	}
 }
@@ -0,0 +1,164 @@
+package attest
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AcceptEncoding advertises the encodings the client accepts, e.g.
+// "gzip, br". The response is then decoded by the assertion rather than
+// the client, so Compressed sees how it was sent.
+func (p *HTTPPlan) AcceptEncoding(encodings string) *HTTPPlan {
+	headers := make(H, len(p.headers)+1)
+	for key, value := range p.headers {
+		headers[key] = value
+	}
+	headers["Accept-Encoding"] = encodings
+
+	p.headers = headers
+	return p
+}
+
+// CompressBody compresses the request body with encoding, gzip or deflate,
+// and sets Content-Encoding.
+func (p *HTTPPlan) CompressBody(encoding string) *HTTPPlan {
+	if encoding != "gzip" && encoding != "deflate" {
+		panic(fmt.Sprintf("CompressBody() supports gzip and deflate, not %q", encoding))
+	}
+
+	p.bodyEncoding = encoding
+	return p
+}
+
+// Compressed expects the response to be sent with Content-Encoding
+// encoding, e.g. "gzip". Checkers see the decoded body; encodings other
+// than gzip and deflate are left as is.
+func (a *HTTPAssert) Compressed(encoding string) *HTTPAssert {
+	encoding = strings.ToLower(encoding)
+	a.encoding = &encoding
+	return a
+}
+
+// NotCompressed expects the response to be sent without a Content-Encoding,
+// e.g. for a client that does not accept any or a body too small to gain.
+func (a *HTTPAssert) NotCompressed() *HTTPAssert {
+	identity := ""
+	a.encoding = &identity
+	return a
+}
+
+// compress returns body compressed with encoding as it is read.
+func compress(encoding string, body io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		if c, ok := body.(io.Closer); ok {
+			defer c.Close()
+		}
+
+		var w io.WriteCloser
+		if encoding == "gzip" {
+			w = gzip.NewWriter(pw)
+		} else {
+			w = zlib.NewWriter(pw)
+		}
+
+		_, err := io.Copy(w, body)
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
+
+// responseEncoding returns how the response was encoded. The client
+// decodes gzip itself unless the plan set Accept-Encoding, and then drops
+// the header.
+func responseEncoding(resp *http.Response) string {
+	if resp.Uncompressed {
+		return "gzip"
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "identity" {
+		return ""
+	}
+
+	return encoding
+}
+
+// decode returns body decoded from encoding. Bodies the client already
+// decoded, and encodings other than gzip and deflate, are returned as is.
+func decode(resp *http.Response, body io.Reader) (io.Reader, error) {
+	if resp.Uncompressed {
+		return body, nil
+	}
+
+	switch responseEncoding(resp) {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding gzip body: %w", err)
+		}
+		return r, nil
+	case "deflate":
+		// deflate should be zlib-wrapped, but some servers send it raw
+		buffered := bufio.NewReader(body)
+		header, err := buffered.Peek(2)
+		if err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 && header[0]&0x0f == 8 {
+			r, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, fmt.Errorf("decoding deflate body: %w", err)
+			}
+			return r, nil
+		}
+		return flate.NewReader(buffered), nil
+	default:
+		return body, nil
+	}
+}
+
+// readResponse decodes and reads a response body.
+func (a *HTTPAssert) readResponse(resp *http.Response, body io.Reader) string {
+	a.contentEncoding = responseEncoding(resp)
+
+	decoded, err := decode(resp, body)
+	if err != nil {
+		panic(fmt.Sprintf("An error occurred: %v", err))
+	}
+
+	responseBody, err := a.readBody(decoded)
+	if err != nil {
+		panic(fmt.Sprintf("An error occurred: %v", err))
+	}
+
+	return responseBody
+}
+
+// checkEncoding panics if the response's encoding was not as expected.
+func (a *HTTPAssert) checkEncoding() {
+	if a.encoding == nil || *a.encoding == a.contentEncoding {
+		return
+	}
+
+	p := a.plan
+	expected, actual := *a.encoding, a.contentEncoding
+	if expected == "" {
+		expected = "none"
+	}
+	if actual == "" {
+		actual = "none"
+	}
+
+	msg := fmt.Sprintf("%s %s\n  Expected Content-Encoding: %s\n  Actual Content-Encoding: %s%s",
+		p.method, p.url, expected, actual, a.formatHelp())
+	panic(a.failure(msg, "encoding", expected, ""))
+}
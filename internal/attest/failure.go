@@ -0,0 +1,95 @@
+package attest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPFailure describes a failed HTTP assertion in enough detail to replay it.
+// HTTP assertions fail with it, and the suite keeps it in TestResult.
+type HTTPFailure struct {
+	// Message is the failure message shown to the user.
+	Message string `json:"message"`
+
+	// Process is the name of the process the request was sent to.
+	Process string `json:"process"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Headers H      `json:"headers,omitempty"`
+	Body    string `json:"body,omitempty"`
+	// BodyFile is the fixture sent as the body instead of Body.
+	BodyFile string `json:"bodyFile,omitempty"`
+
+	// Field is the part of the response that failed: redirect, status,
+	// protocol, body, encoding, json, etag, altsvc, header, cookie, part,
+	// objects, event, connections, code, tls, or order.
+	Field string `json:"field"`
+	// JSONPath is the path of the failed field when Field is json.
+	JSONPath string `json:"jsonPath,omitempty"`
+	Expected string `json:"expected"`
+
+	// Status and Response hold the response that failed the assertion.
+	Status   int    `json:"status"`
+	Response string `json:"response"`
+}
+
+func (f *HTTPFailure) Error() string {
+	return f.Message
+}
+
+// Send sends the failed request to the server at baseURL and returns the response.
+func (f *HTTPFailure) Send(ctx context.Context, baseURL string, timeout time.Duration) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, f.Method, baseURL+f.Path, bytes.NewReader([]byte(f.Body)))
+	if err != nil {
+		return 0, "", err
+	}
+
+	for key, value := range f.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return resp.StatusCode, string(body), nil
+}
+
+// Replay starts a fresh instance of the program and sends it the failed request.
+// State built up by earlier requests in the test is not recreated.
+func Replay(ctx context.Context, config *Config, f *HTTPFailure) (status int, body string, err error) {
+	merged := New().WithConfig(config).config
+
+	do := newDo(ctx, merged)
+	defer do.Done()
+
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	do.Start(f.Process)
+	proc := do.getProcess(f.Process)
+
+	if f.BodyFile != "" {
+		withBody := *f
+		withBody.Body = merged.readFixture(f.BodyFile)
+		f = &withBody
+	}
+
+	return f.Send(ctx, fmt.Sprintf("http://127.0.0.1:%d", proc.realPort), merged.ExecuteTimeout)
+}
@@ -0,0 +1,135 @@
+package attest
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Fault is a failure an upstream injects into the requests it serves,
+// e.g. to check that the implementation retries or opens a circuit breaker.
+type Fault struct {
+	status int
+	delay  time.Duration
+	drop   bool
+	times  int
+}
+
+// FailWith answers HTTP requests with status instead of their route's
+// response. TCP upstreams ignore it.
+func FailWith(status int) Fault {
+	return Fault{status: status}
+}
+
+// Delay holds each request for d before it is answered.
+func Delay(d time.Duration) Fault {
+	return Fault{delay: d}
+}
+
+// DropConnection closes the connection mid-response: HTTP upstreams send
+// the headers and part of the body, TCP upstreams close without answering.
+func DropConnection() Fault {
+	return Fault{drop: true}
+}
+
+// Times limits the fault to the next n requests. By default it lasts until
+// ClearFaults.
+func (f Fault) Times(n int) Fault {
+	f.times = n
+	return f
+}
+
+// InjectFault adds faults to an upstream's requests from now on, each
+// request getting every active fault.
+func (do *Do) InjectFault(upstream string, faults ...Fault) {
+	do.getUpstream(upstream).faults.add(faults)
+}
+
+// ClearFaults removes the faults injected into an upstream, so it recovers.
+func (do *Do) ClearFaults(upstream string) {
+	do.getUpstream(upstream).faults.clear()
+}
+
+// faults holds the faults active on an upstream.
+type faults struct {
+	mu     sync.Mutex
+	active []Fault
+}
+
+func (f *faults) add(faults []Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.active = append(f.active, faults...)
+}
+
+func (f *faults) clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.active = nil
+}
+
+// take returns what the active faults do to the next request, counting it
+// against the faults limited with Times.
+func (f *faults) take() (delay time.Duration, status int, drop bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	remaining := f.active[:0]
+	for _, fault := range f.active {
+		delay += fault.delay
+		if fault.status != 0 {
+			status = fault.status
+		}
+		drop = drop || fault.drop
+
+		if fault.times > 0 {
+			fault.times--
+			if fault.times == 0 {
+				continue
+			}
+		}
+		remaining = append(remaining, fault)
+	}
+	f.active = remaining
+
+	return delay, status, drop
+}
+
+// wrap injects the active faults into handler's requests.
+func (f *faults) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, status, drop := f.take()
+
+		if delay > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		switch {
+		case drop:
+			dropResponse(w)
+		case status != 0:
+			w.WriteHeader(status)
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
+
+// dropResponse sends the headers and part of a body, then closes the
+// connection.
+func dropResponse(w http.ResponseWriter) {
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 1024\r\n\r\npartial")
+	buf.Flush()
+}
@@ -0,0 +1,238 @@
+package attest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var _ Plan[*FilePlan, *FileAssert] = (*FilePlan)(nil)
+var _ Assert = (*FileAssert)(nil)
+
+// FilePlan represents a test plan that reads a file the implementation
+// wrote, e.g. a write-ahead log or snapshot. Each execution reads it anew.
+type FilePlan struct {
+	PlanBase
+
+	path string
+}
+
+func (p *FilePlan) Eventually() *FilePlan {
+	p.setEventually()
+	return p
+}
+
+func (p *FilePlan) Within(timeout time.Duration) *FilePlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *FilePlan) Retry(policy RetryPolicy) *FilePlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *FilePlan) Consistently() *FilePlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *FilePlan) For(timeout time.Duration) *FilePlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *FilePlan) Every(interval time.Duration) *FilePlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *FilePlan) Deadline(d time.Duration) *FilePlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *FilePlan) T() *FileAssert {
+	return &FileAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// FileAssert provides assertions on a file's existence, contents,
+// permissions and size.
+type FileAssert struct {
+	AssertBase
+
+	plan     *FilePlan
+	info     fs.FileInfo
+	contents string
+	err      error
+
+	missing          bool
+	contentsCheckers []Checker[string]
+	permCheckers     []Checker[fs.FileMode]
+	sizeCheckers     []Checker[int]
+}
+
+// Exists expects the file to exist. It is implied by the other checkers.
+func (a *FileAssert) Exists() *FileAssert {
+	return a
+}
+
+// Missing expects the file not to exist, e.g. a temporary file removed
+// after a snapshot was renamed into place.
+func (a *FileAssert) Missing() *FileAssert {
+	a.missing = true
+	return a
+}
+
+// Contents adds checkers for the file's contents. All checkers must pass.
+func (a *FileAssert) Contents(checkers ...Checker[string]) *FileAssert {
+	a.contentsCheckers = append(a.contentsCheckers, checkers...)
+	return a
+}
+
+// Perm adds checkers for the file's permission bits, e.g.
+// Is(fs.FileMode(0600)). All checkers must pass.
+func (a *FileAssert) Perm(checkers ...Checker[fs.FileMode]) *FileAssert {
+	a.permCheckers = append(a.permCheckers, checkers...)
+	return a
+}
+
+// Size adds checkers for the file's size in bytes, e.g. AtLeast(1).
+// All checkers must pass.
+func (a *FileAssert) Size(checkers ...Checker[int]) *FileAssert {
+	a.sizeCheckers = append(a.sizeCheckers, checkers...)
+	return a
+}
+
+func (a *FileAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *FileAssert) execute() bool {
+	a.info, a.contents = nil, ""
+	a.info, a.err = os.Stat(a.plan.path)
+	if a.err == nil && a.info.Mode().IsRegular() && len(a.contentsCheckers) > 0 {
+		var data []byte
+		data, a.err = os.ReadFile(a.plan.path)
+		a.contents = string(data)
+	}
+
+	if a.missing {
+		return errors.Is(a.err, fs.ErrNotExist)
+	}
+
+	return a.err == nil && a.info.Mode().IsRegular() &&
+		checkAll(a.contents, a.contentsCheckers, nil) &&
+		checkAll(a.info.Mode().Perm(), a.permCheckers, nil) &&
+		checkAll(int(a.info.Size()), a.sizeCheckers, nil)
+}
+
+func (a *FileAssert) check() {
+	title := fmt.Sprintf("File %s", a.plan.path)
+
+	if a.missing {
+		if !errors.Is(a.err, fs.ErrNotExist) {
+			actual := fmt.Sprintf("%d bytes", a.info.Size())
+			if a.err != nil {
+				actual = a.err.Error()
+			}
+			panic(fmt.Sprintf("%s\n  Expected: missing\n  Actual: %s%s", title, actual, a.formatHelp()))
+		}
+
+		return
+	}
+
+	if a.err != nil {
+		actual := a.err.Error()
+		if errors.Is(a.err, fs.ErrNotExist) {
+			actual = "missing"
+		}
+		panic(fmt.Sprintf("%s\n  Expected: a file\n  Actual: %s%s", title, actual, a.formatHelp()))
+	}
+
+	if !a.info.Mode().IsRegular() {
+		panic(fmt.Sprintf("%s\n  Expected: a file\n  Actual: %s%s", title, a.info.Mode(), a.formatHelp()))
+	}
+
+	checkAll(a.contents, a.contentsCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected contents: %s\n  Actual contents:%s%s%s",
+			title, m.Expected(), formatContents(actual), explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.info.Mode().Perm(), a.permCheckers, func(m Checker[fs.FileMode], actual fs.FileMode) {
+		msg := fmt.Sprintf("%s\n  Expected permissions: %s\n  Actual permissions: %s%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(int(a.info.Size()), a.sizeCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected size: %s\n  Actual size: %d bytes%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
+
+// formatContents formats file contents as text, or as a hexdump if binary.
+func formatContents(contents string) string {
+	if isBinary(contents) {
+		return formatPayload(contents)
+	}
+
+	return formatText(contents)
+}
+
+// resolvePath returns path as is if absolute, or inside dir.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// commandIn returns the command and the directory to run it in: dir, created
+// if missing, or projectDir if dir is empty. A relative command path such
+// as ./run.sh is made absolute, since it is relative to projectDir.
+func commandIn(command, projectDir, dir string) (string, string) {
+	if dir == "" {
+		return command, projectDir
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create directory %s: %v", dir, err))
+	}
+
+	if filepath.IsAbs(command) || !strings.ContainsRune(command, filepath.Separator) {
+		return command, dir
+	}
+
+	abs, err := filepath.Abs(filepath.Join(projectDir, command))
+	if err != nil {
+		return command, dir
+	}
+
+	return abs, dir
+}
@@ -0,0 +1,194 @@
+package attest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// fixtureSnippet is how many bytes of each side a fixture mismatch shows.
+const fixtureSnippet = 32
+
+// BodyFile streams the fixture file name from Config.Fixtures as the
+// request body, e.g. a large upload, instead of holding it in memory.
+func (p *HTTPPlan) BodyFile(name string) *HTTPPlan {
+	p.bodyFile = name
+	return p
+}
+
+// BodyFile expects the response body to equal the fixture file name from
+// Config.Fixtures. The body is compared as it streams in, and only kept in
+// memory if other checkers need it.
+func (a *HTTPAssert) BodyFile(name string) *HTTPAssert {
+	a.bodyFile = name
+	return a
+}
+
+// SendFile streams the fixture file name from Config.Fixtures to the
+// connection.
+func (p *TCPPlan) SendFile(name string) *TCPPlan {
+	p.steps = append(p.steps, tcpStep{file: name})
+	return p
+}
+
+// ReceivedFile expects the bytes read from the connection to equal the
+// fixture file name from Config.Fixtures, compared as they stream in.
+// Reading stops at the fixture's length, so later bytes are not checked.
+func (a *TCPAssert) ReceivedFile(name string) *TCPAssert {
+	a.receivedFile = name
+	return a
+}
+
+// readBody reads a response body, streaming it against the BodyFile
+// fixture if set. The body is then only kept if other checkers need it.
+func (a *HTTPAssert) readBody(body io.Reader) (string, error) {
+	if a.bodyFile == "" {
+		data, err := io.ReadAll(body)
+		return string(data), err
+	}
+
+	f, _ := a.config.openFixture(a.bodyFile)
+	defer f.Close()
+
+	var kept strings.Builder
+	if len(a.bodyCheckers) > 0 || len(a.jsonCheckers) > 0 || len(a.captures) > 0 ||
+		len(a.parts) > 0 || a.objects != nil {
+		body = io.TeeReader(body, &kept)
+	}
+
+	var err error
+	a.bodyDiff, err = compareStreams(a.bodyFile, body, f)
+	return kept.String(), err
+}
+
+// readFile reads the response from conn, streaming it against the
+// ReceivedFile fixture.
+func (a *TCPAssert) readFile(conn net.Conn, deadline time.Time) {
+	f, size := a.config.openFixture(a.receivedFile)
+	defer f.Close()
+
+	conn.SetReadDeadline(deadline)
+	diff, err := compareStreams(a.receivedFile, io.LimitReader(conn, size), f)
+	a.receivedDiff = diff
+
+	// A response cut short shows in the diff
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		a.err = fmt.Errorf("connection closed: %w", err)
+	}
+}
+
+// openFixture opens the fixture file name from Config.Fixtures and returns
+// it with its size.
+func (c *Config) openFixture(name string) (fs.File, int64) {
+	if c.Fixtures == nil {
+		panic(fmt.Sprintf("Could not open fixture %s: no fixtures configured", name))
+	}
+
+	f, err := c.Fixtures.Open(name)
+	if err != nil {
+		panic(fmt.Sprintf("Could not open fixture %s: %v", name, err))
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		panic(fmt.Sprintf("Could not open fixture %s: %v", name, err))
+	}
+
+	return f, info.Size()
+}
+
+// readFixture returns the contents of the fixture file name, for the rare
+// caller that needs all of it, such as a replay.
+func (c *Config) readFixture(name string) string {
+	f, _ := c.openFixture(name)
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		panic(fmt.Sprintf("Could not read fixture %s: %v", name, err))
+	}
+
+	return string(data)
+}
+
+// streamDiff is the outcome of comparing a stream to a fixture without
+// holding either in memory.
+type streamDiff struct {
+	fixture      string
+	size         int64
+	expectedSize int64
+	// offset is where the streams first differ, or -1 if they are equal.
+	offset int64
+	// actual and expected are the bytes of each stream from offset.
+	actual   []byte
+	expected []byte
+}
+
+// compareStreams reads actual and expected to the end, recording where they
+// first differ. A read error ends actual early and is returned.
+func compareStreams(fixture string, actual, expected io.Reader) (streamDiff, error) {
+	d := streamDiff{fixture: fixture, offset: -1}
+	actualBuf, expectedBuf := make([]byte, 32*1024), make([]byte, 32*1024)
+
+	for {
+		an, actualErr := io.ReadFull(actual, actualBuf)
+		en, expectedErr := io.ReadFull(expected, expectedBuf)
+
+		if d.offset < 0 {
+			i := firstDifference(string(actualBuf[:an]), string(expectedBuf[:en]))
+			if i < max(an, en) {
+				d.offset = d.size + int64(i)
+				d.actual = append([]byte(nil), actualBuf[min(i, an):min(i+fixtureSnippet, an)]...)
+				d.expected = append([]byte(nil), expectedBuf[min(i, en):min(i+fixtureSnippet, en)]...)
+			}
+		}
+		d.size += int64(an)
+		d.expectedSize += int64(en)
+
+		if expectedErr != nil && !isEOF(expectedErr) {
+			panic(fmt.Sprintf("Could not read fixture %s: %v", fixture, expectedErr))
+		}
+		if actualErr != nil && !isEOF(actualErr) {
+			n, _ := io.Copy(io.Discard, expected)
+			d.expectedSize += n
+			return d, actualErr
+		}
+
+		switch {
+		case actualErr != nil && expectedErr != nil:
+			return d, nil
+		case actualErr != nil:
+			n, _ := io.Copy(io.Discard, expected)
+			d.expectedSize += n
+			return d, nil
+		case expectedErr != nil:
+			n, err := io.Copy(io.Discard, actual)
+			d.size += n
+			return d, err
+		}
+	}
+}
+
+// isEOF reports whether err is the end of a stream read with io.ReadFull.
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (d streamDiff) equal() bool {
+	return d.offset < 0
+}
+
+// describe returns the failure lines for a stream that differs from its
+// fixture, labelled e.g. "response".
+func (d streamDiff) describe(label string) string {
+	return fmt.Sprintf("  Expected %s: fixture %s, %d bytes\n  Actual %s: %d bytes\n"+
+		"  First difference at offset %d (0x%x)\n  Expected:%s\n  Actual:%s",
+		label, d.fixture, d.expectedSize, label, d.size, d.offset, d.offset,
+		formatPayload(string(d.expected)), formatPayload(string(d.actual)))
+}
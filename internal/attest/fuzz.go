@@ -0,0 +1,260 @@
+package attest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var _ Assert = (*FuzzAssert)(nil)
+
+// defaultFuzzRuns is how many inputs a fuzz plan generates by default.
+const defaultFuzzRuns = 100
+
+// defaultFuzzWait is how long a fuzz plan reads the reply to an input.
+const defaultFuzzWait = 100 * time.Millisecond
+
+// FuzzPlan sends generated inputs, e.g. malformed requests, to a process
+// over TCP, each on a new connection, and checks that it neither crashes
+// nor stops serving. Inputs that fail are saved to the corpus and sent
+// first by later runs.
+type FuzzPlan struct {
+	ctx    context.Context
+	config *Config
+
+	do       *Do
+	process  string
+	generate func(r *rand.Rand) []byte
+	runs     int
+	seed     uint64
+	wait     time.Duration
+	probe    Assert
+	corpus   string
+}
+
+// Fuzz creates a fuzz plan for a process with inputs from generate. Its
+// seed comes from the run's, so a failure is reproduced with the same seed.
+func (do *Do) Fuzz(name string, generate func(r *rand.Rand) []byte) *FuzzPlan {
+	return &FuzzPlan{
+		ctx:      do.ctx,
+		config:   do.config,
+		do:       do,
+		process:  name,
+		generate: generate,
+		runs:     defaultFuzzRuns,
+		seed:     do.rand.Uint64(),
+		wait:     defaultFuzzWait,
+		corpus:   filepath.Join(filepath.Dir(do.workingDir), "corpus", name),
+	}
+}
+
+// Runs sets how many inputs to generate, 100 by default.
+func (p *FuzzPlan) Runs(n int) *FuzzPlan {
+	if n < 1 {
+		panic("Runs() requires at least one run")
+	}
+
+	p.runs = n
+	return p
+}
+
+// Seed sets the seed of the generator, e.g. the one a failure reported.
+func (p *FuzzPlan) Seed(seed uint64) *FuzzPlan {
+	p.seed = seed
+	return p
+}
+
+// Timeout sets how long to read the reply to each input before moving on,
+// 100ms by default.
+func (p *FuzzPlan) Timeout(d time.Duration) *FuzzPlan {
+	p.wait = d
+	return p
+}
+
+// Probe sets an assertion that must pass after every input, e.g. a health
+// check. Without one, the process only has to keep accepting connections.
+func (p *FuzzPlan) Probe(probe Assert) *FuzzPlan {
+	p.probe = probe
+	return p
+}
+
+func (p *FuzzPlan) T() *FuzzAssert {
+	return &FuzzAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// FuzzAssert checks that a process survives every input of a fuzz plan.
+type FuzzAssert struct {
+	AssertBase
+
+	plan *FuzzPlan
+	// input is the input that failed, which is the run'th generated one,
+	// or the corpus file replayed.
+	input  []byte
+	run    int
+	replay string
+	reason string
+	saved  string
+}
+
+func (a *FuzzAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+}
+
+func (a *FuzzAssert) execute() bool {
+	p := a.plan
+	a.input, a.run, a.replay, a.reason, a.saved = nil, 0, "", "", ""
+
+	for _, file := range p.corpusFiles() {
+		input, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		if reason := a.try(input); reason != "" {
+			a.input, a.replay, a.reason = input, file, reason
+			return false
+		}
+	}
+
+	r := newRand(p.seed)
+	for i := range p.runs {
+		if p.ctx.Err() != nil {
+			return false
+		}
+
+		input := p.generate(r)
+		if reason := a.try(input); reason != "" {
+			a.input, a.run, a.reason = input, i+1, reason
+			a.saved = p.save(input)
+			return false
+		}
+	}
+
+	return true
+}
+
+// corpusFiles returns the saved inputs for the process in name order.
+func (p *FuzzPlan) corpusFiles() []string {
+	entries, err := os.ReadDir(p.corpus)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(p.corpus, entry.Name()))
+		}
+	}
+
+	slices.Sort(files)
+	return files
+}
+
+// save writes a failing input to the corpus, named by its hash, and
+// returns the path or why it could not.
+func (p *FuzzPlan) save(input []byte) string {
+	sum := sha256.Sum256(input)
+	path := filepath.Join(p.corpus, hex.EncodeToString(sum[:8]))
+
+	err := os.MkdirAll(p.corpus, 0755)
+	if err == nil {
+		err = os.WriteFile(path, input, 0644)
+	}
+	if err != nil {
+		return fmt.Sprintf("not saved: %v", err)
+	}
+
+	return path
+}
+
+// try sends input on a new connection and returns why the process did not
+// survive it, or "" if it did.
+func (a *FuzzAssert) try(input []byte) string {
+	p := a.plan
+	addr := p.do.addr(p.process)
+
+	conn, err := net.DialTimeout("tcp", addr, a.config.ExecuteTimeout)
+	if err != nil {
+		return fmt.Sprintf("could not connect: %v", err)
+	}
+
+	conn.Write(input)
+	conn.SetReadDeadline(time.Now().Add(p.wait))
+	buf := make([]byte, 4096)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			break
+		}
+	}
+	conn.Close()
+
+	proc := p.do.getProcess(p.process)
+	if proc.hasExited() {
+		return fmt.Sprintf("process exited: %s", proc.state)
+	}
+
+	if p.probe == nil {
+		probe, err := net.DialTimeout("tcp", addr, a.config.ExecuteTimeout)
+		if err != nil {
+			return fmt.Sprintf("stopped accepting connections: %v", err)
+		}
+		probe.Close()
+		return ""
+	}
+
+	return runProbe(p.probe)
+}
+
+// runProbe runs the probe and returns its failure, or "" if it passed.
+func runProbe(probe Assert) (reason string) {
+	defer func() {
+		if err := recover(); err != nil {
+			reason = "probe failed: " + strings.ReplaceAll(fmt.Sprint(err), "\n", "\n  ")
+		}
+	}()
+
+	// The probe fails the input rather than a soft test
+	probe.base().strict = true
+	probe.Assert("")
+	return ""
+}
+
+func (a *FuzzAssert) check() {
+	if a.reason == "" {
+		return
+	}
+
+	p := a.plan
+	input := fmt.Sprintf("%q", a.input)
+	if len(a.input) > 256 {
+		input = fmt.Sprintf("%q... (%d bytes)", a.input[:256], len(a.input))
+	}
+
+	if a.replay != "" {
+		msg := fmt.Sprintf("Fuzz %s\n  Expected the process to survive the corpus input %s\n  Input: %s\n  Actual: %s%s",
+			p.process, a.replay, input, a.reason, a.formatHelp())
+		panic(msg)
+	}
+
+	msg := fmt.Sprintf("Fuzz %s\n  Expected the process to survive %d inputs\n  Input %d: %s\n  Actual: %s\n  Seed: %d\n  Saved: %s%s",
+		p.process, p.runs, a.run, input, a.reason, p.seed, a.saved, a.formatHelp())
+	panic(msg)
+}
@@ -0,0 +1,167 @@
+package attest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goldenContext is the number of unchanged lines shown around each change
+// in a golden file diff.
+const goldenContext = 2
+
+// goldenChecker compares a value to a golden file.
+type goldenChecker struct {
+	config *Config
+	name   string
+}
+
+// Golden expects the response body to equal the golden file name from
+// Config.Golden. With Config.UpdateGolden set, the body is written to the
+// file in Config.GoldenDir instead.
+func (a *HTTPAssert) Golden(name string) *HTTPAssert {
+	a.bodyCheckers = append(a.bodyCheckers, goldenChecker{config: a.config, name: name})
+	return a
+}
+
+// Golden expects the command output to equal the golden file name from
+// Config.Golden. With Config.UpdateGolden set, the output is written to the
+// file in Config.GoldenDir instead.
+func (a *CLIAssert) Golden(name string) *CLIAssert {
+	a.outputCheckers = append(a.outputCheckers, goldenChecker{config: a.config, name: name})
+	return a
+}
+
+// Golden expects the bytes received to equal the golden file name from
+// Config.Golden. With Config.UpdateGolden set, they are written to the file
+// in Config.GoldenDir instead.
+func (a *TCPAssert) Golden(name string) *TCPAssert {
+	a.receivedCheckers = append(a.receivedCheckers, goldenChecker{config: a.config, name: name})
+	return a
+}
+
+func (m goldenChecker) Check(actual string) bool {
+	if m.config.UpdateGolden {
+		m.update(actual)
+		return true
+	}
+
+	golden, err := m.read()
+	return err == nil && golden == actual
+}
+
+func (m goldenChecker) Expected() string {
+	return fmt.Sprintf("matching golden file %s", m.name)
+}
+
+func (m goldenChecker) explain(actual string) string {
+	golden, err := m.read()
+	if err != nil {
+		return fmt.Sprintf("Golden file: %v", err)
+	}
+
+	return "Diff (- golden, + actual):\n    " + strings.Join(goldenDiff(golden, actual), "\n    ")
+}
+
+// read returns the golden file, preferring GoldenDir so authors see their
+// edits without rebuilding.
+func (m goldenChecker) read() (string, error) {
+	var bytes []byte
+	var err error
+	switch {
+	case m.config.GoldenDir != "":
+		bytes, err = os.ReadFile(filepath.Join(m.config.GoldenDir, m.name))
+	case m.config.Golden != nil:
+		bytes, err = fs.ReadFile(m.config.Golden, m.name)
+	default:
+		err = errors.New("no golden files configured")
+	}
+
+	return string(bytes), err
+}
+
+// update writes actual to the golden file in GoldenDir.
+func (m goldenChecker) update(actual string) {
+	if m.config.GoldenDir == "" {
+		panic("UpdateGolden requires GoldenDir")
+	}
+
+	path := filepath.Join(m.config.GoldenDir, m.name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		panic(fmt.Sprintf("An error occurred: %v", err))
+	}
+	if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+		panic(fmt.Sprintf("An error occurred: %v", err))
+	}
+}
+
+// goldenDiff returns a line diff of golden and actual, marking removed
+// lines with - and added lines with +. Unchanged lines far from any change
+// are collapsed into "...".
+func goldenDiff(golden, actual string) []string {
+	a := strings.Split(golden, "\n")
+	b := strings.Split(actual, "\n")
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	return collapseUnchanged(lines)
+}
+
+// collapseUnchanged replaces runs of unchanged diff lines more than
+// goldenContext lines away from a change with "...".
+func collapseUnchanged(lines []string) []string {
+	near := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		for k := max(i-goldenContext, 0); k <= min(i+goldenContext, len(lines)-1); k++ {
+			near[k] = true
+		}
+	}
+
+	var collapsed []string
+	for i, line := range lines {
+		switch {
+		case near[i]:
+			collapsed = append(collapsed, line)
+		case i == 0 || near[i-1]:
+			collapsed = append(collapsed, "  ...")
+		}
+	}
+
+	return collapsed
+}
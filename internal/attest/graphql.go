@@ -0,0 +1,453 @@
+package attest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/tidwall/gjson"
+)
+
+var _ Plan[*GraphQLPlan, *GraphQLAssert] = (*GraphQLPlan)(nil)
+var _ Assert = (*GraphQLAssert)(nil)
+
+// GraphQLPlan represents a test plan for a GraphQL operation. Queries and
+// mutations are posted over HTTP; subscriptions use the
+// graphql-transport-ws protocol over a WebSocket.
+type GraphQLPlan struct {
+	PlanBase
+
+	process      string
+	addr         string
+	path         string
+	query        string
+	variables    json.RawMessage
+	operation    string
+	headers      H
+	subscription bool
+	wait         time.Duration
+}
+
+// Variables sets the operation variables, given as a JSON object.
+func (p *GraphQLPlan) Variables(variables string) *GraphQLPlan {
+	if !json.Valid([]byte(variables)) {
+		panic(fmt.Sprintf("GraphQL variables are not valid JSON: %s", variables))
+	}
+
+	p.variables = json.RawMessage(variables)
+	return p
+}
+
+// Operation selects the named operation when the document has several.
+func (p *GraphQLPlan) Operation(name string) *GraphQLPlan {
+	p.operation = name
+	return p
+}
+
+// Path sets the endpoint path. The default is /graphql.
+func (p *GraphQLPlan) Path(path string) *GraphQLPlan {
+	p.path = path
+	return p
+}
+
+// Headers sets headers sent with the request or the WebSocket upgrade.
+func (p *GraphQLPlan) Headers(headers H) *GraphQLPlan {
+	p.headers = headers
+	return p
+}
+
+// Subscription runs the operation as a subscription over a WebSocket. The
+// events expected with Next are read, then the subscription is completed.
+func (p *GraphQLPlan) Subscription() *GraphQLPlan {
+	p.subscription = true
+	return p
+}
+
+// MessageTimeout sets how long to wait for each subscription event.
+// The default is Config.ExecuteTimeout.
+func (p *GraphQLPlan) MessageTimeout(d time.Duration) *GraphQLPlan {
+	p.wait = d
+	return p
+}
+
+func (p *GraphQLPlan) Eventually() *GraphQLPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *GraphQLPlan) Within(timeout time.Duration) *GraphQLPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *GraphQLPlan) Retry(policy RetryPolicy) *GraphQLPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *GraphQLPlan) Consistently() *GraphQLPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *GraphQLPlan) For(timeout time.Duration) *GraphQLPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *GraphQLPlan) Every(interval time.Duration) *GraphQLPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *GraphQLPlan) Deadline(d time.Duration) *GraphQLPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *GraphQLPlan) T() *GraphQLAssert {
+	return &GraphQLAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// payload returns the operation as a GraphQL request object.
+func (p *GraphQLPlan) payload() []byte {
+	request := struct {
+		Query         string          `json:"query"`
+		Variables     json.RawMessage `json:"variables,omitempty"`
+		OperationName string          `json:"operationName,omitempty"`
+	}{p.query, p.variables, p.operation}
+
+	data, _ := json.Marshal(request)
+	return data
+}
+
+// GraphQLAssert provides assertions on the result of a GraphQL operation.
+type GraphQLAssert struct {
+	AssertBase
+
+	plan   *GraphQLPlan
+	status int
+	data   string
+	errors []string
+	events []string
+	// failed is the index of the event expectation that was not met.
+	failed int
+	err    error
+
+	statusCheckers []Checker[int]
+	dataCheckers   []Checker[string]
+	errorCheckers  []Checker[string]
+	expected       [][]Checker[string]
+}
+
+// Status adds checkers for the HTTP status of a query or mutation.
+// All checkers must pass.
+func (a *GraphQLAssert) Status(checkers ...Checker[int]) *GraphQLAssert {
+	a.statusCheckers = append(a.statusCheckers, checkers...)
+	return a
+}
+
+// Data adds checkers for the field at the given gjson path under data.
+// All checkers must pass.
+func (a *GraphQLAssert) Data(path string, checkers ...Checker[string]) *GraphQLAssert {
+	for _, checker := range checkers {
+		a.dataCheckers = append(a.dataCheckers, JSON(path, checker))
+	}
+
+	return a
+}
+
+// Error expects an entry in errors whose message passes all checkers.
+// Without it, any entry in errors fails the assertion.
+func (a *GraphQLAssert) Error(checkers ...Checker[string]) *GraphQLAssert {
+	a.errorCheckers = append(a.errorCheckers, checkers...)
+	return a
+}
+
+// Next expects the next subscription event to carry data passing all
+// checkers, usually built with JSON.
+func (a *GraphQLAssert) Next(checkers ...Checker[string]) *GraphQLAssert {
+	a.expected = append(a.expected, checkers)
+	return a
+}
+
+func (a *GraphQLAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *GraphQLAssert) execute() bool {
+	p := a.plan
+	a.status, a.data, a.errors, a.events, a.failed, a.err = 0, "", nil, nil, -1, nil
+
+	if p.subscription {
+		a.err = a.subscribe()
+	} else {
+		a.err = a.post()
+	}
+	if a.err != nil {
+		return false
+	}
+
+	return a.passes()
+}
+
+// post sends a query or mutation over HTTP.
+func (a *GraphQLAssert) post() error {
+	p := a.plan
+	body := p.payload()
+	p.config.Recorder.record(Entry{Kind: EntryHTTP, Process: p.process, Method: "POST", Path: p.path, Headers: p.headers, Body: string(body)})
+
+	req, err := http.NewRequestWithContext(p.ctx, "POST", "http://"+p.addr+p.path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: a.config.ExecuteTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	a.status = resp.StatusCode
+	if !gjson.ValidBytes(respBody) {
+		return fmt.Errorf("response is not JSON: %q", respBody)
+	}
+
+	result := gjson.ParseBytes(respBody)
+	a.data = result.Get("data").Raw
+	a.errors = errorMessages(result.Get("errors"))
+
+	return nil
+}
+
+// errorMessages returns the message of each entry in a GraphQL errors list.
+func errorMessages(errors gjson.Result) []string {
+	var messages []string
+	for _, entry := range errors.Array() {
+		messages = append(messages, entry.Get("message").String())
+	}
+
+	return messages
+}
+
+// subscribe runs a subscription over graphql-transport-ws and reads the
+// expected events.
+func (a *GraphQLAssert) subscribe() error {
+	p := a.plan
+
+	wait := p.wait
+	if wait == 0 {
+		wait = a.config.ExecuteTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
+	defer cancel()
+
+	header := http.Header{}
+	for key, value := range p.headers {
+		header.Set(key, value)
+	}
+
+	conn, _, err := websocket.Dial(ctx, "ws://"+p.addr+p.path, &websocket.DialOptions{
+		HTTPHeader:   header,
+		Subprotocols: []string{"graphql-transport-ws"},
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+
+	send := func(message string) error {
+		return conn.Write(ctx, websocket.MessageText, []byte(message))
+	}
+
+	err = send(`{"type":"connection_init","payload":{}}`)
+	if err != nil {
+		return err
+	}
+	msg, err := readMessage(p.ctx, conn, wait)
+	if err != nil {
+		return fmt.Errorf("connection_init: %w", err)
+	}
+	if typ := gjson.Get(msg.data, "type").String(); typ != "connection_ack" {
+		return fmt.Errorf("connection_init: expected connection_ack, got %q", msg.data)
+	}
+
+	subscribe := fmt.Sprintf(`{"id":"1","type":"subscribe","payload":%s}`, p.payload())
+	err = send(subscribe)
+	if err != nil {
+		return err
+	}
+
+	for i, checkers := range a.expected {
+		event, done, err := a.nextEvent(p.ctx, conn, wait)
+		if err != nil || done {
+			a.failed = i
+			return err
+		}
+
+		a.events = append(a.events, event)
+		if !checkAll(event, checkers, nil) {
+			a.failed = i
+			return nil
+		}
+	}
+
+	send(`{"id":"1","type":"complete"}`)
+	conn.Close(websocket.StatusNormalClosure, "")
+	return nil
+}
+
+// nextEvent reads until the next event and returns its data, answering
+// pings and collecting errors on the way. done is set when the server ends
+// the subscription first.
+func (a *GraphQLAssert) nextEvent(ctx context.Context, conn *websocket.Conn, wait time.Duration) (string, bool, error) {
+	for {
+		msg, err := readMessage(ctx, conn, wait)
+		if err != nil {
+			return "", false, err
+		}
+
+		message := gjson.Parse(msg.data)
+		switch message.Get("type").String() {
+		case "ping":
+			conn.Write(ctx, websocket.MessageText, []byte(`{"type":"pong"}`))
+		case "next":
+			a.errors = append(a.errors, errorMessages(message.Get("payload.errors"))...)
+			return message.Get("payload.data").Raw, false, nil
+		case "error":
+			a.errors = append(a.errors, errorMessages(message.Get("payload"))...)
+			return "", true, nil
+		case "complete":
+			return "", true, nil
+		}
+	}
+}
+
+// passes reports whether the result meets every expectation.
+func (a *GraphQLAssert) passes() bool {
+	if a.failed >= 0 {
+		return false
+	}
+
+	if len(a.errorCheckers) > 0 {
+		if !a.hasError() {
+			return false
+		}
+	} else if len(a.errors) > 0 {
+		return false
+	}
+
+	return checkAll(a.status, a.statusCheckers, nil) &&
+		checkAll(a.data, a.dataCheckers, nil)
+}
+
+// hasError reports whether an error message passes all error checkers.
+func (a *GraphQLAssert) hasError() bool {
+	for _, message := range a.errors {
+		if checkAll(message, a.errorCheckers, nil) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *GraphQLAssert) check() {
+	p := a.plan
+
+	kind := "query"
+	if p.subscription {
+		kind = "subscription"
+	}
+	title := fmt.Sprintf("GraphQL %s %s%s\n  Query: %s", kind, p.addr, p.path, strings.Join(strings.Fields(p.query), " "))
+	if p.variables != nil {
+		title += "\n  Variables: " + string(p.variables)
+	}
+
+	if a.failed >= 0 {
+		if a.failed >= len(a.events) {
+			actual := "the subscription ended"
+			if a.err != nil {
+				actual = a.err.Error()
+			}
+			if len(a.errors) > 0 {
+				actual += fmt.Sprintf(" with errors %q", a.errors)
+			}
+
+			msg := fmt.Sprintf("%s\n  Expected event %d\n  Actual: %s%s", title, a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(a.events[a.failed], a.expected[a.failed], func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected event %d: %s\n  Actual event %d: %s%s",
+				title, a.failed+1, m.Expected(), a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	if len(a.errorCheckers) == 0 && len(a.errors) > 0 {
+		msg := fmt.Sprintf("%s\n  Expected no errors\n  Actual errors: %q%s", title, a.errors, a.formatHelp())
+		panic(msg)
+	}
+	if len(a.errorCheckers) > 0 && !a.hasError() {
+		expected := make([]string, 0, len(a.errorCheckers))
+		for _, checker := range a.errorCheckers {
+			expected = append(expected, checker.Expected())
+		}
+
+		msg := fmt.Sprintf("%s\n  Expected an error: %s\n  Actual errors: %q%s",
+			title, strings.Join(expected, ", "), a.errors, a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.status, a.statusCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected status: %s\n  Actual status: %d %s%s",
+			title, m.Expected(), actual, http.StatusText(actual), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.data, a.dataCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected data: %s\n  Actual data: %s%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
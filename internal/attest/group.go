@@ -0,0 +1,221 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var _ Assert = (*GroupAssert)(nil)
+
+// GroupPlan runs a set of assertions concurrently and checks their outcome
+// as a whole, e.g. that concurrent increments each saw a distinct value.
+type GroupPlan struct {
+	ctx     context.Context
+	config  *Config
+	members []Assert
+}
+
+func (p *GroupPlan) T() *GroupAssert {
+	return &GroupAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// GroupAssert provides assertions on the outcome of a group of assertions.
+type GroupAssert struct {
+	AssertBase
+
+	plan *GroupPlan
+	// failures holds each member's failure, or nil if it passed.
+	failures []any
+	results  []string
+
+	succeededCheckers []Checker[int]
+	resultsCheckers   []Checker[[]string]
+}
+
+// Succeeded adds checkers for the number of members that passed, e.g.
+// AtLeast(2) for a quorum. Without it, every member must pass.
+// All checkers must pass.
+func (a *GroupAssert) Succeeded(checkers ...Checker[int]) *GroupAssert {
+	a.succeededCheckers = append(a.succeededCheckers, checkers...)
+	return a
+}
+
+// Results adds checkers for what each member received, in the order the
+// members were given: the response body for HTTP, the output for CLI, and
+// the bytes read for TCP. All checkers must pass.
+func (a *GroupAssert) Results(checkers ...Checker[[]string]) *GroupAssert {
+	a.resultsCheckers = append(a.resultsCheckers, checkers...)
+	return a
+}
+
+func (a *GroupAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+}
+
+func (a *GroupAssert) execute() bool {
+	members := a.plan.members
+	a.failures = make([]any, len(members))
+	a.results = make([]string, len(members))
+
+	var wg sync.WaitGroup
+	for i, member := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				a.failures[i] = recover()
+				if r, ok := member.(resulter); ok {
+					a.results[i] = r.result()
+				}
+			}()
+
+			// Members fail as usual in a soft test, so the group sees it
+			member.base().strict = true
+			member.Assert("")
+		}()
+	}
+	wg.Wait()
+
+	return checkAll(a.succeeded(), a.succeededOrAll(), nil) &&
+		checkAll(a.results, a.resultsCheckers, nil)
+}
+
+// succeeded returns the number of members that passed.
+func (a *GroupAssert) succeeded() int {
+	n := 0
+	for _, failure := range a.failures {
+		if failure == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// succeededOrAll returns the Succeeded checkers, or one requiring every
+// member to pass.
+func (a *GroupAssert) succeededOrAll() []Checker[int] {
+	if len(a.succeededCheckers) == 0 {
+		return []Checker[int]{Is(len(a.plan.members))}
+	}
+
+	return a.succeededCheckers
+}
+
+// firstFailure returns the first failed member's message, indented.
+func (a *GroupAssert) firstFailure() string {
+	for i, failure := range a.failures {
+		if failure != nil {
+			msg := strings.TrimSpace(fmt.Sprint(failure))
+			return fmt.Sprintf("\n  Failure of plan %d:\n    %s", i+1, strings.ReplaceAll(msg, "\n", "\n    "))
+		}
+	}
+
+	return ""
+}
+
+func (a *GroupAssert) check() {
+	title := fmt.Sprintf("Group of %d plans", len(a.plan.members))
+
+	checkAll(a.succeeded(), a.succeededOrAll(), func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected passed: %s\n  Actual passed: %d%s%s",
+			title, m.Expected(), actual, a.firstFailure(), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.results, a.resultsCheckers, func(m Checker[[]string], actual []string) {
+		msg := fmt.Sprintf("%s\n  Expected results: %s\n  Actual results: %q%s%s",
+			title, m.Expected(), actual, explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+}
+
+// resulter is implemented by asserts whose result a group can compare.
+type resulter interface {
+	result() string
+}
+
+func (a *HTTPAssert) result() string {
+	return a.responseBody
+}
+
+func (a *CLIAssert) result() string {
+	return a.output
+}
+
+func (a *TCPAssert) result() string {
+	return a.received
+}
+
+// allEqualChecker validates that every element of a slice is the same.
+type allEqualChecker[T comparable] struct{}
+
+// AllEqual creates a checker that accepts slices whose elements are all
+// equal, e.g. replicas agreeing on a value.
+func AllEqual[T comparable]() allEqualChecker[T] {
+	return allEqualChecker[T]{}
+}
+
+func (m allEqualChecker[T]) Check(actual []T) bool {
+	for _, v := range actual {
+		if v != actual[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m allEqualChecker[T]) Expected() string {
+	return "all equal"
+}
+
+func (m allEqualChecker[T]) explain(actual []T) string {
+	for i, v := range actual {
+		if v != actual[0] {
+			return fmt.Sprintf("Element %d is %#v, element 1 is %#v", i+1, v, actual[0])
+		}
+	}
+
+	return ""
+}
+
+// distinctChecker validates that no two elements of a slice are equal.
+type distinctChecker[T comparable] struct{}
+
+// Distinct creates a checker that accepts slices without duplicates, e.g.
+// concurrent increments each returning a different count.
+func Distinct[T comparable]() distinctChecker[T] {
+	return distinctChecker[T]{}
+}
+
+func (m distinctChecker[T]) Check(actual []T) bool {
+	return m.explain(actual) == ""
+}
+
+func (m distinctChecker[T]) Expected() string {
+	return "all distinct"
+}
+
+func (m distinctChecker[T]) explain(actual []T) string {
+	seen := make(map[T]int, len(actual))
+	for i, v := range actual {
+		if j, ok := seen[v]; ok {
+			return fmt.Sprintf("Elements %d and %d are both %#v", j+1, i+1, v)
+		}
+		seen[v] = i
+	}
+
+	return ""
+}
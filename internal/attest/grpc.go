@@ -0,0 +1,542 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var _ Plan[*GRPCPlan, *GRPCAssert] = (*GRPCPlan)(nil)
+var _ Assert = (*GRPCAssert)(nil)
+
+// GRPCPlan represents a test plan for a unary gRPC call. Messages are given
+// and asserted as JSON, using descriptors from server reflection or from a
+// compiled descriptor set.
+type GRPCPlan struct {
+	PlanBase
+
+	grpcTarget
+
+	process  string
+	request  string
+	metadata H
+}
+
+// grpcTarget identifies a gRPC method and how to find its descriptor.
+type grpcTarget struct {
+	addr        string
+	service     string
+	method      string
+	descriptors string
+
+	// desc is resolved on first execution.
+	desc protoreflect.MethodDescriptor
+}
+
+// Metadata sets request metadata.
+func (p *GRPCPlan) Metadata(md H) *GRPCPlan {
+	p.metadata = md
+	return p
+}
+
+// Descriptors loads message types from a descriptor set compiled with
+// 'protoc --include_imports -o <path>' instead of using server reflection.
+func (p *GRPCPlan) Descriptors(path string) *GRPCPlan {
+	p.descriptors = path
+	return p
+}
+
+func (p *GRPCPlan) Eventually() *GRPCPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *GRPCPlan) Within(timeout time.Duration) *GRPCPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *GRPCPlan) Retry(policy RetryPolicy) *GRPCPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *GRPCPlan) Consistently() *GRPCPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *GRPCPlan) For(timeout time.Duration) *GRPCPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *GRPCPlan) Every(interval time.Duration) *GRPCPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *GRPCPlan) Deadline(d time.Duration) *GRPCPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *GRPCPlan) T() *GRPCAssert {
+	return &GRPCAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// fullMethod returns the method in the form used on the wire.
+func (p *grpcTarget) fullMethod() string {
+	return "/" + p.service + "/" + p.method
+}
+
+// splitMethod splits "pkg.Service/Method", with or without a leading slash.
+func splitMethod(method string) (string, string) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		panic(fmt.Sprintf("invalid gRPC method %q, expected pkg.Service/Method", method))
+	}
+
+	return service, name
+}
+
+// resolve finds the method descriptor, once per plan.
+func (p *grpcTarget) resolve(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	if p.desc != nil {
+		return p.desc, nil
+	}
+
+	var files *protoregistry.Files
+	var err error
+	if p.descriptors != "" {
+		files, err = loadDescriptorSet(p.descriptors)
+	} else {
+		files, err = reflectFiles(ctx, conn, p.service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(p.service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", p.service, err)
+	}
+
+	service, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", p.service)
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(p.method))
+	if method == nil {
+		return nil, fmt.Errorf("service %s has no method %s", p.service, p.method)
+	}
+
+	p.desc = method
+	return method, nil
+}
+
+// loadDescriptorSet reads a compiled FileDescriptorSet.
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	err = proto.Unmarshal(bytes, &set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %w", path, err)
+	}
+
+	return protodesc.NewFiles(&set)
+}
+
+// reflectFiles fetches the files defining service, and their dependencies,
+// from the server reflection service. Servers offering only the v1alpha
+// API are supported too.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	fetch, closeStream, err := reflectionV1(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection failed: %w", err)
+	}
+
+	raw, err := fetch(service, "")
+	if status.Code(err) == codes.Unimplemented {
+		closeStream()
+
+		fetch, closeStream, err = reflectionV1Alpha(ctx, conn)
+		if err != nil {
+			return nil, fmt.Errorf("server reflection failed: %w", err)
+		}
+
+		raw, err = fetch(service, "")
+	}
+	defer func() { closeStream() }()
+
+	if err != nil {
+		return nil, fmt.Errorf("server reflection failed: %w", err)
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	add := func(raw [][]byte) error {
+		for _, b := range raw {
+			file := &descriptorpb.FileDescriptorProto{}
+			err := proto.Unmarshal(b, file)
+			if err != nil {
+				return err
+			}
+			files[file.GetName()] = file
+		}
+
+		return nil
+	}
+
+	err = add(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch dependencies the server did not include
+	for missing := true; missing; {
+		missing = false
+		for _, file := range files {
+			for _, dep := range file.GetDependency() {
+				if _, ok := files[dep]; ok {
+					continue
+				}
+
+				raw, err := fetch("", dep)
+				if err != nil {
+					return nil, fmt.Errorf("server reflection failed for %s: %w", dep, err)
+				}
+				err = add(raw)
+				if err != nil {
+					return nil, err
+				}
+				missing = true
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+// reflectionFetch returns the serialized files defining symbol or named filename.
+type reflectionFetch func(symbol, filename string) ([][]byte, error)
+
+// reflectionV1 opens a v1 server reflection stream.
+func reflectionV1(ctx context.Context, conn *grpc.ClientConn) (reflectionFetch, func(), error) {
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fetch := func(symbol, filename string) ([][]byte, error) {
+		req := &reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		}
+		if filename != "" {
+			req.MessageRequest = &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: filename}
+		}
+
+		err := stream.Send(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+
+		return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+	}
+
+	return fetch, func() { stream.CloseSend() }, nil
+}
+
+// reflectionV1Alpha opens a v1alpha server reflection stream.
+func reflectionV1Alpha(ctx context.Context, conn *grpc.ClientConn) (reflectionFetch, func(), error) {
+	stream, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fetch := func(symbol, filename string) ([][]byte, error) {
+		req := &reflectionv1alpha.ServerReflectionRequest{
+			MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		}
+		if filename != "" {
+			req.MessageRequest = &reflectionv1alpha.ServerReflectionRequest_FileByFilename{FileByFilename: filename}
+		}
+
+		err := stream.Send(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+
+		return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+	}
+
+	return fetch, func() { stream.CloseSend() }, nil
+}
+
+// grpcJSON encodes messages for assertions, with field names as written in
+// the .proto file and zero values included.
+var grpcJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// metadataChecker checks a header or trailer metadata value.
+type metadataChecker struct {
+	key     string
+	trailer bool
+	checker Checker[string]
+}
+
+// value returns the joined values of the checked key.
+func (m metadataChecker) value(header, trailer metadata.MD) string {
+	md := header
+	if m.trailer {
+		md = trailer
+	}
+
+	return strings.Join(md.Get(m.key), ", ")
+}
+
+// kind returns where the checked key is sent.
+func (m metadataChecker) kind() string {
+	if m.trailer {
+		return "trailer"
+	}
+
+	return "header"
+}
+
+// GRPCAssert provides assertions on the result of a gRPC call.
+type GRPCAssert struct {
+	AssertBase
+
+	plan     *GRPCPlan
+	code     codes.Code
+	message  string
+	response string
+	header   metadata.MD
+	trailer  metadata.MD
+	err      error
+
+	codeCheckers     []Checker[codes.Code]
+	messageCheckers  []Checker[string]
+	responseCheckers []Checker[string]
+	jsonCheckers     []Checker[string]
+	metadataCheckers []metadataChecker
+}
+
+// Code adds checkers for the status code.
+// All checkers must pass.
+func (a *GRPCAssert) Code(checkers ...Checker[codes.Code]) *GRPCAssert {
+	a.codeCheckers = append(a.codeCheckers, checkers...)
+	return a
+}
+
+// StatusMessage adds checkers for the status message of a failed call.
+// All checkers must pass.
+func (a *GRPCAssert) StatusMessage(checkers ...Checker[string]) *GRPCAssert {
+	a.messageCheckers = append(a.messageCheckers, checkers...)
+	return a
+}
+
+// Response adds checkers for the response message encoded as JSON.
+// All checkers must pass.
+func (a *GRPCAssert) Response(checkers ...Checker[string]) *GRPCAssert {
+	a.responseCheckers = append(a.responseCheckers, checkers...)
+	return a
+}
+
+// JSON adds checkers for a field of the response at the given gjson path.
+// All checkers must pass.
+func (a *GRPCAssert) JSON(path string, checkers ...Checker[string]) *GRPCAssert {
+	for _, checker := range checkers {
+		a.jsonCheckers = append(a.jsonCheckers, JSON(path, checker))
+	}
+
+	return a
+}
+
+// Header adds checkers for a response header metadata value. Multiple
+// values are joined with ", ". All checkers must pass.
+func (a *GRPCAssert) Header(key string, checkers ...Checker[string]) *GRPCAssert {
+	for _, checker := range checkers {
+		a.metadataCheckers = append(a.metadataCheckers, metadataChecker{key: key, checker: checker})
+	}
+
+	return a
+}
+
+// Trailer adds checkers for a response trailer metadata value. Multiple
+// values are joined with ", ". All checkers must pass.
+func (a *GRPCAssert) Trailer(key string, checkers ...Checker[string]) *GRPCAssert {
+	for _, checker := range checkers {
+		a.metadataCheckers = append(a.metadataCheckers, metadataChecker{key: key, trailer: true, checker: checker})
+	}
+
+	return a
+}
+
+func (a *GRPCAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *GRPCAssert) execute() bool {
+	p := a.plan
+	a.code, a.message, a.response, a.header, a.trailer, a.err = codes.Unknown, "", "", nil, nil, nil
+
+	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(p.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.Close()
+
+	desc, err := p.resolve(ctx, conn)
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	req := dynamicpb.NewMessage(desc.Input())
+	err = protojson.Unmarshal([]byte(p.request), req)
+	if err != nil {
+		panic(fmt.Sprintf("invalid request for %s: %v", p.fullMethod(), err))
+	}
+
+	for key, value := range p.metadata {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+
+	resp := dynamicpb.NewMessage(desc.Output())
+	err = conn.Invoke(ctx, p.fullMethod(), req, resp, grpc.Header(&a.header), grpc.Trailer(&a.trailer))
+
+	st := status.Convert(err)
+	a.code, a.message = st.Code(), st.Message()
+	if err == nil {
+		bytes, err := grpcJSON.Marshal(resp)
+		if err != nil {
+			a.err = err
+			return false
+		}
+		a.response = string(bytes)
+	}
+
+	return a.passes()
+}
+
+// passes reports whether the call meets every expectation.
+func (a *GRPCAssert) passes() bool {
+	for _, m := range a.metadataCheckers {
+		if !m.checker.Check(m.value(a.header, a.trailer)) {
+			return false
+		}
+	}
+
+	return checkAll(a.code, a.codeCheckers, nil) &&
+		checkAll(a.message, a.messageCheckers, nil) &&
+		checkAll(a.response, a.responseCheckers, nil) &&
+		checkAll(a.response, a.jsonCheckers, nil)
+}
+
+func (a *GRPCAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("gRPC %s\n  Request: %s", p.fullMethod(), p.request)
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	checkAll(a.code, a.codeCheckers, func(m Checker[codes.Code], actual codes.Code) {
+		msg := fmt.Sprintf("%s\n  Expected status: %s\n  Actual status: %s %q%s",
+			title, m.Expected(), actual, a.message, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.message, a.messageCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected status message: %s\n  Actual status message: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	for _, m := range a.metadataCheckers {
+		actual := m.value(a.header, a.trailer)
+		if !m.checker.Check(actual) {
+			msg := fmt.Sprintf("%s\n  Expected %s %s: %s\n  Actual %s %s: %q%s",
+				title, m.kind(), m.key, m.checker.Expected(), m.kind(), m.key, actual, a.formatHelp())
+			panic(msg)
+		}
+	}
+
+	checkAll(a.response, a.responseCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected response: %s\n  Actual response: %s (%s)%s",
+			title, m.Expected(), actual, a.code, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.response, a.jsonCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected JSON: %s\n  Actual response: %s (%s)%s",
+			title, m.Expected(), actual, a.code, a.formatHelp())
+		panic(msg)
+	})
+}
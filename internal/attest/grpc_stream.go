@@ -0,0 +1,348 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var _ Plan[*GRPCStreamPlan, *GRPCStreamAssert] = (*GRPCStreamPlan)(nil)
+var _ Assert = (*GRPCStreamAssert)(nil)
+
+// GRPCStreamPlan represents a test plan for a client-, server- or
+// bidirectional-streaming gRPC call. The scripted messages are sent in
+// order, the send side is closed, then the response stream is read until
+// the server ends it.
+type GRPCStreamPlan struct {
+	PlanBase
+	grpcTarget
+
+	process  string
+	sends    []string
+	metadata H
+	take     int
+}
+
+// Send adds a request message, given as JSON.
+func (p *GRPCStreamPlan) Send(request string) *GRPCStreamPlan {
+	p.sends = append(p.sends, request)
+	return p
+}
+
+// Take stops reading after n messages and cancels the call, for streams
+// the server never ends. The status is then Canceled.
+func (p *GRPCStreamPlan) Take(n int) *GRPCStreamPlan {
+	p.take = n
+	return p
+}
+
+// Metadata sets request metadata.
+func (p *GRPCStreamPlan) Metadata(md H) *GRPCStreamPlan {
+	p.metadata = md
+	return p
+}
+
+// Descriptors loads message types from a compiled descriptor set instead
+// of using server reflection.
+func (p *GRPCStreamPlan) Descriptors(path string) *GRPCStreamPlan {
+	p.descriptors = path
+	return p
+}
+
+func (p *GRPCStreamPlan) Eventually() *GRPCStreamPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *GRPCStreamPlan) Within(timeout time.Duration) *GRPCStreamPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *GRPCStreamPlan) Retry(policy RetryPolicy) *GRPCStreamPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *GRPCStreamPlan) Consistently() *GRPCStreamPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *GRPCStreamPlan) For(timeout time.Duration) *GRPCStreamPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *GRPCStreamPlan) Every(interval time.Duration) *GRPCStreamPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *GRPCStreamPlan) Deadline(d time.Duration) *GRPCStreamPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *GRPCStreamPlan) T() *GRPCStreamAssert {
+	return &GRPCStreamAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// GRPCStreamAssert provides assertions on the messages and final status
+// of a streaming gRPC call.
+type GRPCStreamAssert struct {
+	AssertBase
+
+	plan     *GRPCStreamPlan
+	received []string
+	code     codes.Code
+	message  string
+	header   metadata.MD
+	trailer  metadata.MD
+	// failed is the index of the expectation that was not met.
+	failed int
+	err    error
+
+	expected         [][]Checker[string]
+	countCheckers    []Checker[int]
+	codeCheckers     []Checker[codes.Code]
+	messageCheckers  []Checker[string]
+	metadataCheckers []metadataChecker
+}
+
+// Message expects the next response message, encoded as JSON, to pass all
+// checkers. Messages are matched in the order received.
+func (a *GRPCStreamAssert) Message(checkers ...Checker[string]) *GRPCStreamAssert {
+	a.expected = append(a.expected, checkers)
+	return a
+}
+
+// Count adds checkers for the number of response messages.
+// All checkers must pass.
+func (a *GRPCStreamAssert) Count(checkers ...Checker[int]) *GRPCStreamAssert {
+	a.countCheckers = append(a.countCheckers, checkers...)
+	return a
+}
+
+// Code adds checkers for the status the stream ends with.
+// All checkers must pass.
+func (a *GRPCStreamAssert) Code(checkers ...Checker[codes.Code]) *GRPCStreamAssert {
+	a.codeCheckers = append(a.codeCheckers, checkers...)
+	return a
+}
+
+// StatusMessage adds checkers for the status message the stream ends with.
+// All checkers must pass.
+func (a *GRPCStreamAssert) StatusMessage(checkers ...Checker[string]) *GRPCStreamAssert {
+	a.messageCheckers = append(a.messageCheckers, checkers...)
+	return a
+}
+
+// Header adds checkers for a response header metadata value.
+// All checkers must pass.
+func (a *GRPCStreamAssert) Header(key string, checkers ...Checker[string]) *GRPCStreamAssert {
+	for _, checker := range checkers {
+		a.metadataCheckers = append(a.metadataCheckers, metadataChecker{key: key, checker: checker})
+	}
+
+	return a
+}
+
+// Trailer adds checkers for a response trailer metadata value.
+// All checkers must pass.
+func (a *GRPCStreamAssert) Trailer(key string, checkers ...Checker[string]) *GRPCStreamAssert {
+	for _, checker := range checkers {
+		a.metadataCheckers = append(a.metadataCheckers, metadataChecker{key: key, trailer: true, checker: checker})
+	}
+
+	return a
+}
+
+func (a *GRPCStreamAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *GRPCStreamAssert) execute() bool {
+	p := a.plan
+	a.received, a.code, a.message, a.header, a.trailer, a.failed, a.err = nil, codes.Unknown, "", nil, nil, -1, nil
+
+	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(p.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.Close()
+
+	desc, err := p.resolve(ctx, conn)
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	if !desc.IsStreamingClient() && len(p.sends) != 1 {
+		panic(fmt.Sprintf("%s takes exactly one request, got %d", p.fullMethod(), len(p.sends)))
+	}
+
+	for key, value := range p.metadata {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+
+	streamDesc := &grpc.StreamDesc{
+		StreamName:    p.method,
+		ServerStreams: desc.IsStreamingServer(),
+		ClientStreams: desc.IsStreamingClient(),
+	}
+	stream, err := conn.NewStream(ctx, streamDesc, p.fullMethod())
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	for _, request := range p.sends {
+		req := dynamicpb.NewMessage(desc.Input())
+		err := protojson.Unmarshal([]byte(request), req)
+		if err != nil {
+			panic(fmt.Sprintf("invalid request for %s: %v", p.fullMethod(), err))
+		}
+
+		// A failed send means the stream is over; RecvMsg reports why
+		if stream.SendMsg(req) != nil {
+			break
+		}
+	}
+	stream.CloseSend()
+
+	for {
+		if p.take > 0 && len(a.received) == p.take {
+			cancel()
+			a.code = codes.Canceled
+			break
+		}
+
+		resp := dynamicpb.NewMessage(desc.Output())
+		err := stream.RecvMsg(resp)
+		if errors.Is(err, io.EOF) {
+			a.code = codes.OK
+			break
+		}
+		if err != nil {
+			st := status.Convert(err)
+			a.code, a.message = st.Code(), st.Message()
+			break
+		}
+
+		bytes, err := grpcJSON.Marshal(resp)
+		if err != nil {
+			a.err = err
+			return false
+		}
+		a.received = append(a.received, string(bytes))
+	}
+
+	a.header, _ = stream.Header()
+	a.trailer = stream.Trailer()
+
+	return a.passes()
+}
+
+// passes reports whether the stream meets every expectation, recording
+// the first unmet message expectation.
+func (a *GRPCStreamAssert) passes() bool {
+	for i, checkers := range a.expected {
+		if i >= len(a.received) || !checkAll(a.received[i], checkers, nil) {
+			a.failed = i
+			return false
+		}
+	}
+
+	for _, m := range a.metadataCheckers {
+		if !m.checker.Check(m.value(a.header, a.trailer)) {
+			return false
+		}
+	}
+
+	return checkAll(len(a.received), a.countCheckers, nil) &&
+		checkAll(a.code, a.codeCheckers, nil) &&
+		checkAll(a.message, a.messageCheckers, nil)
+}
+
+func (a *GRPCStreamAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("gRPC %s\n  Sent: [%s]", p.fullMethod(), strings.Join(p.sends, ", "))
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	if a.failed >= 0 {
+		if a.failed >= len(a.received) {
+			msg := fmt.Sprintf("%s\n  Expected message %d\n  Actual: stream ended after %d messages (%s %q)%s",
+				title, a.failed+1, len(a.received), a.code, a.message, a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(a.received[a.failed], a.expected[a.failed], func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected message %d: %s\n  Actual message %d: %s%s",
+				title, a.failed+1, m.Expected(), a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	checkAll(len(a.received), a.countCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected messages: %s\n  Actual messages: %d [%s]%s",
+			title, m.Expected(), actual, strings.Join(a.received, ", "), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.code, a.codeCheckers, func(m Checker[codes.Code], actual codes.Code) {
+		msg := fmt.Sprintf("%s\n  Expected status: %s\n  Actual status: %s %q%s",
+			title, m.Expected(), actual, a.message, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.message, a.messageCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected status message: %s\n  Actual status message: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	for _, m := range a.metadataCheckers {
+		actual := m.value(a.header, a.trailer)
+		if !m.checker.Check(actual) {
+			msg := fmt.Sprintf("%s\n  Expected %s %s: %s\n  Actual %s %s: %q%s",
+				title, m.kind(), m.key, m.checker.Expected(), m.kind(), m.key, actual, a.formatHelp())
+			panic(msg)
+		}
+	}
+}
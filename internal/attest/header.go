@@ -0,0 +1,212 @@
+package attest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// headerExpectation holds the checks for one response header.
+type headerExpectation struct {
+	name     string
+	checkers []Checker[string]
+	values   []Checker[[]string]
+	absent   bool
+}
+
+// cookieExpectation holds the checks for one cookie set by the response.
+type cookieExpectation struct {
+	name     string
+	checkers []Checker[*http.Cookie]
+	absent   bool
+}
+
+// Header adds checkers for a response header, matched case-insensitively.
+// Repeated headers are joined with ", ". All checkers must pass.
+func (a *HTTPAssert) Header(name string, checkers ...Checker[string]) *HTTPAssert {
+	a.headers = append(a.headers, headerExpectation{name: name, checkers: checkers})
+	return a
+}
+
+// HeaderValues adds checkers for every value of a repeated response header,
+// e.g. HasLen[[]string](2). All checkers must pass.
+func (a *HTTPAssert) HeaderValues(name string, checkers ...Checker[[]string]) *HTTPAssert {
+	a.headers = append(a.headers, headerExpectation{name: name, values: checkers})
+	return a
+}
+
+// NoHeader expects the response not to include a header.
+func (a *HTTPAssert) NoHeader(name string) *HTTPAssert {
+	a.headers = append(a.headers, headerExpectation{name: name, absent: true})
+	return a
+}
+
+// Cookie expects the response to set a cookie passing all checkers, e.g.
+// HttpOnly() or SameSite(http.SameSiteStrictMode). The last Set-Cookie for
+// the name wins.
+func (a *HTTPAssert) Cookie(name string, checkers ...Checker[*http.Cookie]) *HTTPAssert {
+	a.cookies = append(a.cookies, cookieExpectation{name: name, checkers: checkers})
+	return a
+}
+
+// NoCookie expects the response not to set a cookie.
+func (a *HTTPAssert) NoCookie(name string) *HTTPAssert {
+	a.cookies = append(a.cookies, cookieExpectation{name: name, absent: true})
+	return a
+}
+
+// responseCookie returns the last cookie the response sets with name.
+func (a *HTTPAssert) responseCookie(name string) *http.Cookie {
+	var found *http.Cookie
+	for _, line := range a.responseHeader.Values("Set-Cookie") {
+		cookie, err := http.ParseSetCookie(line)
+		if err == nil && cookie.Name == name {
+			found = cookie
+		}
+	}
+
+	return found
+}
+
+// headersPass reports whether the headers and cookies meet every
+// expectation.
+func (a *HTTPAssert) headersPass() bool {
+	for _, h := range a.headers {
+		values := a.responseHeader.Values(h.name)
+		if h.absent && len(values) > 0 {
+			return false
+		}
+		if !checkAll(strings.Join(values, ", "), h.checkers, nil) || !checkAll(values, h.values, nil) {
+			return false
+		}
+	}
+
+	for _, c := range a.cookies {
+		cookie := a.responseCookie(c.name)
+		if c.absent != (cookie == nil) {
+			return false
+		}
+		if cookie != nil && !checkAll(cookie, c.checkers, nil) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkHeaders panics if a header or cookie expectation failed.
+func (a *HTTPAssert) checkHeaders() {
+	p := a.plan
+
+	for _, h := range a.headers {
+		values := a.responseHeader.Values(h.name)
+		joined := strings.Join(values, ", ")
+		if h.absent && len(values) > 0 {
+			msg := fmt.Sprintf("%s %s\n  Expected no header %s\n  Actual header %s: %q%s",
+				p.method, p.url, h.name, h.name, joined, a.formatHelp())
+			panic(a.failure(msg, "header", "absent", ""))
+		}
+
+		checkAll(joined, h.checkers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s %s\n  Expected header %s: %s\n  Actual header %s: %q%s%s",
+				p.method, p.url, h.name, m.Expected(), h.name, actual, explain(m, actual), a.formatHelp())
+			panic(a.failure(msg, "header", m.Expected(), ""))
+		})
+
+		checkAll(values, h.values, func(m Checker[[]string], actual []string) {
+			msg := fmt.Sprintf("%s %s\n  Expected header %s values: %s\n  Actual header %s values: %q%s",
+				p.method, p.url, h.name, m.Expected(), h.name, actual, a.formatHelp())
+			panic(a.failure(msg, "header", m.Expected(), ""))
+		})
+	}
+
+	for _, c := range a.cookies {
+		cookie := a.responseCookie(c.name)
+		switch {
+		case c.absent && cookie != nil:
+			msg := fmt.Sprintf("%s %s\n  Expected no cookie %s\n  Actual cookie: %s%s",
+				p.method, p.url, c.name, cookie, a.formatHelp())
+			panic(a.failure(msg, "cookie", "absent", ""))
+		case !c.absent && cookie == nil:
+			msg := fmt.Sprintf("%s %s\n  Expected cookie %s to be set\n  Actual Set-Cookie: %q%s",
+				p.method, p.url, c.name, a.responseHeader.Values("Set-Cookie"), a.formatHelp())
+			panic(a.failure(msg, "cookie", "set", ""))
+		case cookie == nil:
+			continue
+		}
+
+		checkAll(cookie, c.checkers, func(m Checker[*http.Cookie], actual *http.Cookie) {
+			msg := fmt.Sprintf("%s %s\n  Expected cookie %s: %s\n  Actual cookie: %s%s",
+				p.method, p.url, c.name, m.Expected(), actual, a.formatHelp())
+			panic(a.failure(msg, "cookie", m.Expected(), ""))
+		})
+	}
+}
+
+// cookieChecker validates one part of a cookie.
+type cookieChecker struct {
+	expected string
+	check    func(*http.Cookie) bool
+}
+
+func (m cookieChecker) Check(actual *http.Cookie) bool {
+	return actual != nil && m.check(actual)
+}
+
+func (m cookieChecker) Expected() string {
+	return m.expected
+}
+
+// CookieValue creates a checker for a cookie's value.
+func CookieValue(checker Checker[string]) cookieChecker {
+	return cookieChecker{
+		expected: "value " + checker.Expected(),
+		check:    func(c *http.Cookie) bool { return checker.Check(c.Value) },
+	}
+}
+
+// HttpOnly creates a checker that expects a cookie to be HttpOnly.
+func HttpOnly() cookieChecker {
+	return cookieChecker{expected: "HttpOnly", check: func(c *http.Cookie) bool { return c.HttpOnly }}
+}
+
+// Secure creates a checker that expects a cookie to be Secure.
+func Secure() cookieChecker {
+	return cookieChecker{expected: "Secure", check: func(c *http.Cookie) bool { return c.Secure }}
+}
+
+// SameSite creates a checker for a cookie's SameSite attribute.
+func SameSite(mode http.SameSite) cookieChecker {
+	return cookieChecker{
+		expected: "SameSite=" + sameSiteName(mode),
+		check:    func(c *http.Cookie) bool { return c.SameSite == mode },
+	}
+}
+
+// MaxAge creates a checker for a cookie's Max-Age in seconds, which is 0
+// when the attribute is missing or deletes the cookie.
+func MaxAge(checker Checker[int]) cookieChecker {
+	return cookieChecker{
+		expected: "Max-Age " + checker.Expected(),
+		check:    func(c *http.Cookie) bool { return checker.Check(max(c.MaxAge, 0)) },
+	}
+}
+
+// CookiePath creates a checker for a cookie's Path attribute.
+func CookiePath(path string) cookieChecker {
+	return cookieChecker{expected: "Path=" + path, check: func(c *http.Cookie) bool { return c.Path == path }}
+}
+
+// sameSiteName returns the attribute value for a SameSite mode.
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return "unset"
+	}
+}
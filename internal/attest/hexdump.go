@@ -0,0 +1,164 @@
+package attest
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/littleclusters/lc/internal/style"
+)
+
+const (
+	// hexRowBytes is the number of bytes on each hexdump row.
+	hexRowBytes = 8
+	// hexRowWidth is the width of a full row of hex bytes.
+	hexRowWidth = hexRowBytes*3 - 1
+	// hexContextRows is the number of rows shown around the first difference.
+	hexContextRows = 2
+)
+
+// isBinary reports whether s has bytes that do not read as text.
+func isBinary(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+
+	for _, r := range s {
+		if (r < 0x20 && r != '\n' && r != '\r' && r != '\t') || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// payloadMismatch describes a response that failed m, as the "Expected
+// response" and "Actual response" lines of a failure. Binary payloads are
+// shown as a hexdump, side by side with the expected bytes when m is Is.
+func payloadMismatch(m Checker[string], actual string) string {
+	is, exact := m.(isChecker[string])
+	if exact && (isBinary(is.value) || isBinary(actual)) {
+		return fmt.Sprintf("  Expected response: %d bytes\n  Actual response: %d bytes\n%s",
+			len(is.value), len(actual), hexDiff(is.value, actual))
+	}
+
+	if isBinary(actual) {
+		return fmt.Sprintf("  Expected response: %s\n  Actual response: %d bytes\n%s",
+			m.Expected(), len(actual), hexDump(actual))
+	}
+
+	return fmt.Sprintf("  Expected response: %s\n  Actual response: %q", m.Expected(), actual)
+}
+
+// formatPayload follows a label with the payload quoted, or with a hexdump
+// if it is binary.
+func formatPayload(payload string) string {
+	if isBinary(payload) {
+		return fmt.Sprintf(" %d bytes\n%s", len(payload), hexDump(payload))
+	}
+
+	return fmt.Sprintf(" %q", payload)
+}
+
+// hexDump renders data as rows of offset, hex bytes and printable text.
+func hexDump(data string) string {
+	var rows []string
+	for start := 0; start < len(data); start += hexRowBytes {
+		end := min(start+hexRowBytes, len(data))
+		rows = append(rows, fmt.Sprintf("    %04x  %-*s  |%s|",
+			start, hexRowWidth, hexRow(data, start, -1), printable(data[start:end])))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// hexDiff renders expected and actual side by side around the first byte
+// that differs. Rows that differ are marked with >, and the first
+// differing byte is highlighted and pointed at in both columns.
+func hexDiff(expected, actual string) string {
+	offset := firstDifference(expected, actual)
+	rows := (max(len(expected), len(actual)) + hexRowBytes - 1) / hexRowBytes
+	first := offset / hexRowBytes
+	from, to := max(0, first-hexContextRows), min(rows, first+hexContextRows+1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  First difference at offset %d (0x%x)\n", offset, offset)
+	fmt.Fprintf(&b, "      %-6s  %-*s   %s", "offset", hexRowWidth, "expected", "actual")
+	if from > 0 {
+		b.WriteString("\n      ...")
+	}
+
+	for row := from; row < to; row++ {
+		start := row * hexRowBytes
+		marker := " "
+		if hexRow(expected, start, -1) != hexRow(actual, start, -1) {
+			marker = ">"
+		}
+
+		highlight := -1
+		if row == first {
+			highlight = offset
+		}
+		exp := hexRow(expected, start, highlight)
+		padding := hexRowWidth - len(hexRow(expected, start, -1))
+		fmt.Fprintf(&b, "\n    %s %04x    %s%s   %s",
+			marker, start, exp, strings.Repeat(" ", padding), hexRow(actual, start, highlight))
+
+		if row == first {
+			col := (offset - start) * 3
+			fmt.Fprintf(&b, "\n              %s^^%s^^",
+				strings.Repeat(" ", col), strings.Repeat(" ", hexRowWidth-2+3))
+		}
+	}
+
+	if to < rows {
+		b.WriteString("\n      ...")
+	}
+
+	return b.String()
+}
+
+// hexRow returns the bytes of data in the row at start as hex, with the
+// byte at highlight in red.
+func hexRow(data string, start, highlight int) string {
+	if start >= len(data) {
+		return ""
+	}
+
+	end := min(start+hexRowBytes, len(data))
+	parts := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		part := fmt.Sprintf("%02x", data[i])
+		if i == highlight {
+			part = style.Red(part)
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// printable returns data with non-printable bytes replaced by dots.
+func printable(data string) string {
+	b := []byte(data)
+	for i, c := range b {
+		if c < 0x20 || c > 0x7e {
+			b[i] = '.'
+		}
+	}
+
+	return string(b)
+}
+
+// firstDifference returns the offset of the first byte that differs, or the
+// length of the shorter string if one is a prefix of the other.
+func firstDifference(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,194 @@
+package attest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// HTTP2 sends the request over HTTP/2. Plain HTTP uses prior knowledge
+// (h2c) rather than an upgrade from HTTP/1.1, and TLS negotiates h2 with
+// ALPN. SSE streams stay on HTTP/1.1.
+func (p *HTTPPlan) HTTP2() *HTTPPlan {
+	p.http2 = true
+	return p
+}
+
+// Streams sends the request n times concurrently over HTTP/2. The streams
+// share one connection unless the server limits concurrent streams. Every
+// response must pass the assertions.
+func (p *HTTPPlan) Streams(n int) *HTTPPlan {
+	if !p.http2 {
+		panic("Streams() can only be called after HTTP2()")
+	}
+
+	p.streams = n
+	return p
+}
+
+// Connections adds checkers for the number of connections the HTTP/2
+// streams used. All checkers must pass.
+func (a *HTTPAssert) Connections(checkers ...Checker[int]) *HTTPAssert {
+	a.connectionsCheckers = append(a.connectionsCheckers, checkers...)
+	return a
+}
+
+// ErrorCode expects every HTTP/2 stream to fail with an error code from
+// RST_STREAM or GOAWAY, e.g. "REFUSED_STREAM", passing all checkers.
+func (a *HTTPAssert) ErrorCode(checkers ...Checker[string]) *HTTPAssert {
+	a.errorCodeCheckers = append(a.errorCodeCheckers, checkers...)
+	return a
+}
+
+// http2Result is the outcome of one HTTP/2 stream.
+type http2Result struct {
+	resp    *http.Response
+	body    string
+	latency time.Duration
+	err     error
+}
+
+// executeHTTP2 sends the plan's streams over one HTTP/2 transport and
+// checks each result.
+func (a *HTTPAssert) executeHTTP2() bool {
+	p := a.plan
+
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	if p.tls != nil {
+		transport = &http2.Transport{TLSClientConfig: p.tls.client("")}
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport, Timeout: a.config.ExecuteTimeout}
+
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			conns[info.Conn.LocalAddr().String()] = true
+			mu.Unlock()
+		},
+	}
+
+	results := make([]http2Result, max(p.streams, 1))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Go(func() {
+			start := time.Now()
+			resp, err := client.Do(p.newRequest(httptrace.WithClientTrace(p.ctx, trace)))
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			results[i] = http2Result{resp: resp, body: string(body), latency: time.Since(start), err: err}
+		})
+	}
+	wg.Wait()
+
+	a.connections = len(conns)
+	for _, r := range results {
+		a.responseStatus, a.responseBody, a.responseHeader, a.protocol, a.errorCode = 0, "", nil, "", ""
+		a.tls.state, a.tls.err = nil, nil
+
+		if r.err != nil {
+			code, ok := http2ErrorCode(r.err)
+			switch {
+			case ok:
+				a.errorCode = code
+			case p.tls != nil:
+				a.tls.err = r.err
+			default:
+				panic(fmt.Sprintf("An error occurred: %v", r.err))
+			}
+		} else {
+			a.responseStatus = r.resp.StatusCode
+			a.responseHeader = r.resp.Header
+			a.protocol = r.resp.Proto
+			a.responseBody = a.readResponse(r.resp, strings.NewReader(r.body))
+			a.latency = r.latency
+			a.tls.state = r.resp.TLS
+		}
+
+		if !a.passes() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// http2ErrorCode returns the HTTP/2 error code that ended a request, if any.
+func http2ErrorCode(err error) (string, bool) {
+	var streamErr http2.StreamError
+	if errors.As(err, &streamErr) {
+		return streamErr.Code.String(), true
+	}
+
+	var goAway http2.GoAwayError
+	if errors.As(err, &goAway) {
+		return goAway.ErrCode.String(), true
+	}
+
+	var connErr http2.ConnectionError
+	if errors.As(err, &connErr) {
+		return http2.ErrCode(connErr).String(), true
+	}
+
+	return "", false
+}
+
+// checkHTTP2 panics if the HTTP/2 connection or error code expectations
+// failed. It reports whether the stream failed, leaving nothing else to
+// check.
+func (a *HTTPAssert) checkHTTP2() bool {
+	p := a.plan
+
+	checkAll(a.connections, a.connectionsCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s %s (%d streams)\n  Expected connections: %s\n  Actual connections: %d%s",
+			p.method, p.url, max(p.streams, 1), m.Expected(), actual, a.formatHelp())
+		panic(a.failure(msg, "connections", m.Expected(), ""))
+	})
+
+	if len(a.errorCodeCheckers) == 0 {
+		if a.errorCode != "" {
+			msg := fmt.Sprintf("%s %s\n  Expected a response\n  Actual error code: %s%s",
+				p.method, p.url, a.errorCode, a.formatHelp())
+			panic(a.failure(msg, "code", "a response", ""))
+		}
+
+		return false
+	}
+
+	if a.errorCode == "" {
+		msg := fmt.Sprintf("%s %s\n  Expected the stream to fail\n  Actual status: %d %s%s",
+			p.method, p.url, a.responseStatus, http.StatusText(a.responseStatus), a.formatHelp())
+		panic(a.failure(msg, "code", "an error code", ""))
+	}
+
+	checkAll(a.errorCode, a.errorCodeCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected error code: %s\n  Actual error code: %s%s",
+			p.method, p.url, m.Expected(), actual, a.formatHelp())
+		panic(a.failure(msg, "code", m.Expected(), ""))
+	})
+
+	return true
+}
@@ -0,0 +1,37 @@
+package attest
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// HTTP3 sends the request over HTTP/3 to the process's port on UDP. QUIC
+// always runs over TLS, so the request uses https, and the server
+// certificate is not verified unless TLS sets a root CA.
+func (p *HTTPPlan) HTTP3() *HTTPPlan {
+	p.http3 = true
+	return p
+}
+
+// AltSvc adds checkers for the Alt-Svc response header, which advertises
+// HTTP/3 as e.g. h3=":8080". All checkers must pass.
+func (a *HTTPAssert) AltSvc(checkers ...Checker[string]) *HTTPAssert {
+	a.altSvcCheckers = append(a.altSvcCheckers, checkers...)
+	return a
+}
+
+// http3Client returns a client that sends requests over QUIC, and a
+// function that closes its connections. A TLS config from the plan replaces
+// the unverified default.
+func (a *HTTPAssert) http3Client() (*http.Client, func()) {
+	config := a.plan.tls.client("")
+	if config == nil {
+		config = &tls.Config{InsecureSkipVerify: true}
+	}
+	transport := &http3.Transport{TLSClientConfig: config}
+
+	client := &http.Client{Transport: transport, Timeout: a.config.ExecuteTimeout}
+	return client, func() { transport.Close() }
+}
@@ -0,0 +1,31 @@
+package attest
+
+import "time"
+
+// RespondsWithin expects the response to arrive within d, measured from
+// sending the request until the body is read, or the headers for SSE.
+// Unlike Within, the request is not retried.
+func (a *HTTPAssert) RespondsWithin(d time.Duration) *HTTPAssert {
+	return a.Latency(AtMost(d))
+}
+
+// Latency adds checkers for the time taken to respond, measured as in
+// RespondsWithin. With Streams, every stream must pass. All checkers must
+// pass.
+func (a *HTTPAssert) Latency(checkers ...Checker[time.Duration]) *HTTPAssert {
+	a.latencyCheckers = append(a.latencyCheckers, checkers...)
+	return a
+}
+
+// RespondsWithin expects a matching response within d of connecting.
+// Unlike ReadDeadline, a slower match still fails.
+func (a *TCPAssert) RespondsWithin(d time.Duration) *TCPAssert {
+	return a.Latency(AtMost(d))
+}
+
+// Latency adds checkers for the time from connecting until the response
+// satisfies the Received checkers. All checkers must pass.
+func (a *TCPAssert) Latency(checkers ...Checker[time.Duration]) *TCPAssert {
+	a.latencyCheckers = append(a.latencyCheckers, checkers...)
+	return a
+}
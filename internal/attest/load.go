@@ -0,0 +1,140 @@
+package attest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// loadRequest is one kind of request in a load mix.
+type loadRequest struct {
+	weight  int
+	method  string
+	path    string
+	body    []byte
+	headers H
+}
+
+// LoadPlan represents a load test that sends a weighted mix of HTTP
+// requests to a process from concurrent workers for a fixed duration.
+type LoadPlan struct {
+	ctx    context.Context
+	config *Config
+	rand   *rand.Rand
+
+	process  string
+	baseURL  string
+	requests []loadRequest
+	total    int
+	workers  int
+	duration time.Duration
+}
+
+// Request adds a request to the mix, sent in proportion to weight. Like
+// HTTP, args are an optional body and H headers. Transport errors and 5xx
+// responses count as errors.
+func (p *LoadPlan) Request(weight int, method, path string, args ...any) *LoadPlan {
+	if weight < 1 {
+		panic("Request() requires a positive weight")
+	}
+
+	req := loadRequest{weight: weight, method: method, path: path}
+	if len(args) >= 1 {
+		req.body = []byte(args[0].(string))
+	}
+	if len(args) >= 2 {
+		req.headers = args[1].(H)
+	}
+
+	p.requests = append(p.requests, req)
+	p.total += weight
+	return p
+}
+
+// Workers sets the number of concurrent workers.
+func (p *LoadPlan) Workers(n int) *LoadPlan {
+	if n < 1 {
+		panic("Workers() requires at least one worker")
+	}
+
+	p.workers = n
+	return p
+}
+
+// Duration sets how long the load runs.
+func (p *LoadPlan) Duration(d time.Duration) *LoadPlan {
+	p.duration = d
+	return p
+}
+
+// T returns assertions on the throughput, error rate, and latency
+// percentiles of the load.
+func (p *LoadPlan) T() *BenchAssert {
+	if len(p.requests) == 0 {
+		panic("LoadPlan requires at least one Request()")
+	}
+
+	transport := &http.Transport{MaxIdleConnsPerHost: p.workers}
+	client := &http.Client{Transport: transport, Timeout: p.config.ExecuteTimeout}
+
+	bench := &BenchPlan{
+		ctx:      p.ctx,
+		config:   p.config,
+		fn:       func() { p.send(client) },
+		workers:  p.workers,
+		duration: p.duration,
+		title:    fmt.Sprintf("Load on %s: %s", p.process, p.mix()),
+		cleanup:  transport.CloseIdleConnections,
+	}
+
+	return bench.T()
+}
+
+// send sends one request picked from the mix, panicking if it failed.
+func (p *LoadPlan) send(client *http.Client) {
+	n := p.rand.IntN(p.total)
+	req := p.requests[len(p.requests)-1]
+	for _, r := range p.requests {
+		if n < r.weight {
+			req = r
+			break
+		}
+		n -= r.weight
+	}
+
+	httpReq, err := http.NewRequestWithContext(p.ctx, req.method, p.baseURL+req.path, bytes.NewReader(req.body))
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range req.headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		panic(err)
+	}
+	if resp.StatusCode >= 500 {
+		panic(fmt.Sprintf("%s %s returned %d", req.method, req.path, resp.StatusCode))
+	}
+}
+
+// mix describes the request mix, e.g. "80% GET /kv/a, 20% PUT /kv/a".
+func (p *LoadPlan) mix() string {
+	parts := make([]string, len(p.requests))
+	for i, r := range p.requests {
+		parts[i] = fmt.Sprintf("%d%% %s %s", r.weight*100/p.total, r.method, r.path)
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,59 @@
+package attest
+
+import "strings"
+
+// Matcher is a reusable custom check, e.g. "is a valid bencoded
+// dictionary". Match returns nil if actual passes, or an error saying why
+// it does not. Describe says what the matcher expects.
+type Matcher[T any] interface {
+	Match(actual T) error
+	Describe() string
+}
+
+// matcherChecker adapts a Matcher to a Checker.
+type matcherChecker[T any] struct {
+	matcher Matcher[T]
+}
+
+// Satisfies creates a checker from a custom matcher, so it can be passed to
+// any assertion. Failures include the matcher's error.
+func Satisfies[T any](matcher Matcher[T]) matcherChecker[T] {
+	return matcherChecker[T]{matcher: matcher}
+}
+
+func (m matcherChecker[T]) Check(actual T) bool {
+	return m.matcher.Match(actual) == nil
+}
+
+func (m matcherChecker[T]) Expected() string {
+	return m.matcher.Describe()
+}
+
+func (m matcherChecker[T]) explain(actual T) string {
+	err := m.matcher.Match(actual)
+	if err == nil {
+		return ""
+	}
+
+	return "Reason: " + strings.ReplaceAll(err.Error(), "\n", "\n    ")
+}
+
+// matchFunc is a Matcher built from a function.
+type matchFunc[T any] struct {
+	description string
+	match       func(T) error
+}
+
+// MatchFunc creates a Matcher from a description and a function that
+// returns an error when actual does not match.
+func MatchFunc[T any](description string, match func(actual T) error) Matcher[T] {
+	return matchFunc[T]{description: description, match: match}
+}
+
+func (m matchFunc[T]) Match(actual T) error {
+	return m.match(actual)
+}
+
+func (m matchFunc[T]) Describe() string {
+	return m.description
+}
@@ -0,0 +1,374 @@
+package attest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Plan[*MemcachedPlan, *MemcachedAssert] = (*MemcachedPlan)(nil)
+var _ Assert = (*MemcachedAssert)(nil)
+
+// MemcachedPlan represents a test plan that sends one command in the
+// memcached text protocol and reads the framed response.
+type MemcachedPlan struct {
+	PlanBase
+
+	process string
+	addr    string
+	command string
+	key     string
+	value   string
+	delta   uint64
+	flags   uint32
+	exptime int
+	noreply bool
+}
+
+// Set stores value under key.
+func (p *MemcachedPlan) Set(key, value string) *MemcachedPlan {
+	p.command, p.key, p.value = "set", key, value
+	return p
+}
+
+// Add stores value under key only if the key does not exist.
+func (p *MemcachedPlan) Add(key, value string) *MemcachedPlan {
+	p.command, p.key, p.value = "add", key, value
+	return p
+}
+
+// Get retrieves key.
+func (p *MemcachedPlan) Get(key string) *MemcachedPlan {
+	p.command, p.key = "get", key
+	return p
+}
+
+// Delete removes key.
+func (p *MemcachedPlan) Delete(key string) *MemcachedPlan {
+	p.command, p.key = "delete", key
+	return p
+}
+
+// Incr increments the numeric value of key by delta.
+func (p *MemcachedPlan) Incr(key string, delta uint64) *MemcachedPlan {
+	p.command, p.key, p.delta = "incr", key, delta
+	return p
+}
+
+// Decr decrements the numeric value of key by delta.
+func (p *MemcachedPlan) Decr(key string, delta uint64) *MemcachedPlan {
+	p.command, p.key, p.delta = "decr", key, delta
+	return p
+}
+
+// Flags sets the client flags stored with the value.
+func (p *MemcachedPlan) Flags(flags uint32) *MemcachedPlan {
+	p.flags = flags
+	return p
+}
+
+// Expire sets the expiration time in seconds stored with the value.
+func (p *MemcachedPlan) Expire(seconds int) *MemcachedPlan {
+	p.exptime = seconds
+	return p
+}
+
+// NoReply sends the command with noreply. The server must not answer.
+func (p *MemcachedPlan) NoReply() *MemcachedPlan {
+	p.noreply = true
+	return p
+}
+
+func (p *MemcachedPlan) Eventually() *MemcachedPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *MemcachedPlan) Within(timeout time.Duration) *MemcachedPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *MemcachedPlan) Retry(policy RetryPolicy) *MemcachedPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *MemcachedPlan) Consistently() *MemcachedPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *MemcachedPlan) For(timeout time.Duration) *MemcachedPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *MemcachedPlan) Every(interval time.Duration) *MemcachedPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *MemcachedPlan) Deadline(d time.Duration) *MemcachedPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *MemcachedPlan) T() *MemcachedAssert {
+	return &MemcachedAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// line returns the command line, without the data block.
+func (p *MemcachedPlan) line() string {
+	var line string
+	switch p.command {
+	case "set", "add":
+		line = fmt.Sprintf("%s %s %d %d %d", p.command, p.key, p.flags, p.exptime, len(p.value))
+	case "incr", "decr":
+		line = fmt.Sprintf("%s %s %d", p.command, p.key, p.delta)
+	case "get", "delete":
+		line = fmt.Sprintf("%s %s", p.command, p.key)
+	default:
+		panic("memcached plan has no command, use Set, Add, Get, Delete, Incr or Decr")
+	}
+
+	if p.noreply {
+		line += " noreply"
+	}
+
+	return line
+}
+
+// request returns the bytes sent for the command.
+func (p *MemcachedPlan) request() string {
+	request := p.line() + "\r\n"
+	if p.command == "set" || p.command == "add" {
+		request += p.value + "\r\n"
+	}
+
+	return request
+}
+
+// MemcachedAssert provides assertions on a memcached response.
+type MemcachedAssert struct {
+	AssertBase
+
+	plan  *MemcachedPlan
+	reply string
+	// hit is whether a get returned a VALUE block.
+	hit   bool
+	value string
+	flags int
+	err   error
+
+	replyCheckers []Checker[string]
+	valueCheckers []Checker[string]
+	flagsCheckers []Checker[int]
+	miss          bool
+}
+
+// Reply adds checkers for the response line, e.g. "STORED", "NOT_FOUND" or
+// the new value after incr. For get it is "END" after any value.
+// All checkers must pass.
+func (a *MemcachedAssert) Reply(checkers ...Checker[string]) *MemcachedAssert {
+	a.replyCheckers = append(a.replyCheckers, checkers...)
+	return a
+}
+
+// Value expects get to return a value passing all checkers.
+func (a *MemcachedAssert) Value(checkers ...Checker[string]) *MemcachedAssert {
+	a.valueCheckers = append(a.valueCheckers, checkers...)
+	return a
+}
+
+// ValueFlags expects get to return a value with flags passing all checkers.
+func (a *MemcachedAssert) ValueFlags(checkers ...Checker[int]) *MemcachedAssert {
+	a.flagsCheckers = append(a.flagsCheckers, checkers...)
+	return a
+}
+
+// Miss expects get to return no value.
+func (a *MemcachedAssert) Miss() *MemcachedAssert {
+	a.miss = true
+	return a
+}
+
+func (a *MemcachedAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *MemcachedAssert) execute() bool {
+	p := a.plan
+	a.reply, a.hit, a.value, a.flags, a.err = "", false, "", 0, nil
+
+	request := p.request()
+	if p.noreply {
+		// A reply to the command would arrive before the version
+		request += "version\r\n"
+	}
+
+	conn, err := net.DialTimeout("tcp", p.addr, a.config.ExecuteTimeout)
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.Close()
+	defer closeWhenDone(p.ctx, conn)()
+
+	conn.SetDeadline(time.Now().Add(a.config.ExecuteTimeout))
+	p.config.Recorder.record(Entry{Kind: EntryTCP, Process: p.process, Body: request})
+
+	_, err = io.WriteString(conn, request)
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	reader := bufio.NewReader(conn)
+	if p.command == "get" && !p.noreply {
+		a.err = a.readValues(reader)
+	} else {
+		a.reply, a.err = readLine(reader)
+	}
+	if a.err != nil {
+		return false
+	}
+
+	return a.passes()
+}
+
+// readLine reads a line terminated by \r\n.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", fmt.Errorf("malformed response: line %q not terminated by \\r\\n", line)
+	}
+
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+// readValues reads VALUE blocks until END, or an error line.
+func (a *MemcachedAssert) readValues(reader *bufio.Reader) error {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "VALUE" {
+			a.reply = line
+			return nil
+		}
+
+		if len(fields) < 4 || fields[1] != a.plan.key {
+			return fmt.Errorf("malformed response: unexpected %q", line)
+		}
+		flags, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("malformed response: invalid flags in %q", line)
+		}
+		size, err := strconv.Atoi(fields[3])
+		if err != nil || size < 0 {
+			return fmt.Errorf("malformed response: invalid length in %q", line)
+		}
+
+		data := make([]byte, size+2)
+		_, err = io.ReadFull(reader, data)
+		if err != nil {
+			return fmt.Errorf("malformed response: data shorter than %d bytes: %w", size, err)
+		}
+		if string(data[size:]) != "\r\n" {
+			return fmt.Errorf("malformed response: data not terminated by \\r\\n after %d bytes", size)
+		}
+
+		a.hit, a.value, a.flags = true, string(data[:size]), flags
+	}
+}
+
+// passes reports whether the response meets every expectation.
+func (a *MemcachedAssert) passes() bool {
+	if a.plan.noreply {
+		return strings.HasPrefix(a.reply, "VERSION")
+	}
+
+	if a.miss && a.hit {
+		return false
+	}
+	if (len(a.valueCheckers) > 0 || len(a.flagsCheckers) > 0) && !a.hit {
+		return false
+	}
+
+	return checkAll(a.reply, a.replyCheckers, nil) &&
+		checkAll(a.value, a.valueCheckers, nil) &&
+		checkAll(a.flags, a.flagsCheckers, nil)
+}
+
+func (a *MemcachedAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("memcached %s\n  Command: %s", p.addr, p.line())
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	if p.noreply {
+		if !strings.HasPrefix(a.reply, "VERSION") {
+			msg := fmt.Sprintf("%s\n  Expected no reply\n  Actual reply: %q%s", title, a.reply, a.formatHelp())
+			panic(msg)
+		}
+
+		return
+	}
+
+	if a.miss && a.hit {
+		msg := fmt.Sprintf("%s\n  Expected a miss\n  Actual value: %q (flags %d)%s", title, a.value, a.flags, a.formatHelp())
+		panic(msg)
+	}
+	if (len(a.valueCheckers) > 0 || len(a.flagsCheckers) > 0) && !a.hit {
+		msg := fmt.Sprintf("%s\n  Expected a value\n  Actual reply: %q%s", title, a.reply, a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.reply, a.replyCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected reply: %s\n  Actual reply: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.value, a.valueCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected value: %s\n  Actual value: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.flags, a.flagsCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected flags: %s\n  Actual flags: %d%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
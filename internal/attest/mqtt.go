@@ -0,0 +1,620 @@
+package attest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+var _ Plan[*MQTTPlan, *MQTTAssert] = (*MQTTPlan)(nil)
+var _ Assert = (*MQTTAssert)(nil)
+
+// defaultMQTTWindow is how long the subscriber waits for messages.
+const defaultMQTTWindow = time.Second
+
+// MQTT 3.1.1 control packet types.
+const (
+	mqttConnect    = 1
+	mqttConnAck    = 2
+	mqttPublish    = 3
+	mqttPubAck     = 4
+	mqttSubscribe  = 8
+	mqttSubAck     = 9
+	mqttDisconnect = 14
+)
+
+// mqttMessage is a message published or received.
+type mqttMessage struct {
+	topic   string
+	payload string
+	qos     byte
+}
+
+func (m mqttMessage) String() string {
+	return fmt.Sprintf("%s %q (QoS %d)", m.topic, m.payload, m.qos)
+}
+
+// mqttSubscription is a topic filter with its requested QoS.
+type mqttSubscription struct {
+	filter string
+	qos    byte
+}
+
+// MQTTPlan represents a test plan for an MQTT 3.1.1 broker. A subscriber
+// connection subscribes to the topic filters, a second publisher connection
+// sends the messages, then the subscriber collects what the broker delivers
+// within the window.
+type MQTTPlan struct {
+	PlanBase
+
+	process       string
+	addr          string
+	username      string
+	password      string
+	subscriptions []mqttSubscription
+	publishes     []mqttMessage
+	window        time.Duration
+}
+
+// Credentials sets the username and password sent in CONNECT.
+func (p *MQTTPlan) Credentials(username, password string) *MQTTPlan {
+	p.username, p.password = username, password
+	return p
+}
+
+// Subscribe subscribes the subscriber connection to a topic filter.
+func (p *MQTTPlan) Subscribe(filter string, qos byte) *MQTTPlan {
+	p.subscriptions = append(p.subscriptions, mqttSubscription{filter: filter, qos: qos})
+	return p
+}
+
+// Publish publishes a message with QoS 0 or 1 from the publisher connection.
+// With QoS 1 the broker must acknowledge it with PUBACK.
+func (p *MQTTPlan) Publish(topic, payload string, qos byte) *MQTTPlan {
+	if qos > 1 {
+		panic(fmt.Sprintf("unsupported QoS %d, expected 0 or 1", qos))
+	}
+
+	p.publishes = append(p.publishes, mqttMessage{topic: topic, payload: payload, qos: qos})
+	return p
+}
+
+// Window sets how long the subscriber waits for messages, one second by
+// default. Waiting ends early once all expected messages arrived, unless
+// the message count is checked.
+func (p *MQTTPlan) Window(d time.Duration) *MQTTPlan {
+	p.window = d
+	return p
+}
+
+func (p *MQTTPlan) Eventually() *MQTTPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *MQTTPlan) Within(timeout time.Duration) *MQTTPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *MQTTPlan) Retry(policy RetryPolicy) *MQTTPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *MQTTPlan) Consistently() *MQTTPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *MQTTPlan) For(timeout time.Duration) *MQTTPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *MQTTPlan) Every(interval time.Duration) *MQTTPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *MQTTPlan) Deadline(d time.Duration) *MQTTPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *MQTTPlan) T() *MQTTAssert {
+	return &MQTTAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// messageWindow returns how long the subscriber waits for messages.
+func (p *MQTTPlan) messageWindow() time.Duration {
+	if p.window == 0 {
+		return defaultMQTTWindow
+	}
+
+	return p.window
+}
+
+// mqttConn is a client connection to the broker.
+type mqttConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID uint16
+}
+
+// mqttString appends a length-prefixed UTF-8 string.
+func mqttString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// send writes a control packet.
+func (c *mqttConn) send(typ, flags byte, body []byte) error {
+	packet := []byte{typ<<4 | flags}
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// receive reads the next control packet.
+func (c *mqttConn) receive() (byte, byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if i == 4 {
+			return 0, 0, nil, fmt.Errorf("malformed remaining length")
+		}
+
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(c.reader, body)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return header >> 4, header & 0x0F, body, nil
+}
+
+// expect reads the next packet and fails unless it has the given type.
+func (c *mqttConn) expect(typ byte, name string) ([]byte, error) {
+	got, _, body, err := c.receive()
+	if err != nil {
+		return nil, fmt.Errorf("no %s: %w", name, err)
+	}
+	if got != typ {
+		return nil, fmt.Errorf("expected %s, got packet type %d", name, got)
+	}
+
+	return body, nil
+}
+
+// connect sends CONNECT with a clean session and returns the CONNACK
+// return code.
+func (c *mqttConn) connect(clientID, username, password string) (int, error) {
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+
+	body := mqttString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, 60)
+	body = mqttString(body, clientID)
+	if username != "" {
+		body = mqttString(body, username)
+	}
+	if password != "" {
+		body = mqttString(body, password)
+	}
+
+	err := c.send(mqttConnect, 0, body)
+	if err != nil {
+		return 0, err
+	}
+
+	ack, err := c.expect(mqttConnAck, "CONNACK")
+	if err != nil {
+		return 0, err
+	}
+	if len(ack) != 2 {
+		return 0, fmt.Errorf("malformed CONNACK")
+	}
+
+	return int(ack[1]), nil
+}
+
+// subscribe sends SUBSCRIBE and returns the granted QoS of each filter.
+func (c *mqttConn) subscribe(subscriptions []mqttSubscription) ([]int, error) {
+	c.nextID++
+	body := binary.BigEndian.AppendUint16(nil, c.nextID)
+	for _, s := range subscriptions {
+		body = append(mqttString(body, s.filter), s.qos)
+	}
+
+	err := c.send(mqttSubscribe, 0x02, body)
+	if err != nil {
+		return nil, err
+	}
+
+	ack, err := c.expect(mqttSubAck, "SUBACK")
+	if err != nil {
+		return nil, err
+	}
+	if len(ack) < 2 || binary.BigEndian.Uint16(ack) != c.nextID {
+		return nil, fmt.Errorf("SUBACK does not match SUBSCRIBE %d", c.nextID)
+	}
+
+	var granted []int
+	for _, code := range ack[2:] {
+		granted = append(granted, int(code))
+	}
+
+	return granted, nil
+}
+
+// publish sends PUBLISH and, for QoS 1, waits for the PUBACK.
+func (c *mqttConn) publish(msg mqttMessage) error {
+	body := mqttString(nil, msg.topic)
+	if msg.qos > 0 {
+		c.nextID++
+		body = binary.BigEndian.AppendUint16(body, c.nextID)
+	}
+	body = append(body, msg.payload...)
+
+	err := c.send(mqttPublish, msg.qos<<1, body)
+	if err != nil || msg.qos == 0 {
+		return err
+	}
+
+	ack, err := c.expect(mqttPubAck, "PUBACK")
+	if err != nil {
+		return err
+	}
+	if len(ack) != 2 || binary.BigEndian.Uint16(ack) != c.nextID {
+		return fmt.Errorf("PUBACK does not match PUBLISH %d", c.nextID)
+	}
+
+	return nil
+}
+
+// deliveries reads PUBLISH packets until the deadline or until done
+// reports enough messages, acknowledging those sent with QoS 1.
+func (c *mqttConn) deliveries(deadline time.Time, done func([]mqttMessage) bool) ([]mqttMessage, error) {
+	c.conn.SetReadDeadline(deadline)
+
+	var received []mqttMessage
+	for !done(received) {
+		typ, flags, body, err := c.receive()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		if typ != mqttPublish || len(body) < 2 {
+			continue
+		}
+
+		qos := (flags >> 1) & 0x03
+		length := int(binary.BigEndian.Uint16(body))
+		if len(body) < 2+length {
+			return received, fmt.Errorf("malformed PUBLISH")
+		}
+		topic, rest := string(body[2:2+length]), body[2+length:]
+
+		if qos > 0 {
+			if len(rest) < 2 {
+				return received, fmt.Errorf("malformed PUBLISH")
+			}
+			c.send(mqttPubAck, 0, rest[:2])
+			rest = rest[2:]
+		}
+
+		received = append(received, mqttMessage{topic: topic, payload: string(rest), qos: qos})
+	}
+
+	return received, nil
+}
+
+// dialMQTT connects and sends CONNECT.
+func dialMQTT(addr, role, username, password string, timeout time.Duration) (*mqttConn, int, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	c := &mqttConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	clientID := fmt.Sprintf("lc-%s-%d", role, rand.N(1_000_000))
+	code, err := c.connect(clientID, username, password)
+	if err != nil {
+		conn.Close()
+		return nil, 0, fmt.Errorf("%s: %w", role, err)
+	}
+
+	return c, code, nil
+}
+
+// close sends DISCONNECT and closes the connection.
+func (c *mqttConn) close() {
+	c.send(mqttDisconnect, 0, nil)
+	c.conn.Close()
+}
+
+// mqttExpectation is an expected delivery, in order.
+type mqttExpectation struct {
+	topic    string
+	checkers []Checker[string]
+}
+
+// MQTTAssert provides assertions on the broker's handshakes and on the
+// messages delivered to the subscriber.
+type MQTTAssert struct {
+	AssertBase
+
+	plan     *MQTTPlan
+	connAck  int
+	granted  []int
+	received []mqttMessage
+	// failed is the index of the expectation that was not met.
+	failed int
+	err    error
+
+	connAckCheckers []Checker[int]
+	grantedCheckers []Checker[int]
+	expected        []mqttExpectation
+	countCheckers   []Checker[int]
+}
+
+// ConnAck adds checkers for the CONNACK return code. Without them, any
+// code other than 0 fails the assertion.
+func (a *MQTTAssert) ConnAck(checkers ...Checker[int]) *MQTTAssert {
+	a.connAckCheckers = append(a.connAckCheckers, checkers...)
+	return a
+}
+
+// GrantedQoS adds checkers for the QoS the broker grants each subscription,
+// 128 for a rejected one. All checkers must pass.
+func (a *MQTTAssert) GrantedQoS(checkers ...Checker[int]) *MQTTAssert {
+	a.grantedCheckers = append(a.grantedCheckers, checkers...)
+	return a
+}
+
+// Message expects the next message delivered to the subscriber to be on
+// topic, with a payload passing all checkers.
+func (a *MQTTAssert) Message(topic string, checkers ...Checker[string]) *MQTTAssert {
+	a.expected = append(a.expected, mqttExpectation{topic: topic, checkers: checkers})
+	return a
+}
+
+// Count adds checkers for the number of messages delivered within the
+// window. All checkers must pass.
+func (a *MQTTAssert) Count(checkers ...Checker[int]) *MQTTAssert {
+	a.countCheckers = append(a.countCheckers, checkers...)
+	return a
+}
+
+func (a *MQTTAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *MQTTAssert) execute() bool {
+	p := a.plan
+	a.connAck, a.granted, a.received, a.failed, a.err = 0, nil, nil, -1, nil
+
+	var subscriber *mqttConn
+	if len(p.subscriptions) > 0 {
+		var err error
+		subscriber, a.connAck, err = dialMQTT(p.addr, "subscriber", p.username, p.password, a.config.ExecuteTimeout)
+		if err != nil {
+			a.err = err
+			return false
+		}
+		defer subscriber.close()
+		defer closeWhenDone(p.ctx, subscriber.conn)()
+
+		if a.connAck != 0 {
+			return a.passes()
+		}
+
+		a.granted, err = subscriber.subscribe(p.subscriptions)
+		if err != nil {
+			a.err = fmt.Errorf("subscriber: %w", err)
+			return false
+		}
+	}
+
+	if len(p.publishes) > 0 {
+		publisher, code, err := dialMQTT(p.addr, "publisher", p.username, p.password, a.config.ExecuteTimeout)
+		if err != nil {
+			a.err = err
+			return false
+		}
+		defer publisher.close()
+		defer closeWhenDone(p.ctx, publisher.conn)()
+
+		a.connAck = code
+		if a.connAck != 0 {
+			return a.passes()
+		}
+
+		for _, msg := range p.publishes {
+			err := publisher.publish(msg)
+			if err != nil {
+				a.err = fmt.Errorf("publisher: %w", err)
+				return false
+			}
+		}
+	}
+
+	if subscriber != nil && (len(a.expected) > 0 || len(a.countCheckers) > 0) {
+		var err error
+		a.received, err = subscriber.deliveries(time.Now().Add(p.messageWindow()), func(received []mqttMessage) bool {
+			return len(a.countCheckers) == 0 && len(a.expected) > 0 && len(received) >= len(a.expected)
+		})
+		if err != nil {
+			a.err = fmt.Errorf("subscriber: %w", err)
+			return false
+		}
+	}
+
+	return a.passes()
+}
+
+// passes reports whether the session meets every expectation.
+func (a *MQTTAssert) passes() bool {
+	if len(a.connAckCheckers) == 0 && a.connAck != 0 {
+		return false
+	}
+	if !checkAll(a.connAck, a.connAckCheckers, nil) {
+		return false
+	}
+	if a.connAck != 0 {
+		return true
+	}
+
+	for _, code := range a.granted {
+		if !checkAll(code, a.grantedCheckers, nil) {
+			return false
+		}
+	}
+
+	for i, want := range a.expected {
+		if i >= len(a.received) || a.received[i].topic != want.topic || !checkAll(a.received[i].payload, want.checkers, nil) {
+			a.failed = i
+			return false
+		}
+	}
+
+	return checkAll(len(a.received), a.countCheckers, nil)
+}
+
+// formatReceived lists the delivered messages.
+func (a *MQTTAssert) formatReceived() string {
+	if len(a.received) == 0 {
+		return " (none)"
+	}
+
+	var lines []string
+	for _, msg := range a.received {
+		lines = append(lines, "\n    "+msg.String())
+	}
+
+	return strings.Join(lines, "")
+}
+
+func (a *MQTTAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("MQTT %s", p.addr)
+	for _, s := range p.subscriptions {
+		title += fmt.Sprintf("\n  Subscribed: %s (QoS %d)", s.filter, s.qos)
+	}
+	for _, msg := range p.publishes {
+		title += fmt.Sprintf("\n  Published: %s", msg)
+	}
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	if len(a.connAckCheckers) == 0 && a.connAck != 0 {
+		msg := fmt.Sprintf("%s\n  Expected CONNACK: 0\n  Actual CONNACK: %d%s", title, a.connAck, a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.connAck, a.connAckCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected CONNACK: %s\n  Actual CONNACK: %d%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	for i, code := range a.granted {
+		checkAll(code, a.grantedCheckers, func(m Checker[int], actual int) {
+			msg := fmt.Sprintf("%s\n  Expected granted QoS for %s: %s\n  Actual granted QoS: %d%s",
+				title, p.subscriptions[i].filter, m.Expected(), actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	if a.failed >= 0 {
+		want := a.expected[a.failed]
+		if a.failed >= len(a.received) {
+			msg := fmt.Sprintf("%s\n  Expected message %d on %s within %s\n  Actual messages:%s%s",
+				title, a.failed+1, want.topic, p.messageWindow(), a.formatReceived(), a.formatHelp())
+			panic(msg)
+		}
+
+		actual := a.received[a.failed]
+		if actual.topic != want.topic {
+			msg := fmt.Sprintf("%s\n  Expected message %d on %s\n  Actual message %d: %s%s",
+				title, a.failed+1, want.topic, a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(actual.payload, want.checkers, func(m Checker[string], payload string) {
+			msg := fmt.Sprintf("%s\n  Expected message %d on %s: %s\n  Actual message %d: %s%s",
+				title, a.failed+1, want.topic, m.Expected(), a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	checkAll(len(a.received), a.countCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected messages within %s: %s\n  Actual messages:%s%s",
+			title, p.messageWindow(), m.Expected(), a.formatReceived(), a.formatHelp())
+		panic(msg)
+	})
+}
@@ -0,0 +1,208 @@
+package attest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// FormPart is a field or file in a multipart/form-data request.
+type FormPart struct {
+	name        string
+	filename    string
+	contentType string
+	data        []byte
+	// fixture is the file in Config.Fixtures to send instead of data.
+	fixture string
+}
+
+// FormField creates a form field part.
+func FormField(name, value string) FormPart {
+	return FormPart{name: name, data: []byte(value)}
+}
+
+// FormFile creates a file part sent as filename.
+func FormFile(name, filename string, data []byte) FormPart {
+	return FormPart{name: name, filename: filename, data: data}
+}
+
+// FormFixture creates a file part with the contents of the fixture file
+// from Config.Fixtures, sent as filename.
+func FormFixture(name, filename, fixture string) FormPart {
+	return FormPart{name: name, filename: filename, fixture: fixture}
+}
+
+// WithContentType sets the part's Content-Type. File parts default to
+// application/octet-stream.
+func (f FormPart) WithContentType(contentType string) FormPart {
+	f.contentType = contentType
+	return f
+}
+
+// Multipart sends the parts as a multipart/form-data body, e.g. a file
+// upload with its fields, and sets the Content-Type with its boundary.
+func (p *HTTPPlan) Multipart(parts ...FormPart) *HTTPPlan {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for _, part := range parts {
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(part.name))
+		if part.filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(part.filename))
+		}
+
+		header := textproto.MIMEHeader{"Content-Disposition": {disposition}}
+		switch {
+		case part.contentType != "":
+			header.Set("Content-Type", part.contentType)
+		case part.filename != "":
+			header.Set("Content-Type", "application/octet-stream")
+		}
+
+		data := part.data
+		if part.fixture != "" {
+			data = []byte(p.config.readFixture(part.fixture))
+		}
+
+		pw, _ := w.CreatePart(header)
+		pw.Write(data)
+	}
+	w.Close()
+
+	headers := make(H, len(p.headers)+1)
+	for key, value := range p.headers {
+		headers[key] = value
+	}
+	headers["Content-Type"] = w.FormDataContentType()
+
+	p.headers = headers
+	p.body = body.Bytes()
+	return p
+}
+
+// escapeQuotes escapes a name for a quoted Content-Disposition parameter.
+func escapeQuotes(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// partExpectation holds the checks for one part of a multipart response.
+type partExpectation struct {
+	name     string
+	checkers []Checker[string]
+	filename []Checker[string]
+}
+
+// Part expects the response to be multipart, e.g. a server echoing an
+// upload as multipart/form-data, with a part of the form name passing all
+// checkers on its contents.
+func (a *HTTPAssert) Part(name string, checkers ...Checker[string]) *HTTPAssert {
+	a.parts = append(a.parts, partExpectation{name: name, checkers: checkers})
+	return a
+}
+
+// PartFilename adds checkers for the filename of the multipart response's
+// part of the form name. All checkers must pass.
+func (a *HTTPAssert) PartFilename(name string, checkers ...Checker[string]) *HTTPAssert {
+	a.parts = append(a.parts, partExpectation{name: name, filename: checkers})
+	return a
+}
+
+// responsePart is a part read from a multipart response.
+type responsePart struct {
+	filename string
+	data     string
+}
+
+// responseParts parses the response as multipart, by form name. The first
+// part with a name wins.
+func (a *HTTPAssert) responseParts() (map[string]responsePart, error) {
+	mediaType, params, err := mime.ParseMediaType(a.responseHeader.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("Content-Type %q is not multipart", a.responseHeader.Get("Content-Type"))
+	}
+
+	parts := make(map[string]responsePart)
+	r := multipart.NewReader(strings.NewReader(a.responseBody), params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := parts[part.FormName()]; !ok {
+			parts[part.FormName()] = responsePart{filename: part.FileName(), data: string(data)}
+		}
+	}
+}
+
+// partsPass reports whether the multipart response meets every part
+// expectation.
+func (a *HTTPAssert) partsPass() bool {
+	if len(a.parts) == 0 {
+		return true
+	}
+
+	parts, err := a.responseParts()
+	if err != nil {
+		return false
+	}
+
+	for _, e := range a.parts {
+		part, ok := parts[e.name]
+		if !ok || !checkAll(part.data, e.checkers, nil) || !checkAll(part.filename, e.filename, nil) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkParts panics if a part expectation failed.
+func (a *HTTPAssert) checkParts() {
+	if len(a.parts) == 0 {
+		return
+	}
+
+	p := a.plan
+	parts, err := a.responseParts()
+	if err != nil {
+		msg := fmt.Sprintf("%s %s\n  Expected a multipart response\n  Actual: %v%s",
+			p.method, p.url, err, a.formatHelp())
+		panic(a.failure(msg, "part", "multipart", ""))
+	}
+
+	for _, e := range a.parts {
+		part, ok := parts[e.name]
+		if !ok {
+			msg := fmt.Sprintf("%s %s\n  Expected part %s\n  Actual parts: %q%s",
+				p.method, p.url, e.name, slices.Sorted(maps.Keys(parts)), a.formatHelp())
+			panic(a.failure(msg, "part", e.name, ""))
+		}
+
+		checkAll(part.data, e.checkers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s %s\n  Expected part %s: %s\n  Actual part %s:%s%s%s",
+				p.method, p.url, e.name, m.Expected(), e.name, formatContents(actual), explain(m, actual), a.formatHelp())
+			panic(a.failure(msg, "part", m.Expected(), ""))
+		})
+
+		checkAll(part.filename, e.filename, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s %s\n  Expected part %s filename: %s\n  Actual part %s filename: %q%s",
+				p.method, p.url, e.name, m.Expected(), e.name, actual, a.formatHelp())
+			panic(a.failure(msg, "part", m.Expected(), ""))
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package attest
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// network holds the links between nodes and which of them are cut.
+type network struct {
+	mu    sync.Mutex
+	links map[string]*link
+	// groups maps each partitioned node to its side, and isolated holds the
+	// nodes cut off from every other.
+	groups   map[string]int
+	isolated map[string]bool
+}
+
+func newNetwork() *network {
+	return &network{links: make(map[string]*link)}
+}
+
+// link is a TCP proxy a node reaches another through, so the harness can
+// cut it or degrade its traffic.
+type link struct {
+	from, to string
+	listener net.Listener
+	// target resolves the address of to when a connection is made, so a
+	// link can be handed out before its node starts. It returns "" while
+	// there is no such node.
+	target func() string
+	toxics *toxics
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool
+}
+
+// Link returns the address from should use to reach to, e.g. in the peer
+// list it is started with, so Partition and Isolate can cut the two apart.
+// The node to need not be running yet.
+func (do *Do) Link(from, to string) string {
+	n := do.network
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := from + "->" + to
+	if l, exists := n.links[key]; exists {
+		return l.listener.Addr().String()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to start link: %v", err))
+	}
+
+	l := &link{
+		from:     from,
+		to:       to,
+		listener: listener,
+		target: func() string {
+			if _, exists := do.processes.Get(to); !exists {
+				return ""
+			}
+			return do.addr(to)
+		},
+		toxics: &toxics{rand: do.rand},
+		conns:  make(map[net.Conn]bool),
+	}
+	n.links[key] = l
+	go l.serve(n)
+
+	return listener.Addr().String()
+}
+
+// Proxy runs a link in front of a process, reachable as a process named
+// name, e.g. so requests made with HTTP(name, ...) go through the toxics
+// of InjectToxic(name, target, ...).
+func (do *Do) Proxy(name, target string) {
+	do.Link(name, target)
+	l := do.network.getLink(name, target)
+	do.processes.Set(name, &Process{realPort: l.listener.Addr().(*net.TCPAddr).Port})
+}
+
+// Partition splits the nodes into groups that cannot reach each other
+// until Heal, replacing any earlier partition. Open connections across
+// groups are closed, and new ones connect but go unanswered.
+func (do *Do) Partition(groups ...[]string) {
+	n := do.network
+	n.mu.Lock()
+	n.groups = make(map[string]int)
+	for i, group := range groups {
+		for _, node := range group {
+			n.groups[node] = i
+		}
+	}
+	n.mu.Unlock()
+
+	n.cutLinks()
+}
+
+// Isolate cuts the nodes off from every other node until Heal.
+func (do *Do) Isolate(nodes ...string) {
+	n := do.network
+	n.mu.Lock()
+	if n.isolated == nil {
+		n.isolated = make(map[string]bool)
+	}
+	for _, node := range nodes {
+		n.isolated[node] = true
+	}
+	n.mu.Unlock()
+
+	n.cutLinks()
+}
+
+// Heal restores every link cut by Partition or Isolate. Connections left
+// unanswered while cut are closed, so nodes reconnect.
+func (do *Do) Heal() {
+	n := do.network
+	n.mu.Lock()
+	n.groups, n.isolated = nil, nil
+	links := make([]*link, 0, len(n.links))
+	for _, l := range n.links {
+		links = append(links, l)
+	}
+	n.mu.Unlock()
+
+	for _, l := range links {
+		l.closeConns(true)
+	}
+}
+
+// isCut reports whether from cannot reach to.
+func (n *network) isCut(from, to string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.isolated[from] || n.isolated[to] {
+		return true
+	}
+
+	a, ok := n.groups[from]
+	b, ok2 := n.groups[to]
+	return ok && ok2 && a != b
+}
+
+// cutLinks closes the forwarded connections of every cut link.
+func (n *network) cutLinks() {
+	n.mu.Lock()
+	links := make([]*link, 0, len(n.links))
+	for _, l := range n.links {
+		links = append(links, l)
+	}
+	n.mu.Unlock()
+
+	for _, l := range links {
+		if n.isCut(l.from, l.to) {
+			l.closeConns(false)
+		}
+	}
+}
+
+// close stops every link.
+func (n *network) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, l := range n.links {
+		l.listener.Close()
+		l.closeConns(false)
+		l.closeConns(true)
+	}
+}
+
+// serve forwards connections to the link's target, or holds them
+// unanswered while the link is cut.
+func (l *link) serve(n *network) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		if n.isCut(l.from, l.to) {
+			l.track(conn, true)
+			go func() {
+				io.Copy(io.Discard, conn)
+				l.untrack(conn)
+			}()
+			continue
+		}
+
+		go l.forward(conn)
+	}
+}
+
+// forward pipes conn to the target until either side closes.
+func (l *link) forward(conn net.Conn) {
+	target := l.target()
+	if target == "" {
+		conn.Close()
+		return
+	}
+
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	l.track(conn, false)
+	l.track(upstream, false)
+	defer l.untrack(conn)
+	defer l.untrack(upstream)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		l.pipe(upstream, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		l.pipe(conn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// track records a connection, held unanswered if held, so it can be closed.
+func (l *link) track(conn net.Conn, held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.conns[conn] = held
+}
+
+// untrack closes and forgets a connection.
+func (l *link) untrack(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	conn.Close()
+	delete(l.conns, conn)
+}
+
+// closeConns closes the held connections if held, else the forwarded ones.
+func (l *link) closeConns(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for conn, h := range l.conns {
+		if h == held {
+			conn.Close()
+			delete(l.conns, conn)
+		}
+	}
+}
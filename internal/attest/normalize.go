@@ -0,0 +1,100 @@
+package attest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Normalizer rewrites text before it is checked, e.g. to ignore colors.
+type Normalizer struct {
+	description string
+	apply       func(string) string
+}
+
+// ansiPattern matches ANSI escape sequences: CSI sequences such as colors
+// and cursor movement, and OSC sequences such as hyperlinks.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// trailingSpacePattern matches spaces and tabs at the end of a line,
+// capturing the line ending that follows them.
+var trailingSpacePattern = regexp.MustCompile(`[ \t]+(\r\n|\r|\n|$)`)
+
+// StripANSI removes ANSI escape codes such as colors.
+func StripANSI() Normalizer {
+	return Normalizer{
+		description: "ANSI codes",
+		apply: func(s string) string {
+			return ansiPattern.ReplaceAllString(s, "")
+		},
+	}
+}
+
+// TrimTrailingSpace removes spaces and tabs at the end of each line.
+func TrimTrailingSpace() Normalizer {
+	return Normalizer{
+		description: "trailing whitespace",
+		apply: func(s string) string {
+			return trailingSpacePattern.ReplaceAllString(s, "$1")
+		},
+	}
+}
+
+// NormalizeLineEndings turns \r\n and \r line endings into \n.
+func NormalizeLineEndings() Normalizer {
+	return Normalizer{
+		description: "line endings",
+		apply: func(s string) string {
+			return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+		},
+	}
+}
+
+// normalizedChecker checks text after normalizing it.
+type normalizedChecker struct {
+	checker     Checker[string]
+	normalizers []Normalizer
+}
+
+// Normalized creates a checker that applies the normalizers to the actual
+// text, in order, before checking it, e.g.
+// Normalized(Is("ok\n"), StripANSI()) for colored output. Without
+// normalizers, it applies all of them.
+func Normalized(checker Checker[string], normalizers ...Normalizer) normalizedChecker {
+	if len(normalizers) == 0 {
+		normalizers = []Normalizer{StripANSI(), NormalizeLineEndings(), TrimTrailingSpace()}
+	}
+
+	return normalizedChecker{checker: checker, normalizers: normalizers}
+}
+
+func (m normalizedChecker) normalize(actual string) string {
+	for _, n := range m.normalizers {
+		actual = n.apply(actual)
+	}
+
+	return actual
+}
+
+func (m normalizedChecker) Check(actual string) bool {
+	return m.checker.Check(m.normalize(actual))
+}
+
+func (m normalizedChecker) Expected() string {
+	ignored := make([]string, len(m.normalizers))
+	for i, n := range m.normalizers {
+		ignored[i] = n.description
+	}
+
+	return fmt.Sprintf("ignoring %s, %s", strings.Join(ignored, " and "), m.checker.Expected())
+}
+
+func (m normalizedChecker) explain(actual string) string {
+	normalized := m.normalize(actual)
+	reason := explain(m.checker, normalized)
+	if normalized == actual {
+		return strings.TrimPrefix(reason, "\n  ")
+	}
+
+	return fmt.Sprintf("Normalized: %q%s", normalized, reason)
+}
@@ -0,0 +1,131 @@
+package attest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/littleclusters/lc/pkg/threadsafe"
+)
+
+// marks holds the named points in time recorded during a run.
+type marks = threadsafe.Map[string, time.Time]
+
+// Mark records that the named event happened now, e.g. after starting a
+// write in another goroutine. Marks are shared by every test in the run, and
+// marking a name again moves it.
+func (do *Do) Mark(name string) {
+	do.marks.Set(name, time.Now())
+}
+
+// HappensBefore expects the marks to have been recorded in the given order,
+// e.g. do.HappensBefore("primary-ack", "replica-read").
+func (do *Do) HappensBefore(names ...string) {
+	for i := 1; i < len(names); i++ {
+		before, after := names[i-1], names[i]
+		beforeAt, beforeOk := do.marks.Get(before)
+		afterAt, afterOk := do.marks.Get(after)
+
+		switch {
+		case !beforeOk || !afterOk:
+			missing := before
+			if beforeOk {
+				missing = after
+			}
+			panic(fmt.Sprintf("Ordering violation\n  Expected: %q before %q\n  Actual: %q was never marked",
+				before, after, missing))
+		case afterAt.Before(beforeAt):
+			panic(fmt.Sprintf("Ordering violation\n  Expected: %q before %q\n  Actual: %q happened %s earlier",
+				before, after, after, beforeAt.Sub(afterAt).Round(time.Microsecond)))
+		}
+	}
+}
+
+// orderAssert holds the ordering expectations shared by the HTTP and TCP
+// asserts.
+type orderAssert struct {
+	marks *marks
+	mark  string
+	after []string
+	// passed is when the first passing response was received.
+	passed time.Time
+}
+
+// Mark records when the passing response was received under name, once the
+// assertion passes. With Eventually or Consistently, the first passing
+// response counts.
+func (a *HTTPAssert) Mark(name string) *HTTPAssert {
+	a.order.mark = name
+	return a
+}
+
+// After expects the passing response to be received after the named mark,
+// e.g. a replica only returning a key once the primary acknowledged the
+// write. A mark that is missing when the assertion ends is a violation.
+func (a *HTTPAssert) After(names ...string) *HTTPAssert {
+	a.order.after = append(a.order.after, names...)
+	return a
+}
+
+// Mark records when the matching response was received under name, once the
+// assertion passes. With Eventually or Consistently, the first match counts.
+func (a *TCPAssert) Mark(name string) *TCPAssert {
+	a.order.mark = name
+	return a
+}
+
+// After expects the matching response to be received after the named mark.
+// A mark that is missing when the assertion ends is a violation.
+func (a *TCPAssert) After(names ...string) *TCPAssert {
+	a.order.after = append(a.order.after, names...)
+	return a
+}
+
+// observe notes when the first passing response was received.
+func (o *orderAssert) observe(ok bool) bool {
+	if ok && o.passed.IsZero() {
+		o.passed = time.Now()
+	}
+
+	return ok
+}
+
+// check calls fail with the first ordering violation, then records the mark.
+// Retrying cannot fix a response that came too early, so the order is
+// checked once the other expectations passed.
+func (o *orderAssert) check(fail func(detail, expected string)) {
+	if o.passed.IsZero() {
+		return
+	}
+
+	for _, name := range o.after {
+		expected := fmt.Sprintf("after %q", name)
+
+		at, ok := o.marks.Get(name)
+		if !ok || o.passed.Before(at) {
+			actual := fmt.Sprintf("%q was not marked yet", name)
+			if ok {
+				actual = fmt.Sprintf("received %s before %q", at.Sub(o.passed).Round(time.Microsecond), name)
+			}
+			fail(fmt.Sprintf("  Ordering violation\n  Expected response: %s\n  Actual: %s", expected, actual), expected)
+		}
+	}
+
+	if o.mark != "" {
+		o.marks.Set(o.mark, o.passed)
+	}
+}
+
+func (a *HTTPAssert) checkOrder() {
+	p := a.plan
+	a.order.check(func(detail, expected string) {
+		msg := fmt.Sprintf("%s %s\n%s%s", p.method, p.url, detail, a.formatHelp())
+		panic(a.failure(msg, "order", expected, ""))
+	})
+}
+
+func (a *TCPAssert) checkOrder() {
+	p := a.plan
+	a.order.check(func(detail, expected string) {
+		panic(fmt.Sprintf("TCP %s\n  Sent: %s\n%s%s", p.addr, p.formatSent(), detail, a.formatHelp()))
+	})
+}
@@ -0,0 +1,453 @@
+package attest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Plan[*OutboundPlan, *OutboundAssert] = (*OutboundPlan)(nil)
+var _ Assert = (*OutboundAssert)(nil)
+
+// Interceptor is an HTTP server the harness runs for the implementation's
+// outbound requests, e.g. the API a client challenge consumes. It records
+// every request, then forwards it to its target or answers itself.
+type Interceptor struct {
+	name     string
+	listener net.Listener
+	server   *http.Server
+	// handler forwards or answers requests, if set.
+	handler http.Handler
+
+	mu         sync.Mutex
+	requests   []outboundRequest
+	status     int
+	body       string
+	failFirst  int
+	failStatus int
+}
+
+// outboundRequest is a request the interceptor received.
+type outboundRequest struct {
+	method string
+	path   string
+	query  string
+	header http.Header
+	body   string
+	at     time.Time
+}
+
+// Intercept starts an interceptor for outbound requests, to point the
+// implementation at with StartWithEnv or a flag. With a target, a process
+// name or URL, requests are forwarded there; without, each is answered
+// with 200 OK until Respond says otherwise.
+func (do *Do) Intercept(name string, target ...string) *Interceptor {
+	var handler http.Handler
+	if len(target) > 0 {
+		handler = httputil.NewSingleHostReverseProxy(do.targetURL(target[0]))
+	}
+
+	return do.intercept(name, handler)
+}
+
+// intercept starts an interceptor passing requests to handler, if set.
+func (do *Do) intercept(name string, handler http.Handler) *Interceptor {
+	if _, exists := do.interceptors.Get(name); exists {
+		panic(fmt.Sprintf("interceptor %q already exists", name))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to start interceptor: %v", err))
+	}
+
+	ic := &Interceptor{name: name, listener: listener, handler: handler, status: http.StatusOK}
+	ic.server = &http.Server{Handler: ic}
+	go ic.server.Serve(listener)
+
+	do.interceptors.Set(name, ic)
+	return ic
+}
+
+// targetURL resolves a process name or URL to forward to.
+func (do *Do) targetURL(target string) *url.URL {
+	if !strings.Contains(target, "://") {
+		target = "http://" + do.addr(target)
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		panic(fmt.Sprintf("invalid interceptor target %q: %v", target, err))
+	}
+
+	return u
+}
+
+// getInterceptor retrieves an interceptor by name or panics if not found.
+func (do *Do) getInterceptor(name string) *Interceptor {
+	if ic, exists := do.interceptors.Get(name); exists {
+		return ic
+	}
+
+	panic(fmt.Sprintf("interceptor %q not found", name))
+}
+
+// URL returns the interceptor's base URL, e.g. http://127.0.0.1:41234.
+func (ic *Interceptor) URL() string {
+	return "http://" + ic.Addr()
+}
+
+// Addr returns the host:port the interceptor listens on.
+func (ic *Interceptor) Addr() string {
+	return ic.listener.Addr().String()
+}
+
+// Respond sets how the interceptor answers requests it does not forward.
+func (ic *Interceptor) Respond(status int, body string) *Interceptor {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	ic.status, ic.body = status, body
+	return ic
+}
+
+// FailFirst answers the next n requests with status instead, e.g. 503 to
+// check that the implementation retries.
+func (ic *Interceptor) FailFirst(n, status int) *Interceptor {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	ic.failFirst, ic.failStatus = n, status
+	return ic
+}
+
+// ServeHTTP records the request, then forwards or answers it.
+func (ic *Interceptor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	ic.mu.Lock()
+	ic.requests = append(ic.requests, outboundRequest{
+		method: r.Method,
+		path:   r.URL.Path,
+		query:  r.URL.RawQuery,
+		header: r.Header.Clone(),
+		body:   string(body),
+		at:     time.Now(),
+	})
+
+	failing := ic.failFirst > 0
+	if failing {
+		ic.failFirst--
+	}
+	failStatus, status, respBody := ic.failStatus, ic.status, ic.body
+	ic.mu.Unlock()
+
+	switch {
+	case failing:
+		w.WriteHeader(failStatus)
+	case ic.handler != nil:
+		ic.handler.ServeHTTP(w, r)
+	default:
+		w.WriteHeader(status)
+		io.WriteString(w, respBody)
+	}
+}
+
+// received returns a snapshot of the requests received so far.
+func (ic *Interceptor) received() []outboundRequest {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	return append([]outboundRequest(nil), ic.requests...)
+}
+
+// close stops the interceptor.
+func (ic *Interceptor) close() {
+	ic.server.Close()
+}
+
+// Outbound creates a test plan for the requests an interceptor received.
+func (do *Do) Outbound(name string) *OutboundPlan {
+	return &OutboundPlan{
+		PlanBase: PlanBase{
+			timing: TimingImmediate,
+			ctx:    do.ctx,
+			config: do.config,
+		},
+
+		interceptor: do.getInterceptor(name),
+	}
+}
+
+// OutboundPlan represents a test plan for the outbound requests an
+// interceptor received, optionally narrowed by method and path.
+type OutboundPlan struct {
+	PlanBase
+
+	interceptor *Interceptor
+	method      string
+	path        string
+}
+
+// Method only checks requests with the method.
+func (p *OutboundPlan) Method(method string) *OutboundPlan {
+	p.method = method
+	return p
+}
+
+// Path only checks requests to the path, without its query.
+func (p *OutboundPlan) Path(path string) *OutboundPlan {
+	p.path = path
+	return p
+}
+
+func (p *OutboundPlan) Eventually() *OutboundPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *OutboundPlan) Within(timeout time.Duration) *OutboundPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *OutboundPlan) Retry(policy RetryPolicy) *OutboundPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *OutboundPlan) Consistently() *OutboundPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *OutboundPlan) For(timeout time.Duration) *OutboundPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *OutboundPlan) Every(interval time.Duration) *OutboundPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *OutboundPlan) Deadline(d time.Duration) *OutboundPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *OutboundPlan) T() *OutboundAssert {
+	return &OutboundAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// OutboundAssert provides assertions on the outbound requests an
+// interceptor received. Without Count, at least one must match.
+type OutboundAssert struct {
+	AssertBase
+
+	plan     *OutboundPlan
+	requests []outboundRequest
+
+	countCheckers []Checker[int]
+	headers       []headerExpectation
+	queryCheckers []Checker[string]
+	bodyCheckers  []Checker[string]
+	gapCheckers   []Checker[time.Duration]
+}
+
+// Count adds checkers for the number of matching requests, e.g. Is(3) for
+// two retries. All checkers must pass.
+func (a *OutboundAssert) Count(checkers ...Checker[int]) *OutboundAssert {
+	a.countCheckers = append(a.countCheckers, checkers...)
+	return a
+}
+
+// Header adds checkers for a header of every matching request. Repeated
+// headers are joined with ", ". All checkers must pass.
+func (a *OutboundAssert) Header(name string, checkers ...Checker[string]) *OutboundAssert {
+	a.headers = append(a.headers, headerExpectation{name: name, checkers: checkers})
+	return a
+}
+
+// NoHeader expects no matching request to include a header.
+func (a *OutboundAssert) NoHeader(name string) *OutboundAssert {
+	a.headers = append(a.headers, headerExpectation{name: name, absent: true})
+	return a
+}
+
+// Query adds checkers for the raw query of every matching request.
+// All checkers must pass.
+func (a *OutboundAssert) Query(checkers ...Checker[string]) *OutboundAssert {
+	a.queryCheckers = append(a.queryCheckers, checkers...)
+	return a
+}
+
+// Body adds checkers for the body of every matching request.
+// All checkers must pass.
+func (a *OutboundAssert) Body(checkers ...Checker[string]) *OutboundAssert {
+	a.bodyCheckers = append(a.bodyCheckers, checkers...)
+	return a
+}
+
+// Gaps adds checkers for the time between consecutive matching requests,
+// e.g. AtLeast(100*time.Millisecond) for a retry backoff. All checkers must
+// pass for every gap.
+func (a *OutboundAssert) Gaps(checkers ...Checker[time.Duration]) *OutboundAssert {
+	a.gapCheckers = append(a.gapCheckers, checkers...)
+	return a
+}
+
+func (a *OutboundAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *OutboundAssert) execute() bool {
+	p := a.plan
+
+	a.requests = nil
+	for _, r := range p.interceptor.received() {
+		if (p.method == "" || r.method == p.method) && (p.path == "" || r.path == p.path) {
+			a.requests = append(a.requests, r)
+		}
+	}
+
+	return a.passes()
+}
+
+// passes reports whether the matching requests meet every expectation.
+func (a *OutboundAssert) passes() bool {
+	if len(a.countCheckers) == 0 && len(a.requests) == 0 {
+		return false
+	}
+	if !checkAll(len(a.requests), a.countCheckers, nil) {
+		return false
+	}
+
+	for i, r := range a.requests {
+		for _, h := range a.headers {
+			values := r.header.Values(h.name)
+			if h.absent && len(values) > 0 || !checkAll(strings.Join(values, ", "), h.checkers, nil) {
+				return false
+			}
+		}
+		if !checkAll(r.query, a.queryCheckers, nil) || !checkAll(r.body, a.bodyCheckers, nil) {
+			return false
+		}
+		if i > 0 && !checkAll(r.at.Sub(a.requests[i-1].at), a.gapCheckers, nil) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describe returns what the plan matches, e.g. "GET /users to api".
+func (p *OutboundPlan) describe() string {
+	matched := strings.TrimSpace(p.method + " " + p.path)
+	if matched == "" {
+		matched = "requests"
+	}
+
+	return fmt.Sprintf("%s to %s", matched, p.interceptor.name)
+}
+
+// formatRequests lists the requests an interceptor received, with the time
+// each arrived after the first.
+func formatRequests(requests []outboundRequest) string {
+	if len(requests) == 0 {
+		return " none"
+	}
+
+	var b strings.Builder
+	for _, r := range requests {
+		target := r.path
+		if r.query != "" {
+			target += "?" + r.query
+		}
+		fmt.Fprintf(&b, "\n    +%s %s %s", r.at.Sub(requests[0].at).Round(time.Millisecond), r.method, target)
+	}
+
+	return b.String()
+}
+
+func (a *OutboundAssert) check() {
+	p := a.plan
+	title := "Outbound " + p.describe()
+
+	if len(a.countCheckers) == 0 && len(a.requests) == 0 {
+		msg := fmt.Sprintf("%s\n  Expected a request\n  Received:%s%s",
+			title, formatRequests(p.interceptor.received()), a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(len(a.requests), a.countCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected requests: %s\n  Actual requests: %d\n  Received:%s%s",
+			title, m.Expected(), actual, formatRequests(p.interceptor.received()), a.formatHelp())
+		panic(msg)
+	})
+
+	for i, r := range a.requests {
+		request := fmt.Sprintf("request %d", i+1)
+
+		for _, h := range a.headers {
+			values := r.header.Values(h.name)
+			if h.absent && len(values) > 0 {
+				msg := fmt.Sprintf("%s\n  Expected %s without header %s\n  Actual %s: %q%s",
+					title, request, h.name, h.name, strings.Join(values, ", "), a.formatHelp())
+				panic(msg)
+			}
+
+			checkAll(strings.Join(values, ", "), h.checkers, func(m Checker[string], actual string) {
+				msg := fmt.Sprintf("%s\n  Expected %s header %s: %s\n  Actual %s: %q%s",
+					title, request, h.name, m.Expected(), h.name, actual, a.formatHelp())
+				panic(msg)
+			})
+		}
+
+		checkAll(r.query, a.queryCheckers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected %s query: %s\n  Actual query: %q%s",
+				title, request, m.Expected(), actual, a.formatHelp())
+			panic(msg)
+		})
+
+		checkAll(r.body, a.bodyCheckers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected %s body: %s\n  Actual body:%s%s%s",
+				title, request, m.Expected(), formatText(actual), explain(m, actual), a.formatHelp())
+			panic(msg)
+		})
+
+		if i == 0 {
+			continue
+		}
+		checkAll(r.at.Sub(a.requests[i-1].at), a.gapCheckers, func(m Checker[time.Duration], actual time.Duration) {
+			msg := fmt.Sprintf("%s\n  Expected gap before %s: %s\n  Actual gap: %s\n  Received:%s%s",
+				title, request, m.Expected(), actual.Round(time.Millisecond), formatRequests(a.requests), a.formatHelp())
+			panic(msg)
+		})
+	}
+}
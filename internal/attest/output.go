@@ -0,0 +1,152 @@
+package attest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// outputStreams captures a command's stdout and stderr, both apart and
+// interleaved in the order they were read. Writes to the two streams in
+// quick succession may be read out of order.
+type outputStreams struct {
+	mu       sync.Mutex
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	combined bytes.Buffer
+}
+
+// writer returns a writer to buf that also appends to the combined output.
+func (s *outputStreams) writer(buf *bytes.Buffer) io.Writer {
+	return streamWriter{streams: s, buf: buf}
+}
+
+type streamWriter struct {
+	streams *outputStreams
+	buf     *bytes.Buffer
+}
+
+func (w streamWriter) Write(data []byte) (int, error) {
+	w.streams.mu.Lock()
+	defer w.streams.mu.Unlock()
+
+	w.buf.Write(data)
+	return w.streams.combined.Write(data)
+}
+
+// Stdout adds checkers for what the command wrote to stdout, whatever its
+// exit code. All checkers must pass.
+func (a *CLIAssert) Stdout(checkers ...Checker[string]) *CLIAssert {
+	a.stdoutCheckers = append(a.stdoutCheckers, checkers...)
+	return a
+}
+
+// Stderr adds checkers for what the command wrote to stderr, whatever its
+// exit code, e.g. that an error message goes there rather than to stdout.
+// All checkers must pass.
+func (a *CLIAssert) Stderr(checkers ...Checker[string]) *CLIAssert {
+	a.stderrCheckers = append(a.stderrCheckers, checkers...)
+	return a
+}
+
+// StderrEmpty expects the command to write nothing to stderr.
+func (a *CLIAssert) StderrEmpty() *CLIAssert {
+	return a.Stderr(HasLen[string](0))
+}
+
+// JSON parses stdout as a JSON document, e.g. from a --json flag, and adds
+// checkers for the field at the given gjson path or JSONPath. Exists, IsType
+// and HasLen check presence, type and length. All checkers must pass.
+func (a *CLIAssert) JSON(path string, checkers ...Checker[string]) *CLIAssert {
+	for _, checker := range checkers {
+		a.jsonCheckers = append(a.jsonCheckers, JSON(path, checker))
+	}
+
+	return a
+}
+
+// JSONLines parses stdout as JSON Lines, one document per line, and adds
+// checkers for the field at the given path across lines: # is the number
+// of lines, 0.id the id on the first line and #.level the levels of all
+// lines as an array. All checkers must pass.
+func (a *CLIAssert) JSONLines(path string, checkers ...Checker[string]) *CLIAssert {
+	a.jsonLines = true
+	for _, checker := range checkers {
+		field := JSON(path, checker)
+		field.path = ".." + field.path
+		a.jsonCheckers = append(a.jsonCheckers, field)
+	}
+
+	return a
+}
+
+// invalidJSON describes why stdout is not valid JSON, or JSON Lines, or
+// returns "" if it is.
+func (a *CLIAssert) invalidJSON() string {
+	if !a.jsonLines {
+		if !json.Valid([]byte(a.stdout)) {
+			return "not valid JSON"
+		}
+		return ""
+	}
+
+	for i, line := range strings.Split(a.stdout, "\n") {
+		if strings.TrimSpace(line) != "" && !json.Valid([]byte(line)) {
+			return fmt.Sprintf("line %d is not valid JSON: %q", i+1, line)
+		}
+	}
+
+	return ""
+}
+
+// checkStreams fails with the first stdout or stderr checker that failed.
+func (a *CLIAssert) checkStreams() {
+	p := a.plan
+	title := fmt.Sprintf("%s %s", p.command, strings.Join(p.args, " "))
+
+	checkAll(a.stdout, a.stdoutCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected stdout: %s\n  Actual stdout:%s%s%s%s",
+			title, m.Expected(), formatText(actual), explain(m, actual), a.formatCombined(actual), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.stderr, a.stderrCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected stderr: %s\n  Actual stderr:%s%s%s%s",
+			title, m.Expected(), formatText(actual), explain(m, actual), a.formatCombined(actual), a.formatHelp())
+		panic(msg)
+	})
+
+	if len(a.jsonCheckers) == 0 {
+		return
+	}
+
+	if reason := a.invalidJSON(); reason != "" {
+		msg := fmt.Sprintf("%s\n  Expected stdout: valid JSON\n  Actual: %s\n  Stdout:%s%s",
+			title, reason, formatText(a.stdout), a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.stdout, a.jsonCheckers, func(m Checker[string], actual string) {
+		reason := explain(m, actual)
+		if field, ok := m.(JSONFieldChecker); ok {
+			actual = field.actual(actual)
+		}
+
+		msg := fmt.Sprintf("%s\n  Expected JSON: %s\n  Actual value: %v%s%s",
+			title, m.Expected(), actual, reason, a.formatHelp())
+		panic(msg)
+	})
+}
+
+// formatCombined formats stdout and stderr interleaved, for context unless
+// the failure already shows all of it.
+func (a *CLIAssert) formatCombined(shown string) string {
+	if a.combined == shown {
+		return ""
+	}
+
+	return "\n  Combined output:" + formatText(a.combined)
+}
@@ -22,8 +22,15 @@ type Plan[P any, A any] interface {
 	Within(time.Duration) P
 	// Consistently configures the plan to verify success for the entire duration.
 	Consistently() P
+	// Retry sets the retry policy for Eventually, e.g. exponential backoff.
+	Retry(RetryPolicy) P
 	// For sets a custom timeout for Consistently.
 	For(time.Duration) P
+	// Every sets how often Consistently checks the plan.
+	Every(time.Duration) P
+	// Deadline bounds the whole assertion, retries included, so a hung
+	// server fails the plan instead of stalling the suite.
+	Deadline(time.Duration) P
 	// T returns the test for this plan.
 	T() A
 }
@@ -35,6 +42,10 @@ var _ Plan[*CLIPlan, *CLIAssert] = (*CLIPlan)(nil)
 type PlanBase struct {
 	timing  timing
 	timeout time.Duration
+	retry   *RetryPolicy
+	every   time.Duration
+	// deadline bounds the whole assertion. Zero uses Config.PlanDeadline.
+	deadline time.Duration
 
 	ctx context.Context
 
@@ -43,7 +54,7 @@ type PlanBase struct {
 
 func (b *PlanBase) setEventually() {
 	b.timing = TimingEventually
-	b.timeout = b.config.DefaultRetryTimeout
+	b.timeout = b.config.scaled(b.config.DefaultRetryTimeout)
 }
 
 func (b *PlanBase) setWithin(timeout time.Duration) {
@@ -51,12 +62,12 @@ func (b *PlanBase) setWithin(timeout time.Duration) {
 		panic("Within() can only be called after Eventually()")
 	}
 
-	b.timeout = timeout
+	b.timeout = b.config.scaled(timeout)
 }
 
 func (b *PlanBase) setConsistently() {
 	b.timing = TimingConsistently
-	b.timeout = b.config.DefaultRetryTimeout
+	b.timeout = b.config.scaled(b.config.DefaultRetryTimeout)
 }
 
 func (b *PlanBase) setFor(timeout time.Duration) {
@@ -64,7 +75,24 @@ func (b *PlanBase) setFor(timeout time.Duration) {
 		panic("For() can only be called after Consistently()")
 	}
 
-	b.timeout = timeout
+	b.timeout = b.config.scaled(timeout)
+}
+
+func (b *PlanBase) setEvery(interval time.Duration) {
+	if interval <= 0 {
+		panic("Every() requires a positive interval")
+	}
+
+	b.every = interval
+}
+
+// pollInterval returns how often Consistently checks the plan.
+func (b *PlanBase) pollInterval() time.Duration {
+	if b.every > 0 {
+		return b.every
+	}
+
+	return b.config.RetryPollInterval
 }
 
 // H is a convenience type for HTTP headers.
@@ -74,10 +102,32 @@ type H map[string]string
 type HTTPPlan struct {
 	PlanBase
 
+	process string
 	method  string
+	path    string
 	url     string
 	headers H
 	body    []byte
+	// bodyFile is the fixture streamed as the body instead of body.
+	bodyFile string
+	// bodyEncoding compresses the body, e.g. gzip.
+	bodyEncoding string
+	// maxRedirects is how many redirects to follow, 0 for the default and
+	// -1 for none.
+	maxRedirects int
+	signer       *sigV4
+
+	sse       bool
+	eventWait time.Duration
+	http2     bool
+	streams   int
+	http3     bool
+	tls       *TLSConfig
+	marks     *marks
+	// session is the session the request belongs to, if any.
+	session *Session
+	// vars holds the variables the plan's captures set.
+	vars *variables
 }
 
 func (p *HTTPPlan) Eventually() *HTTPPlan {
@@ -90,6 +140,11 @@ func (p *HTTPPlan) Within(timeout time.Duration) *HTTPPlan {
 	return p
 }
 
+func (p *HTTPPlan) Retry(policy RetryPolicy) *HTTPPlan {
+	p.setRetry(policy)
+	return p
+}
+
 func (p *HTTPPlan) Consistently() *HTTPPlan {
 	p.setConsistently()
 	return p
@@ -100,10 +155,21 @@ func (p *HTTPPlan) For(timeout time.Duration) *HTTPPlan {
 	return p
 }
 
+func (p *HTTPPlan) Every(interval time.Duration) *HTTPPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *HTTPPlan) Deadline(d time.Duration) *HTTPPlan {
+	p.setDeadline(d)
+	return p
+}
+
 func (p *HTTPPlan) T() *HTTPAssert {
 	return &HTTPAssert{
 		AssertBase: AssertBase{config: p.config},
 		plan:       p,
+		order:      orderAssert{marks: p.marks},
 	}
 }
 
@@ -113,6 +179,43 @@ type CLIPlan struct {
 
 	command string
 	args    []string
+	stdin   []byte
+	// stdinFile is read on each execution when stdin is nil.
+	stdinFile string
+	env       []string
+	// harnessEnv is the environment the harness adds, e.g. LC_CLOCK_FILE.
+	harnessEnv []string
+	// dir is where the command runs, the project directory if empty.
+	dir        string
+	workingDir string
+}
+
+// InDir runs the command in dir instead of the project directory, e.g. a
+// directory seeded with fixtures that the command may modify. A relative
+// dir is inside the working directory, and is created if missing.
+func (p *CLIPlan) InDir(dir string) *CLIPlan {
+	p.dir = resolvePath(p.workingDir, dir)
+	return p
+}
+
+// WithEnv sets an environment variable for the command, e.g. to check it
+// honors NO_COLOR.
+func (p *CLIPlan) WithEnv(key, value string) *CLIPlan {
+	p.env = append(p.env, key+"="+value)
+	return p
+}
+
+// WithStdin feeds data to the command's standard input.
+func (p *CLIPlan) WithStdin(data []byte) *CLIPlan {
+	p.stdin = data
+	return p
+}
+
+// WithStdinFile feeds the contents of the file at path to the command's
+// standard input, e.g. a fixture for a filter like sort or wc.
+func (p *CLIPlan) WithStdinFile(path string) *CLIPlan {
+	p.stdinFile = path
+	return p
 }
 
 func (p *CLIPlan) Eventually() *CLIPlan {
@@ -125,6 +228,11 @@ func (p *CLIPlan) Within(timeout time.Duration) *CLIPlan {
 	return p
 }
 
+func (p *CLIPlan) Retry(policy RetryPolicy) *CLIPlan {
+	p.setRetry(policy)
+	return p
+}
+
 func (p *CLIPlan) Consistently() *CLIPlan {
 	p.setConsistently()
 	return p
@@ -135,6 +243,16 @@ func (p *CLIPlan) For(timeout time.Duration) *CLIPlan {
 	return p
 }
 
+func (p *CLIPlan) Every(interval time.Duration) *CLIPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *CLIPlan) Deadline(d time.Duration) *CLIPlan {
+	p.setDeadline(d)
+	return p
+}
+
 func (p *CLIPlan) T() *CLIAssert {
 	return &CLIAssert{
 		AssertBase: AssertBase{config: p.config},
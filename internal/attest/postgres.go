@@ -0,0 +1,586 @@
+package attest
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var _ Plan[*PostgresPlan, *PostgresAssert] = (*PostgresPlan)(nil)
+var _ Assert = (*PostgresAssert)(nil)
+
+// pgProtocolVersion is protocol 3.0, sent in the startup message.
+const pgProtocolVersion = 3 << 16
+
+// PostgresPlan represents a test plan that connects with the PostgreSQL
+// wire protocol, performs the startup handshake and sends a simple query.
+type PostgresPlan struct {
+	PlanBase
+
+	process  string
+	addr     string
+	query    string
+	user     string
+	database string
+	password string
+}
+
+// User sets the user sent in the startup message, "postgres" by default.
+func (p *PostgresPlan) User(user string) *PostgresPlan {
+	p.user = user
+	return p
+}
+
+// Database sets the database sent in the startup message. It defaults to
+// the user name.
+func (p *PostgresPlan) Database(database string) *PostgresPlan {
+	p.database = database
+	return p
+}
+
+// Password sets the password for cleartext or MD5 authentication.
+func (p *PostgresPlan) Password(password string) *PostgresPlan {
+	p.password = password
+	return p
+}
+
+func (p *PostgresPlan) Eventually() *PostgresPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *PostgresPlan) Within(timeout time.Duration) *PostgresPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *PostgresPlan) Retry(policy RetryPolicy) *PostgresPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *PostgresPlan) Consistently() *PostgresPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *PostgresPlan) For(timeout time.Duration) *PostgresPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *PostgresPlan) Every(interval time.Duration) *PostgresPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *PostgresPlan) Deadline(d time.Duration) *PostgresPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *PostgresPlan) T() *PostgresAssert {
+	return &PostgresAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// pgConn is a frontend connection speaking protocol 3.0.
+type pgConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// send writes a message with the given type, or an untyped startup
+// message when typ is 0.
+func (c *pgConn) send(typ byte, body []byte) error {
+	var msg []byte
+	if typ != 0 {
+		msg = append(msg, typ)
+	}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(body)+4))
+	msg = append(msg, body...)
+
+	_, err := c.conn.Write(msg)
+	return err
+}
+
+// receive reads the next backend message.
+func (c *pgConn) receive() (byte, []byte, error) {
+	var header [5]byte
+	_, err := io.ReadFull(c.reader, header[:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 || length > 1<<30 {
+		return 0, nil, fmt.Errorf("invalid length %d for message %q", length, header[0])
+	}
+
+	body := make([]byte, length-4)
+	_, err = io.ReadFull(c.reader, body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], body, nil
+}
+
+// cstring appends s as a null-terminated string.
+func cstring(b []byte, s string) []byte {
+	return append(append(b, s...), 0)
+}
+
+// pgError is a decoded ErrorResponse.
+type pgError struct {
+	severity string
+	code     string
+	message  string
+}
+
+func (e *pgError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.severity, e.code, e.message)
+}
+
+// parseError decodes the fields of an ErrorResponse.
+func parseError(body []byte) *pgError {
+	e := &pgError{}
+	for len(body) > 1 {
+		field := body[0]
+		value, rest, _ := strings.Cut(string(body[1:]), "\x00")
+		body = []byte(rest)
+
+		switch field {
+		case 'S':
+			e.severity = value
+		case 'C':
+			e.code = value
+		case 'M':
+			e.message = value
+		}
+	}
+
+	return e
+}
+
+// startup performs the handshake until the server is ready for a query.
+func (c *pgConn) startup(user, database, password string) error {
+	body := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	body = cstring(cstring(body, "user"), user)
+	body = cstring(cstring(body, "database"), database)
+	body = append(body, 0)
+
+	err := c.send(0, body)
+	if err != nil {
+		return err
+	}
+
+	for {
+		typ, body, err := c.receive()
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		case 'R':
+			if len(body) < 4 {
+				return fmt.Errorf("malformed authentication request")
+			}
+
+			switch method := binary.BigEndian.Uint32(body); method {
+			case 0:
+			case 3:
+				err = c.send('p', cstring(nil, password))
+			case 5:
+				if len(body) < 8 {
+					return fmt.Errorf("malformed MD5 authentication request")
+				}
+				err = c.send('p', cstring(nil, md5Password(user, password, body[4:8])))
+			default:
+				return fmt.Errorf("unsupported authentication method %d", method)
+			}
+			if err != nil {
+				return err
+			}
+		case 'E':
+			return fmt.Errorf("startup failed: %w", parseError(body))
+		case 'Z':
+			return nil
+		}
+	}
+}
+
+// md5Password computes the response to an MD5 authentication request.
+func md5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+// pgResult is the outcome of a simple query. When the query has several
+// statements, columns, rows and tag are those of the last one.
+type pgResult struct {
+	columns []string
+	rows    [][]*string
+	tag     string
+	err     *pgError
+}
+
+// query sends a simple query and reads the results until the server is
+// ready again.
+func (c *pgConn) query(sql string) (*pgResult, error) {
+	err := c.send('Q', cstring(nil, sql))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pgResult{}
+	for {
+		typ, body, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case 'T':
+			result.columns, result.rows = parseRowDescription(body), nil
+		case 'D':
+			row, err := parseDataRow(body)
+			if err != nil {
+				return nil, err
+			}
+			result.rows = append(result.rows, row)
+		case 'C':
+			result.tag, _, _ = strings.Cut(string(body), "\x00")
+		case 'E':
+			result.err = parseError(body)
+		case 'Z':
+			return result, nil
+		}
+	}
+}
+
+// parseRowDescription returns the column names of a RowDescription.
+func parseRowDescription(body []byte) []string {
+	if len(body) < 2 {
+		return nil
+	}
+
+	count := int(binary.BigEndian.Uint16(body))
+	body = body[2:]
+
+	columns := make([]string, 0, count)
+	for range count {
+		name, rest, ok := strings.Cut(string(body), "\x00")
+		if !ok || len(rest) < 18 {
+			break
+		}
+
+		columns = append(columns, name)
+		// Skip table OID, column number, type OID, size, modifier and format
+		body = []byte(rest[18:])
+	}
+
+	return columns
+}
+
+// parseDataRow returns the values of a DataRow, nil for NULL.
+func parseDataRow(body []byte) ([]*string, error) {
+	if len(body) < 2 {
+		return nil, fmt.Errorf("malformed DataRow")
+	}
+
+	count := int(binary.BigEndian.Uint16(body))
+	body = body[2:]
+
+	row := make([]*string, 0, count)
+	for range count {
+		if len(body) < 4 {
+			return nil, fmt.Errorf("malformed DataRow")
+		}
+
+		length := int32(binary.BigEndian.Uint32(body))
+		body = body[4:]
+		if length < 0 {
+			row = append(row, nil)
+			continue
+		}
+		if int(length) > len(body) {
+			return nil, fmt.Errorf("malformed DataRow")
+		}
+
+		value := string(body[:length])
+		row = append(row, &value)
+		body = body[length:]
+	}
+
+	return row, nil
+}
+
+// pgCellChecker checks a value in a result row.
+type pgCellChecker struct {
+	row     int
+	column  string
+	checker Checker[string]
+}
+
+// PostgresAssert provides assertions on the result of a query.
+type PostgresAssert struct {
+	AssertBase
+
+	plan   *PostgresPlan
+	result *pgResult
+	err    error
+
+	columnsCheckers  []Checker[string]
+	rowCountCheckers []Checker[int]
+	cellCheckers     []pgCellChecker
+	tagCheckers      []Checker[string]
+	errorCheckers    []Checker[string]
+	codeCheckers     []Checker[string]
+}
+
+// Columns adds checkers for the column names, joined with ", ".
+// All checkers must pass.
+func (a *PostgresAssert) Columns(checkers ...Checker[string]) *PostgresAssert {
+	a.columnsCheckers = append(a.columnsCheckers, checkers...)
+	return a
+}
+
+// RowCount adds checkers for the number of rows returned.
+// All checkers must pass.
+func (a *PostgresAssert) RowCount(checkers ...Checker[int]) *PostgresAssert {
+	a.rowCountCheckers = append(a.rowCountCheckers, checkers...)
+	return a
+}
+
+// Cell adds checkers for the value in a column of the given zero-based
+// row. NULL is "NULL". All checkers must pass.
+func (a *PostgresAssert) Cell(row int, column string, checkers ...Checker[string]) *PostgresAssert {
+	for _, checker := range checkers {
+		a.cellCheckers = append(a.cellCheckers, pgCellChecker{row: row, column: column, checker: checker})
+	}
+
+	return a
+}
+
+// CommandTag adds checkers for the CommandComplete tag, e.g. "INSERT 0 1".
+// All checkers must pass.
+func (a *PostgresAssert) CommandTag(checkers ...Checker[string]) *PostgresAssert {
+	a.tagCheckers = append(a.tagCheckers, checkers...)
+	return a
+}
+
+// Error expects the query to fail, with an error message passing all
+// checkers. Without Error or SQLState, any ErrorResponse fails the assertion.
+func (a *PostgresAssert) Error(checkers ...Checker[string]) *PostgresAssert {
+	a.errorCheckers = append(a.errorCheckers, checkers...)
+	return a
+}
+
+// SQLState expects the query to fail, with an SQLSTATE code passing all
+// checkers, e.g. "42P01".
+func (a *PostgresAssert) SQLState(checkers ...Checker[string]) *PostgresAssert {
+	a.codeCheckers = append(a.codeCheckers, checkers...)
+	return a
+}
+
+func (a *PostgresAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *PostgresAssert) execute() bool {
+	p := a.plan
+	a.result, a.err = nil, nil
+
+	conn, err := net.DialTimeout("tcp", p.addr, a.config.ExecuteTimeout)
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.Close()
+	defer closeWhenDone(p.ctx, conn)()
+
+	conn.SetDeadline(time.Now().Add(a.config.ExecuteTimeout))
+	pg := &pgConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	database := p.database
+	if database == "" {
+		database = p.user
+	}
+
+	err = pg.startup(p.user, database, p.password)
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	a.result, err = pg.query(p.query)
+	if err != nil {
+		a.err = err
+		return false
+	}
+
+	// Terminate
+	pg.send('X', nil)
+
+	return a.passes()
+}
+
+// expectsError reports whether the assertion expects the query to fail.
+func (a *PostgresAssert) expectsError() bool {
+	return len(a.errorCheckers) > 0 || len(a.codeCheckers) > 0
+}
+
+// passes reports whether the result meets every expectation.
+func (a *PostgresAssert) passes() bool {
+	r := a.result
+	if r.err != nil {
+		return a.expectsError() &&
+			checkAll(r.err.message, a.errorCheckers, nil) &&
+			checkAll(r.err.code, a.codeCheckers, nil)
+	}
+	if a.expectsError() {
+		return false
+	}
+
+	for _, c := range a.cellCheckers {
+		value, ok := a.cell(c)
+		if !ok || !c.checker.Check(value) {
+			return false
+		}
+	}
+
+	return checkAll(strings.Join(r.columns, ", "), a.columnsCheckers, nil) &&
+		checkAll(len(r.rows), a.rowCountCheckers, nil) &&
+		checkAll(r.tag, a.tagCheckers, nil)
+}
+
+// cell returns the value for a cell checker, if the row and column exist.
+func (a *PostgresAssert) cell(c pgCellChecker) (string, bool) {
+	r := a.result
+	if c.row < 0 || c.row >= len(r.rows) {
+		return "", false
+	}
+
+	for i, column := range r.columns {
+		if column == c.column && i < len(r.rows[c.row]) {
+			if value := r.rows[c.row][i]; value != nil {
+				return *value, true
+			}
+			return "NULL", true
+		}
+	}
+
+	return "", false
+}
+
+// formatRows lists the result like psql's unaligned output.
+func (a *PostgresAssert) formatRows() string {
+	r := a.result
+	lines := []string{"\n    " + strings.Join(r.columns, "|")}
+	for _, row := range r.rows {
+		values := make([]string, 0, len(row))
+		for _, value := range row {
+			if value == nil {
+				values = append(values, "NULL")
+			} else {
+				values = append(values, *value)
+			}
+		}
+		lines = append(lines, "\n    "+strings.Join(values, "|"))
+	}
+
+	return strings.Join(lines, "")
+}
+
+func (a *PostgresAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("PostgreSQL %s\n  Query: %s", p.addr, p.query)
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	r := a.result
+	if r.err != nil && !a.expectsError() {
+		msg := fmt.Sprintf("%s\n  Expected the query to succeed\n  Actual error: %v%s", title, r.err, a.formatHelp())
+		panic(msg)
+	}
+	if r.err == nil && a.expectsError() {
+		msg := fmt.Sprintf("%s\n  Expected the query to fail\n  Actual: %s%s", title, r.tag, a.formatHelp())
+		panic(msg)
+	}
+
+	if r.err != nil {
+		checkAll(r.err.message, a.errorCheckers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected error: %s\n  Actual error: %q%s",
+				title, m.Expected(), actual, a.formatHelp())
+			panic(msg)
+		})
+
+		checkAll(r.err.code, a.codeCheckers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected SQLSTATE: %s\n  Actual SQLSTATE: %s (%s)%s",
+				title, m.Expected(), actual, r.err.message, a.formatHelp())
+			panic(msg)
+		})
+
+		return
+	}
+
+	checkAll(strings.Join(r.columns, ", "), a.columnsCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected columns: %s\n  Actual columns: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(len(r.rows), a.rowCountCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected rows: %s\n  Actual rows: %d%s%s",
+			title, m.Expected(), actual, a.formatRows(), a.formatHelp())
+		panic(msg)
+	})
+
+	for _, c := range a.cellCheckers {
+		value, ok := a.cell(c)
+		if !ok {
+			msg := fmt.Sprintf("%s\n  Expected row %d column %s: %s\n  Actual result:%s%s",
+				title, c.row, c.column, c.checker.Expected(), a.formatRows(), a.formatHelp())
+			panic(msg)
+		}
+
+		if !c.checker.Check(value) {
+			msg := fmt.Sprintf("%s\n  Expected row %d column %s: %s\n  Actual row %d column %s: %q%s",
+				title, c.row, c.column, c.checker.Expected(), c.row, c.column, value, a.formatHelp())
+			panic(msg)
+		}
+	}
+
+	checkAll(r.tag, a.tagCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected command tag: %s\n  Actual command tag: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
@@ -0,0 +1,218 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var _ Assert = (*ProcessAssert)(nil)
+
+// ProcessPlan sends a signal to a running process and checks how it
+// shuts down, e.g. that SIGTERM lets in-flight requests finish.
+type ProcessPlan struct {
+	ctx    context.Context
+	config *Config
+
+	do       *Do
+	process  string
+	signal   syscall.Signal
+	delay    time.Duration
+	inFlight []Assert
+}
+
+// InFlight starts the assertions, sends the signal d later so they are
+// still running, and expects them to pass, e.g. slow requests a graceful
+// shutdown must finish.
+func (p *ProcessPlan) InFlight(d time.Duration, members ...Assert) *ProcessPlan {
+	p.delay = d
+	p.inFlight = append(p.inFlight, members...)
+	return p
+}
+
+func (p *ProcessPlan) T() *ProcessAssert {
+	return &ProcessAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// ProcessAssert provides assertions on how a process handles a signal.
+type ProcessAssert struct {
+	AssertBase
+
+	plan     *ProcessPlan
+	exited   bool
+	elapsed  time.Duration
+	exitCode int
+	state    string
+	failures []any
+
+	exitsWithin      time.Duration
+	survives         time.Duration
+	exitCodeCheckers []Checker[int]
+}
+
+// ExitsWithin expects the process to exit within d of the signal. Without
+// it or Survives, the process must exit within ProcessShutdownTimeout.
+func (a *ProcessAssert) ExitsWithin(d time.Duration) *ProcessAssert {
+	a.exitsWithin = d
+	return a
+}
+
+// Survives expects the process to still be running d after the signal,
+// e.g. SIGHUP reloading the configuration.
+func (a *ProcessAssert) Survives(d time.Duration) *ProcessAssert {
+	a.survives = d
+	return a
+}
+
+// ExitCode adds checkers for the exit code, which is -1 if the process was
+// killed by a signal. All checkers must pass.
+func (a *ProcessAssert) ExitCode(checkers ...Checker[int]) *ProcessAssert {
+	a.exitCodeCheckers = append(a.exitCodeCheckers, checkers...)
+	return a
+}
+
+func (a *ProcessAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+}
+
+func (a *ProcessAssert) execute() bool {
+	p := a.plan
+	proc := p.do.getProcess(p.process)
+	if proc.cmd == nil {
+		panic(fmt.Sprintf("process %q was not started, so it cannot be signalled", p.process))
+	}
+
+	a.failures = make([]any, len(p.inFlight))
+	var wg sync.WaitGroup
+	for i, member := range p.inFlight {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { a.failures[i] = recover() }()
+
+			// In-flight requests fail as usual in a soft test, so they are
+			// reported here
+			member.base().strict = true
+			member.Assert("")
+		}()
+	}
+
+	select {
+	case <-p.ctx.Done():
+	case <-time.After(p.delay):
+	}
+
+	p.do.signal(p.process, p.signal)
+	sent := time.Now()
+
+	wait := a.exitsWithin
+	if a.survives > 0 {
+		wait = a.survives
+	} else if wait == 0 {
+		wait = p.config.ProcessShutdownTimeout
+	}
+
+	select {
+	case <-proc.exited:
+		a.exited = true
+		a.elapsed = time.Since(sent)
+		a.exitCode = proc.state.ExitCode()
+		a.state = proc.state.String()
+	case <-time.After(p.config.scaled(wait)):
+	case <-p.ctx.Done():
+	}
+	wg.Wait()
+
+	if a.survives > 0 {
+		return !a.exited && a.firstFailure() == ""
+	}
+
+	return a.exited && a.firstFailure() == "" && checkAll(a.exitCode, a.exitCodeCheckers, nil)
+}
+
+// firstFailure returns the first failed in-flight assertion's message,
+// indented.
+func (a *ProcessAssert) firstFailure() string {
+	for i, failure := range a.failures {
+		if failure != nil {
+			msg := strings.TrimSpace(fmt.Sprint(failure))
+			return fmt.Sprintf("\n  In-flight plan %d failed:\n    %s", i+1, strings.ReplaceAll(msg, "\n", "\n    "))
+		}
+	}
+
+	return ""
+}
+
+func (a *ProcessAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("Process %s after %s", p.process, signalName(p.signal))
+
+	switch {
+	case a.survives > 0 && a.exited:
+		panic(fmt.Sprintf("%s\n  Expected: still running after %s\n  Actual: %s after %s%s",
+			title, a.survives, a.state, a.elapsed.Round(time.Millisecond), a.formatHelp()))
+	case a.survives == 0 && !a.exited:
+		wait := a.exitsWithin
+		if wait == 0 {
+			wait = p.config.ProcessShutdownTimeout
+		}
+		panic(fmt.Sprintf("%s\n  Expected: exit within %s\n  Actual: still running%s",
+			title, wait, a.formatHelp()))
+	}
+
+	if failure := a.firstFailure(); failure != "" {
+		panic(fmt.Sprintf("%s\n  Expected: in-flight plans to finish%s%s", title, failure, a.formatHelp()))
+	}
+
+	checkAll(a.exitCode, a.exitCodeCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected exit code: %s\n  Actual: %s%s",
+			title, m.Expected(), a.state, a.formatHelp())
+		panic(msg)
+	})
+}
+
+// signal sends sig to the process group without waiting for it to exit.
+func (do *Do) signal(name string, sig syscall.Signal) {
+	do.config.Recorder.record(Entry{Kind: EntrySignal, Process: name, Signal: int(sig)})
+
+	proc := do.getProcess(name)
+	if proc.cmd == nil || proc.hasExited() {
+		return
+	}
+
+	syscall.Kill(-proc.cmd.Process.Pid, sig)
+}
+
+// signalName returns the conventional name of sig, e.g. SIGTERM.
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGHUP:
+		return "SIGHUP"
+	case syscall.SIGQUIT:
+		return "SIGQUIT"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	case syscall.SIGUSR1:
+		return "SIGUSR1"
+	case syscall.SIGUSR2:
+		return "SIGUSR2"
+	}
+
+	return fmt.Sprintf("signal %d", int(sig))
+}
@@ -0,0 +1,206 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+var _ Assert = (*PropertyAssert[int])(nil)
+
+// defaultPropertyRuns is how many sequences a property plan tries by default.
+const defaultPropertyRuns = 100
+
+// defaultPropertyLen is the longest sequence a property plan generates by
+// default.
+const defaultPropertyLen = 20
+
+// maxShrinks bounds the sequences tried while shrinking a failure.
+const maxShrinks = 500
+
+// PropertyPlan checks that an invariant holds for random sequences of
+// operations, e.g. that for any sequence of SET and DEL, GET returns the
+// last value set. A failing sequence is shrunk to the fewest operations
+// that still fail.
+type PropertyPlan[T any] struct {
+	ctx    context.Context
+	config *Config
+
+	do     *Do
+	name   string
+	gen    func(r *rand.Rand) T
+	check  func(do *Do, ops []T)
+	reset  func(do *Do)
+	runs   int
+	maxLen int
+	seed   uint64
+}
+
+// Property creates a property plan. gen generates one operation, and check
+// applies a sequence to the implementation, usually alongside a model,
+// failing like a test does, e.g. with a failed assertion. Its seed comes
+// from the run's, so a failure is reproduced with the same seed.
+func Property[T any](do *Do, name string, gen func(r *rand.Rand) T, check func(do *Do, ops []T)) *PropertyPlan[T] {
+	return &PropertyPlan[T]{
+		ctx:    do.ctx,
+		config: do.config,
+		do:     do,
+		name:   name,
+		gen:    gen,
+		check:  check,
+		runs:   defaultPropertyRuns,
+		maxLen: defaultPropertyLen,
+		seed:   do.rand.Uint64(),
+	}
+}
+
+// Runs sets how many sequences to try, 100 by default.
+func (p *PropertyPlan[T]) Runs(n int) *PropertyPlan[T] {
+	if n < 1 {
+		panic("Runs() requires at least one run")
+	}
+
+	p.runs = n
+	return p
+}
+
+// MaxLen sets the longest sequence to generate, 20 by default.
+func (p *PropertyPlan[T]) MaxLen(n int) *PropertyPlan[T] {
+	if n < 1 {
+		panic("MaxLen() requires at least one operation")
+	}
+
+	p.maxLen = n
+	return p
+}
+
+// Seed sets the seed of the generator, e.g. the one a failure reported.
+func (p *PropertyPlan[T]) Seed(seed uint64) *PropertyPlan[T] {
+	p.seed = seed
+	return p
+}
+
+// Reset runs fn before every sequence, including those tried while
+// shrinking, e.g. to clear the implementation's state.
+func (p *PropertyPlan[T]) Reset(fn func(do *Do)) *PropertyPlan[T] {
+	p.reset = fn
+	return p
+}
+
+func (p *PropertyPlan[T]) T() *PropertyAssert[T] {
+	return &PropertyAssert[T]{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// PropertyAssert checks that a property holds for every sequence tried.
+type PropertyAssert[T any] struct {
+	AssertBase
+
+	plan *PropertyPlan[T]
+	// ops is the shrunk failing sequence, first generated on run with
+	// original operations.
+	ops      []T
+	run      int
+	original int
+	failure  string
+}
+
+func (a *PropertyAssert[T]) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer startDeadline(&p.ctx, p.config, 0, &a.AssertBase)()
+
+	a.execute()
+	a.verify(p.ctx, a.check)
+}
+
+func (a *PropertyAssert[T]) execute() bool {
+	p := a.plan
+	a.ops, a.run, a.original, a.failure = nil, 0, 0, ""
+
+	r := newRand(p.seed)
+	for i := range p.runs {
+		if p.ctx.Err() != nil {
+			return false
+		}
+
+		ops := make([]T, 1+r.IntN(p.maxLen))
+		for j := range ops {
+			ops[j] = p.gen(r)
+		}
+
+		if failure := p.try(ops); failure != "" {
+			a.run, a.original = i+1, len(ops)
+			a.ops, a.failure = p.shrink(ops, failure)
+			return false
+		}
+	}
+
+	return true
+}
+
+// try applies ops and returns how the check failed, or "" if it passed.
+func (p *PropertyPlan[T]) try(ops []T) (failure string) {
+	defer func() {
+		if err := recover(); err != nil {
+			failure = fmt.Sprint(err)
+		}
+	}()
+
+	// Assertions fail the sequence rather than a soft test
+	view := *p.do
+	view.ctx = context.WithValue(p.do.ctx, softKey{}, nil)
+
+	if p.reset != nil {
+		p.reset(&view)
+	}
+	p.check(&view, ops)
+	return ""
+}
+
+// shrink removes operations from a failing sequence, in chunks from half
+// of it down to one, while it still fails.
+func (p *PropertyPlan[T]) shrink(ops []T, failure string) ([]T, string) {
+	tries := 0
+	for chunk := len(ops) / 2; chunk >= 1 && tries < maxShrinks; {
+		shrunk := false
+		for i := 0; i+chunk <= len(ops) && tries < maxShrinks; {
+			candidate := append(append([]T(nil), ops[:i]...), ops[i+chunk:]...)
+			tries++
+
+			if f := p.try(candidate); f != "" {
+				ops, failure, shrunk = candidate, f, true
+				continue
+			}
+			i += chunk
+		}
+
+		if !shrunk {
+			chunk /= 2
+		}
+		chunk = min(chunk, len(ops)/2)
+	}
+
+	return ops, failure
+}
+
+func (a *PropertyAssert[T]) check() {
+	if a.failure == "" {
+		return
+	}
+
+	p := a.plan
+	var ops strings.Builder
+	for _, op := range a.ops {
+		fmt.Fprintf(&ops, "\n    %v", op)
+	}
+
+	msg := fmt.Sprintf("Property %s\n  Expected it to hold for %d runs\n  Run %d failed, shrunk from %d to %d operations:%s\n  Failure: %s\n  Seed: %d%s",
+		p.name, p.runs, a.run, a.original, len(a.ops), ops.String(),
+		strings.ReplaceAll(a.failure, "\n", "\n  "), p.seed, a.formatHelp())
+	panic(msg)
+}
@@ -0,0 +1,437 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var _ Plan[*PTYPlan, *PTYAssert] = (*PTYPlan)(nil)
+var _ Assert = (*PTYAssert)(nil)
+
+// PTYPlan represents a test plan that runs the command under a
+// pseudo-terminal and scripts an exchange with it, like expect: wait for
+// a prompt, send a line, wait for the reply. Each execution starts a new
+// session.
+type PTYPlan struct {
+	PlanBase
+
+	command string
+	args    []string
+	env     []string
+	// harnessEnv is the environment the harness adds, e.g. LC_CLOCK_FILE.
+	harnessEnv []string
+	steps      []ptyStep
+	// dir is where the command runs, the project directory if empty.
+	dir        string
+	workingDir string
+}
+
+// ptyStep is a single step of an interactive exchange, either a line to
+// send or a pattern to wait for.
+type ptyStep struct {
+	send    string
+	expect  *regexp.Regexp
+	timeout time.Duration
+}
+
+// Expect waits for output matching pattern, e.g. `\$ $` for a shell
+// prompt. Each Expect only sees output after the previous match, including
+// the echo of sent lines. The step fails after the optional timeout,
+// ExecuteTimeout by default.
+func (p *PTYPlan) Expect(pattern string, timeout ...time.Duration) *PTYPlan {
+	step := ptyStep{expect: regexp.MustCompile("(?m)" + pattern)}
+	if len(timeout) > 0 {
+		step.timeout = timeout[0]
+	}
+
+	p.steps = append(p.steps, step)
+	return p
+}
+
+// Send types line followed by Enter.
+func (p *PTYPlan) Send(line string) *PTYPlan {
+	p.steps = append(p.steps, ptyStep{send: line + "\n"})
+	return p
+}
+
+// SendEOF types Ctrl-D, which ends input at the start of a line.
+func (p *PTYPlan) SendEOF() *PTYPlan {
+	p.steps = append(p.steps, ptyStep{send: "\x04"})
+	return p
+}
+
+// WithEnv sets an environment variable for the command, e.g. TERM.
+func (p *PTYPlan) WithEnv(key, value string) *PTYPlan {
+	p.env = append(p.env, key+"="+value)
+	return p
+}
+
+// InDir runs the command in dir instead of the project directory. A
+// relative dir is inside the working directory, and is created if missing.
+func (p *PTYPlan) InDir(dir string) *PTYPlan {
+	p.dir = resolvePath(p.workingDir, dir)
+	return p
+}
+
+func (p *PTYPlan) Eventually() *PTYPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *PTYPlan) Within(timeout time.Duration) *PTYPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *PTYPlan) Retry(policy RetryPolicy) *PTYPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *PTYPlan) Consistently() *PTYPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *PTYPlan) For(timeout time.Duration) *PTYPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *PTYPlan) Every(interval time.Duration) *PTYPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *PTYPlan) Deadline(d time.Duration) *PTYPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *PTYPlan) T() *PTYAssert {
+	return &PTYAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// PTYAssert provides assertions on an interactive session. Every Expect
+// step must match.
+type PTYAssert struct {
+	AssertBase
+
+	plan *PTYPlan
+	// failedStep is the index of the step that timed out, or -1.
+	failedStep int
+	// pending is the output the failed step saw.
+	pending  string
+	output   string
+	exited   bool
+	exitCode int
+	err      error
+
+	exitCheckers   []Checker[int]
+	outputCheckers []Checker[string]
+}
+
+// ExitCode expects the command to exit once the steps are done, and adds
+// checkers for its exit code. Without it, the command is killed after the
+// last step. All checkers must pass.
+func (a *PTYAssert) ExitCode(checkers ...Checker[int]) *PTYAssert {
+	a.exitCheckers = append(a.exitCheckers, checkers...)
+	return a
+}
+
+// Output adds checkers for the whole transcript, including the echo of sent
+// lines, with line endings as \n. All checkers must pass.
+func (a *PTYAssert) Output(checkers ...Checker[string]) *PTYAssert {
+	a.outputCheckers = append(a.outputCheckers, checkers...)
+	return a
+}
+
+func (a *PTYAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *PTYAssert) execute() bool {
+	p := a.plan
+	a.failedStep, a.pending, a.output, a.exited, a.err = -1, "", "", false, nil
+
+	master, tty, err := openPTY()
+	if err != nil {
+		panic(fmt.Sprintf("Could not open a pseudo-terminal: %v", err))
+	}
+	defer master.Close()
+
+	command, dir := commandIn(p.command, a.config.Dir, p.dir)
+	cmd := exec.Command(command, p.args...)
+	cmd.Dir = dir
+	cmd.Env = a.config.environ(slices.Concat(p.harnessEnv, p.env))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+	err = cmd.Start()
+	tty.Close()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer func() {
+		if !a.exited {
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			<-exited
+		}
+	}()
+
+	session := newPTYSession(master)
+	a.err = a.runSteps(session)
+
+	if a.err == nil && len(a.exitCheckers) > 0 {
+		select {
+		case <-exited:
+			a.exited = true
+			a.exitCode = cmd.ProcessState.ExitCode()
+		case <-time.After(a.config.ExecuteTimeout):
+			a.err = fmt.Errorf("still running %s after the last step", a.config.ExecuteTimeout)
+		case <-p.ctx.Done():
+			a.err = fmt.Errorf("%s was cancelled", p.command)
+		}
+	}
+
+	// The output after an exit only arrives once the terminal is drained
+	if a.exited {
+		session.wait(func(string) bool { return false }, time.Second, nil)
+	}
+	a.output = session.transcript()
+
+	return a.err == nil &&
+		checkAll(a.exitCode, a.exitCheckers, nil) &&
+		checkAll(a.output, a.outputCheckers, nil)
+}
+
+// runSteps sends and expects each step in turn, stopping at the first step
+// that times out.
+func (a *PTYAssert) runSteps(session *ptySession) error {
+	p := a.plan
+	for i, step := range p.steps {
+		if step.expect == nil {
+			_, err := session.master.Write([]byte(step.send))
+			if err != nil {
+				a.failedStep = i
+				return err
+			}
+			continue
+		}
+
+		timeout := step.timeout
+		if timeout == 0 {
+			timeout = a.config.ExecuteTimeout
+		}
+
+		matched := session.wait(func(pending string) bool {
+			loc := step.expect.FindStringIndex(pending)
+			if loc == nil {
+				return false
+			}
+			session.consumed += loc[1]
+			return true
+		}, a.config.scaled(timeout), p.ctx.Done())
+
+		if !matched {
+			a.failedStep = i
+			a.pending = session.pending()
+			if p.ctx.Err() != nil {
+				return fmt.Errorf("%s was cancelled", p.command)
+			}
+			if session.closed() {
+				return errors.New("the command exited")
+			}
+			return fmt.Errorf("no match within %s", timeout)
+		}
+	}
+
+	return nil
+}
+
+// describe returns a step as shown in a failure.
+func (s ptyStep) describe() string {
+	if s.expect == nil {
+		return fmt.Sprintf("send %q", s.send)
+	}
+
+	return fmt.Sprintf("expect %q", strings.TrimPrefix(s.expect.String(), "(?m)"))
+}
+
+func (a *PTYAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("%s %s (interactive)", p.command, strings.Join(p.args, " "))
+
+	if a.err != nil {
+		if a.failedStep >= 0 {
+			msg := fmt.Sprintf("%s\n  Step %d: %s\n  Actual: %s\n  Output since the last match:%s%s",
+				title, a.failedStep+1, p.steps[a.failedStep].describe(), a.err,
+				formatText(a.pending), a.formatHelp())
+			panic(msg)
+		}
+
+		msg := fmt.Sprintf("%s\n  Expected the command to exit\n  Actual: %s\n  Output:%s%s",
+			title, a.err, formatText(a.output), a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.exitCode, a.exitCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected exit code: %s\n  Actual exit code: %d%s%s",
+			title, m.Expected(), actual, explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.output, a.outputCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected output: %s\n  Actual output:%s%s%s",
+			title, m.Expected(), formatText(actual), explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+}
+
+// ptySession collects what the command writes to the terminal.
+type ptySession struct {
+	master *os.File
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  strings.Builder
+	eof  bool
+	// consumed is how much of the output earlier Expect steps matched.
+	consumed int
+}
+
+// newPTYSession starts reading from master until the terminal closes.
+func newPTYSession(master *os.File) *ptySession {
+	s := &ptySession{master: master}
+	s.cond = sync.NewCond(&s.mu)
+
+	go func() {
+		data := make([]byte, 4096)
+		for {
+			n, err := master.Read(data)
+
+			s.mu.Lock()
+			s.buf.WriteString(strings.ReplaceAll(string(data[:n]), "\r\n", "\n"))
+			s.eof = err != nil
+			s.cond.Broadcast()
+			s.mu.Unlock()
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// wait calls match with the output after the last match whenever more
+// arrives, until it returns true, the terminal closes, timeout passes or
+// done is closed. It reports whether match returned true.
+func (s *ptySession) wait(match func(pending string) bool, timeout time.Duration, done <-chan struct{}) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cond.Broadcast()
+	})
+	defer stop()
+
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if match(s.buf.String()[s.consumed:]) {
+			return true
+		}
+		if s.eof || ctx.Err() != nil {
+			return false
+		}
+		s.cond.Wait()
+	}
+}
+
+// pending returns the output after the last match.
+func (s *ptySession) pending() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()[s.consumed:]
+}
+
+// transcript returns all output so far.
+func (s *ptySession) transcript() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// closed reports whether the terminal closed, e.g. because the command
+// exited.
+func (s *ptySession) closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eof
+}
+
+// ioctl performs a terminal ioctl on f without switching it to blocking
+// mode, as f.Fd() would.
+func ioctl(f *os.File, req, arg uintptr) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg)
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package attest
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY opens a pseudo-terminal and returns its master and slave ends.
+func openPTY() (master, tty *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := make([]byte, 128)
+	err = ioctl(master, syscall.TIOCPTYGRANT, 0)
+	if err == nil {
+		err = ioctl(master, syscall.TIOCPTYUNLK, 0)
+	}
+	if err == nil {
+		err = ioctl(master, syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0])))
+	}
+	if err == nil {
+		name, _, _ = bytes.Cut(name, []byte{0})
+		tty, err = os.OpenFile(string(name), os.O_RDWR|syscall.O_NOCTTY, 0)
+	}
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	return master, tty, nil
+}
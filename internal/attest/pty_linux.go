@@ -0,0 +1,32 @@
+package attest
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY opens a pseudo-terminal and returns its master and slave ends.
+func openPTY() (master, tty *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock int32
+	var n uint32
+	err = ioctl(master, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock)))
+	if err == nil {
+		err = ioctl(master, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n)))
+	}
+	if err == nil {
+		tty, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	}
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	return master, tty, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package attest
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY reports that pseudo-terminals are not supported.
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminals are only supported on Linux and macOS")
+}
@@ -0,0 +1,48 @@
+package attest
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// lockedSource is a rand.Source that is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.src.Uint64()
+}
+
+// newRand creates a random generator for the given seed.
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewPCG(seed, seed)})
+}
+
+// Rand returns the run's seeded random generator.
+// Use it for randomized keys, payloads, and orderings so failures can be
+// reproduced with the same seed. It is safe for concurrent use.
+func (do *Do) Rand() *rand.Rand {
+	return do.rand
+}
+
+// Seed returns the seed of the run's random generator.
+func (do *Do) Seed() uint64 {
+	return do.seed
+}
+
+const randAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandString returns a seeded random alphanumeric string of length n.
+func (do *Do) RandString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randAlphabet[do.rand.IntN(len(randAlphabet))]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,217 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Kinds of recorded entries.
+const (
+	EntryTest    = "test"
+	EntryStart   = "start"
+	EntryStop    = "stop"
+	EntryKill    = "kill"
+	EntryRestart = "restart"
+	EntrySignal  = "signal"
+	EntryHTTP    = "http"
+	EntryExec    = "exec"
+	EntryTCP     = "tcp"
+	EntryUDP     = "udp"
+)
+
+// replayIdleTimeout ends a replayed exchange whose response has no known end.
+const replayIdleTimeout = 500 * time.Millisecond
+
+// Entry is a single action the harness took during a recorded run.
+type Entry struct {
+	// Offset is the time since the recording started.
+	Offset time.Duration `json:"offset"`
+	Kind   string        `json:"kind"`
+
+	Test    string   `json:"test,omitempty"`
+	Process string   `json:"process,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Signal  int      `json:"signal,omitempty"`
+
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Headers H      `json:"headers,omitempty"`
+	Body    string `json:"body,omitempty"`
+	// BodyFile is the fixture sent as the body instead of Body.
+	BodyFile string `json:"bodyFile,omitempty"`
+}
+
+// Recorder captures the traffic the harness sends during a run.
+// A nil Recorder records nothing. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	start   time.Time
+	entries []Entry
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// record appends an entry stamped with its offset.
+func (r *Recorder) record(entry Entry) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.Offset = time.Since(r.start)
+	r.entries = append(r.entries, entry)
+}
+
+// Entries returns the recorded entries in order.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Entry(nil), r.entries...)
+}
+
+// ReplayResult is the outcome of replaying one entry.
+type ReplayResult struct {
+	// Status is the HTTP status or exit code of the replayed request or command.
+	Status   int
+	Output   string
+	Duration time.Duration
+	Err      error
+}
+
+// ReplayEntries performs recorded actions against fresh instances of the
+// program without checking any expectations. With realtime set, the original
+// spacing between entries is kept. Each result is passed to report.
+func ReplayEntries(ctx context.Context, config *Config, entries []Entry, realtime bool, report func(Entry, ReplayResult)) {
+	merged := New().WithConfig(config).config
+	merged.Recorder = nil
+
+	do := newDo(ctx, merged)
+	defer do.Done()
+
+	start := time.Now()
+	for _, entry := range entries {
+		if realtime {
+			select {
+			case <-ctx.Done():
+			case <-time.After(entry.Offset - time.Since(start)):
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		began := time.Now()
+		result := do.replay(entry)
+		result.Duration = time.Since(began)
+		report(entry, result)
+	}
+}
+
+// replay performs a single recorded action, turning failures into errors.
+func (do *Do) replay(entry Entry) (result ReplayResult) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			result.Err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	switch entry.Kind {
+	case EntryStart:
+		do.StartWithEnv(entry.Process, entry.Env, entry.Args...)
+	case EntryStop:
+		do.Stop(entry.Process)
+	case EntryKill:
+		do.Kill(entry.Process)
+	case EntryRestart:
+		var sig []syscall.Signal
+		if entry.Signal != 0 {
+			sig = append(sig, syscall.Signal(entry.Signal))
+		}
+		do.Restart(entry.Process, sig...)
+	case EntrySignal:
+		do.signal(entry.Process, syscall.Signal(entry.Signal))
+	case EntryHTTP:
+		proc := do.getProcess(entry.Process)
+		f := &HTTPFailure{Method: entry.Method, Path: entry.Path, Headers: entry.Headers, Body: entry.Body}
+		if entry.BodyFile != "" {
+			f.Body = do.config.readFixture(entry.BodyFile)
+		}
+		result.Status, result.Output, result.Err = f.Send(do.ctx, fmt.Sprintf("http://127.0.0.1:%d", proc.realPort), do.config.ExecuteTimeout)
+	case EntryTCP:
+		result.Output, result.Err = replayTCP(do.addr(entry.Process), entry.Body, do.config.ExecuteTimeout)
+	case EntryUDP:
+		result.Output, _, result.Err = exchangeDatagram(do.ctx, do.addr(entry.Process), []byte(entry.Body), defaultDatagramTimeout)
+	case EntryExec:
+		ctx, cancel := context.WithTimeout(do.ctx, do.config.ExecuteTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, do.config.Command, entry.Args...)
+		cmd.Dir = do.config.Dir
+		cmd.Env = do.config.environ(entry.Env)
+		cmd.Stdin = strings.NewReader(entry.Body)
+		output, err := cmd.CombinedOutput()
+		result.Output = string(output)
+		if cmd.ProcessState != nil {
+			result.Status = cmd.ProcessState.ExitCode()
+		}
+		if _, ok := err.(*exec.ExitError); !ok {
+			result.Err = err
+		}
+	}
+
+	return result
+}
+
+// replayTCP writes data to a new connection and returns what arrives until
+// the server closes it or stays quiet for replayIdleTimeout.
+func replayTCP(addr, data string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(data))
+	if err != nil {
+		return "", err
+	}
+
+	var received []byte
+	buf := make([]byte, 4096)
+	for {
+		conn.SetReadDeadline(time.Now().Add(replayIdleTimeout))
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		if err != nil {
+			return string(received), nil
+		}
+	}
+}
+
+// record captures the request the plan is about to send.
+func (p *HTTPPlan) record() {
+	p.config.Recorder.record(Entry{
+		Kind:     EntryHTTP,
+		Process:  p.process,
+		Method:   p.method,
+		Path:     p.path,
+		Headers:  p.headers,
+		Body:     string(p.body),
+		BodyFile: p.bodyFile,
+	})
+}
@@ -0,0 +1,128 @@
+package attest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxRedirects is how many redirects a plan follows by default, as
+// many as net/http does.
+const defaultMaxRedirects = 10
+
+// redirectHop is a redirect the client followed.
+type redirectHop struct {
+	status   int
+	location string
+}
+
+// NoRedirects makes the plan stop at the first response, so a redirect can
+// be checked with Status and Header("Location").
+func (p *HTTPPlan) NoRedirects() *HTTPPlan {
+	p.maxRedirects = -1
+	return p
+}
+
+// MaxRedirects sets how many redirects the plan follows, 10 by default.
+// A longer chain fails the assertion.
+func (p *HTTPPlan) MaxRedirects(n int) *HTTPPlan {
+	p.maxRedirects = n
+	return p
+}
+
+// Redirects adds checkers for the number of redirects followed.
+// All checkers must pass.
+func (a *HTTPAssert) Redirects(checkers ...Checker[int]) *HTTPAssert {
+	a.redirectsCheckers = append(a.redirectsCheckers, checkers...)
+	return a
+}
+
+// RedirectChain adds checkers for the redirects followed, each as its
+// status and Location joined by " -> ", e.g. Is("301 /new -> 302 /login").
+// All checkers must pass.
+func (a *HTTPAssert) RedirectChain(checkers ...Checker[string]) *HTTPAssert {
+	a.chainCheckers = append(a.chainCheckers, checkers...)
+	return a
+}
+
+// followRedirects returns client following redirects as the plan says,
+// recording each hop and stopping at a loop.
+func (a *HTTPAssert) followRedirects(client *http.Client) *http.Client {
+	p := a.plan
+	limit := p.maxRedirects
+	if limit == 0 {
+		limit = defaultMaxRedirects
+	}
+
+	following := *client
+	following.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if limit < 0 {
+			return http.ErrUseLastResponse
+		}
+
+		a.redirects = append(a.redirects, redirectHop{
+			status:   req.Response.StatusCode,
+			location: req.Response.Header.Get("Location"),
+		})
+
+		for _, earlier := range via {
+			if earlier.URL.String() == req.URL.String() {
+				a.redirectErr = fmt.Errorf("redirect loop back to %s", req.URL.RequestURI())
+				return http.ErrUseLastResponse
+			}
+		}
+
+		if len(via) > limit {
+			a.redirectErr = fmt.Errorf("more than %d redirects", limit)
+			return http.ErrUseLastResponse
+		}
+
+		return nil
+	}
+
+	return &following
+}
+
+// formatChain formats the redirects followed as "301 /new -> 302 /login".
+func (a *HTTPAssert) formatChain() string {
+	hops := make([]string, len(a.redirects))
+	for i, hop := range a.redirects {
+		hops[i] = fmt.Sprintf("%d %s", hop.status, hop.location)
+	}
+
+	return strings.Join(hops, " -> ")
+}
+
+// redirectsPass reports whether the redirects meet every expectation.
+func (a *HTTPAssert) redirectsPass() bool {
+	return a.redirectErr == nil &&
+		checkAll(len(a.redirects), a.redirectsCheckers, nil) &&
+		checkAll(a.formatChain(), a.chainCheckers, nil)
+}
+
+// checkRedirects panics if the redirects were not as expected.
+func (a *HTTPAssert) checkRedirects() {
+	p := a.plan
+	chain := a.formatChain()
+	if chain == "" {
+		chain = "none"
+	}
+
+	if a.redirectErr != nil {
+		msg := fmt.Sprintf("%s %s\n  Expected the redirects to end\n  Actual: %v\n  Chain: %s%s",
+			p.method, p.url, a.redirectErr, chain, a.formatHelp())
+		panic(a.failure(msg, "redirect", "an end to the redirects", ""))
+	}
+
+	checkAll(len(a.redirects), a.redirectsCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s %s\n  Expected redirects: %s\n  Actual redirects: %d\n  Chain: %s%s",
+			p.method, p.url, m.Expected(), actual, chain, a.formatHelp())
+		panic(a.failure(msg, "redirect", m.Expected(), ""))
+	})
+
+	checkAll(a.formatChain(), a.chainCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s %s\n  Expected redirect chain: %s\n  Actual redirect chain: %q%s%s",
+			p.method, p.url, m.Expected(), actual, explain(m, actual), a.formatHelp())
+		panic(a.failure(msg, "redirect", m.Expected(), ""))
+	})
+}
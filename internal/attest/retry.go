@@ -0,0 +1,61 @@
+package attest
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how often Eventually retries. The zero value polls
+// every Config.RetryPollInterval until the timeout.
+type RetryPolicy struct {
+	// Interval is the wait before the first attempt.
+	// Zero uses Config.RetryPollInterval.
+	Interval time.Duration
+	// Multiplier grows the wait after each attempt, e.g. 2 for exponential
+	// backoff. Values at or below 1 keep the interval fixed.
+	Multiplier float64
+	// MaxInterval caps the wait between attempts. Zero leaves it uncapped.
+	MaxInterval time.Duration
+	// Jitter randomizes each wait by up to this fraction of it, e.g. 0.2
+	// for ±20%, so retries from concurrent plans spread out.
+	Jitter float64
+	// MaxAttempts gives up after this many attempts, even if the timeout
+	// has not passed. Zero retries until the timeout.
+	MaxAttempts int
+}
+
+// delay returns the wait before the given attempt, counting from zero.
+func (r RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(r.Interval)
+	if r.Multiplier > 1 {
+		d *= math.Pow(r.Multiplier, float64(attempt))
+	}
+	if r.MaxInterval > 0 {
+		d = math.Min(d, float64(r.MaxInterval))
+	}
+	if r.Jitter > 0 {
+		d *= 1 + r.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(d)
+}
+
+// setRetry sets the plan's retry policy for Eventually.
+func (b *PlanBase) setRetry(policy RetryPolicy) {
+	b.retry = &policy
+}
+
+// retryPolicy returns the plan's retry policy, or the configured one, with
+// the interval defaulted.
+func (b *PlanBase) retryPolicy() RetryPolicy {
+	policy := b.config.RetryPolicy
+	if b.retry != nil {
+		policy = *b.retry
+	}
+	if policy.Interval == 0 {
+		policy.Interval = b.config.RetryPollInterval
+	}
+
+	return policy
+}
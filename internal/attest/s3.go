@@ -0,0 +1,402 @@
+package attest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var _ Plan[*S3MultipartPlan, *S3MultipartAssert] = (*S3MultipartPlan)(nil)
+var _ Assert = (*S3MultipartAssert)(nil)
+
+// sigV4 signs requests with AWS Signature Version 4 for the s3 service.
+type sigV4 struct {
+	accessKey string
+	secretKey string
+	region    string
+}
+
+// sign adds the x-amz-date, x-amz-content-sha256 and Authorization headers.
+// Every header already on the request is signed, along with host.
+func (s *sigV4) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.Join(strings.Fields(strings.Join(values, ",")), " ")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes the query sorted by key and value, with spaces as
+// %20 rather than +.
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	slices.Sort(pairs)
+
+	return strings.ReplaceAll(strings.Join(pairs, "&"), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// ETagOf returns the ETag S3 gives an object uploaded in one request.
+func ETagOf(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// MultipartETag returns the ETag S3 gives an object uploaded in parts: the
+// MD5 of the concatenated part MD5s, followed by the part count.
+func MultipartETag(parts ...string) string {
+	var sums []byte
+	for _, part := range parts {
+		sum := md5.Sum([]byte(part))
+		sums = append(sums, sum[:]...)
+	}
+
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(parts))
+}
+
+// unquoteETag strips the quotes S3 puts around ETags.
+func unquoteETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// SignV4 signs the request with AWS Signature Version 4 for the s3
+// service, using the given credentials and region.
+func (p *HTTPPlan) SignV4(accessKey, secretKey, region string) *HTTPPlan {
+	p.signer = &sigV4{accessKey: accessKey, secretKey: secretKey, region: region}
+	return p
+}
+
+// ETag adds checkers for the ETag response header, without its quotes.
+// All checkers must pass.
+func (a *HTTPAssert) ETag(checkers ...Checker[string]) *HTTPAssert {
+	a.etagCheckers = append(a.etagCheckers, checkers...)
+	return a
+}
+
+// Objects expects the body to be a ListObjects or ListObjectsV2 result
+// listing exactly these keys, in order. With no keys it expects an empty
+// listing.
+func (a *HTTPAssert) Objects(keys ...string) *HTTPAssert {
+	a.objects = append([]string{}, keys...)
+	return a
+}
+
+// listBucketResult is the body of a ListObjects or ListObjectsV2 response.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+// listObjects returns the keys in a ListObjects response body.
+func listObjects(body string) ([]string, error) {
+	var result listBucketResult
+	err := xml.Unmarshal([]byte(body), &result)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(result.Contents))
+	for _, object := range result.Contents {
+		keys = append(keys, object.Key)
+	}
+
+	return keys, nil
+}
+
+// S3MultipartPlan represents a test plan that uploads an object in parts:
+// it initiates a multipart upload, uploads each part, then completes it.
+type S3MultipartPlan struct {
+	PlanBase
+
+	process string
+	baseURL string
+	path    string
+	parts   []string
+	signer  *sigV4
+}
+
+// Part adds a part to upload. Parts are numbered from 1 in the order added.
+func (p *S3MultipartPlan) Part(data string) *S3MultipartPlan {
+	p.parts = append(p.parts, data)
+	return p
+}
+
+// SignV4 signs every request with AWS Signature Version 4.
+func (p *S3MultipartPlan) SignV4(accessKey, secretKey, region string) *S3MultipartPlan {
+	p.signer = &sigV4{accessKey: accessKey, secretKey: secretKey, region: region}
+	return p
+}
+
+func (p *S3MultipartPlan) Eventually() *S3MultipartPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *S3MultipartPlan) Within(timeout time.Duration) *S3MultipartPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *S3MultipartPlan) Retry(policy RetryPolicy) *S3MultipartPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *S3MultipartPlan) Consistently() *S3MultipartPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *S3MultipartPlan) For(timeout time.Duration) *S3MultipartPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *S3MultipartPlan) Every(interval time.Duration) *S3MultipartPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *S3MultipartPlan) Deadline(d time.Duration) *S3MultipartPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *S3MultipartPlan) T() *S3MultipartAssert {
+	return &S3MultipartAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// S3MultipartAssert provides assertions on a completed multipart upload.
+type S3MultipartAssert struct {
+	AssertBase
+
+	plan   *S3MultipartPlan
+	etag   string
+	object string
+	err    error
+
+	etagCheckers   []Checker[string]
+	objectCheckers []Checker[string]
+}
+
+// ETag adds checkers for the ETag returned when the upload completes,
+// without its quotes. All checkers must pass.
+func (a *S3MultipartAssert) ETag(checkers ...Checker[string]) *S3MultipartAssert {
+	a.etagCheckers = append(a.etagCheckers, checkers...)
+	return a
+}
+
+// Object reads the object back after the upload completes and adds
+// checkers for its content. All checkers must pass.
+func (a *S3MultipartAssert) Object(checkers ...Checker[string]) *S3MultipartAssert {
+	a.objectCheckers = append(a.objectCheckers, checkers...)
+	return a
+}
+
+func (a *S3MultipartAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *S3MultipartAssert) execute() bool {
+	a.etag, a.object, a.err = "", "", nil
+
+	a.err = a.upload()
+	if a.err != nil {
+		return false
+	}
+
+	return checkAll(a.etag, a.etagCheckers, nil) &&
+		checkAll(a.object, a.objectCheckers, nil)
+}
+
+// upload runs the multipart upload, then reads the object back if needed.
+func (a *S3MultipartAssert) upload() error {
+	p := a.plan
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	_, body, err := a.send("POST", p.path+"?uploads", "")
+	if err != nil {
+		return fmt.Errorf("initiate: %w", err)
+	}
+	err = xml.Unmarshal([]byte(body), &initiated)
+	if err != nil || initiated.UploadID == "" {
+		return fmt.Errorf("initiate: no UploadId in %q", body)
+	}
+	uploadID := url.QueryEscape(initiated.UploadID)
+
+	var complete strings.Builder
+	complete.WriteString("<CompleteMultipartUpload>")
+	for i, part := range p.parts {
+		header, _, err := a.send("PUT", fmt.Sprintf("%s?partNumber=%d&uploadId=%s", p.path, i+1, uploadID), part)
+		if err != nil {
+			return fmt.Errorf("upload part %d: %w", i+1, err)
+		}
+		fmt.Fprintf(&complete, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", i+1, header.Get("ETag"))
+	}
+	complete.WriteString("</CompleteMultipartUpload>")
+
+	var completed struct {
+		ETag string `xml:"ETag"`
+	}
+	_, body, err = a.send("POST", p.path+"?uploadId="+uploadID, complete.String())
+	if err != nil {
+		return fmt.Errorf("complete: %w", err)
+	}
+	err = xml.Unmarshal([]byte(body), &completed)
+	if err != nil {
+		return fmt.Errorf("complete: malformed response %q", body)
+	}
+	a.etag = unquoteETag(completed.ETag)
+
+	if len(a.objectCheckers) > 0 {
+		_, a.object, err = a.send("GET", p.path, "")
+		if err != nil {
+			return fmt.Errorf("get object: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// send makes one request and fails on a non-2xx status.
+func (a *S3MultipartAssert) send(method, path, body string) (http.Header, string, error) {
+	p := a.plan
+	p.config.Recorder.record(Entry{Kind: EntryHTTP, Process: p.process, Method: method, Path: path, Body: body})
+
+	req, err := http.NewRequestWithContext(p.ctx, method, p.baseURL+path, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, "", err
+	}
+	if p.signer != nil {
+		p.signer.sign(req, []byte(body), time.Now())
+	}
+
+	client := &http.Client{Timeout: a.config.ExecuteTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return resp.Header, string(respBody), nil
+}
+
+func (a *S3MultipartAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("S3 multipart upload %s (%d parts)", p.path, len(p.parts))
+
+	if a.err != nil {
+		panic(fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp()))
+	}
+
+	checkAll(a.etag, a.etagCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected ETag: %s\n  Actual ETag: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.object, a.objectCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n  Expected object: %s\n  Actual object: %q%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
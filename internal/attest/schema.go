@@ -0,0 +1,115 @@
+package attest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"github.com/tidwall/gjson"
+)
+
+// schemaChecker validates a JSON document against a JSON Schema.
+type schemaChecker struct {
+	schema *jsonschema.Schema
+	title  string
+}
+
+// MatchesSchema creates a checker that validates a JSON document against a
+// JSON Schema, e.g. one embedded with the stage. Failures list each
+// violation with its path. It panics if the schema is invalid.
+func MatchesSchema(schema string) schemaChecker {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		panic(fmt.Sprintf("invalid JSON schema: %v", err))
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", doc); err != nil {
+		panic(fmt.Sprintf("invalid JSON schema: %v", err))
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		panic(fmt.Sprintf("invalid JSON schema: %v", err))
+	}
+
+	return schemaChecker{schema: compiled, title: gjson.Get(schema, "title").String()}
+}
+
+func (m schemaChecker) Check(actual string) bool {
+	return len(m.violations(actual)) == 0
+}
+
+func (m schemaChecker) Expected() string {
+	if m.title != "" {
+		return fmt.Sprintf("matching JSON schema %q", m.title)
+	}
+
+	return "matching JSON schema"
+}
+
+func (m schemaChecker) explain(actual string) string {
+	violations := m.violations(actual)
+	if len(violations) == 0 {
+		return ""
+	}
+
+	return "Violations:\n    " + strings.Join(violations, "\n    ")
+}
+
+// violations returns each way actual breaks the schema, prefixed with the
+// JSONPath of the offending value.
+func (m schemaChecker) violations(actual string) []string {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(actual))
+	if err != nil {
+		return []string{fmt.Sprintf("$: invalid JSON: %v", err)}
+	}
+
+	err = m.schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{fmt.Sprintf("$: %v", err)}
+	}
+
+	var violations []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		if _, ok := unit.Error.Kind.(*kind.Group); ok {
+			continue
+		}
+
+		violations = append(violations, fmt.Sprintf("%s: %s", pointerPath(unit.InstanceLocation), unit.Error))
+	}
+
+	return violations
+}
+
+// pointerPath converts a JSON pointer such as /items/0/id to a JSONPath
+// such as $.items[0].id.
+func pointerPath(pointer string) string {
+	if pointer == "" {
+		return "$"
+	}
+
+	var b strings.Builder
+	b.WriteString("$")
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch {
+		case token != "" && strings.Trim(token, "0123456789") == "":
+			fmt.Fprintf(&b, "[%s]", token)
+		case strings.ContainsAny(token, ".[]' "):
+			fmt.Fprintf(&b, "['%s']", token)
+		default:
+			b.WriteString("." + token)
+		}
+	}
+
+	return b.String()
+}
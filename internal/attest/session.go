@@ -0,0 +1,36 @@
+package attest
+
+import (
+	"net/http"
+)
+
+// Session runs a sequence of HTTP requests to a process as one client:
+// they share connections and cookies, and values captured from earlier
+// responses fill in later requests, e.g. an id from a POST in the path of a
+// GET and a DELETE. Its variables fall back to the test's.
+type Session struct {
+	do      *Do
+	process string
+	client  *http.Client
+	vars    *variables
+}
+
+// HTTP creates a test plan for a request in the session. References to
+// variables such as {{id}} in the path, body and header values are
+// replaced by their values when the plan is created.
+func (s *Session) HTTP(method, path string, args ...any) *HTTPPlan {
+	p := s.do.http(s.vars, s.process, method, path, args...)
+	p.session = s
+	return p
+}
+
+// Set sets a variable, e.g. a username the later requests refer to.
+func (s *Session) Set(name, value string) {
+	s.vars.set(name, value)
+}
+
+// Var returns the value of a variable, e.g. to check a later response
+// against a captured id. It panics if the variable is not set.
+func (s *Session) Var(name string) string {
+	return s.vars.value(name)
+}
@@ -0,0 +1,96 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// softKey is the context key of a soft test's collected failures.
+type softKey struct{}
+
+// softFailures collects the failed assertions of a soft test.
+type softFailures struct {
+	mu       sync.Mutex
+	failures []any
+}
+
+func (s *softFailures) add(failure any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure)
+}
+
+func (s *softFailures) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.failures) > 0
+}
+
+// firstHTTP returns the first failed HTTP assertion, if any.
+func (s *softFailures) firstHTTP() *HTTPFailure {
+	for _, failure := range s.failures {
+		if f, ok := failure.(*HTTPFailure); ok {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// String lists every failure, numbered in the order they happened.
+func (s *softFailures) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	noun := "assertions"
+	if len(s.failures) == 1 {
+		noun = "assertion"
+	}
+
+	parts := []string{fmt.Sprintf("%d %s failed", len(s.failures), noun)}
+	for i, failure := range s.failures {
+		parts = append(parts, fmt.Sprintf("[%d/%d] %s", i+1, len(s.failures), strings.TrimSpace(fmt.Sprint(failure))))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// verify runs check, collecting its failure instead of panicking when the
+// assertion belongs to a soft test.
+func (a *AssertBase) verify(ctx context.Context, check func()) {
+	soft, ok := ctx.Value(softKey{}).(*softFailures)
+	if !ok || a.strict {
+		check()
+		return
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			soft.add(err)
+		}
+	}()
+
+	check()
+}
+
+func (a *AssertBase) base() *AssertBase {
+	return a
+}
+
+// runSoft runs a soft test with a view of do whose plans collect their
+// failures, then fails with all of them.
+func runSoft(do *Do, fn func(*Do)) {
+	soft := &softFailures{}
+
+	view := *do
+	view.ctx = context.WithValue(do.ctx, softKey{}, soft)
+	fn(&view)
+
+	if soft.failed() {
+		panic(soft)
+	}
+}
@@ -0,0 +1,197 @@
+package attest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// sseEvent is an event read from a text/event-stream response.
+type sseEvent struct {
+	name string
+	data string
+	id   string
+}
+
+func (e sseEvent) String() string {
+	s := fmt.Sprintf("%s %q", e.name, e.data)
+	if e.id != "" {
+		s += fmt.Sprintf(" (id %s)", e.id)
+	}
+
+	return s
+}
+
+// sseExpectation is an expected event, in order.
+type sseExpectation struct {
+	name         string
+	dataCheckers []Checker[string]
+	idCheckers   []Checker[string]
+}
+
+// SSE reads the response as a Server-Sent Events stream. The connection
+// stays open while the events expected with Event are read, then closes.
+func (p *HTTPPlan) SSE() *HTTPPlan {
+	p.sse = true
+	return p
+}
+
+// EventTimeout sets how long to wait for each expected event.
+// The default is Config.ExecuteTimeout.
+func (p *HTTPPlan) EventTimeout(d time.Duration) *HTTPPlan {
+	p.eventWait = d
+	return p
+}
+
+// Event expects the next event of an SSE stream to have the given name and
+// data passing all checkers. Unnamed events are named "message".
+func (a *HTTPAssert) Event(name string, checkers ...Checker[string]) *HTTPAssert {
+	a.events = append(a.events, sseExpectation{name: name, dataCheckers: checkers})
+	return a
+}
+
+// EventID adds checkers for the id of the event added last with Event.
+// The id carries over from earlier events when the server does not set one.
+func (a *HTTPAssert) EventID(checkers ...Checker[string]) *HTTPAssert {
+	if len(a.events) == 0 {
+		panic("EventID() can only be called after Event()")
+	}
+
+	last := &a.events[len(a.events)-1]
+	last.idCheckers = append(last.idCheckers, checkers...)
+	return a
+}
+
+// readEvents reads the expected events from an SSE stream and returns the
+// raw stream read. It stops at the first event that does not match.
+func (a *HTTPAssert) readEvents(body io.Reader) string {
+	p := a.plan
+
+	wait := p.eventWait
+	if wait == 0 {
+		wait = a.config.ExecuteTimeout
+	}
+
+	type result struct {
+		event sseEvent
+		raw   string
+	}
+	results := make(chan result)
+	done := make(chan struct{})
+	defer close(done)
+
+	var streamErr error
+	go func() {
+		defer close(results)
+
+		var raw strings.Builder
+		var event sseEvent
+		var data []string
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			raw.WriteString(line + "\n")
+
+			if line != "" {
+				field, value, _ := strings.Cut(line, ":")
+				value = strings.TrimPrefix(value, " ")
+				switch field {
+				case "event":
+					event.name = value
+				case "data":
+					data = append(data, value)
+				case "id":
+					event.id = value
+				}
+				continue
+			}
+
+			if data == nil {
+				event.name = ""
+				continue
+			}
+
+			if event.name == "" {
+				event.name = "message"
+			}
+			event.data = strings.Join(data, "\n")
+
+			select {
+			case results <- result{event: event, raw: raw.String()}:
+			case <-done:
+				return
+			}
+
+			event.name, data = "", nil
+		}
+
+		streamErr = scanner.Err()
+	}()
+
+	var raw string
+	for i, want := range a.events {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				a.eventFailed, a.eventErr = i, fmt.Errorf("stream ended")
+				if streamErr != nil {
+					a.eventErr = fmt.Errorf("stream ended: %w", streamErr)
+				}
+				return raw
+			}
+
+			raw = r.raw
+			a.received = append(a.received, r.event)
+			if r.event.name != want.name ||
+				!checkAll(r.event.data, want.dataCheckers, nil) ||
+				!checkAll(r.event.id, want.idCheckers, nil) {
+				a.eventFailed = i
+				return raw
+			}
+		case <-time.After(wait):
+			a.eventFailed, a.eventErr = i, fmt.Errorf("no event within %s", wait)
+			return raw
+		}
+	}
+
+	return raw
+}
+
+// checkEvents panics if an expected event was not received.
+func (a *HTTPAssert) checkEvents() {
+	if a.eventFailed < 0 {
+		return
+	}
+
+	p := a.plan
+	title := fmt.Sprintf("%s %s (SSE)", p.method, p.url)
+	want := a.events[a.eventFailed]
+	n := a.eventFailed + 1
+
+	if a.eventFailed >= len(a.received) {
+		msg := fmt.Sprintf("%s\n  Expected event %d: %s\n  Error: %v%s",
+			title, n, want.name, a.eventErr, a.formatHelp())
+		panic(a.failure(msg, "event", want.name, ""))
+	}
+
+	actual := a.received[a.eventFailed]
+	if actual.name != want.name {
+		msg := fmt.Sprintf("%s\n  Expected event %d: %s\n  Actual event %d: %s%s",
+			title, n, want.name, n, actual, a.formatHelp())
+		panic(a.failure(msg, "event", want.name, ""))
+	}
+
+	checkAll(actual.data, want.dataCheckers, func(m Checker[string], data string) {
+		msg := fmt.Sprintf("%s\n  Expected event %d data: %s\n  Actual event %d: %s%s",
+			title, n, m.Expected(), n, actual, a.formatHelp())
+		panic(a.failure(msg, "event", m.Expected(), ""))
+	})
+
+	checkAll(actual.id, want.idCheckers, func(m Checker[string], id string) {
+		msg := fmt.Sprintf("%s\n  Expected event %d id: %s\n  Actual event %d: %s%s",
+			title, n, m.Expected(), n, actual, a.formatHelp())
+		panic(a.failure(msg, "event", m.Expected(), ""))
+	})
+}
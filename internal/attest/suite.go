@@ -3,29 +3,30 @@ package attest
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/fatih/color"
-)
-
-var (
-	green     = color.New(color.FgGreen).SprintFunc()
-	red       = color.New(color.FgRed).SprintFunc()
-	bold      = color.New(color.Bold).SprintFunc()
-	checkMark = green("✓")
-	crossMark = red("✗")
+	"github.com/littleclusters/lc/internal/style"
 )
 
 // Suite represents a test suite with setup and test functions.
 type Suite struct {
-	setupFn func(*Do)
-	tests   []TestFunc
-	config  *Config
+	setupFn   func(*Do)
+	tests     []TestFunc
+	upstreams []namedUpstream
+	filter    func(name string) bool
+	config    *Config
 }
 
 // TestFunc represents a single test case with name and function.
 type TestFunc struct {
 	Name string
 	Fn   func(*Do)
+	// Parallel marks the test as independent of its neighbours.
+	Parallel bool
+	// Soft runs every assertion and fails with all that failed.
+	Soft bool
 }
 
 // New creates a new empty test suite.
@@ -34,8 +35,12 @@ func New() *Suite {
 }
 
 // WithConfig sets the configuration for the test suite.
+// Fields left unset keep their current values.
 func (s *Suite) WithConfig(config *Config) *Suite {
 	merged := DefaultConfig()
+	if s.config != nil {
+		*merged = *s.config
+	}
 
 	if config.Command != "" {
 		merged.Command = config.Command
@@ -45,6 +50,14 @@ func (s *Suite) WithConfig(config *Config) *Suite {
 		merged.WorkingDir = config.WorkingDir
 	}
 
+	if config.Dir != "" {
+		merged.Dir = config.Dir
+	}
+
+	if len(config.Env) > 0 {
+		merged.Env = config.Env
+	}
+
 	if config.ProcessStartTimeout != 0 {
 		merged.ProcessStartTimeout = config.ProcessStartTimeout
 	}
@@ -65,10 +78,66 @@ func (s *Suite) WithConfig(config *Config) *Suite {
 		merged.RetryPollInterval = config.RetryPollInterval
 	}
 
+	if config.RetryPolicy != (RetryPolicy{}) {
+		merged.RetryPolicy = config.RetryPolicy
+	}
+
+	if config.TimeoutScale != 0 {
+		merged.TimeoutScale = config.TimeoutScale
+	}
+
 	if config.ExecuteTimeout != 0 {
 		merged.ExecuteTimeout = config.ExecuteTimeout
 	}
 
+	if config.PlanDeadline != 0 {
+		merged.PlanDeadline = config.PlanDeadline
+	}
+
+	if config.Verbose {
+		merged.Verbose = true
+	}
+
+	if config.Quiet {
+		merged.Quiet = true
+	}
+
+	if config.KeepGoing {
+		merged.KeepGoing = true
+	}
+
+	if config.Seed != 0 {
+		merged.Seed = config.Seed
+	}
+
+	if config.Parallel != 0 {
+		merged.Parallel = config.Parallel
+	}
+
+	if config.Recorder != nil {
+		merged.Recorder = config.Recorder
+	}
+
+	if config.Golden != nil {
+		merged.Golden = config.Golden
+	}
+
+	if config.GoldenDir != "" {
+		merged.GoldenDir = config.GoldenDir
+	}
+
+	if config.UpdateGolden {
+		merged.UpdateGolden = true
+	}
+
+	if config.Fixtures != nil {
+		merged.Fixtures = config.Fixtures
+	}
+
+	if config.VirtualClock {
+		merged.VirtualClock = true
+	}
+
 	s.config = merged
 	return s
 }
@@ -85,8 +154,84 @@ func (s *Suite) Test(name string, fn func(*Do)) *Suite {
 	return s
 }
 
-// Run executes the test suite and returns results.
+// TestParallel adds a test case that may run concurrently with adjacent
+// parallel tests when Config.Parallel is greater than one.
+// It must not restart processes or touch state other tests rely on.
+func (s *Suite) TestParallel(name string, fn func(*Do)) *Suite {
+	s.tests = append(s.tests, TestFunc{Name: name, Fn: fn, Parallel: true})
+	return s
+}
+
+// TestSoft adds a test case that runs every assertion and reports all that
+// failed, instead of stopping at the first, e.g. for a stage with many small
+// requirements. Errors other than failed assertions still stop the test.
+func (s *Suite) TestSoft(name string, fn func(*Do)) *Suite {
+	s.tests = append(s.tests, TestFunc{Name: name, Fn: fn, Soft: true})
+	return s
+}
+
+// Filter restricts the suite to tests whose name satisfies fn.
+// Setup always runs.
+func (s *Suite) Filter(fn func(name string) bool) *Suite {
+	s.filter = fn
+	return s
+}
+
+// TestNames returns the names of the tests selected to run, in order.
+func (s *Suite) TestNames() []string {
+	names := make([]string, 0, len(s.tests))
+	for _, test := range s.selected() {
+		names = append(names, test.Name)
+	}
+
+	return names
+}
+
+// selected returns the tests that pass the filter.
+func (s *Suite) selected() []TestFunc {
+	if s.filter == nil {
+		return s.tests
+	}
+
+	var tests []TestFunc
+	for _, test := range s.tests {
+		if s.filter(test.Name) {
+			tests = append(tests, test)
+		}
+	}
+
+	return tests
+}
+
+// TestResult holds the outcome of a single test.
+type TestResult struct {
+	Name     string
+	Passed   bool
+	Duration time.Duration
+	// Failure is the failure message of a failed test.
+	Failure string
+	// HTTP holds the details of a failed HTTP assertion, if that is what failed.
+	HTTP *HTTPFailure
+}
+
+// Report holds the outcome of a suite run.
+type Report struct {
+	Passed bool
+	// Results holds the tests that ran, in order.
+	Results []TestResult
+	// WorkingDir is the directory holding this run's files and logs.
+	WorkingDir string
+	// Seed is the seed of the run's random generator.
+	Seed uint64
+}
+
+// Run executes the test suite and returns whether it passed.
 func (s *Suite) Run(ctx context.Context) bool {
+	return s.RunReport(ctx).Passed
+}
+
+// RunReport executes the test suite and returns the outcome of each test.
+func (s *Suite) RunReport(ctx context.Context) *Report {
 	config := s.config
 	if config == nil {
 		config = DefaultConfig()
@@ -94,18 +239,22 @@ func (s *Suite) Run(ctx context.Context) bool {
 
 	do := newDo(ctx, config)
 	defer do.Done()
+	do.startUpstreams(s.upstreams)
+
+	report := &Report{WorkingDir: do.workingDir, Seed: do.seed}
+	runStart := time.Now()
 
 	// Run setup function if defined
-	var failed bool
+	var failed, setupFailed bool
 	if s.setupFn != nil {
 		func() {
 			defer func() {
 				err := recover()
 				if err != nil {
 					failed = true
+					setupFailed = true
 
-					fmt.Printf("%s %s\n", crossMark, "SETUP")
-					fmt.Printf("\n%s\n", err)
+					printFailure(config, "SETUP", err)
 				}
 			}()
 
@@ -113,42 +262,173 @@ func (s *Suite) Run(ctx context.Context) bool {
 		}()
 	}
 
-	// Run each test, stopping on first failure or cancellation
-	for _, test := range s.tests {
-		if failed {
-			break
-		}
-
+	// Run each test, stopping on cancellation, or on the first failure
+	// unless KeepGoing. Adjacent parallel tests run together as a group.
+	tests := s.selected()
+	for i := 0; i < len(tests) && !setupFailed && !(failed && !config.KeepGoing); {
 		select {
 		case <-ctx.Done():
-			return false
+			return report
 		default:
 		}
 
-		func() {
-			defer func() {
-				err := recover()
-				if err != nil {
-					failed = true
+		group := tests[i : i+1]
+		if config.Parallel > 1 && tests[i].Parallel {
+			j := i + 1
+			for j < len(tests) && tests[j].Parallel {
+				j++
+			}
+			group = tests[i:j]
+		}
+		i += len(group)
 
-					fmt.Printf("%s %s\n", crossMark, test.Name)
-					fmt.Printf("\n%s\n", err)
-				}
-			}()
+		for _, result := range runGroup(do, config, group) {
+			report.Results = append(report.Results, result)
+			if !result.Passed {
+				failed = true
+			}
+		}
+	}
+
+	switch {
+	case config.Quiet:
+		printSummary(report, len(tests), failed, time.Since(runStart))
+	case failed:
+		fmt.Printf("\n%s %s\n", style.Bold("FAILED"), style.CrossMark())
+		fmt.Printf("Seed: %d\n", do.seed)
+	default:
+		fmt.Printf("\n%s %s\n", style.Bold("PASSED"), style.CheckMark())
+	}
+
+	if config.Verbose {
+		fmt.Printf("Logs: %s\n", do.workingDir)
+	}
+
+	report.Passed = !failed
+	return report
+}
+
+// outputMu serializes result output from tests running in parallel.
+var outputMu sync.Mutex
+
+// runGroup runs a group of tests with up to config.Parallel at a time and
+// returns their results in order.
+func runGroup(do *Do, config *Config, group []TestFunc) []TestResult {
+	results := make([]TestResult, len(group))
+	if len(group) == 1 {
+		results[0] = runTest(do, config, group[0])
+		return results
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, config.Parallel)
+	for i, test := range group {
+		wg.Add(1)
+		sem <- struct{}{}
 
-			test.Fn(do)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = runTest(do, config, test)
 		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runTest runs a single test, recovering its failure.
+// Process logs are marked where the test starts and ends.
+func runTest(do *Do, config *Config, test TestFunc) (result TestResult) {
+	result = TestResult{Name: test.Name, Passed: true}
+	start := time.Now()
+	do.markLogs("=== RUN   %s", test.Name)
+	config.Recorder.record(Entry{Kind: EntryTest, Test: test.Name})
+
+	defer func() {
+		err := recover()
+		result.Duration = time.Since(start)
+
+		outputMu.Lock()
+		defer outputMu.Unlock()
+
+		// A cancelled run fails whatever was in flight; report it as
+		// interrupted rather than as the failure it caused
+		if err != nil && do.ctx.Err() != nil {
+			result.Passed = false
+			result.Failure = "interrupted"
+			do.markLogs("--- FAIL: %s (interrupted)", test.Name)
+			fmt.Printf("%s %s: interrupted\n", style.CrossMark(), test.Name)
+			return
+		}
+
+		if err != nil {
+			result.Passed = false
+			result.Failure = fmt.Sprint(err)
+			result.HTTP, _ = err.(*HTTPFailure)
+			if soft, ok := err.(*softFailures); ok {
+				result.HTTP = soft.firstHTTP()
+			}
+
+			do.markLogs("--- FAIL: %s (%s)", test.Name, result.Duration.Round(time.Millisecond))
+			printFailure(config, test.Name, err)
+			return
+		}
+
+		do.markLogs("--- PASS: %s (%s)", test.Name, result.Duration.Round(time.Millisecond))
+		fmt.Printf("%s %s\n", style.CheckMark(), test.Name)
+	}()
+
+	// Variables a test captures are its own
+	view := *do
+	view.vars = newVariables(nil)
 
-		if !failed {
-			fmt.Printf("%s %s\n", checkMark, test.Name)
+	if test.Soft {
+		runSoft(&view, test.Fn)
+	} else {
+		test.Fn(&view)
+	}
+	return result
+}
+
+// printFailure reports a failed test, condensed to a single line in quiet mode.
+func printFailure(config *Config, name string, err any) {
+	if config.Quiet {
+		fmt.Printf("%s %s: %s\n", style.CrossMark(), name, firstLine(fmt.Sprint(err)))
+		return
+	}
+
+	fmt.Printf("%s %s\n", style.CrossMark(), name)
+	fmt.Printf("\n%s\n", err)
+}
+
+// printSummary prints the one-line quiet mode summary of a run.
+func printSummary(report *Report, total int, failed bool, elapsed time.Duration) {
+	passed := 0
+	for _, result := range report.Results {
+		if result.Passed {
+			passed++
 		}
 	}
 
+	elapsed = elapsed.Round(10 * time.Millisecond)
 	if failed {
-		fmt.Printf("\n%s %s\n", bold("FAILED"), crossMark)
-	} else {
-		fmt.Printf("\n%s %s\n", bold("PASSED"), checkMark)
+		fmt.Printf("%s %s %d/%d passed in %s (seed %d)\n", style.Bold("FAILED"), style.CrossMark(), passed, total, elapsed, report.Seed)
+		return
+	}
+
+	fmt.Printf("%s %s %d/%d passed in %s\n", style.Bold("PASSED"), style.CheckMark(), passed, total, elapsed)
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for line := range strings.Lines(s) {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			return line
+		}
 	}
 
-	return !failed
+	return ""
 }
@@ -0,0 +1,366 @@
+package attest
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+var _ Plan[*TCPPlan, *TCPAssert] = (*TCPPlan)(nil)
+var _ Assert = (*TCPAssert)(nil)
+
+// tcpStep is a write, a fixture to stream, or a pause in a TCP exchange.
+type tcpStep struct {
+	data  []byte
+	file  string
+	delay time.Duration
+}
+
+// TCPPlan represents a test plan for a raw TCP exchange: connect, write a
+// sequence of bytes, and read the response.
+type TCPPlan struct {
+	PlanBase
+
+	process  string
+	addr     string
+	steps    []tcpStep
+	deadline time.Duration
+	tls      *TLSConfig
+	marks    *marks
+}
+
+// Send writes data to the connection.
+func (p *TCPPlan) Send(data string) *TCPPlan {
+	p.steps = append(p.steps, tcpStep{data: []byte(data)})
+	return p
+}
+
+// SendBytes writes raw bytes to the connection.
+func (p *TCPPlan) SendBytes(data []byte) *TCPPlan {
+	p.steps = append(p.steps, tcpStep{data: data})
+	return p
+}
+
+// Pause waits before the next write, e.g. to split a message across packets.
+func (p *TCPPlan) Pause(d time.Duration) *TCPPlan {
+	p.steps = append(p.steps, tcpStep{delay: d})
+	return p
+}
+
+// ReadDeadline sets how long to wait for a response that satisfies the
+// checkers. The default is Config.ExecuteTimeout.
+func (p *TCPPlan) ReadDeadline(d time.Duration) *TCPPlan {
+	p.deadline = d
+	return p
+}
+
+func (p *TCPPlan) Eventually() *TCPPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *TCPPlan) Within(timeout time.Duration) *TCPPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *TCPPlan) Retry(policy RetryPolicy) *TCPPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *TCPPlan) Consistently() *TCPPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *TCPPlan) For(timeout time.Duration) *TCPPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *TCPPlan) Every(interval time.Duration) *TCPPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *TCPPlan) Deadline(d time.Duration) *TCPPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *TCPPlan) T() *TCPAssert {
+	return &TCPAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+		order:      orderAssert{marks: p.marks},
+	}
+}
+
+// sent returns everything the plan writes, in order.
+func (p *TCPPlan) sent() string {
+	var b strings.Builder
+	for _, step := range p.steps {
+		b.Write(step.data)
+		if step.file != "" {
+			b.WriteString(p.config.readFixture(step.file))
+		}
+	}
+
+	return b.String()
+}
+
+// formatSent formats what the plan writes for a failure, naming fixtures
+// rather than showing them.
+func (p *TCPPlan) formatSent() string {
+	var parts []string
+	var data strings.Builder
+	for _, step := range p.steps {
+		data.Write(step.data)
+		if step.file != "" {
+			if data.Len() > 0 {
+				parts = append(parts, fmt.Sprintf("%q", data.String()))
+				data.Reset()
+			}
+			parts = append(parts, "fixture "+step.file)
+		}
+	}
+	if data.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", data.String()))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+// TCPAssert provides assertions on the bytes received over a TCP connection.
+type TCPAssert struct {
+	AssertBase
+
+	plan     *TCPPlan
+	received string
+	latency  time.Duration
+	err      error
+	// connected is whether the last attempt connected.
+	connected bool
+	tls       tlsAssert
+	order     orderAssert
+
+	receivedCheckers []Checker[string]
+	latencyCheckers  []Checker[time.Duration]
+	closed           bool
+	closedWithin     time.Duration
+	// receivedFile is the fixture the response is streamed against, if any.
+	receivedFile string
+	receivedDiff streamDiff
+	refused      bool
+	notListening bool
+}
+
+// Received adds checkers for the bytes read from the connection.
+// Reading stops once all checkers pass, the server closes the connection,
+// or the read deadline passes. All checkers must pass.
+func (a *TCPAssert) Received(checkers ...Checker[string]) *TCPAssert {
+	a.receivedCheckers = append(a.receivedCheckers, checkers...)
+	return a
+}
+
+// Closed expects the server to close the connection after responding.
+func (a *TCPAssert) Closed() *TCPAssert {
+	a.closed = true
+	return a
+}
+
+func (a *TCPAssert) Assert(help string) {
+	a.help = help
+
+	execute := func() bool { return a.order.observe(a.execute()) }
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, execute, p.timeout, p.pollInterval())
+	default:
+		execute()
+	}
+
+	a.verify(p.ctx, func() {
+		a.check()
+		a.checkOrder()
+	})
+}
+
+func (a *TCPAssert) execute() bool {
+	p := a.plan
+	if p.process != "" && p.config.Recorder != nil {
+		p.config.Recorder.record(Entry{Kind: EntryTCP, Process: p.process, Body: p.sent()})
+	}
+
+	a.received, a.err, a.connected = "", nil, false
+	a.tls.state, a.tls.err = nil, nil
+	deadline := p.deadline
+	if deadline == 0 {
+		deadline = a.config.ExecuteTimeout
+	}
+	if a.closedWithin > 0 {
+		deadline = a.closedWithin
+	}
+
+	dialer := net.Dialer{Timeout: a.config.ExecuteTimeout}
+	conn, err := dialer.DialContext(p.ctx, "tcp", p.addr)
+	if err != nil {
+		a.err = err
+		return a.expectsNoConnection() && a.dialPasses(err)
+	}
+	defer conn.Close()
+	defer closeWhenDone(p.ctx, conn)()
+
+	a.connected = true
+	if a.expectsNoConnection() {
+		return false
+	}
+
+	if p.tls != nil {
+		host, _, _ := net.SplitHostPort(p.addr)
+		tlsConn := tls.Client(conn, p.tls.client(host))
+		if err := tlsConn.HandshakeContext(p.ctx); err != nil {
+			a.tls.err = err
+			return a.tls.passes()
+		}
+
+		state := tlsConn.ConnectionState()
+		a.tls.state = &state
+		conn = tlsConn
+	}
+
+	start := time.Now()
+	for _, step := range p.steps {
+		if step.delay > 0 {
+			select {
+			case <-p.ctx.Done():
+				a.err = p.ctx.Err()
+				return false
+			case <-time.After(step.delay):
+			}
+			continue
+		}
+
+		err := p.write(conn, step)
+		if err != nil {
+			a.err = err
+			return false
+		}
+	}
+
+	if a.receivedFile != "" {
+		a.readFile(conn, time.Now().Add(deadline))
+		a.latency = time.Since(start)
+		return a.err == nil && a.receivedDiff.equal() && a.tls.passes() &&
+			checkAll(a.latency, a.latencyCheckers, nil)
+	}
+
+	a.received, a.err = readUntil(conn, time.Now().Add(deadline), func(received string, eof bool) bool {
+		// An expected TLS error can only show up on a later read
+		return (eof || !a.closed) && len(a.tls.errorCheckers) == 0 &&
+			checkAll(received, a.receivedCheckers, nil)
+	})
+	a.latency = time.Since(start)
+	if isTLSAlert(a.err) {
+		a.tls.state, a.tls.err, a.err = nil, a.err, nil
+	}
+
+	return a.err == nil && a.tls.passes() && checkAll(a.latency, a.latencyCheckers, nil)
+}
+
+// write writes a step's data or streams its fixture to conn.
+func (p *TCPPlan) write(conn net.Conn, step tcpStep) error {
+	if step.file == "" {
+		_, err := conn.Write(step.data)
+		return err
+	}
+
+	f, _ := p.config.openFixture(step.file)
+	defer f.Close()
+
+	_, err := io.Copy(conn, f)
+	return err
+}
+
+// readUntil reads from conn until done accepts what was received, the
+// connection closes, or the deadline passes. Reaching the deadline or the
+// end of the stream is only an error if done never accepts.
+func readUntil(conn net.Conn, deadline time.Time, done func(received string, eof bool) bool) (string, error) {
+	conn.SetReadDeadline(deadline)
+
+	var received []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+
+		eof := err != nil && !errors.Is(err, os.ErrDeadlineExceeded)
+		if done(string(received), eof) {
+			return string(received), nil
+		}
+
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return string(received), fmt.Errorf("no matching response before the read deadline")
+			}
+
+			return string(received), fmt.Errorf("connection closed: %w", err)
+		}
+	}
+}
+
+func (a *TCPAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("TCP %s\n  Sent: %s", p.addr, p.formatSent())
+
+	if a.expectsNoConnection() {
+		a.checkDial(title)
+		return
+	}
+
+	if a.tls.check(func(detail, field, expected string) {
+		panic(fmt.Sprintf("%s\n%s%s", title, detail, a.formatHelp()))
+	}) {
+		return
+	}
+
+	if a.receivedFile != "" && !a.receivedDiff.equal() {
+		panic(fmt.Sprintf("%s\n%s%s", title, a.receivedDiff.describe("response"), a.formatHelp()))
+	}
+
+	checkAll(a.received, a.receivedCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n%s%s%s",
+			title, payloadMismatch(m, actual), explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+
+	checkAll(a.latency, a.latencyCheckers, func(m Checker[time.Duration], actual time.Duration) {
+		msg := fmt.Sprintf("%s\n  Expected latency: %s\n  Actual latency: %s%s",
+			title, m.Expected(), actual.Round(time.Microsecond), a.formatHelp())
+		panic(msg)
+	})
+
+	if a.err != nil {
+		msg := fmt.Sprintf("%s\n  Expected connection closed by server\n  Error: %v%s", title, a.err, a.formatHelp())
+		if a.closedWithin > 0 && a.connected {
+			msg = fmt.Sprintf("%s\n  Expected connection closed by server within %s\n  Actual: still open%s",
+				title, a.closedWithin, a.formatHelp())
+		}
+		if !a.closed {
+			msg = fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp())
+		}
+		panic(msg)
+	}
+}
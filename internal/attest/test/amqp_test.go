@@ -0,0 +1,395 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// AMQP frame types.
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameEnd       = 0xCE
+	amqpClassBasic = 60
+)
+
+// amqpArgs builds method arguments.
+type amqpArgs []byte
+
+func (b amqpArgs) octet(v byte) amqpArgs      { return append(b, v) }
+func (b amqpArgs) short(v uint16) amqpArgs    { return binary.BigEndian.AppendUint16(b, v) }
+func (b amqpArgs) long(v uint32) amqpArgs     { return binary.BigEndian.AppendUint32(b, v) }
+func (b amqpArgs) longlong(v uint64) amqpArgs { return binary.BigEndian.AppendUint64(b, v) }
+func (b amqpArgs) shortstr(s string) amqpArgs { return append(append(b, byte(len(s))), s...) }
+func (b amqpArgs) longstr(s string) amqpArgs  { return append(b.long(uint32(len(s))), s...) }
+
+// shortstr reads a short string at offset, returning it and the next offset.
+func shortstr(b []byte, offset int) (string, int) {
+	n := int(b[offset])
+	return string(b[offset+1 : offset+1+n]), offset + 1 + n
+}
+
+// amqpMessage is a queued message.
+type amqpMessage struct {
+	body        string
+	redelivered bool
+}
+
+// amqpBroker is a minimal AMQP 0.9.1 broker with a default exchange. The
+// queue "forgetful" drops nacked messages instead of requeueing them.
+type amqpBroker struct {
+	mu     sync.Mutex
+	queues map[string][]amqpMessage
+}
+
+// amqpSession is a client connection to the broker.
+type amqpSession struct {
+	broker *amqpBroker
+	conn   net.Conn
+	mu     sync.Mutex
+
+	confirm   bool
+	published uint64
+	consuming string
+	ctag      string
+	tag       uint64
+	unacked   map[uint64]amqpMessage
+}
+
+func (s *amqpSession) frame(typ byte, channel uint16, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frame := amqpArgs{typ}.short(channel).long(uint32(len(payload)))
+	frame = append(frame, payload...)
+	s.conn.Write(append(frame, frameEnd))
+}
+
+func (s *amqpSession) method(channel, class, method uint16, args amqpArgs) {
+	s.frame(frameMethod, channel, append(amqpArgs{}.short(class).short(method), args...))
+}
+
+// deliver sends queued messages to the consumer.
+func (s *amqpSession) deliver(channel uint16) {
+	if s.consuming == "" {
+		return
+	}
+
+	b := s.broker
+	b.mu.Lock()
+	pending := b.queues[s.consuming]
+	b.queues[s.consuming] = nil
+	b.mu.Unlock()
+
+	for _, msg := range pending {
+		s.tag++
+		s.unacked[s.tag] = msg
+
+		redelivered := byte(0)
+		if msg.redelivered {
+			redelivered = 1
+		}
+		s.method(channel, amqpClassBasic, 60, amqpArgs{}.shortstr(s.ctag).longlong(s.tag).octet(redelivered).shortstr("").shortstr(s.consuming))
+		s.frame(frameHeader, channel, amqpArgs{}.short(amqpClassBasic).short(0).longlong(uint64(len(msg.body))).short(0))
+		s.frame(frameBody, channel, []byte(msg.body))
+	}
+}
+
+func (b *amqpBroker) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return
+	}
+
+	s := &amqpSession{broker: b, conn: conn, unacked: make(map[uint64]amqpMessage)}
+	s.method(0, 10, 10, amqpArgs{}.octet(0).octet(9).long(0).longstr("PLAIN").longstr("en_US"))
+
+	var publishing struct {
+		key  string
+		size uint64
+		body []byte
+	}
+
+	for {
+		var fh [7]byte
+		if _, err := io.ReadFull(r, fh[:]); err != nil {
+			return
+		}
+		channel := binary.BigEndian.Uint16(fh[1:])
+		payload := make([]byte, binary.BigEndian.Uint32(fh[3:])+1)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+		payload = payload[:len(payload)-1]
+
+		switch fh[0] {
+		case frameHeader:
+			publishing.size = binary.BigEndian.Uint64(payload[4:])
+			publishing.body = nil
+			if publishing.size > 0 {
+				continue
+			}
+			fallthrough
+		case frameBody:
+			if fh[0] == frameBody {
+				publishing.body = append(publishing.body, payload...)
+			}
+			if uint64(len(publishing.body)) < publishing.size {
+				continue
+			}
+
+			b.mu.Lock()
+			if _, ok := b.queues[publishing.key]; ok {
+				b.queues[publishing.key] = append(b.queues[publishing.key], amqpMessage{body: string(publishing.body)})
+			}
+			b.mu.Unlock()
+
+			if s.confirm {
+				s.published++
+				s.method(channel, amqpClassBasic, 80, amqpArgs{}.longlong(s.published).octet(0))
+			}
+			s.deliver(channel)
+			continue
+		case frameMethod:
+		default:
+			continue
+		}
+
+		class, method, args := binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:]), payload[4:]
+		switch {
+		case class == 10 && method == 11:
+			s.method(0, 10, 30, amqpArgs{}.short(16).long(131072).short(0))
+		case class == 10 && method == 40:
+			s.method(0, 10, 41, amqpArgs{}.shortstr(""))
+		case class == 10 && method == 50:
+			s.method(0, 10, 51, nil)
+			return
+		case class == 20 && method == 10:
+			s.method(channel, 20, 11, amqpArgs{}.longstr(""))
+		case class == 20 && method == 40:
+			s.method(channel, 20, 41, nil)
+		case class == 50 && method == 10:
+			queue, _ := shortstr(args, 2)
+			b.mu.Lock()
+			if _, ok := b.queues[queue]; !ok {
+				b.queues[queue] = nil
+			}
+			count := len(b.queues[queue])
+			b.mu.Unlock()
+			s.method(channel, 50, 11, amqpArgs{}.shortstr(queue).long(uint32(count)).long(0))
+		case class == 85 && method == 10:
+			s.confirm = true
+			s.method(channel, 85, 11, nil)
+		case class == amqpClassBasic && method == 40:
+			_, next := shortstr(args, 2)
+			publishing.key, _ = shortstr(args, next)
+		case class == amqpClassBasic && method == 20:
+			var next int
+			s.consuming, next = shortstr(args, 2)
+			s.ctag, _ = shortstr(args, next)
+			s.method(channel, amqpClassBasic, 21, amqpArgs{}.shortstr(s.ctag))
+			s.deliver(channel)
+		case class == amqpClassBasic && method == 30:
+			ctag, _ := shortstr(args, 0)
+			s.consuming = ""
+			s.method(channel, amqpClassBasic, 31, amqpArgs{}.shortstr(ctag))
+		case class == amqpClassBasic && method == 80:
+			delete(s.unacked, binary.BigEndian.Uint64(args))
+		case class == amqpClassBasic && method == 120:
+			tag := binary.BigEndian.Uint64(args)
+			msg := s.unacked[tag]
+			delete(s.unacked, tag)
+
+			if args[8]&0x02 != 0 && s.consuming != "forgetful" {
+				msg.redelivered = true
+				b.mu.Lock()
+				b.queues[s.consuming] = append([]amqpMessage{msg}, b.queues[s.consuming]...)
+				b.mu.Unlock()
+				s.deliver(channel)
+			}
+		}
+	}
+}
+
+// serveAMQP runs the broker.
+func serveAMQP(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	b := &amqpBroker{queues: make(map[string][]amqpMessage)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.handle(conn)
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestAMQP(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Publish and Consume",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "jobs", "first").
+					Publish("", "jobs", "second").
+					Consume("jobs").
+					T().
+					Delivery(Is("first")).
+					Delivery(Is("second")).
+					NoDelivery().
+					Assert("Broker should deliver messages in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Delivery Mismatch",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "jobs", "first").
+					Consume("jobs").
+					T().
+					Delivery(Is("second")).
+					Assert("Should fail when the body differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Delivery",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "unrouted", "lost").
+					Consume("jobs").
+					DeliveryTimeout(100 * time.Millisecond).
+					T().
+					Delivery(Is("lost")).
+					Assert("Should fail when nothing is delivered")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Publisher Confirms",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Confirm().
+					Publish("", "jobs", "a").
+					Publish("", "jobs", "b").
+					T().
+					Confirmed().
+					Assert("Broker should confirm each publish")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Redelivery After Nack",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "jobs", "retry me").
+					Consume("jobs").
+					T().
+					Nack(Is("retry me")).
+					Redelivery(Is("retry me")).
+					NoDelivery().
+					Assert("Broker should requeue nacked messages")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Redelivery",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("forgetful").
+					Publish("", "forgetful", "retry me").
+					Consume("forgetful").
+					DeliveryTimeout(100 * time.Millisecond).
+					T().
+					Nack(Is("retry me")).
+					Redelivery(Is("retry me")).
+					Assert("Should fail when the broker drops nacked messages")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Not Redelivered",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "jobs", "fresh").
+					Consume("jobs").
+					T().
+					Redelivery(Is("fresh")).
+					Assert("Should fail when the delivery is not flagged as redelivered")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unexpected Delivery",
+			testFunc: func(do *Do) {
+				do.AMQP("svc").
+					DeclareQueue("jobs").
+					Publish("", "jobs", "extra").
+					Consume("jobs").
+					DeliveryTimeout(100 * time.Millisecond).
+					T().
+					NoDelivery().
+					Assert("Should fail when a message is delivered")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveAMQP(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
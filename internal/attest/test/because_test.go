@@ -0,0 +1,90 @@
+package attest_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestBecause(t *testing.T) {
+	tests := []struct {
+		name     string
+		testFunc func(*Do)
+		want     string
+	}{
+		{
+			name: "HTTP Because",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/").T().
+					Header("X-Request-Id", Is("42")).
+					Because("clients match responses to requests by ID").
+					Assert("Server should echo the request ID header")
+			},
+			want: "\n  Because clients match responses to requests by ID\n\n  Server should echo the request ID header",
+		},
+		{
+			name: "HTTP Hint",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/").T().
+					Status(Is(201)).
+					Because("the key was created").
+					Hint("return 201 Created for new keys").
+					Assert("Server should report the new key")
+			},
+			want: "Actual status: 200 OK\n  Because the key was created\n  Hint: return 201 Created for new keys\n\n  Server should report the new key",
+		},
+		{
+			name: "TCP Hint",
+			testFunc: func(do *Do) {
+				do.TCP("echo").Send("PING\n").T().
+					Received(Is("+PONG\r\n")).
+					Hint("reply with a RESP simple string").
+					Assert("Server should answer PING")
+			},
+			want: "\n  Hint: reply with a RESP simple string\n\n  Server should answer PING",
+		},
+		{
+			name: "Consistently",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/").Consistently().For(100 * time.Millisecond).Every(20 * time.Millisecond).T().
+					Status(Is(404)).
+					Because("the key was deleted").
+					Assert("Server should not return deleted keys")
+			},
+			want: "\n  Failed on sample 1, checking every 20ms\n  Because the key was deleted\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			echo := serveTCP(t, func(conn net.Conn) {
+				conn.Write([]byte("PONG\n"))
+			})
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Quiet: true}).
+				Setup(func(do *Do) {
+					do.MockProcess("web", strings.Split(server.URL, ":")[2])
+					do.MockProcess("echo", echo)
+				}).
+				Test(tt.name, tt.testFunc).
+				RunReport(context.Background())
+
+			if report.Passed || len(report.Results) != 1 {
+				t.Fatalf("test should fail with one result, got %+v", report.Results)
+			}
+
+			if failure := report.Results[0].Failure; !strings.Contains(failure, tt.want) {
+				t.Errorf("failure should contain %q, got:\n%s", tt.want, failure)
+			}
+		})
+	}
+}
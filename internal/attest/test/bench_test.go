@@ -0,0 +1,175 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestBench(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Thresholds Met",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Bench(func() {
+					do.HTTP("svc", "GET", "/").T().
+						Status(Is(200)).
+						Assert("")
+				}).Workers(4).Duration(300*time.Millisecond).T().
+					ErrorRate(Is(0.0)).
+					Throughput(AtLeast(10.0)).
+					Latency(99, AtMost(time.Second)).
+					Assert("Fast server should meet benchmark thresholds")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Throughput Too Low",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Bench(func() {
+					do.HTTP("svc", "GET", "/").T().
+						Status(Is(200)).
+						Assert("")
+				}).Workers(1).Duration(300 * time.Millisecond).T().
+					Throughput(AtLeast(1_000.0)).
+					Assert("Should fail when throughput is below threshold")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Latency Too High",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(50 * time.Millisecond)
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Bench(func() {
+					do.HTTP("svc", "GET", "/").T().
+						Status(Is(200)).
+						Assert("")
+				}).Workers(2).Duration(300*time.Millisecond).T().
+					Latency(99, AtMost(10*time.Millisecond)).
+					Assert("Should fail when p99 latency exceeds threshold")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Failed Operations Count As Errors",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			testFunc: func(do *Do) {
+				do.Bench(func() {
+					do.HTTP("svc", "GET", "/").T().
+						Status(Is(200)).
+						Assert("")
+				}).Workers(2).Duration(300 * time.Millisecond).T().
+					ErrorRate(AtMost(0.01)).
+					Assert("Should fail when operations fail")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Load Mix Thresholds Met",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					w.WriteHeader(http.StatusCreated)
+					return
+				}
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Load("svc").
+					Request(8, "GET", "/kv/a").
+					Request(2, "PUT", "/kv/a", "1", H{"Content-Type": "text/plain"}).
+					Workers(4).Duration(300*time.Millisecond).T().
+					ErrorRate(Is(0.0)).
+					Throughput(AtLeast(10.0)).
+					Latency(50, AtMost(time.Second)).
+					Latency(95, AtMost(time.Second)).
+					Latency(99, AtMost(time.Second)).
+					Assert("Server should handle the read-heavy mix")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Load Errors In Mix",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Load("svc").
+					Request(1, "GET", "/kv/a").
+					Request(1, "PUT", "/kv/a", "1").
+					Workers(2).Duration(300 * time.Millisecond).T().
+					ErrorRate(AtMost(0.1)).
+					Assert("Should fail when writes return 5xx")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Load Percentile Too High",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(30 * time.Millisecond)
+				w.Write([]byte("OK"))
+			},
+			testFunc: func(do *Do) {
+				do.Load("svc").
+					Request(1, "GET", "/").
+					Workers(2).Duration(300*time.Millisecond).T().
+					Latency(95, AtMost(5*time.Millisecond)).
+					Assert("Should fail when p95 latency exceeds threshold")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			suite := New().WithConfig(&Config{WorkingDir: t.TempDir()})
+
+			success := suite.
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,11 @@ package attest_test
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -49,6 +53,38 @@ func TestCLI(t *testing.T) {
 			},
 			shouldPass: false,
 		},
+		{
+			name:   "Output Matches",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo id: 3f2a9c1e-7b4d-4e8a-9f60-2c5d8e1b7a34; echo created: 2024-05-01").T().
+					OutputMatches(`^id: [0-9a-f-]{36}$`).
+					OutputMatches(`^created: \d{4}-\d{2}-\d{2}$`).
+					OutputContains("id: ", "created: ").
+					Assert("Output should contain an id and a date")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Output Matches Mismatch",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo id: 42; echo done").T().
+					OutputMatches(`^id: [0-9a-f-]{36}$`).
+					Assert("Should fail when no line matches")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Output Contains Mismatch",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo one; echo two").T().
+					OutputContains("one", "three").
+					Assert("Should fail when a substring is missing")
+			},
+			shouldPass: false,
+		},
 		{
 			name:   "Timeout",
 			config: &Config{Command: "sleep", ExecuteTimeout: 50 * time.Millisecond},
@@ -60,6 +96,201 @@ func TestCLI(t *testing.T) {
 			},
 			shouldPass: false,
 		},
+		{
+			name:   "Killed By Signal",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "kill -SEGV $$").T().
+					KilledBy(syscall.SIGSEGV).
+					ExitCode(Is(-1)).
+					Assert("Command should crash with SIGSEGV")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Killed By Wrong Signal",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "kill -TERM $$").T().
+					KilledBy(syscall.SIGSEGV).
+					Assert("Should fail when the command is killed by another signal")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Exited Instead Of Killed",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "exit 2").T().
+					KilledBy(syscall.SIGPIPE).
+					Assert("Should fail when the command exits normally")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Timeout Is Not Killed By Signal",
+			config: &Config{Command: "sleep", ExecuteTimeout: 50 * time.Millisecond},
+			testFunc: func(do *Do) {
+				do.Exec("20").T().
+					KilledBy(syscall.SIGKILL).
+					Assert("Should fail when the harness kills the command")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Max Runtime",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "exit 0").T().
+					MaxRuntime(500 * time.Millisecond).
+					Assert("Command should exit quickly")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Max Runtime Exceeded",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "sleep 0.2").T().
+					ExitCode(Is(0)).
+					MaxRuntime(100 * time.Millisecond).
+					Assert("Should fail when the command runs too long")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Stdin",
+			config: &Config{Command: "sort"},
+			testFunc: func(do *Do) {
+				do.Exec().WithStdin([]byte("banana\napple\ncherry\n")).T().
+					Output(Is("apple\nbanana\ncherry\n")).
+					Assert("Sort should read lines from stdin")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Stdin File",
+			config: &Config{Command: "wc"},
+			testFunc: func(do *Do) {
+				path := filepath.Join(do.WorkingDir(), "input.txt")
+				os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644)
+
+				do.Exec("-l").WithStdinFile(path).T().
+					Output(Matches(`^\s*3\n$`)).
+					Assert("wc should count the lines of the fixture")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Stdin File Missing",
+			config: &Config{Command: "cat"},
+			testFunc: func(do *Do) {
+				do.Exec().WithStdinFile(filepath.Join(do.WorkingDir(), "missing.txt")).T().
+					Assert("Should fail when the fixture does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "No Stdin",
+			config: &Config{Command: "cat"},
+			testFunc: func(do *Do) {
+				do.Exec().T().
+					ExitCode(Is(0)).
+					Output(Is("")).
+					Assert("Commands should see an empty stdin by default")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Env",
+			config: &Config{Command: "sh", Env: []string{"GREETING=hello", "NAME=suite"}},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo $GREETING $NAME").WithEnv("NAME", "plan").T().
+					Output(Is("hello plan\n")).
+					Assert("Plan variables should override the suite's")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Env Mismatch",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo ${LOG_LEVEL:-info}").WithEnv("LOG_LEVEL", "debug").T().
+					Output(Is("info\n")).
+					Assert("Should fail when the variable is set")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "In Dir",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				fixtures := filepath.Join(do.WorkingDir(), "fixtures")
+				os.MkdirAll(fixtures, 0755)
+				os.WriteFile(filepath.Join(fixtures, "a.txt"), []byte("a"), 0644)
+
+				do.Exec("-c", "rm a.txt && touch b.txt && ls").InDir("fixtures").T().
+					Output(Is("b.txt\n")).
+					Assert("Command should run among the fixtures")
+
+				do.File("fixtures/b.txt").T().
+					Exists().
+					Assert("Command should write to the fixture directory")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "In New Dir",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "ls | wc -l").InDir("empty").T().
+					Output(Matches(`^\s*0\n$`)).
+					Assert("Missing directories should be created")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Separate Streams",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo data; echo warning >&2").T().
+					Stdout(Is("data\n")).
+					Stderr(Is("warning\n")).
+					Assert("Data should go to stdout and warnings to stderr")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Error On Stdout",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo 'error: no such file'; exit 1").T().
+					ExitCode(Is(1)).
+					Stderr(Contains("no such file")).
+					Assert("Should fail when the error is written to stdout")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Stderr Empty",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo ok").T().
+					StderrEmpty().
+					Assert("Successful runs should not write to stderr")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Stderr Not Empty",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", "echo ok; echo debug >&2").T().
+					StderrEmpty().
+					Assert("Should fail when stray output goes to stderr")
+			},
+			shouldPass: false,
+		},
 		{
 			name:   "Eventually OK",
 			config: &Config{Command: "sh"},
@@ -271,6 +502,82 @@ func TestCLI(t *testing.T) {
 			},
 			shouldPass: false,
 		},
+		{
+			name:   "JSON Output",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `echo '{"name": "web", "tags": ["a", "b"], "replicas": 3}'`).T().
+					JSON("name", Is("web")).
+					JSON("$.tags[1]", Is("b")).
+					JSON("tags", HasLen[string](2)).
+					JSON("replicas", IsType("number")).
+					Assert("Should check fields of JSON output")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "JSON Output - field mismatch",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `echo '{"name": "web"}'`).T().
+					JSON("name", Is("db")).
+					Assert("Should fail when a field does not match")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "JSON Output - invalid JSON",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `echo 'name: web'`).T().
+					JSON("name", Exists()).
+					Assert("Should fail when stdout is not JSON")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "JSON Lines Output",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `echo '{"id": 1, "level": "info"}'; echo '{"id": 2, "level": "warn"}'`).T().
+					JSONLines("#", Is("2")).
+					JSONLines("0.id", Is("1")).
+					JSONLines("#.level", Not(Contains("error"))).
+					Assert("Should check fields across JSON Lines")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "JSON Lines Output - invalid line",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `echo '{"id": 1}'; echo 'oops'`).T().
+					JSONLines("#", Is("1")).
+					Assert("Should fail when a line is not JSON")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Normalized Output",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `printf '\033[1;32mok\033[0m  \r\ndone\t\r\n'`).T().
+					Output(Normalized(Is("ok\ndone\n"))).
+					Stdout(Normalized(Contains("ok\r\n"), StripANSI(), TrimTrailingSpace())).
+					Assert("Should ignore colors, trailing whitespace and line endings")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Normalized Output - only selected normalizers",
+			config: &Config{Command: "sh"},
+			testFunc: func(do *Do) {
+				do.Exec("-c", `printf '\033[32mok\033[0m  \n'`).T().
+					Output(Normalized(Is("ok\n"), StripANSI())).
+					Assert("Should fail when trailing whitespace is not ignored")
+			},
+			shouldPass: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -303,3 +610,84 @@ func TestCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestCLIInDir(t *testing.T) {
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, "run.sh"), []byte("#!/bin/sh\npwd\n"), 0755)
+
+	sandbox := t.TempDir()
+	config := &Config{Command: "./run.sh", Dir: project, WorkingDir: t.TempDir()}
+
+	success := New().WithConfig(config).
+		Test("Relative Command", func(do *Do) {
+			do.Exec().T().
+				Output(Is(project + "\n")).
+				Assert("Command should run in the project directory by default")
+
+			do.Exec().InDir(sandbox).T().
+				Output(Is(sandbox + "\n")).
+				Assert("./run.sh should still be found from another directory")
+		}).
+		Run(context.Background())
+
+	if !success {
+		t.Error("Relative Command test should pass but failed")
+	}
+}
+
+func TestCLIStreamsFailure(t *testing.T) {
+	report := New().WithConfig(&Config{Command: "sh", WorkingDir: t.TempDir(), Quiet: true}).
+		Test("Stray Stderr", func(do *Do) {
+			do.Exec("-c", "echo one; sleep 0.05; echo oops >&2; sleep 0.05; echo two").T().
+				StderrEmpty().
+				Assert("Stderr should be empty")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Expected stderr: length 0\n  Actual stderr: \"oops\\n\"\n  Combined output:\n    | one\n    | oops\n    | two"
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
+
+func TestNormalizedFailure(t *testing.T) {
+	report := New().WithConfig(&Config{Command: "sh", WorkingDir: t.TempDir(), Quiet: true}).
+		Test("Colored", func(do *Do) {
+			do.Exec("-c", `printf '\033[31mfail\033[0m\n'`).T().
+				Output(Normalized(Is("ok\n"), StripANSI())).
+				Assert("Should print ok")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Expected output: ignoring ANSI codes, ok\n"
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) || !strings.Contains(failure, `Normalized: "fail\n"`) {
+		t.Errorf("failure should contain %q and the normalized output, got:\n%s", want, failure)
+	}
+}
+
+func TestCLIJSONLinesFailure(t *testing.T) {
+	report := New().WithConfig(&Config{Command: "sh", WorkingDir: t.TempDir(), Quiet: true}).
+		Test("Invalid Line", func(do *Do) {
+			do.Exec("-c", `echo '{"id": 1}'; echo 'oops'`).T().
+				JSONLines("#", Is("2")).
+				Assert("Should print JSON Lines")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Expected stdout: valid JSON\n  Actual: line 2 is not valid JSON: \"oops\""
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
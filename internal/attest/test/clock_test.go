@@ -0,0 +1,102 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// ttl scripts a key that expires a minute after it is set, by the clock in
+// $LC_CLOCK_FILE.
+const (
+	ttlSet = `echo $(( $(cat "$LC_CLOCK_FILE") + 60000 )) > "$1/expiry"`
+	ttlGet = `if [ "$(cat "$LC_CLOCK_FILE")" -lt "$(cat "$1/expiry")" ]; then echo fresh; else echo expired; fi`
+)
+
+func TestVirtualClock(t *testing.T) {
+	tests := []struct {
+		name       string
+		clock      bool
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name:  "Clock File",
+			clock: true,
+			testFunc: func(do *Do) {
+				start := do.Now()
+				do.Exec("-c", `cat "$LC_CLOCK_FILE"`).T().
+					Output(Is(fmt.Sprint(start.UnixMilli()))).
+					Assert("Command should see the virtual time")
+
+				do.Advance(5 * time.Minute)
+				do.Exec("-c", `cat "$LC_CLOCK_FILE"`).T().
+					Output(Is(fmt.Sprint(start.Add(5 * time.Minute).UnixMilli()))).
+					Assert("Command should see the advanced time")
+			},
+			shouldPass: true,
+		},
+		{
+			name:  "Expiry",
+			clock: true,
+			testFunc: func(do *Do) {
+				do.Exec("-c", ttlSet, "_", do.WorkingDir()).T().
+					ExitCode(Is(0)).
+					Assert("Key should be set")
+
+				do.Advance(30 * time.Second)
+				do.Exec("-c", ttlGet, "_", do.WorkingDir()).T().
+					Output(Is("fresh\n")).
+					Assert("Key should live for its TTL")
+
+				do.Advance(time.Minute)
+				do.Exec("-c", ttlGet, "_", do.WorkingDir()).T().
+					Output(Is("expired\n")).
+					Assert("Key should expire after its TTL")
+			},
+			shouldPass: true,
+		},
+		{
+			name:  "Not Advanced",
+			clock: true,
+			testFunc: func(do *Do) {
+				do.Exec("-c", ttlSet, "_", do.WorkingDir()).T().Assert("Key should be set")
+
+				do.Exec("-c", ttlGet, "_", do.WorkingDir()).T().
+					Output(Is("expired\n")).
+					Assert("Should fail when the clock did not move")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Without Clock",
+			testFunc: func(do *Do) {
+				do.Advance(time.Minute)
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Command: "sh", WorkingDir: t.TempDir(), VirtualClock: tt.clock}
+
+			success := New().WithConfig(config).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
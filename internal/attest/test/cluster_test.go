@@ -0,0 +1,163 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// fakeCluster is three nodes sharing a replicated store. Killing the leader
+// hands leadership to the next live node, which loses the store if lossy.
+// With splitBrain, every node claims to lead.
+type fakeCluster struct {
+	mu         sync.Mutex
+	servers    []*httptest.Server
+	leader     int
+	store      map[string]string
+	lossy      bool
+	splitBrain bool
+}
+
+func newFakeCluster(lossy, splitBrain bool) *fakeCluster {
+	c := &fakeCluster{store: make(map[string]string), lossy: lossy, splitBrain: splitBrain}
+	for i := range 3 {
+		c.servers = append(c.servers, httptest.NewServer(c.node(i)))
+	}
+	return c
+}
+
+func (c *fakeCluster) node(i int) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		role := "follower"
+		if i == c.leader || c.splitBrain {
+			role = "leader"
+		}
+		fmt.Fprintf(w, `{"role":%q}`, role)
+	})
+	mux.HandleFunc("PUT /kv/{key}", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if i != c.leader {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		c.store[r.PathValue("key")] = string(body)
+	})
+	mux.HandleFunc("GET /kv/{key}", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		value, ok := c.store[r.PathValue("key")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, value)
+	})
+	return mux
+}
+
+// kill stops the node, electing the next one if it led.
+func (c *fakeCluster) kill(node string) {
+	i := int(node[1] - '1')
+	c.servers[i].Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i == c.leader {
+		c.leader = (i + 1) % len(c.servers)
+		if c.lossy {
+			c.store = make(map[string]string)
+		}
+	}
+}
+
+func (c *fakeCluster) close() {
+	for _, s := range c.servers {
+		s.Close()
+	}
+}
+
+// survivesLeaderChange writes through the leader, kills it, and reads the
+// write back from the rest.
+func survivesLeaderChange(c *fakeCluster) func(*Do) {
+	return func(do *Do) {
+		status := do.Cluster("n1", "n2", "n3").HTTP("GET", "/status").Value("role").Eventually().T().
+			ExactlyOne(Is("leader"))
+		status.Assert("Cluster should elect one leader")
+		leader := status.Leader()
+
+		do.HTTP(leader, "PUT", "/kv/x", "42").T().
+			Status(Is(200)).
+			Assert("Leader should accept the write")
+
+		do.Cluster("n1", "n2", "n3").HTTP("GET", "/kv/x").T().
+			Quorum(Is("42")).
+			Assert("Quorum should have the write")
+
+		c.kill(leader)
+
+		status = do.Cluster("n1", "n2", "n3").HTTP("GET", "/status").Value("role").Eventually().T().
+			ExactlyOne(Is("leader"))
+		status.Assert("Cluster should elect a new leader")
+		if status.Leader() == leader {
+			panic("the dead node still leads")
+		}
+
+		do.Cluster("n1", "n2", "n3").HTTP("GET", "/kv/x").T().
+			Quorum(Is("42")).
+			Assert("Write should survive the leader change")
+	}
+}
+
+func TestCluster(t *testing.T) {
+	tests := []struct {
+		name       string
+		lossy      bool
+		splitBrain bool
+		shouldPass bool
+	}{
+		{name: "Survives Leader Change", shouldPass: true},
+		{name: "Lost Write", lossy: true, shouldPass: false},
+		{name: "Split Brain", splitBrain: true, shouldPass: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeCluster(tt.lossy, tt.splitBrain)
+			defer c.close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, DefaultRetryTimeout: 300 * time.Millisecond}).
+				Setup(func(do *Do) {
+					for i, s := range c.servers {
+						do.MockProcess(fmt.Sprintf("n%d", i+1), strings.Split(s.URL, ":")[2])
+					}
+				}).
+				Test(tt.name, survivesLeaderChange(c)).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// kvStore is the key-value store of the test server. A durable store
+// appends each write to a log before acknowledging it and replays the log
+// when it starts.
+type kvStore struct {
+	mu     sync.Mutex
+	values map[string]string
+	log    *os.File
+}
+
+func newKVStore(path string, durable bool) *kvStore {
+	kv := &kvStore{values: make(map[string]string)}
+	if !durable {
+		return kv
+	}
+
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, _ := strings.Cut(scanner.Text(), "\t")
+			kv.values[key] = value
+		}
+		f.Close()
+	}
+
+	kv.log, _ = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	return kv
+}
+
+func (kv *kvStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/kv/")
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if r.Method == http.MethodPut {
+		body, _ := io.ReadAll(r.Body)
+		if kv.log != nil {
+			fmt.Fprintf(kv.log, "%s\t%s\n", key, body)
+			kv.log.Sync()
+		}
+		kv.values[key] = string(body)
+		return
+	}
+
+	value, ok := kv.values[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	io.WriteString(w, value)
+}
+
+func TestCrash(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Durable Writes",
+			mode: "graceful",
+			testFunc: func(do *Do) {
+				do.Crash("server").
+					Write("PUT", "/kv/key-{{i}}", "value-{{i}}").
+					Read("GET", "/kv/key-{{i}}").
+					Writes(20).
+					T().
+					Assert("Acknowledged writes should survive a crash")
+
+				do.HTTP("server", "GET", "/kv/key-1").T().
+					Body(Is("value-1")).
+					Assert("Server should serve again after the restart")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Lost Writes",
+			mode: "volatile",
+			testFunc: func(do *Do) {
+				do.Crash("server").
+					Write("PUT", "/kv/key-{{i}}", "value-{{i}}").
+					Read("GET", "/kv/key-{{i}}").
+					Writes(20).
+					KillAfter(5).
+					T().
+					Assert("Should fail when writes are only kept in memory")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unacknowledged Writes",
+			mode: "graceful",
+			testFunc: func(do *Do) {
+				do.Crash("server").
+					Write("POST", "/slow", "value-{{i}}").
+					Read("GET", "/kv/key-{{i}}").
+					Writes(3).
+					T().
+					Assert("Should fail when no write is acknowledged")
+			},
+			shouldPass: false,
+		},
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(shutdownEnv, tt.mode)
+
+			config := &Config{
+				Command:                executable,
+				WorkingDir:             t.TempDir(),
+				ExecuteTimeout:         100 * time.Millisecond,
+				ProcessShutdownTimeout: time.Second,
+			}
+
+			success := New().WithConfig(config).
+				Setup(func(do *Do) {
+					do.Start("server")
+				}).
+				Test(tt.name, tt.testFunc).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package attest_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		planDeadline time.Duration
+		testFunc     func(*Do)
+		want         string
+	}{
+		{
+			name: "Hung HTTP Handler",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/hang").Deadline(200 * time.Millisecond).T().
+					Status(Is(200)).
+					Assert("Should fail when the handler never responds")
+			},
+			want: "Plan did not finish within its 200ms deadline\n  Last error:",
+		},
+		{
+			name: "Hung TCP Server",
+			testFunc: func(do *Do) {
+				do.TCP("silent").Send("PING\n").Deadline(200 * time.Millisecond).T().
+					Received(Is("PONG\n")).
+					Assert("Should fail when the server never replies")
+			},
+			want: "Plan did not finish within its 200ms deadline",
+		},
+		{
+			name: "Deadline Bounds Eventually",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/missing").Eventually().Within(5 * time.Second).Deadline(200 * time.Millisecond).T().
+					Status(Is(200)).
+					Assert("Should fail at the deadline rather than the timeout")
+			},
+			want: "Plan did not finish within its 200ms deadline",
+		},
+		{
+			name: "Deadline Cuts Consistently Short",
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/").Consistently().For(5 * time.Second).Deadline(200 * time.Millisecond).T().
+					Status(Is(200)).
+					Assert("Should fail when the period cannot finish")
+			},
+			want: "Plan did not finish within its 200ms deadline",
+		},
+		{
+			name:         "Config Plan Deadline",
+			planDeadline: 200 * time.Millisecond,
+			testFunc: func(do *Do) {
+				do.HTTP("web", "GET", "/hang").T().
+					Assert("Should fail when the handler never responds")
+			},
+			want: "Plan did not finish within its 200ms deadline",
+		},
+		{
+			name:         "Config Plan Deadline Bounds Property",
+			planDeadline: 200 * time.Millisecond,
+			testFunc: func(do *Do) {
+				Property(do, "slow", func(r *rand.Rand) int { return r.IntN(10) }, func(do *Do, ops []int) {
+					time.Sleep(50 * time.Millisecond)
+				}).T().
+					Assert("Should fail when the runs take too long")
+			},
+			want: "Plan did not finish within its 200ms deadline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/hang":
+					<-r.Context().Done()
+				case "/missing":
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			silent := serveTCP(t, func(conn net.Conn) {
+				conn.Read(make([]byte, 64))
+				time.Sleep(5 * time.Second)
+			})
+
+			config := &Config{
+				WorkingDir:     t.TempDir(),
+				ExecuteTimeout: 5 * time.Second,
+				PlanDeadline:   tt.planDeadline,
+				Quiet:          true,
+			}
+
+			start := time.Now()
+			report := New().WithConfig(config).
+				Setup(func(do *Do) {
+					do.MockProcess("web", strings.Split(server.URL, ":")[2])
+					do.MockProcess("silent", silent)
+				}).
+				Test(tt.name, tt.testFunc).
+				RunReport(context.Background())
+
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("test should stop at the deadline, took %s", elapsed)
+			}
+
+			if report.Passed || len(report.Results) != 1 {
+				t.Fatalf("test should fail with one result, got %+v", report.Results)
+			}
+
+			if failure := report.Results[0].Failure; !strings.Contains(failure, tt.want) {
+				t.Errorf("failure should contain %q, got:\n%s", tt.want, failure)
+			}
+		})
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	report := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: 5 * time.Second, Quiet: true}).
+		Setup(func(do *Do) {
+			do.MockProcess("web", strings.Split(server.URL, ":")[2])
+		}).
+		Test("Hung", func(do *Do) {
+			do.HTTP("web", "GET", "/").T().
+				Assert("Should stop when the run is cancelled")
+		}).
+		Test("Never Run", func(do *Do) {}).
+		RunReport(ctx)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run should stop when cancelled, took %s", elapsed)
+	}
+
+	if report.Passed || len(report.Results) != 1 {
+		t.Fatalf("run should stop after the interrupted test, got %+v", report.Results)
+	}
+
+	if failure := report.Results[0].Failure; failure != "interrupted" {
+		t.Errorf("test should be interrupted, got:\n%s", failure)
+	}
+}
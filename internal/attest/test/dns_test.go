@@ -0,0 +1,284 @@
+package attest_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxUDPResponse is the classic DNS limit for responses over UDP.
+const maxUDPResponse = 512
+
+// resolve answers a query for the example.test zone, or returns nil to drop
+// it. Responses over UDP larger than 512 bytes are truncated.
+func resolve(query []byte, udp bool) []byte {
+	var msg dnsmessage.Message
+	if msg.Unpack(query) != nil || len(msg.Questions) != 1 {
+		return nil
+	}
+
+	q := msg.Questions[0]
+	header := func(typ dnsmessage.Type, ttl uint32) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: q.Name, Type: typ, Class: dnsmessage.ClassINET, TTL: ttl}
+	}
+
+	msg.Response = true
+	msg.Authoritative = true
+	msg.Answers = nil
+
+	switch {
+	case q.Name.String() == "example.test." && q.Type == dnsmessage.TypeA:
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header(q.Type, 300), Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}})
+	case q.Name.String() == "example.test." && q.Type == dnsmessage.TypeAAAA:
+		ip := [16]byte{15: 1}
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header(q.Type, 300), Body: &dnsmessage.AAAAResource{AAAA: ip}})
+	case q.Name.String() == "example.test." && q.Type == dnsmessage.TypeTXT:
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header(q.Type, 60), Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}}})
+	case q.Name.String() == "www.example.test.":
+		target := dnsmessage.MustNewName("example.test.")
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header(dnsmessage.TypeCNAME, 3600), Body: &dnsmessage.CNAMEResource{CNAME: target}})
+	case q.Name.String() == "drop.example.test.":
+		return nil
+	case q.Name.String() == "big.example.test.":
+		for i := range 64 {
+			msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header(dnsmessage.TypeA, 300), Body: &dnsmessage.AResource{A: [4]byte{10, 0, 1, byte(i)}}})
+		}
+	default:
+		msg.RCode = dnsmessage.RCodeNameError
+	}
+
+	response, err := msg.Pack()
+	if err != nil {
+		return nil
+	}
+
+	if udp && len(response) > maxUDPResponse {
+		msg.Answers = nil
+		msg.Truncated = true
+		response, _ = msg.Pack()
+	}
+
+	return response
+}
+
+// serveDNS runs the example.test zone over UDP and TCP on the same port.
+func serveDNS(t *testing.T) string {
+	t.Helper()
+
+	var listener net.Listener
+	var conn net.PacketConn
+	for {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err = net.ListenPacket("udp", listener.Addr().String())
+		if err == nil {
+			break
+		}
+		listener.Close()
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		conn.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if response := resolve(buf[:n], true); response != nil {
+				conn.WriteTo(response, addr)
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer c.Close()
+
+				var length [2]byte
+				if _, err := io.ReadFull(c, length[:]); err != nil {
+					return
+				}
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(c, query); err != nil {
+					return
+				}
+
+				response := resolve(query, false)
+				c.Write(binary.BigEndian.AppendUint16(nil, uint16(len(response))))
+				c.Write(response)
+			}()
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestDNS(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "A Record",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test", "A").T().
+					RCode(Is("NOERROR")).
+					Flags(Contains("aa")).
+					Answer(Is("10.0.0.1")).
+					TTL(Is(300)).
+					Assert("Server should answer authoritatively")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "AAAA Record",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test", "aaaa").T().
+					Answer(Is("::1")).
+					Assert("Server should answer IPv6 queries")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "TXT Record",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test.", "TXT").T().
+					Answer(Is("v=spf1 -all")).
+					TTL(AtMost(60)).
+					Assert("Server should join TXT strings")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "CNAME Record",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "www.example.test", "CNAME").T().
+					Answer(Is("example.test.")).
+					AnswerCount(Is(1)).
+					Assert("Server should return the alias target")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Answer Mismatch",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test", "A").T().
+					Answer(Is("10.0.0.2")).
+					Assert("Should fail when no answer matches")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "TTL Mismatch",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test", "A").T().
+					TTL(AtMost(60)).
+					Assert("Should fail when the TTL is too long")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "NXDOMAIN",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "missing.test", "A").T().
+					RCode(Is("NXDOMAIN")).
+					AnswerCount(Is(0)).
+					Assert("Server should reject unknown names")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "RCode Mismatch",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "missing.test", "A").T().
+					RCode(Is("NOERROR")).
+					Assert("Should fail when the name does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Truncated over UDP",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "big.example.test", "A").T().
+					Flags(Contains("tc")).
+					AnswerCount(Is(0)).
+					Assert("Server should truncate large UDP responses")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Complete over TCP",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "big.example.test", "A").TCP().T().
+					Flags(Not(Contains("tc"))).
+					AnswerCount(Is(64)).
+					Assert("Server should send the full response over TCP")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Recursion Desired",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "example.test", "A").NoRecursion().T().
+					Flags(Is("qr aa")).
+					Assert("Server should echo the RD flag")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Response",
+			testFunc: func(do *Do) {
+				do.DNS("svc", "drop.example.test", "A").Timeout(100 * time.Millisecond).T().
+					RCode(Is("NOERROR")).
+					Assert("Should fail when the server does not reply")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveDNS(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir()}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package attest_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+const page = "<p>compress me, compress me, compress me</p>"
+
+// compressingHandler serves page compressed as the client accepts on /page,
+// always uncompressed on /plain, and with a lying Content-Encoding on
+// /broken. /echo answers with the decoded request body.
+func compressingHandler(w http.ResponseWriter, r *http.Request) {
+	accept := r.Header.Get("Accept-Encoding")
+	switch r.URL.Path {
+	case "/page":
+		w.Header().Set("Vary", "Accept-Encoding")
+		switch {
+		case strings.Contains(accept, "gzip"):
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(page))
+			gz.Close()
+		case strings.Contains(accept, "deflate"):
+			w.Header().Set("Content-Encoding", "deflate")
+			zw := zlib.NewWriter(w)
+			zw.Write([]byte(page))
+			zw.Close()
+		default:
+			w.Write([]byte(page))
+		}
+	case "/plain":
+		w.Write([]byte(page))
+	case "/broken":
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte(page))
+	case "/echo":
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		io.Copy(w, body)
+	}
+}
+
+func TestContentEncoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Gzip",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/page").AcceptEncoding("gzip, br").T().
+					Compressed("gzip").
+					Header("Vary", Contains("Accept-Encoding")).
+					Body(Is(page)).
+					Assert("Server should gzip the page")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Deflate",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/page").AcceptEncoding("deflate").T().
+					Compressed("deflate").
+					Body(Is(page)).
+					Assert("Server should deflate the page")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Default Client",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/page").T().
+					Compressed("gzip").
+					Body(Is(page)).
+					Assert("Server should gzip for the default client")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Identity",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/page").AcceptEncoding("identity").T().
+					NotCompressed().
+					Body(Is(page)).
+					Assert("Server should not compress when the client does not accept it")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Not Compressed",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/plain").AcceptEncoding("gzip").T().
+					Compressed("gzip").
+					Assert("Should fail when the response is not compressed")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unexpectedly Compressed",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/page").AcceptEncoding("gzip").T().
+					NotCompressed().
+					Assert("Should fail when the response is compressed")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Invalid Gzip",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/broken").AcceptEncoding("gzip").T().
+					Compressed("gzip").
+					Assert("Should fail when the body is not gzip")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Compressed Request",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/echo", page).CompressBody("gzip").T().
+					Body(Is(page)).
+					Assert("Server should decode a gzip request body")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(compressingHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
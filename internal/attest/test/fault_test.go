@@ -0,0 +1,124 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Retry Through Failures",
+			testFunc: func(do *Do) {
+				do.InjectFault("backend", FailWith(http.StatusServiceUnavailable).Times(2))
+				if body := fetchWithRetry("http://"+do.UpstreamAddr("backend")+"/users", 0); body != "alice,bob" {
+					panic("client gave up with " + body)
+				}
+
+				do.Outbound("backend").Path("/users").T().
+					Count(Is(3)).
+					Assert("Client should retry until the backend recovers")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Scripted Failures",
+			testFunc: func(do *Do) {
+				do.HTTP("direct", "GET", "/flaky").T().Status(Is(500)).Assert("First request should fail")
+				do.HTTP("direct", "GET", "/flaky").T().Status(Is(500)).Assert("Second request should fail")
+				do.HTTP("direct", "GET", "/flaky").T().Status(Is(200)).Assert("Route should recover")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Clear Faults",
+			testFunc: func(do *Do) {
+				do.InjectFault("backend", FailWith(http.StatusInternalServerError))
+				for range 3 {
+					do.HTTP("direct", "GET", "/users").T().
+						Status(Is(500)).
+						Assert("Backend should fail until the faults are cleared")
+				}
+
+				do.ClearFaults("backend")
+				do.HTTP("direct", "GET", "/users").T().
+					Status(Is(200)).
+					Assert("Backend should recover")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Delay",
+			testFunc: func(do *Do) {
+				do.InjectFault("backend", Delay(200*time.Millisecond).Times(1))
+
+				do.HTTP("direct", "GET", "/users").T().
+					Latency(AtLeast(200 * time.Millisecond)).
+					Assert("Backend should be slow once")
+
+				do.HTTP("direct", "GET", "/users").T().
+					Latency(AtMost(100 * time.Millisecond)).
+					Assert("Backend should be fast again")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "TCP Drop",
+			testFunc: func(do *Do) {
+				do.InjectFault("cache", DropConnection().Times(1))
+				if line := exchange(do.UpstreamAddr("cache"), "PING\r\n"); line != "" {
+					panic("dropped PING answered with " + line)
+				}
+				if line := exchange(do.UpstreamAddr("cache"), "PING\r\n"); line != "+PONG\r\n" {
+					panic("PING answered with " + line)
+				}
+			},
+			shouldPass: true,
+		},
+		{
+			name: "HTTP Drop",
+			testFunc: func(do *Do) {
+				do.InjectFault("backend", DropConnection())
+
+				do.HTTP("direct", "GET", "/users").T().
+					Status(Is(200)).
+					Assert("Should fail when the connection drops mid-response")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Upstream("backend", HTTPUpstream().
+					Route("GET /users", Reply(http.StatusOK, "alice,bob")).
+					Route("GET /flaky", Reply(http.StatusInternalServerError, "").Times(2), Reply(http.StatusOK, "ok"))).
+				Upstream("cache", TCPUpstream().Answer("PING\r\n", "+PONG\r\n")).
+				Setup(func(do *Do) {
+					do.MockProcess("direct", strings.Split(do.UpstreamAddr("backend"), ":")[1])
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
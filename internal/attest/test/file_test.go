@@ -0,0 +1,318 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// writeFile writes data to name in the working directory after delay.
+func writeFile(do *Do, name, data string, perm fs.FileMode, delay time.Duration) {
+	path := filepath.Join(do.WorkingDir(), name)
+	write := func() {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(data), perm)
+	}
+
+	if delay == 0 {
+		write()
+		return
+	}
+	time.AfterFunc(delay, write)
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Contents",
+			testFunc: func(do *Do) {
+				writeFile(do, "data/wal.log", "SET a 1\nSET b 2\n", 0644, 0)
+
+				do.File("data/wal.log").T().
+					Exists().
+					Contents(HasPrefix("SET a 1\n"), Contains("SET b 2")).
+					Size(Is(16)).
+					Assert("WAL should hold both writes")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Contents",
+			testFunc: func(do *Do) {
+				writeFile(do, "wal.log", "SET a 1\n", 0644, 0)
+
+				do.File("wal.log").T().
+					Contents(Contains("SET b 2")).
+					Assert("Should fail when a write is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing File",
+			testFunc: func(do *Do) {
+				do.File("snapshot.db").T().
+					Exists().
+					Assert("Should fail when the file was never written")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Expected Missing",
+			testFunc: func(do *Do) {
+				do.File("snapshot.tmp").T().
+					Missing().
+					Assert("Temporary file should be gone")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected File",
+			testFunc: func(do *Do) {
+				writeFile(do, "snapshot.tmp", "partial", 0644, 0)
+
+				do.File("snapshot.tmp").T().
+					Missing().
+					Assert("Should fail when the temporary file is left behind")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Permissions",
+			testFunc: func(do *Do) {
+				writeFile(do, "secret.key", "key", 0600, 0)
+
+				do.File("secret.key").T().
+					Perm(Is(fs.FileMode(0600))).
+					Assert("Key should only be readable by its owner")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Permissions",
+			testFunc: func(do *Do) {
+				writeFile(do, "secret.key", "key", 0644, 0)
+
+				do.File("secret.key").T().
+					Perm(Is(fs.FileMode(0600))).
+					Assert("Should fail when the key is world readable")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Directory",
+			testFunc: func(do *Do) {
+				os.Mkdir(filepath.Join(do.WorkingDir(), "data"), 0755)
+
+				do.File("data").T().
+					Assert("Should fail when the path is a directory")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Eventually Flushed",
+			testFunc: func(do *Do) {
+				writeFile(do, "snapshot.db", "snapshot", 0644, 100*time.Millisecond)
+
+				do.File("snapshot.db").Eventually().T().
+					Contents(Is("snapshot")).
+					Assert("Snapshot should be flushed")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Not Yet Flushed",
+			testFunc: func(do *Do) {
+				writeFile(do, "snapshot.db", "snapshot", 0644, 100*time.Millisecond)
+
+				do.File("snapshot.db").T().
+					Contents(Is("snapshot")).
+					Assert("Should fail when the snapshot is read before the flush")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Absolute Path",
+			testFunc: func(do *Do) {
+				writeFile(do, "abs.txt", "ok", 0644, 0)
+
+				do.File(filepath.Join(do.WorkingDir(), "abs.txt")).T().
+					Contents(Is("ok")).
+					Assert("Absolute paths should be used as is")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+// writeSegments writes n log segments of size bytes under data/.
+func writeSegments(do *Do, n, size int) {
+	for i := range n {
+		writeFile(do, fmt.Sprintf("data/segment-%d.log", i), strings.Repeat("x", size), 0644, 0)
+	}
+	writeFile(do, "data/index/meta.json", "{}", 0644, 0)
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Rotated Segments",
+			testFunc: func(do *Do) {
+				writeSegments(do, 3, 100)
+
+				do.Dir("data").Glob("segment-*.log").T().
+					Count(AtMost(3)).
+					EachSize(AtMost(100)).
+					TotalSize(Is(300)).
+					Files(HasLen[[]string](3)).
+					Assert("Segments should be rotated")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Too Many Segments",
+			testFunc: func(do *Do) {
+				writeSegments(do, 4, 100)
+
+				do.Dir("data").Glob("segment-*.log").T().
+					Count(AtMost(3)).
+					Assert("Should fail when old segments are not removed")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Oversized Segment",
+			testFunc: func(do *Do) {
+				writeSegments(do, 2, 100)
+				writeFile(do, "data/segment-9.log", strings.Repeat("x", 101), 0644, 0)
+
+				do.Dir("data").Glob("segment-*.log").T().
+					EachSize(AtMost(100)).
+					Assert("Should fail when a segment is not rotated in time")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Whole Tree",
+			testFunc: func(do *Do) {
+				writeSegments(do, 2, 10)
+
+				do.Dir("data").T().
+					Count(Is(3)).
+					TotalSize(AtMost(22)).
+					Assert("Tree should include nested files")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Nested Glob",
+			testFunc: func(do *Do) {
+				writeSegments(do, 2, 10)
+
+				do.Dir("data").Glob("*/*.json").T().
+					Files(Satisfies(MatchFunc("index metadata", func(files []string) error {
+						if len(files) != 1 || files[0] != "index/meta.json" {
+							return fmt.Errorf("got %q", files)
+						}
+						return nil
+					}))).
+					Assert("Glob should match paths relative to the directory")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Missing Directory",
+			testFunc: func(do *Do) {
+				do.Dir("data").T().
+					Count(Is(0)).
+					Assert("Should fail when the directory does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Eventually Compacted",
+			testFunc: func(do *Do) {
+				writeSegments(do, 4, 10)
+				time.AfterFunc(100*time.Millisecond, func() {
+					os.Remove(filepath.Join(do.WorkingDir(), "data/segment-0.log"))
+				})
+
+				do.Dir("data").Glob("segment-*.log").Eventually().T().
+					Count(AtMost(3)).
+					Assert("Compaction should remove old segments")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestFileFailure(t *testing.T) {
+	report := New().WithConfig(&Config{WorkingDir: t.TempDir(), Quiet: true}).
+		Test("Binary Contents", func(do *Do) {
+			writeFile(do, "wal.bin", "\x00\x01\x02", 0644, 0)
+
+			do.File("wal.bin").T().
+				Contents(Is("\x00\x01\x03")).
+				Assert("WAL should end with the last record")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Actual contents: 3 bytes\n    0000  00 01 02"
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
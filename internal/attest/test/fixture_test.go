@@ -0,0 +1,210 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// upload is a fixture larger than any single read.
+var upload = strings.Repeat("0123456789abcdef", 16*1024)
+
+var fixtures = fstest.MapFS{
+	"upload.bin":  {Data: []byte(upload)},
+	"frames.bin":  {Data: []byte(upload[:64*1024])},
+	"greeting":    {Data: []byte("HELLO\n")},
+	"status.json": {Data: []byte(`{"size": 262144}`)},
+}
+
+// uploadHandler echoes request bodies on /echo, corrupting one byte on
+// /corrupt, and reports their size on /size.
+func uploadHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	switch r.URL.Path {
+	case "/echo":
+		w.Write(body)
+	case "/corrupt":
+		body[100000] = 'X'
+		w.Write(body)
+	case "/size":
+		fmt.Fprintf(w, `{"size": %d, "length": %d}`, len(body), r.ContentLength)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Upload And Echo",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/echo").BodyFile("upload.bin").T().
+					Status(Is(200)).
+					BodyFile("upload.bin").
+					Assert("Server should echo the upload")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Content Length",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/size").BodyFile("upload.bin").T().
+					JSON("size", Is("262144")).
+					JSON("length", Is("262144")).
+					Assert("Upload should be sent with its length")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Corrupted Echo",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/corrupt").BodyFile("upload.bin").T().
+					BodyFile("upload.bin").
+					Assert("Should fail when a byte differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Other Checkers See The Body",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/echo", `{"size": 262144}`).T().
+					BodyFile("status.json").
+					JSON("size", Is("262144")).
+					Assert("Body should be kept for the JSON checker")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Missing Fixture",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/echo").BodyFile("missing.bin").T().
+					Assert("Should fail when the fixture does not exist")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(uploadHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Fixtures: fixtures}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestTCPFixtures(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(net.Conn)
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Stream Echo",
+			handler: func(conn net.Conn) {
+				io.Copy(conn, conn)
+			},
+			testFunc: func(do *Do) {
+				do.TCP("svc").SendFile("frames.bin").T().
+					ReceivedFile("frames.bin").
+					Assert("Server should echo the stream")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Short Response",
+			handler: func(conn net.Conn) {
+				conn.Write([]byte("HELL"))
+				time.Sleep(time.Second)
+			},
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("hi\n").ReadDeadline(200 * time.Millisecond).T().
+					ReceivedFile("greeting").
+					Assert("Should fail when the response is cut short")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveTCP(t, tt.handler)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Fixtures: fixtures}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestFixtureMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(uploadHandler))
+	defer server.Close()
+
+	port := strings.Split(server.URL, ":")[2]
+
+	report := New().WithConfig(&Config{WorkingDir: t.TempDir(), Quiet: true, Fixtures: fixtures}).
+		Setup(func(do *Do) {
+			do.MockProcess("svc", port)
+		}).
+		Test("Corrupted", func(do *Do) {
+			do.HTTP("svc", "POST", "/corrupt").BodyFile("upload.bin").T().
+				BodyFile("upload.bin").
+				Assert("Server should echo the upload")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Expected response: fixture upload.bin, 262144 bytes\n  Actual response: 262144 bytes\n" +
+		"  First difference at offset 100000 (0x186a0)\n  Expected: \"0123456789abcdef0123456789abcdef\"\n" +
+		"  Actual: \"X123456789abcdef0123456789abcdef\""
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
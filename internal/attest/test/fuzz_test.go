@@ -0,0 +1,135 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// garbage generates up to 64 random bytes, with a NUL only if nul is set.
+func garbage(nul bool) func(r *rand.Rand) []byte {
+	return func(r *rand.Rand) []byte {
+		b := make([]byte, 1+r.IntN(64))
+		for i := range b {
+			b[i] = byte(1 + r.IntN(255))
+		}
+		if nul {
+			b[r.IntN(len(b))] = 0
+		}
+		return b
+	}
+}
+
+// fragileServer answers each line with "+<line>", but a line with a NUL
+// wedges it: later lines get no answer.
+func fragileServer(wedged *atomic.Bool) func(net.Conn) {
+	return func(conn net.Conn) {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			if strings.ContainsRune(scanner.Text(), 0) {
+				wedged.Store(true)
+			}
+			if !wedged.Load() {
+				conn.Write([]byte("+" + scanner.Text() + "\r\n"))
+			}
+		}
+	}
+}
+
+func TestFuzz(t *testing.T) {
+	probe := func(do *Do) Assert {
+		return do.TCP("svc").Send("PING\n").ReadDeadline(100 * time.Millisecond).T().
+			Received(Is("+PING\r\n"))
+	}
+
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Survives",
+			testFunc: func(do *Do) {
+				do.Fuzz("svc", garbage(false)).Runs(10).Probe(probe(do)).T().
+					Assert("Server should survive garbage")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Default Probe",
+			testFunc: func(do *Do) {
+				do.Fuzz("svc", garbage(true)).Runs(5).T().
+					Assert("Server should keep accepting connections")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wedged",
+			testFunc: func(do *Do) {
+				do.Fuzz("svc", garbage(true)).Runs(20).Probe(probe(do)).T().
+					Assert("Should fail when an input wedges the server")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wedged atomic.Bool
+			port := serveTCP(t, fragileServer(&wedged))
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzCorpus(t *testing.T) {
+	workingDir := t.TempDir()
+	var wedged atomic.Bool
+
+	run := func(generate func(r *rand.Rand) []byte) bool {
+		wedged.Store(false)
+		port := serveTCP(t, fragileServer(&wedged))
+
+		return New().WithConfig(&Config{WorkingDir: workingDir, ExecuteTimeout: time.Second}).
+			Setup(func(do *Do) {
+				do.MockProcess("svc", port)
+			}).
+			Test("Fuzz", func(do *Do) {
+				probe := do.TCP("svc").Send("PING\n").ReadDeadline(100 * time.Millisecond).T().
+					Received(Is("+PING\r\n"))
+				do.Fuzz("svc", generate).Runs(10).Probe(probe).T().
+					Assert("Server should survive garbage")
+			}).
+			Run(context.Background())
+	}
+
+	if run(garbage(true)) {
+		t.Fatal("first run should fail on a NUL")
+	}
+	if run(garbage(false)) {
+		t.Error("second run should replay the saved input and fail")
+	}
+}
@@ -0,0 +1,169 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+const goldenPage = "<html>\n<head>\n<title>Home</title>\n</head>\n<body>\n<p>Welcome</p>\n</body>\n</html>\n"
+
+func TestGolden(t *testing.T) {
+	golden := fstest.MapFS{
+		"home.html":     {Data: []byte(goldenPage)},
+		"about.html":    {Data: []byte(strings.Replace(goldenPage, "Welcome", "About us", 1))},
+		"cli/hello.txt": {Data: []byte("Hello World\n")},
+		"cli/wrong.txt": {Data: []byte("Goodbye\n")},
+	}
+
+	tests := []struct {
+		name       string
+		config     *Config
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name:   "Body Matches Golden File",
+			config: &Config{Golden: golden},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(Is(200)).
+					Golden("home.html").
+					Assert("Server should serve the home page")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "Body Differs From Golden File",
+			config: &Config{Golden: golden},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Golden("about.html").
+					Assert("Should fail when the page differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Missing Golden File",
+			config: &Config{Golden: golden},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Golden("missing.html").
+					Assert("Should fail when the golden file does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "No Golden Files",
+			config: &Config{},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Golden("home.html").
+					Assert("Should fail when no golden files are configured")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "CLI Output Golden File",
+			config: &Config{Golden: golden, Command: "echo"},
+			testFunc: func(do *Do) {
+				do.Exec("Hello World").T().
+					Golden("cli/hello.txt").
+					Assert("Output should match the golden file")
+			},
+			shouldPass: true,
+		},
+		{
+			name:   "CLI Output Differs",
+			config: &Config{Golden: golden, Command: "echo"},
+			testFunc: func(do *Do) {
+				do.Exec("Hello World").T().
+					Golden("cli/wrong.txt").
+					Assert("Should fail when the output differs")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(goldenPage))
+			}))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			config := tt.config
+			config.WorkingDir = t.TempDir()
+			config.ExecuteTimeout = time.Second
+
+			success := New().WithConfig(config).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(goldenPage))
+	}))
+	defer server.Close()
+
+	port := strings.Split(server.URL, ":")[2]
+	dir := t.TempDir()
+
+	run := func(update bool) bool {
+		return New().WithConfig(&Config{WorkingDir: t.TempDir(), GoldenDir: dir, UpdateGolden: update}).
+			Setup(func(do *Do) {
+				do.MockProcess("svc", port)
+			}).
+			Test("Golden", func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Golden("pages/home.html").
+					Assert("Server should serve the home page")
+			}).
+			Run(context.Background())
+	}
+
+	if run(false) {
+		t.Fatal("Golden test should fail before the file is written")
+	}
+	if !run(true) {
+		t.Fatal("Golden update should pass")
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "pages", "home.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != goldenPage {
+		t.Errorf("Golden file = %q, want %q", written, goldenPage)
+	}
+
+	if !run(false) {
+		t.Error("Golden test should pass after the update")
+	}
+}
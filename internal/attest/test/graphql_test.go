@@ -0,0 +1,228 @@
+package attest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// graphqlRequest is a GraphQL request body.
+type graphqlRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+// graphqlHandler serves a tiny schema. Queries for hello and greet return
+// data, boom returns an error, and the ticks subscription counts to three
+// over graphql-transport-ws, or fails for ticks(fail: true).
+func graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Upgrade") != "" {
+		graphqlSubscriptions(w, r)
+		return
+	}
+
+	var req graphqlRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.Contains(req.Query, "hello"):
+		fmt.Fprint(w, `{"data":{"hello":"world","user":{"id":"1","tags":["a","b"]}}}`)
+	case strings.Contains(req.Query, "greet"):
+		fmt.Fprintf(w, `{"data":{"greet":"Hello, %s!"}}`, req.Variables["name"])
+	case strings.Contains(req.Query, "boom"):
+		fmt.Fprint(w, `{"data":{"boom":null},"errors":[{"message":"boom exploded","path":["boom"]}]}`)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":[{"message":"Cannot query field"}]}`)
+	}
+}
+
+func graphqlSubscriptions(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"graphql-transport-ws"}})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx := r.Context()
+	send := func(format string, args ...any) {
+		conn.Write(ctx, websocket.MessageText, fmt.Appendf(nil, format, args...))
+	}
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var msg struct {
+			ID      string         `json:"id"`
+			Type    string         `json:"type"`
+			Payload graphqlRequest `json:"payload"`
+		}
+		json.Unmarshal(data, &msg)
+
+		switch msg.Type {
+		case "connection_init":
+			send(`{"type":"connection_ack"}`)
+		case "subscribe":
+			if strings.Contains(msg.Payload.Query, "fail") {
+				send(`{"id":%q,"type":"error","payload":[{"message":"ticks unavailable"}]}`, msg.ID)
+				continue
+			}
+
+			send(`{"type":"ping"}`)
+			for i := 1; i <= 3; i++ {
+				send(`{"id":%q,"type":"next","payload":{"data":{"ticks":%d}}}`, msg.ID, i)
+			}
+			send(`{"id":%q,"type":"complete"}`, msg.ID)
+		}
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Query",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "{ hello user { id tags } }").T().
+					Status(Is(200)).
+					Data("hello", Is("world")).
+					Data("user.id", Is("1")).
+					Data("user.tags", HasLen[string](2)).
+					Assert("Server should resolve the query")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Variables",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "query Greet($name: String!) { greet(name: $name) }").
+					Variables(`{"name": "Ada"}`).
+					Operation("Greet").
+					T().
+					Data("greet", Is("Hello, Ada!")).
+					Assert("Server should use the variables")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Data Mismatch",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "{ hello }").T().
+					Data("hello", Is("mars")).
+					Assert("Should fail when the data differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Expected Error",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "{ boom }").T().
+					Error(Contains("exploded")).
+					Data("boom", IsNull[string]()).
+					Assert("Server should report the resolver error")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Error",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "{ boom }").T().
+					Status(Is(200)).
+					Assert("Should fail when the response has errors")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Error",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "{ hello }").T().
+					Error(Contains("exploded")).
+					Assert("Should fail when no error is returned")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Subscription",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "subscription { ticks }").Subscription().T().
+					Next(JSON("ticks", Is("1"))).
+					Next(JSON("ticks", Is("2"))).
+					Next(JSON("ticks", Is("3"))).
+					Assert("Server should stream each tick")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Subscription Event Mismatch",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "subscription { ticks }").Subscription().T().
+					Next(JSON("ticks", Is("1"))).
+					Next(JSON("ticks", Is("3"))).
+					Assert("Should fail when an event differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Subscription Ends Early",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "subscription { ticks }").Subscription().T().
+					Next(JSON("ticks", Is("1"))).
+					Next(JSON("ticks", Is("2"))).
+					Next(JSON("ticks", Is("3"))).
+					Next(JSON("ticks", Is("4"))).
+					Assert("Should fail when the subscription completes first")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Subscription Error",
+			testFunc: func(do *Do) {
+				do.GraphQL("svc", "subscription { ticks(fail: true) }").Subscription().T().
+					Next(JSON("ticks", Is("1"))).
+					Assert("Should fail when the subscription errors")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(graphqlHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
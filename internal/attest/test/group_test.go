@@ -0,0 +1,163 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// serveIncrements runs a counter. /incr increments it atomically, /racy loses
+// updates by reading and writing it in separate steps, and /odd fails
+// every other request.
+func serveIncrements(t *testing.T) string {
+	t.Helper()
+
+	var mu sync.Mutex
+	count, odd := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/incr":
+			mu.Lock()
+			count++
+			n := count
+			mu.Unlock()
+			w.Write([]byte(strconv.Itoa(n)))
+		case "/racy":
+			mu.Lock()
+			n := count
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			count = n + 1
+			mu.Unlock()
+			w.Write([]byte(strconv.Itoa(n + 1)))
+		case "/odd":
+			mu.Lock()
+			odd++
+			fail := odd%2 == 1
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		case "/value":
+			w.Write([]byte("v1"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.Split(server.URL, ":")[2]
+}
+
+// requests returns n assertions that path answers with 200.
+func requests(do *Do, n int, path string) []Assert {
+	members := make([]Assert, n)
+	for i := range members {
+		members[i] = do.HTTP("svc", "POST", path).T().Status(Is(200))
+	}
+
+	return members
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Concurrent Increments",
+			testFunc: func(do *Do) {
+				do.Group(requests(do, 8, "/incr")...).T().
+					Results(Distinct[string](), HasLen[[]string](8)).
+					Assert("Concurrent increments should each see a new count")
+
+				do.HTTP("svc", "POST", "/incr").T().
+					Body(Is("9")).
+					Assert("Counter should include every increment")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Lost Updates",
+			testFunc: func(do *Do) {
+				do.Group(requests(do, 4, "/racy")...).T().
+					Results(Distinct[string]()).
+					Assert("Should fail when increments overwrite each other")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Quorum",
+			testFunc: func(do *Do) {
+				do.Group(requests(do, 4, "/odd")...).T().
+					Succeeded(AtLeast(2)).
+					Assert("Half the requests should succeed")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "All Must Pass",
+			testFunc: func(do *Do) {
+				do.Group(requests(do, 4, "/odd")...).T().
+					Assert("Should fail when any request fails")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Consistent Results",
+			testFunc: func(do *Do) {
+				do.Group(
+					do.HTTP("svc", "GET", "/value").T(),
+					do.HTTP("svc", "GET", "/value").Eventually().T().Body(Is("v1")),
+					do.TCP("svc").Send("GET /value HTTP/1.0\r\n\r\n").T().Received(Contains("v1")),
+				).T().
+					Results(AllEqual[string]()).
+					Assert("Should fail when the TCP result includes headers")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Equal Results",
+			testFunc: func(do *Do) {
+				do.Group(
+					do.HTTP("svc", "GET", "/value").T(),
+					do.HTTP("svc", "GET", "/value").Eventually().T().Body(Is("v1")),
+				).T().
+					Results(AllEqual[string]()).
+					Assert("Every read should return the same value")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveIncrements(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package attest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// serveCounter runs a test.Counter service built on the well-known wrapper
+// types:
+//
+//	Sum(stream Int64Value) returns (Int64Value)
+//	Count(Int64Value) returns (stream Int64Value)
+//	Echo(stream StringValue) returns (stream StringValue)
+//
+// Count streams 1..n, failing with ResourceExhausted after 5 messages, and
+// streams forever when n is 0.
+func serveCounter(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(srv any, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		switch method {
+		case "/test.Counter/Sum":
+			var sum int64
+			for {
+				n := &wrapperspb.Int64Value{}
+				err := stream.RecvMsg(n)
+				if errors.Is(err, io.EOF) {
+					return stream.SendMsg(wrapperspb.Int64(sum))
+				}
+				if err != nil {
+					return err
+				}
+				sum += n.Value
+			}
+		case "/test.Counter/Count":
+			n := &wrapperspb.Int64Value{}
+			err := stream.RecvMsg(n)
+			if err != nil {
+				return err
+			}
+
+			for i := int64(1); n.Value == 0 || i <= n.Value; i++ {
+				if i > 5 && n.Value != 0 {
+					return status.Error(codes.ResourceExhausted, "count limited to 5")
+				}
+
+				err := stream.SendMsg(wrapperspb.Int64(i))
+				if err != nil {
+					return err
+				}
+				if n.Value == 0 {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+			return nil
+		case "/test.Counter/Echo":
+			for {
+				s := &wrapperspb.StringValue{}
+				err := stream.RecvMsg(s)
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+
+				err = stream.SendMsg(s)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	server := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// writeCounterDescriptors writes a descriptor set for test.Counter.
+func writeCounterDescriptors(t *testing.T) string {
+	t.Helper()
+
+	method := func(name, input, output string, clientStreaming, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(name),
+			InputType:       proto.String(input),
+			OutputType:      proto.String(output),
+			ClientStreaming: proto.Bool(clientStreaming),
+			ServerStreaming: proto.Bool(serverStreaming),
+		}
+	}
+
+	counter := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("counter.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/wrappers.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Counter"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Sum", ".google.protobuf.Int64Value", ".google.protobuf.Int64Value", true, false),
+				method("Count", ".google.protobuf.Int64Value", ".google.protobuf.Int64Value", false, true),
+				method("Echo", ".google.protobuf.StringValue", ".google.protobuf.StringValue", true, true),
+			},
+		}},
+	}
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(wrapperspb.File_google_protobuf_wrappers_proto),
+			counter,
+		},
+	}
+
+	bytes, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "counter.pb")
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGRPCStream(t *testing.T) {
+	descriptors := writeCounterDescriptors(t)
+
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Client Streaming",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Sum").Descriptors(descriptors).
+					Send(`"1"`).Send(`"2"`).Send(`"3"`).T().
+					Message(Is(`"6"`)).
+					Count(Is(1)).
+					Code(Is(codes.OK)).
+					Assert("Server should sum the stream")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Server Streaming",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"3"`).T().
+					Message(Is(`"1"`)).
+					Message(Is(`"2"`)).
+					Message(Is(`"3"`)).
+					Count(Is(3)).
+					Assert("Server should stream each number in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Message Mismatch",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"3"`).T().
+					Message(Is(`"1"`)).
+					Message(Is(`"3"`)).
+					Assert("Should fail when messages arrive out of order")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Stream Ended Early",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"1"`).T().
+					Message(Is(`"1"`)).
+					Message(Is(`"2"`)).
+					Assert("Should fail when the stream ends before the second message")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Count Mismatch",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"2"`).T().
+					Count(Is(3)).
+					Assert("Should fail when the message count differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Trailing Status",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"8"`).T().
+					Count(Is(5)).
+					Code(Is(codes.ResourceExhausted)).
+					StatusMessage(Contains("limited")).
+					Assert("Server should stop the stream at the limit")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Trailing Status Mismatch",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"8"`).T().
+					Code(Is(codes.OK)).
+					Assert("Should fail when the stream ends with an error")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Bidirectional",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Echo").Descriptors(descriptors).
+					Send(`"a"`).Send(`"b"`).T().
+					Message(Is(`"a"`)).
+					Message(Is(`"b"`)).
+					Count(Is(2)).
+					Code(Is(codes.OK)).
+					Assert("Server should echo each message")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Take",
+			testFunc: func(do *Do) {
+				do.GRPCStream("svc", "test.Counter/Count").Descriptors(descriptors).
+					Send(`"0"`).Take(3).T().
+					Message(Is(`"1"`)).
+					Count(Is(3)).
+					Assert("Server should keep streaming")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveCounter(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
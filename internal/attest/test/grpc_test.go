@@ -0,0 +1,214 @@
+package attest_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// serveGRPC runs a health service, echoing the x-request-id metadata in
+// headers and trailers, and optionally server reflection.
+func serveGRPC(t *testing.T, withReflection bool) (string, *health.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	echo := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ids := md.Get("x-request-id"); len(ids) > 0 {
+			grpc.SetHeader(ctx, metadata.Pairs("x-request-id", ids[0]))
+			grpc.SetTrailer(ctx, metadata.Pairs("x-served-by", "test"))
+		}
+
+		return handler(ctx, req)
+	}
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(echo))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	if withReflection {
+		reflection.Register(server)
+	}
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port), healthServer
+}
+
+// writeHealthDescriptors writes a descriptor set for the health service.
+func writeHealthDescriptors(t *testing.T) string {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto)},
+	}
+
+	bytes, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "health.pb")
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGRPC(t *testing.T) {
+	descriptors := writeHealthDescriptors(t)
+
+	tests := []struct {
+		name           string
+		withReflection bool
+		setup          func(*health.Server)
+		testFunc       func(*Do)
+		shouldPass     bool
+	}{
+		{
+			name:           "Reflection",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{}`).T().
+					Code(Is(codes.OK)).
+					JSON("status", Is("SERVING")).
+					Assert("Server should report it is serving")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Descriptor Set",
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "/grpc.health.v1.Health/Check", `{"service": ""}`).Descriptors(descriptors).T().
+					Code(Is(codes.OK)).
+					Response(Contains(`"status":"SERVING"`)).
+					Assert("Server should report it is serving")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Descriptors",
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{}`).T().
+					Code(Is(codes.OK)).
+					Assert("Should fail without reflection or a descriptor set")
+			},
+			shouldPass: false,
+		},
+		{
+			name:           "Status Code",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{"service": "missing"}`).T().
+					Code(Is(codes.NotFound)).
+					StatusMessage(Contains("unknown service")).
+					Assert("Server should not know the service")
+			},
+			shouldPass: true,
+		},
+		{
+			name:           "Status Code Mismatch",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{"service": "missing"}`).T().
+					Code(Is(codes.OK)).
+					Assert("Should fail when the call returns NotFound")
+			},
+			shouldPass: false,
+		},
+		{
+			name:           "Metadata",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{}`).Metadata(H{"x-request-id": "42"}).T().
+					Header("x-request-id", Is("42")).
+					Trailer("x-served-by", Is("test")).
+					Assert("Server should echo the request ID")
+			},
+			shouldPass: true,
+		},
+		{
+			name:           "Metadata Mismatch",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{}`).T().
+					Header("x-request-id", Is("42")).
+					Assert("Should fail when the header is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name:           "Unknown Method",
+			withReflection: true,
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Ping", `{}`).T().
+					Code(Is(codes.OK)).
+					Assert("Should fail when the method does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name:           "Eventually OK",
+			withReflection: true,
+			setup: func(s *health.Server) {
+				s.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+				time.AfterFunc(300*time.Millisecond, func() {
+					s.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+				})
+			},
+			testFunc: func(do *Do) {
+				do.GRPC("svc", "grpc.health.v1.Health/Check", `{}`).Eventually().T().
+					JSON("status", Is("SERVING")).
+					Assert("Server should eventually be serving")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, healthServer := serveGRPC(t, tt.withReflection)
+			if tt.setup != nil {
+				tt.setup(healthServer)
+			}
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// sessionHandler sets a session cookie on /login and clears it on /logout.
+func sessionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/login":
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Cookie")
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/"})
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    "abc123",
+			Path:     "/",
+			MaxAge:   3600,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	case "/logout":
+		http.SetCookie(w, &http.Cookie{Name: "session", Path: "/", MaxAge: -1})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Repeated Headers",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					Header("vary", Is("Accept, Cookie")).
+					HeaderValues("Vary", HasLen[[]string](2)).
+					HeaderValues("Set-Cookie", HasLen[[]string](2)).
+					NoHeader("X-Powered-By").
+					Assert("Server should send both Vary values")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Header Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					Header("Vary", Is("Accept")).
+					Assert("Should fail when a header has more values")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Header Present",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					NoHeader("vary").
+					Assert("Should fail when the header is sent")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Session Cookie Attributes",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					Cookie("session",
+						CookieValue(Matches(`^[a-z0-9]+$`)),
+						HttpOnly(),
+						Secure(),
+						SameSite(http.SameSiteStrictMode),
+						MaxAge(AtLeast(3600)),
+						CookiePath("/"),
+					).
+					Cookie("theme", CookieValue(Is("dark"))).
+					Assert("Server should set a locked-down session cookie")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Cookie Attribute Missing",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					Cookie("theme", HttpOnly()).
+					Assert("Should fail when the cookie is not HttpOnly")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "SameSite Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					Cookie("session", SameSite(http.SameSiteLaxMode)).
+					Assert("Should fail when SameSite differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Cookie Not Set",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Cookie("session").
+					Assert("Should fail when no cookie is set")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Cookie Cleared",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/logout").T().
+					Cookie("session", CookieValue(Is("")), MaxAge(Is(0))).
+					NoCookie("theme").
+					Assert("Server should clear the session cookie")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Cookie",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/login").T().
+					NoCookie("theme").
+					Assert("Should fail when the cookie is set")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(sessionHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
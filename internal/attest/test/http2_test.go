@@ -0,0 +1,170 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// h2cHandler answers with the request protocol. /slow holds each stream
+// open so concurrent streams overlap, and /abort resets the stream.
+func h2cHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/slow":
+		time.Sleep(100 * time.Millisecond)
+	case "/abort":
+		panic(http.ErrAbortHandler)
+	}
+
+	w.Write([]byte(r.Proto))
+}
+
+// serveH2C runs a server speaking HTTP/1.1, and HTTP/2 with prior knowledge
+// when h2c is set.
+func serveH2C(t *testing.T, h2c bool) string {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(h2cHandler))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(h2c)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return strings.Split(server.URL, ":")[2]
+}
+
+func TestHTTP2(t *testing.T) {
+	tests := []struct {
+		name       string
+		h2c        bool
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Prior Knowledge",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").HTTP2().T().
+					Status(Is(200)).
+					Protocol(Is("HTTP/2.0")).
+					Body(Is("HTTP/2.0")).
+					Assert("Server should speak HTTP/2 without an upgrade")
+
+				do.HTTP("svc", "GET", "/").T().
+					Protocol(Is("HTTP/1.1")).
+					Assert("Server should still speak HTTP/1.1")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No HTTP/2 Support",
+			h2c:  false,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").HTTP2().T().
+					Protocol(Is("HTTP/2.0")).
+					Assert("Should fail when the server only speaks HTTP/1.1")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Protocol Mismatch",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Protocol(Is("HTTP/2.0")).
+					Assert("Should fail when the request used HTTP/1.1")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Multiplexed Streams",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/slow").HTTP2().Streams(8).T().
+					Status(Is(200)).
+					Connections(Is(1)).
+					Assert("Server should serve concurrent streams on one connection")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Connections Mismatch",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/slow").HTTP2().Streams(4).T().
+					Connections(Is(4)).
+					Assert("Should fail when the streams share a connection")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Stream Reset",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/abort").HTTP2().T().
+					ErrorCode(Is("INTERNAL_ERROR")).
+					Assert("Server should reset the aborted stream")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Error Code Mismatch",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/abort").HTTP2().T().
+					ErrorCode(Is("REFUSED_STREAM")).
+					Assert("Should fail when the error code differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unexpected Reset",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/abort").HTTP2().T().
+					Status(Is(200)).
+					Assert("Should fail when the stream is reset")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Reset",
+			h2c:  true,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").HTTP2().T().
+					ErrorCode(Is("INTERNAL_ERROR")).
+					Assert("Should fail when the stream succeeds")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveH2C(t, tt.h2c)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
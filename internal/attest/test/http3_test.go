@@ -0,0 +1,176 @@
+package attest_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// selfSignedCert returns a certificate for 127.0.0.1 and localhost.
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// serveHTTP3 runs an HTTP/3 server on UDP and an HTTP/1.1 server on the
+// same TCP port. Over TCP, every path but /plain advertises HTTP/3.
+func serveHTTP3(t *testing.T) string {
+	t.Helper()
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(udp.LocalAddr().(*net.UDPAddr).Port)
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		udp.Close()
+		t.Skipf("TCP port %s is taken: %v", port, err)
+	}
+
+	h3 := &http3.Server{
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}}),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello over " + r.Proto))
+		}),
+	}
+	go h3.Serve(udp)
+
+	h1 := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plain" {
+			h3.SetQUICHeaders(w.Header())
+		}
+		w.Write([]byte("hello over " + r.Proto))
+	})}
+	go h1.Serve(tcp)
+
+	t.Cleanup(func() {
+		h1.Close()
+		h3.Close()
+		udp.Close()
+	})
+
+	return port
+}
+
+func TestHTTP3(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(do *Do, port string)
+		shouldPass bool
+	}{
+		{
+			name: "QUIC Handshake",
+			testFunc: func(do *Do, port string) {
+				do.HTTP("svc", "GET", "/").HTTP3().T().
+					Status(Is(200)).
+					Protocol(Is("HTTP/3.0")).
+					Body(Is("hello over HTTP/3.0")).
+					Assert("Server should answer over HTTP/3")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Alt-Svc Advertised",
+			testFunc: func(do *Do, port string) {
+				do.HTTP("svc", "GET", "/").T().
+					Protocol(Is("HTTP/1.1")).
+					AltSvc(Contains(`h3=":` + port + `"`)).
+					Assert("Server should advertise HTTP/3 on its port")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Alt-Svc Missing",
+			testFunc: func(do *Do, port string) {
+				do.HTTP("svc", "GET", "/plain").T().
+					AltSvc(Contains("h3=")).
+					Assert("Should fail when HTTP/3 is not advertised")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Body Mismatch",
+			testFunc: func(do *Do, port string) {
+				do.HTTP("svc", "GET", "/").HTTP3().T().
+					Body(Is("hello over HTTP/1.1")).
+					Assert("Should fail when the body differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "No QUIC Listener",
+			testFunc: func(do *Do, port string) {
+				do.HTTP("other", "GET", "/").HTTP3().T().
+					Status(Is(200)).
+					Assert("Should fail when nothing answers on UDP")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveHTTP3(t)
+
+			closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			closedPort := strconv.Itoa(closed.LocalAddr().(*net.UDPAddr).Port)
+			closed.Close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+					do.MockProcess("other", closedPort)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do, port)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
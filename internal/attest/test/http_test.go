@@ -12,6 +12,24 @@ import (
 	. "github.com/littleclusters/lc/internal/attest"
 )
 
+// itemsSchema requires a name and a list of items with string ids.
+const itemsSchema = `{
+	"title": "Item list",
+	"type": "object",
+	"required": ["name", "items"],
+	"properties": {
+		"name": {"type": "string"},
+		"items": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["id"],
+				"properties": {"id": {"type": "string"}}
+			}
+		}
+	}
+}`
+
 func TestHTTP(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -414,6 +432,30 @@ func TestHTTP(t *testing.T) {
 			},
 			shouldPass: false,
 		},
+		{
+			name: "AtLeast/AtMost Checker - status within range",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(AtLeast(200), AtMost(299)).
+					Assert("Should pass when status is within range")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "AtLeast/AtMost Checker - status outside range",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(AtLeast(200), AtMost(299)).
+					Assert("Should fail when status is outside range")
+			},
+			shouldPass: false,
+		},
 		{
 			name: "JSON Checker - simple field",
 			handler: func(w http.ResponseWriter, r *http.Request) {
@@ -473,6 +515,202 @@ func TestHTTP(t *testing.T) {
 			},
 			shouldPass: false,
 		},
+		{
+			name: "JSON Checker - JSONPath syntax",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"items":[{"id":"x","tags":["a","b"]},{"id":"y"}],"a.b":true}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					JSON("$.items[0].id", Is("x")).
+					JSON("$.items[*].id", Is(`["x","y"]`)).
+					JSON("$['a.b']", Is("true")).
+					JSON("$.items", HasLen[string](2), IsType("array")).
+					JSON("$", IsType("object")).
+					Assert("Should match fields by JSONPath")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "JSON Checker - presence and types",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"id":7,"name":"a","ok":false,"leader":null}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					JSON("leader", Exists(), IsType("null")).
+					JSON("secret", Not(Exists())).
+					JSON("id", IsType("number"), Not(IsType("string"))).
+					JSON("name", IsType("string")).
+					JSON("ok", IsType("boolean")).
+					Assert("Should check field presence and types")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "JSON Checker - unexpected field",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"id":7,"secret":"hunter2"}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					JSON("$.secret", Not(Exists())).
+					Assert("Should fail when the field is present")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "JSON Checker - type mismatch",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"id":"7"}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					JSON("id", IsType("number")).
+					Assert("Should fail when a number is sent as a string")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "JSON Checker - length of missing field",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					JSON("$.items", HasLen[string](0)).
+					Assert("Should fail when the array is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Schema Checker - valid body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"name":"a","items":[{"id":"x","extra":1}]}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Body(MatchesSchema(itemsSchema)).
+					JSON("items.0", MatchesSchema(`{"type":"object","required":["id"]}`)).
+					Assert("Should pass when the body matches the schema")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Schema Checker - violations",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"items":[{"id":1},{}]}`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Body(MatchesSchema(itemsSchema)).
+					Assert("Should fail when the body breaks the schema")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Schema Checker - invalid JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`not json`))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Body(MatchesSchema(itemsSchema)).
+					Assert("Should fail when the body is not JSON")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Body Matches and Contains",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("<html>\n<title>Home</title>\n<p>Generated at 2024-05-01T10:00:00Z</p>\n</html>\n"))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					BodyMatches(`^<title>Home</title>$`).
+					BodyMatches(`Generated at \d{4}-\d{2}-\d{2}T`).
+					BodyContains("<html>", "</html>").
+					Assert("Should match parts of the body")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Body Matches Mismatch",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("<html>\n<title>About</title>\n</html>\n"))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					BodyMatches(`^<title>Home</title>$`).
+					Assert("Should fail when no line matches")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Body Contains Mismatch",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("hello"))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					BodyContains("hello", "world").
+					Assert("Should fail when a substring is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Responds Within",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("fast"))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(Is(200)).
+					RespondsWithin(time.Second).
+					Latency(AtLeast(time.Duration(0))).
+					Assert("Should pass when the server answers quickly")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Slow Response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte("slow"))
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(Is(200)).
+					RespondsWithin(50 * time.Millisecond).
+					Assert("Should fail when the body takes too long")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Slow Response Eventually",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+			},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().Within(300 * time.Millisecond).T().
+					RespondsWithin(10 * time.Millisecond).
+					Assert("Should fail when every attempt is too slow")
+			},
+			shouldPass: false,
+		},
 		{
 			name: "Multiple Checkers - multiple status checkers",
 			handler: func(w http.ResponseWriter, r *http.Request) {
@@ -565,3 +803,162 @@ func TestHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		testFunc  func(*Do)
+		wantField string
+		wantPath  string
+		wantExp   string
+	}{
+		{
+			name: "Status",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/kv/missing").T().
+					Status(Is(200)).
+					Assert("Server should find the key")
+			},
+			wantField: "status",
+			wantExp:   "200",
+		},
+		{
+			name: "Body",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/kv/missing").T().
+					Body(Is("Nairobi")).
+					Assert("Server should return the value")
+			},
+			wantField: "body",
+			wantExp:   "Nairobi",
+		},
+		{
+			name: "JSON",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/kv/missing").T().
+					JSON("error", Is("gone")).
+					Assert("Server should explain the error")
+			},
+			wantField: "json",
+			wantPath:  "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"key not found"}`))
+			}))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir()}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, tt.testFunc).
+				RunReport(context.Background())
+
+			if report.Passed || len(report.Results) != 1 {
+				t.Fatalf("test should fail with one result, got %+v", report.Results)
+			}
+
+			f := report.Results[0].HTTP
+			if f == nil {
+				t.Fatal("failed HTTP assertion should be recorded")
+			}
+
+			if f.Process != "svc" || f.Method != "GET" || f.Path != "/kv/missing" {
+				t.Errorf("request = %s %s %s, want svc GET /kv/missing", f.Process, f.Method, f.Path)
+			}
+
+			if f.Field != tt.wantField || f.JSONPath != tt.wantPath {
+				t.Errorf("field = %q %q, want %q %q", f.Field, f.JSONPath, tt.wantField, tt.wantPath)
+			}
+
+			if tt.wantExp != "" && f.Expected != tt.wantExp {
+				t.Errorf("expected = %q, want %q", f.Expected, tt.wantExp)
+			}
+
+			if f.Status != 404 || f.Response != `{"error":"key not found"}` {
+				t.Errorf("response = %d %q", f.Status, f.Response)
+			}
+
+			status, body, err := f.Send(context.Background(), server.URL, time.Second)
+			if err != nil || status != f.Status || body != f.Response {
+				t.Errorf("Send() = %d %q %v, want the recorded response", status, body, err)
+			}
+		})
+	}
+}
+
+func TestHTTPRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Nairobi"))
+	}))
+	defer server.Close()
+
+	port := strings.Split(server.URL, ":")[2]
+	recorder := NewRecorder()
+
+	passed := New().WithConfig(&Config{WorkingDir: t.TempDir(), Recorder: recorder}).
+		Setup(func(do *Do) {
+			do.MockProcess("svc", port)
+		}).
+		Test("Record", func(do *Do) {
+			do.HTTP("svc", "PUT", "/kv/kenya:capital", "Nairobi", H{"X-Test": "1"}).T().
+				Status(Is(200)).
+				Assert("Server should accept the value")
+		}).
+		Run(context.Background())
+
+	if !passed {
+		t.Fatal("suite should pass")
+	}
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2: %+v", len(entries), entries)
+	}
+
+	if entries[0].Kind != EntryTest || entries[0].Test != "Record" {
+		t.Errorf("entries[0] = %+v, want test marker", entries[0])
+	}
+
+	e := entries[1]
+	if e.Kind != EntryHTTP || e.Process != "svc" || e.Method != "PUT" || e.Path != "/kv/kenya:capital" ||
+		e.Body != "Nairobi" || e.Headers["X-Test"] != "1" {
+		t.Errorf("entries[1] = %+v, want the PUT request", e)
+	}
+}
+
+func TestHTTPTimeoutScale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	port := strings.Split(server.URL, ":")[2]
+
+	start := time.Now()
+	passed := New().WithConfig(&Config{WorkingDir: t.TempDir(), TimeoutScale: 0.1, Quiet: true}).
+		Setup(func(do *Do) {
+			do.MockProcess("svc", port)
+		}).
+		Test("Scaled", func(do *Do) {
+			do.HTTP("svc", "GET", "/health").Eventually().Within(5 * time.Second).T().
+				Status(Is(200)).
+				Assert("Server should become healthy")
+		}).
+		Run(context.Background())
+
+	if passed {
+		t.Fatal("suite should fail")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("scaled Eventually took %s, want about 500ms", elapsed)
+	}
+}
@@ -0,0 +1,155 @@
+package attest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// bencodedDict matches a bencoded dictionary of byte-string keys and
+// values, e.g. d3:foo3:bare.
+type bencodedDict struct{}
+
+func (bencodedDict) Match(actual string) error {
+	rest, ok := strings.CutPrefix(actual, "d")
+	if !ok {
+		return errors.New("does not start with d")
+	}
+
+	for rest != "e" {
+		for range 2 {
+			var n int
+			if _, err := fmt.Sscanf(rest, "%d:", &n); err != nil {
+				return fmt.Errorf("expected a byte string at %q", rest)
+			}
+
+			start := strings.Index(rest, ":") + 1
+			if start+n > len(rest) {
+				return fmt.Errorf("byte string at %q is truncated", rest)
+			}
+			rest = rest[start+n:]
+		}
+
+		if rest == "" {
+			return errors.New("does not end with e")
+		}
+	}
+
+	return nil
+}
+
+func (bencodedDict) Describe() string {
+	return "a bencoded dictionary"
+}
+
+func TestMatcher(t *testing.T) {
+	even := MatchFunc("an even number", func(actual int) error {
+		if actual%2 != 0 {
+			return fmt.Errorf("%d is odd", actual)
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		config     *Config
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Custom Body Matcher",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/dict").T().
+					Status(Satisfies(even)).
+					Body(Satisfies[string](bencodedDict{})).
+					Assert("Server should return a bencoded dictionary")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Custom Body Matcher Fails",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/truncated").T().
+					Body(Satisfies[string](bencodedDict{})).
+					Assert("Should fail when the dictionary is truncated")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Custom Status Matcher Fails",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/missing").T().
+					Status(Satisfies(MatchFunc("a server error", func(actual int) error {
+						if actual < 500 {
+							return fmt.Errorf("%d is not a server error", actual)
+						}
+						return nil
+					}))).
+					Assert("Should fail when the status is a client error")
+			},
+			shouldPass: false,
+		},
+		{
+			name:   "Custom CLI Matchers",
+			config: &Config{Command: "echo"},
+			testFunc: func(do *Do) {
+				do.Exec("d4:name5:alicee").T().
+					ExitCode(Satisfies(even)).
+					Output(Satisfies(MatchFunc("a dictionary line", func(actual string) error {
+						return bencodedDict{}.Match(strings.TrimSuffix(actual, "\n"))
+					}))).
+					Assert("Output should be a bencoded dictionary")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/dict":
+					w.Write([]byte("d3:foo3:bar4:spam4:eggse"))
+				case "/truncated":
+					w.Write([]byte("d3:foo10:bare"))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			config := &Config{}
+			if tt.config != nil {
+				config = tt.config
+			}
+			config.WorkingDir = t.TempDir()
+			config.ExecuteTimeout = time.Second
+
+			success := New().WithConfig(config).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package attest_test
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// cacheItem is a stored memcached value.
+type cacheItem struct {
+	value string
+	flags string
+}
+
+// serveMemcached runs a minimal memcached text protocol server. Keys
+// starting with "chatty" ignore noreply, and "broken" values are framed
+// with the wrong length.
+func serveMemcached(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	items := map[string]cacheItem{"broken": {value: "oops"}}
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			fields := strings.Fields(line)
+			noreply := fields[len(fields)-1] == "noreply" && !strings.HasPrefix(fields[1], "chatty")
+			reply := func(s string) {
+				if !noreply {
+					io.WriteString(conn, s+"\r\n")
+				}
+			}
+
+			mu.Lock()
+			switch fields[0] {
+			case "set", "add":
+				size, _ := strconv.Atoi(fields[4])
+				data := make([]byte, size+2)
+				io.ReadFull(r, data)
+
+				if _, ok := items[fields[1]]; ok && fields[0] == "add" {
+					reply("NOT_STORED")
+					break
+				}
+				items[fields[1]] = cacheItem{value: string(data[:size]), flags: fields[2]}
+				reply("STORED")
+			case "get":
+				if item, ok := items[fields[1]]; ok {
+					size := len(item.value)
+					if fields[1] == "broken" {
+						size++
+					}
+					fmt.Fprintf(conn, "VALUE %s %s %d\r\n%s\r\n", fields[1], cmp.Or(item.flags, "0"), size, item.value)
+				}
+				io.WriteString(conn, "END\r\n")
+			case "delete":
+				if _, ok := items[fields[1]]; !ok {
+					reply("NOT_FOUND")
+					break
+				}
+				delete(items, fields[1])
+				reply("DELETED")
+			case "incr", "decr":
+				item, ok := items[fields[1]]
+				if !ok {
+					reply("NOT_FOUND")
+					break
+				}
+				n, _ := strconv.ParseUint(item.value, 10, 64)
+				delta, _ := strconv.ParseUint(fields[2], 10, 64)
+				if fields[0] == "incr" {
+					n += delta
+				} else {
+					n -= min(n, delta)
+				}
+				item.value = strconv.FormatUint(n, 10)
+				items[fields[1]] = item
+				reply(item.value)
+			case "version":
+				io.WriteString(conn, "VERSION 1.6.0\r\n")
+			default:
+				io.WriteString(conn, "ERROR\r\n")
+			}
+			mu.Unlock()
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestMemcached(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Set and Get",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("greeting", "hello").Flags(42).Expire(60).T().
+					Reply(Is("STORED")).
+					Assert("Server should store the value")
+
+				do.Memcached("svc").Get("greeting").T().
+					Value(Is("hello")).
+					ValueFlags(Is(42)).
+					Reply(Is("END")).
+					Assert("Server should return the value with its flags")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Value Mismatch",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("greeting", "hello").T().Reply(Is("STORED")).Assert("Server should store the value")
+				do.Memcached("svc").Get("greeting").T().
+					Value(Is("goodbye")).
+					Assert("Should fail when the value differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Miss",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Get("missing").T().
+					Miss().
+					Reply(Is("END")).
+					Assert("Server should return no value for unknown keys")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Hit",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("greeting", "hello").T().Reply(Is("STORED")).Assert("Server should store the value")
+				do.Memcached("svc").Get("greeting").T().
+					Miss().
+					Assert("Should fail when the key exists")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Add Existing",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("greeting", "hello").T().Reply(Is("STORED")).Assert("Server should store the value")
+				do.Memcached("svc").Add("greeting", "again").T().
+					Reply(Is("NOT_STORED")).
+					Assert("Server should not overwrite on add")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Delete",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("greeting", "hello").T().Reply(Is("STORED")).Assert("Server should store the value")
+				do.Memcached("svc").Delete("greeting").T().Reply(Is("DELETED")).Assert("Server should delete the key")
+				do.Memcached("svc").Delete("greeting").T().Reply(Is("NOT_FOUND")).Assert("Server should report the missing key")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Incr and Decr",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("counter", "10").T().Reply(Is("STORED")).Assert("Server should store the counter")
+				do.Memcached("svc").Incr("counter", 5).T().Reply(Is("15")).Assert("Server should increment")
+				do.Memcached("svc").Decr("counter", 20).T().Reply(Is("0")).Assert("Server should clamp at zero")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Reply",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("quiet", "shh").NoReply().T().Assert("Server should not answer noreply")
+				do.Memcached("svc").Get("quiet").T().Value(Is("shh")).Assert("Server should still store the value")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Reply",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Set("chatty", "hi").NoReply().T().
+					Assert("Should fail when the server answers noreply")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Bad Framing",
+			testFunc: func(do *Do) {
+				do.Memcached("svc").Get("broken").T().
+					Value(Is("oops")).
+					Assert("Should fail when the data length is wrong")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveMemcached(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: 500 * time.Millisecond}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
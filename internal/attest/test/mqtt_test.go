@@ -0,0 +1,287 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// mqttPacket encodes a control packet with a short remaining length.
+func mqttPacket(header byte, body []byte) []byte {
+	return append([]byte{header, byte(len(body))}, body...)
+}
+
+// topicMatches reports whether topic matches a filter with + and # wildcards.
+func topicMatches(filter, topic string) bool {
+	f, t := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range f {
+		if level == "#" {
+			return true
+		}
+		if i >= len(t) || (level != "+" && level != t[i]) {
+			return false
+		}
+	}
+
+	return len(f) == len(t)
+}
+
+// broker is a minimal MQTT 3.1.1 broker. It rejects the user "banned",
+// grants at most QoS 1, and does not route topics under "blackhole/".
+type broker struct {
+	mu   sync.Mutex
+	subs map[net.Conn][]string
+}
+
+func (b *broker) handle(conn net.Conn) {
+	defer conn.Close()
+	defer func() {
+		b.mu.Lock()
+		delete(b.subs, conn)
+		b.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		length, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case 1:
+			code := byte(0)
+			if strings.Contains(string(body), "banned") {
+				code = 5
+			}
+			conn.Write(mqttPacket(0x20, []byte{0, code}))
+		case 8:
+			var filters []string
+			granted := append([]byte{}, body[:2]...)
+			for rest := body[2:]; len(rest) > 2; {
+				n := int(binary.BigEndian.Uint16(rest))
+				filters = append(filters, string(rest[2:2+n]))
+				granted = append(granted, min(rest[2+n], 1))
+				rest = rest[3+n:]
+			}
+
+			b.mu.Lock()
+			b.subs[conn] = append(b.subs[conn], filters...)
+			b.mu.Unlock()
+			conn.Write(mqttPacket(0x90, granted))
+		case 3:
+			qos := (header >> 1) & 0x03
+			n := int(binary.BigEndian.Uint16(body))
+			topic, rest := string(body[2:2+n]), body[2+n:]
+			if qos > 0 {
+				conn.Write(mqttPacket(0x40, rest[:2]))
+				rest = rest[2:]
+			}
+			if strings.HasPrefix(topic, "blackhole/") {
+				continue
+			}
+
+			out := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+			out = append(append(out, topic...), rest...)
+
+			b.mu.Lock()
+			for sub, filters := range b.subs {
+				for _, filter := range filters {
+					if topicMatches(filter, topic) {
+						sub.Write(mqttPacket(0x30, out))
+						break
+					}
+				}
+			}
+			b.mu.Unlock()
+		case 14:
+			return
+		}
+	}
+}
+
+// serveMQTT runs the broker.
+func serveMQTT(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	b := &broker{subs: make(map[net.Conn][]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.handle(conn)
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestMQTT(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Publish and Receive",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/+", 0).
+					Publish("sensors/temp", "21.5", 0).
+					T().
+					Message("sensors/temp", Is("21.5")).
+					Assert("Broker should deliver to matching subscribers")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "QoS 1",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/#", 1).
+					Publish("sensors/a", "1", 1).
+					Publish("sensors/b/c", "2", 1).
+					T().
+					GrantedQoS(Is(1)).
+					Message("sensors/a", Is("1")).
+					Message("sensors/b/c", Is("2")).
+					Assert("Broker should acknowledge and deliver in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Granted QoS Mismatch",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/#", 2).
+					T().
+					GrantedQoS(Is(2)).
+					Assert("Should fail when the broker downgrades the subscription")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Payload Mismatch",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/+", 0).
+					Publish("sensors/temp", "21.5", 0).
+					T().
+					Message("sensors/temp", Is("22")).
+					Assert("Should fail when the payload differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Not Delivered",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("blackhole/#", 0).
+					Publish("blackhole/x", "lost", 1).
+					Window(100*time.Millisecond).
+					T().
+					Message("blackhole/x", Is("lost")).
+					Assert("Should fail when the broker drops the message")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "No Matching Subscription",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/+", 0).
+					Publish("alerts/fire", "!", 0).
+					Window(100 * time.Millisecond).
+					T().
+					Count(Is(0)).
+					Assert("Broker should not deliver unmatched topics")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Count Mismatch",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Subscribe("sensors/+", 0).
+					Publish("sensors/a", "1", 0).
+					Window(100 * time.Millisecond).
+					T().
+					Count(Is(2)).
+					Assert("Should fail when fewer messages arrive")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Connection Refused",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Credentials("banned", "secret").
+					Publish("sensors/a", "1", 0).
+					T().
+					ConnAck(Is(5)).
+					Assert("Broker should reject banned users")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Refusal",
+			testFunc: func(do *Do) {
+				do.MQTT("svc").
+					Credentials("banned", "secret").
+					Subscribe("sensors/+", 0).
+					T().
+					Count(Is(0)).
+					Assert("Should fail when the broker refuses the connection")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveMQTT(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// uploadStore accepts multipart uploads on POST /upload, echoing the parts
+// back as multipart and storing files for GET /files/{name}. POST /plain
+// answers the upload with its field names as text.
+func uploadStore() http.Handler {
+	var mu sync.Mutex
+	files := make(map[string][]byte)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		echo := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", echo.FormDataContentType())
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+
+			data, _ := io.ReadAll(part)
+			if part.FileName() != "" {
+				mu.Lock()
+				files[part.FileName()] = data
+				mu.Unlock()
+			}
+
+			var pw io.Writer
+			if part.FileName() != "" {
+				pw, _ = echo.CreateFormFile(part.FormName(), part.FileName())
+			} else {
+				pw, _ = echo.CreateFormField(part.FormName())
+			}
+			pw.Write(data)
+		}
+		echo.Close()
+	})
+	mux.HandleFunc("POST /plain", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		fmt.Fprint(w, r.FormValue("title"))
+	})
+	mux.HandleFunc("GET /files/{name}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		data, ok := files[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	})
+
+	return mux
+}
+
+func TestMultipart(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Upload And Echo",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/upload").
+					Multipart(
+						FormField("title", "Notes"),
+						FormFile("file", "notes.txt", []byte("hello\n")).WithContentType("text/plain"),
+					).T().
+					Status(Is(200)).
+					Part("title", Is("Notes")).
+					Part("file", Is("hello\n")).
+					PartFilename("file", Is("notes.txt")).
+					Assert("Server should echo every part")
+
+				do.HTTP("svc", "GET", "/files/notes.txt").T().
+					Body(Is("hello\n")).
+					Assert("Server should store the file")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Fixture File",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/upload").
+					Multipart(FormFixture("file", "upload.bin", "upload.bin")).T().
+					Part("file", HasLen[string](len(upload))).
+					Assert("Server should accept a large file")
+
+				do.HTTP("svc", "GET", "/files/upload.bin").T().
+					BodyFile("upload.bin").
+					Assert("Server should store the file intact")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Form Value",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/plain").
+					Multipart(FormField("title", `Say "hi"`)).T().
+					Body(Is(`Say "hi"`)).
+					Assert("Server should parse the field")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Part",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/upload").
+					Multipart(FormField("title", "Notes")).T().
+					Part("title", Is("Drafts")).
+					Assert("Should fail when a part differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Part",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/upload").
+					Multipart(FormField("title", "Notes")).T().
+					Part("file", Exists()).
+					Assert("Should fail when a part is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Not Multipart",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/plain").
+					Multipart(FormField("title", "Notes")).T().
+					Part("title", Is("Notes")).
+					Assert("Should fail when the response is not multipart")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(uploadStore())
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Fixtures: fixtures}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
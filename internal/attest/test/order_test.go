@@ -0,0 +1,165 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// serveReplicated runs a store whose /write acknowledges after 200ms, then
+// makes the value readable from /read. With early set, the value is readable
+// before the acknowledgement, like a replica serving an uncommitted write.
+func serveReplicated(t *testing.T, early bool) string {
+	t.Helper()
+
+	var mu sync.Mutex
+	var value string
+	store := func(v string) {
+		mu.Lock()
+		defer mu.Unlock()
+		value = v
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/write":
+			if early {
+				store("v1")
+			}
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("OK"))
+			w.(http.Flusher).Flush()
+			store("v1")
+		case "/read":
+			mu.Lock()
+			defer mu.Unlock()
+			w.Write([]byte(value))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.Split(server.URL, ":")[2]
+}
+
+func TestOrdering(t *testing.T) {
+	tests := []struct {
+		name       string
+		early      bool
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Read After Acknowledged Write",
+			testFunc: func(do *Do) {
+				do.Concurrently(
+					func() {
+						do.HTTP("primary", "POST", "/write").T().
+							Status(Is(200)).
+							Mark("ack").
+							Assert("Primary should acknowledge the write")
+					},
+					func() {
+						do.HTTP("replica", "GET", "/read").Eventually().T().
+							Body(Is("v1")).
+							After("ack").
+							Assert("Replica should only return the write once acknowledged")
+					},
+				)
+			},
+			shouldPass: true,
+		},
+		{
+			name:  "Read Before Acknowledgement",
+			early: true,
+			testFunc: func(do *Do) {
+				do.Concurrently(
+					func() {
+						do.HTTP("primary", "POST", "/write").T().
+							Mark("ack").
+							Assert("Primary should acknowledge the write")
+					},
+					func() {
+						do.HTTP("replica", "GET", "/read").Eventually().T().
+							Body(Is("v1")).
+							After("ack").
+							Assert("Should fail when the replica returns the write early")
+					},
+				)
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Mark",
+			testFunc: func(do *Do) {
+				do.HTTP("replica", "GET", "/read").T().
+					After("ack").
+					Assert("Should fail when nothing was marked")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Happens Before",
+			testFunc: func(do *Do) {
+				do.HTTP("primary", "POST", "/write").T().
+					Mark("ack").
+					Assert("Primary should acknowledge the write")
+				do.Mark("read")
+
+				do.HappensBefore("ack", "read")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Happens Before Violated",
+			testFunc: func(do *Do) {
+				do.Mark("read")
+				do.HTTP("primary", "POST", "/write").T().
+					Mark("ack").
+					Assert("Primary should acknowledge the write")
+
+				do.HappensBefore("ack", "read")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unmarked Failure",
+			testFunc: func(do *Do) {
+				do.HTTP("primary", "POST", "/write").T().
+					Status(Is(500)).
+					Mark("ack").
+					Assert("Should fail when the write fails")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveReplicated(t, tt.early)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("primary", port)
+					do.MockProcess("replica", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package attest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// fetchWithRetry stands in for an implementation's API client: it GETs url
+// with a bearer token, retrying failures up to three times with a backoff.
+func fetchWithRetry(url string, backoff time.Duration) string {
+	for range 3 {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer token")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return string(body)
+			}
+		}
+		time.Sleep(backoff)
+	}
+
+	return ""
+}
+
+func TestOutbound(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Retries",
+			testFunc: func(do *Do) {
+				api := do.Intercept("api").FailFirst(2, http.StatusServiceUnavailable)
+				go fetchWithRetry(api.URL()+"/users?page=2", 50*time.Millisecond)
+
+				do.Outbound("api").Method("GET").Path("/users").Eventually().T().
+					Count(Is(3)).
+					Header("Authorization", Is("Bearer token")).
+					Query(Is("page=2")).
+					Gaps(AtLeast(40 * time.Millisecond)).
+					Assert("Client should retry with a backoff")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Forwarded",
+			testFunc: func(do *Do) {
+				api := do.Intercept("api", "backend")
+				if body := fetchWithRetry(api.URL()+"/users", 0); body != "alice,bob" {
+					panic("forwarded response was " + body)
+				}
+
+				do.Outbound("api").T().
+					Count(Is(1)).
+					NoHeader("Cookie").
+					Assert("Client should fetch the users once")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Request",
+			testFunc: func(do *Do) {
+				do.Intercept("api")
+
+				do.Outbound("api").Path("/users").T().
+					Assert("Should fail when the client sends nothing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Too Few Retries",
+			testFunc: func(do *Do) {
+				api := do.Intercept("api").FailFirst(5, http.StatusServiceUnavailable)
+				fetchWithRetry(api.URL()+"/users", 0)
+
+				do.Outbound("api").T().
+					Count(Is(5)).
+					Assert("Should fail when the client gives up early")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Wrong Header",
+			testFunc: func(do *Do) {
+				api := do.Intercept("api")
+				fetchWithRetry(api.URL()+"/users", 0)
+
+				do.Outbound("api").T().
+					Header("Authorization", Is("Bearer other")).
+					Assert("Should fail when a header differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "No Backoff",
+			testFunc: func(do *Do) {
+				api := do.Intercept("api").FailFirst(2, http.StatusServiceUnavailable)
+				fetchWithRetry(api.URL()+"/users", 0)
+
+				do.Outbound("api").T().
+					Gaps(AtLeast(time.Second)).
+					Assert("Should fail when retries do not back off")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("alice,bob"))
+			}))
+			defer backend.Close()
+
+			port := strings.Split(backend.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("backend", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// meshCluster is three nodes reaching each other through links. A node
+// leads while it reaches a majority and no lower node, unless stubborn,
+// where n1 leads regardless.
+type meshCluster struct {
+	mu       sync.Mutex
+	servers  []*httptest.Server
+	peers    [][]string
+	stubborn bool
+}
+
+func newMeshCluster(stubborn bool) *meshCluster {
+	c := &meshCluster{stubborn: stubborn, peers: make([][]string, 3)}
+	for i := range 3 {
+		c.servers = append(c.servers, httptest.NewServer(c.node(i)))
+	}
+	return c
+}
+
+func (c *meshCluster) node(i int) http.Handler {
+	client := &http.Client{Timeout: 200 * time.Millisecond}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		peers := c.peers[i]
+		c.mu.Unlock()
+
+		reachable := make([]bool, len(peers))
+		var wg sync.WaitGroup
+		for j, peer := range peers {
+			if peer == "" {
+				continue
+			}
+			wg.Go(func() {
+				resp, err := client.Get("http://" + peer + "/ping")
+				if err == nil {
+					resp.Body.Close()
+					reachable[j] = true
+				}
+			})
+		}
+		wg.Wait()
+
+		votes, lower := 1, false
+		for j, ok := range reachable {
+			if ok {
+				votes++
+				lower = lower || j < i
+			}
+		}
+
+		role := "follower"
+		if (votes >= 2 && !lower) || (c.stubborn && i == 0) {
+			role = "leader"
+		}
+		fmt.Fprintf(w, `{"role":%q}`, role)
+	})
+	return mux
+}
+
+// link gives every node the addresses of its peers through do's links.
+func (c *meshCluster) link(do *Do) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.servers {
+		c.peers[i] = make([]string, len(c.servers))
+		for j := range c.servers {
+			if i != j {
+				c.peers[i][j] = do.Link(fmt.Sprintf("n%d", i+1), fmt.Sprintf("n%d", j+1))
+			}
+		}
+	}
+}
+
+func (c *meshCluster) close() {
+	for _, s := range c.servers {
+		s.Close()
+	}
+}
+
+// stepsDownWhenCut checks that n1 leads, loses leadership while cut off
+// from the majority, and regains it once healed.
+func stepsDownWhenCut(cut func(do *Do)) func(*Do) {
+	return func(do *Do) {
+		status := func() *ClusterAssert {
+			return do.Cluster("n1", "n2", "n3").HTTP("GET", "/status").Value("role").Eventually().T().
+				ExactlyOne(Is("leader"))
+		}
+
+		before := status()
+		before.Assert("Cluster should elect one leader")
+		if before.Leader() != "n1" {
+			panic("n1 should lead first")
+		}
+
+		cut(do)
+
+		during := status()
+		during.Assert("Majority side should elect its own leader")
+		if during.Leader() == "n1" {
+			panic("n1 leads without a majority")
+		}
+
+		do.Heal()
+
+		after := status()
+		after.Assert("Cluster should settle on one leader after healing")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	tests := []struct {
+		name       string
+		cut        func(do *Do)
+		stubborn   bool
+		shouldPass bool
+	}{
+		{
+			name:       "Minority Steps Down",
+			cut:        func(do *Do) { do.Partition([]string{"n1"}, []string{"n2", "n3"}) },
+			shouldPass: true,
+		},
+		{
+			name:       "Isolated Leader Steps Down",
+			cut:        func(do *Do) { do.Isolate("n1") },
+			shouldPass: true,
+		},
+		{
+			name:       "Stubborn Leader",
+			cut:        func(do *Do) { do.Partition([]string{"n1"}, []string{"n2", "n3"}) },
+			stubborn:   true,
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newMeshCluster(tt.stubborn)
+			defer c.close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, DefaultRetryTimeout: 2 * time.Second}).
+				Setup(func(do *Do) {
+					for i, s := range c.servers {
+						do.MockProcess(fmt.Sprintf("n%d", i+1), strings.Split(s.URL, ":")[2])
+					}
+					c.link(do)
+				}).
+				Test(tt.name, stepsDownWhenCut(tt.cut)).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// pgMessage encodes a backend message.
+func pgMessage(typ byte, body []byte) []byte {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	return append(msg, body...)
+}
+
+// pgRowDescription encodes a RowDescription with text columns.
+func pgRowDescription(columns ...string) []byte {
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(columns)))
+	for _, column := range columns {
+		body = append(append(body, column...), 0)
+		body = append(body, make([]byte, 18)...)
+	}
+
+	return pgMessage('T', body)
+}
+
+// pgDataRow encodes a DataRow, with nil for NULL.
+func pgDataRow(values ...*string) []byte {
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(values)))
+	for _, value := range values {
+		if value == nil {
+			body = binary.BigEndian.AppendUint32(body, 0xFFFFFFFF)
+			continue
+		}
+		body = binary.BigEndian.AppendUint32(body, uint32(len(*value)))
+		body = append(body, *value...)
+	}
+
+	return pgMessage('D', body)
+}
+
+// pgErrorResponse encodes an ErrorResponse.
+func pgErrorResponse(code, message string) []byte {
+	body := []byte("SERROR\x00C" + code + "\x00M" + message + "\x00\x00")
+	return pgMessage('E', body)
+}
+
+// readPGMessage reads a typed frontend message.
+func readPGMessage(r *bufio.Reader) (byte, string, error) {
+	var header [5]byte
+	_, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return 0, "", err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+	_, err = io.ReadFull(r, body)
+	return header[0], strings.TrimSuffix(string(body), "\x00"), err
+}
+
+// servePG runs a minimal PostgreSQL server with a users table. The user
+// "secret" must send the password "hunter2" in cleartext, and "hashed"
+// the same password with MD5.
+func servePG(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	ready := pgMessage('Z', []byte{'I'})
+	alice := "alice"
+	one, two := "1", "2"
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return
+		}
+		startup := make([]byte, binary.BigEndian.Uint32(length[:])-4)
+		if _, err := io.ReadFull(r, startup); err != nil {
+			return
+		}
+		params := strings.Split(string(startup[4:]), "\x00")
+		user := params[1]
+
+		salt := []byte{1, 2, 3, 4}
+		var expected string
+		switch user {
+		case "secret":
+			conn.Write(pgMessage('R', binary.BigEndian.AppendUint32(nil, 3)))
+			expected = "hunter2"
+		case "hashed":
+			conn.Write(pgMessage('R', append(binary.BigEndian.AppendUint32(nil, 5), salt...)))
+			inner := md5.Sum([]byte("hunter2" + user))
+			outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+			expected = "md5" + hex.EncodeToString(outer[:])
+		}
+
+		if expected != "" {
+			_, password, err := readPGMessage(r)
+			if err != nil {
+				return
+			}
+			if password != expected {
+				conn.Write(pgErrorResponse("28P01", "password authentication failed for user \""+user+"\""))
+				return
+			}
+		}
+
+		conn.Write(pgMessage('R', binary.BigEndian.AppendUint32(nil, 0)))
+		conn.Write(ready)
+
+		for {
+			typ, query, err := readPGMessage(r)
+			if err != nil || typ == 'X' {
+				return
+			}
+
+			switch {
+			case query == "SELECT 1":
+				conn.Write(pgRowDescription("?column?"))
+				conn.Write(pgDataRow(&one))
+				conn.Write(pgMessage('C', []byte("SELECT 1\x00")))
+			case query == "SELECT id, name FROM users":
+				conn.Write(pgRowDescription("id", "name"))
+				conn.Write(pgDataRow(&one, &alice))
+				conn.Write(pgDataRow(&two, nil))
+				conn.Write(pgMessage('C', []byte("SELECT 2\x00")))
+			case strings.HasPrefix(query, "INSERT INTO users"):
+				conn.Write(pgMessage('C', []byte("INSERT 0 1\x00")))
+			default:
+				conn.Write(pgErrorResponse("42P01", "relation does not exist"))
+			}
+			conn.Write(ready)
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestPostgres(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Select",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT id, name FROM users").T().
+					Columns(Is("id, name")).
+					RowCount(Is(2)).
+					Cell(0, "name", Is("alice")).
+					Cell(1, "name", Is("NULL")).
+					CommandTag(Is("SELECT 2")).
+					Assert("Server should return both users")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Cell Mismatch",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT id, name FROM users").T().
+					Cell(1, "name", Is("bob")).
+					Assert("Should fail when the value differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Row",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").T().
+					Cell(1, "?column?", Is("1")).
+					Assert("Should fail when the row does not exist")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Row Count Mismatch",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").T().
+					RowCount(Is(2)).
+					Assert("Should fail when the row count differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Command Tag",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "INSERT INTO users VALUES (3, 'carol')").T().
+					CommandTag(Is("INSERT 0 1")).
+					RowCount(Is(0)).
+					Assert("Server should report the insert")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Expected Error",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT * FROM missing").T().
+					SQLState(Is("42P01")).
+					Error(Contains("does not exist")).
+					Assert("Server should reject unknown tables")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unexpected Error",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT * FROM missing").T().
+					RowCount(Is(0)).
+					Assert("Should fail when the query returns an error")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Error",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").T().
+					SQLState(Is("42P01")).
+					Assert("Should fail when the query succeeds")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Cleartext Password",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").User("secret").Password("hunter2").T().
+					Cell(0, "?column?", Is("1")).
+					Assert("Server should accept the password")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "MD5 Password",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").User("hashed").Password("hunter2").T().
+					Cell(0, "?column?", Is("1")).
+					Assert("Server should accept the hashed password")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Password",
+			testFunc: func(do *Do) {
+				do.Postgres("svc", "SELECT 1").User("hashed").Password("wrong").T().
+					RowCount(Is(1)).
+					Assert("Should fail when authentication fails")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := servePG(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package attest_test
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// shutdownEnv selects how the test binary behaves when run as a server:
+// "graceful" finishes in-flight requests and flushes, "abrupt" exits at
+// once with status 1, and "stubborn" ignores SIGTERM. SIGHUP is ignored.
+// "volatile" shuts down like "graceful" but keeps its /kv/ store in memory.
+const shutdownEnv = "ATTEST_SHUTDOWN_SERVER"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(shutdownEnv); mode != "" {
+		runShutdownServer(mode)
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+// runShutdownServer serves /env, which answers with $LOG_LEVEL or the
+// variable in ?name=, a key-value store under /kv/, and anything else after
+// 300ms, until it is told to stop.
+func runShutdownServer(mode string) {
+	flags := flag.NewFlagSet("server", flag.ExitOnError)
+	port := flags.String("port", "", "")
+	workingDir := flags.String("working-dir", "", "")
+	flags.Parse(os.Args[1:])
+
+	kv := newKVStore(filepath.Join(*workingDir, "kv-"+*port+".log"), mode != "volatile")
+
+	server := &http.Server{
+		Addr: "127.0.0.1:" + *port,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/env" {
+				name := r.URL.Query().Get("name")
+				if name == "" {
+					name = "LOG_LEVEL"
+				}
+				w.Write([]byte(os.Getenv(name)))
+				return
+			}
+			if strings.HasPrefix(r.URL.Path, "/kv/") {
+				kv.ServeHTTP(w, r)
+				return
+			}
+
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte("done"))
+		}),
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for sig := range signals {
+			switch {
+			case sig == syscall.SIGHUP || mode == "stubborn":
+			case mode == "abrupt":
+				os.Exit(1)
+			default:
+				server.Shutdown(context.Background())
+				os.WriteFile(filepath.Join(*workingDir, "flushed"), []byte("ok"), 0644)
+				os.Exit(0)
+			}
+		}
+	}()
+
+	server.ListenAndServe()
+	select {}
+}
+
+func TestSignal(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Graceful Shutdown",
+			mode: "graceful",
+			testFunc: func(do *Do) {
+				do.Signal("server", syscall.SIGTERM).
+					InFlight(100*time.Millisecond, do.HTTP("server", "GET", "/slow").T().Body(Is("done"))).
+					T().
+					ExitsWithin(2 * time.Second).
+					ExitCode(Is(0)).
+					Assert("Server should finish in-flight requests before exiting")
+
+				do.File("flushed").T().
+					Contents(Is("ok")).
+					Assert("Server should flush before exiting")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Dropped Requests",
+			mode: "abrupt",
+			testFunc: func(do *Do) {
+				do.Signal("server", syscall.SIGTERM).
+					InFlight(100*time.Millisecond, do.HTTP("server", "GET", "/slow").T().Body(Is("done"))).
+					T().
+					Assert("Should fail when in-flight requests are dropped")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Exit Code",
+			mode: "abrupt",
+			testFunc: func(do *Do) {
+				do.Signal("server", syscall.SIGTERM).T().
+					ExitCode(Is(0)).
+					Assert("Should fail when the server exits with an error")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Ignores SIGTERM",
+			mode: "stubborn",
+			testFunc: func(do *Do) {
+				do.Signal("server", syscall.SIGTERM).T().
+					ExitsWithin(300 * time.Millisecond).
+					Assert("Should fail when the server does not exit")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Survives SIGHUP",
+			mode: "graceful",
+			testFunc: func(do *Do) {
+				do.Signal("server", syscall.SIGHUP).T().
+					Survives(200 * time.Millisecond).
+					Assert("Server should reload on SIGHUP")
+
+				do.HTTP("server", "GET", "/slow").T().
+					Body(Is("done")).
+					Assert("Server should still serve after SIGHUP")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Exits Instead Of Surviving",
+			mode: "abrupt",
+			testFunc: func(do *Do) {
+				// The exit ends the wait, so a long window only bounds slow
+				// exits, e.g. under the race detector
+				do.Signal("server", syscall.SIGTERM).T().
+					Survives(5 * time.Second).
+					Assert("Should fail when the server exits")
+			},
+			shouldPass: false,
+		},
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(shutdownEnv, tt.mode)
+
+			config := &Config{
+				Command:                executable,
+				WorkingDir:             t.TempDir(),
+				ExecuteTimeout:         time.Second,
+				ProcessShutdownTimeout: time.Second,
+			}
+
+			success := New().WithConfig(config).
+				Setup(func(do *Do) {
+					do.Start("server")
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestStartWithEnv(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(shutdownEnv, "graceful")
+
+	config := &Config{
+		Command:                executable,
+		WorkingDir:             t.TempDir(),
+		ExecuteTimeout:         time.Second,
+		ProcessShutdownTimeout: time.Second,
+		Env:                    []string{"LOG_LEVEL=info"},
+	}
+
+	success := New().WithConfig(config).
+		Setup(func(do *Do) {
+			do.Start("default")
+			do.StartWithEnv("debug", []string{"LOG_LEVEL=debug"})
+		}).
+		Test("Env", func(do *Do) {
+			do.HTTP("default", "GET", "/env").T().
+				Body(Is("info")).
+				Assert("Server should see the suite's environment")
+
+			do.HTTP("debug", "GET", "/env").T().
+				Body(Is("debug")).
+				Assert("Server should see its own environment")
+
+			do.Restart("debug")
+			do.HTTP("debug", "GET", "/env").T().
+				Body(Is("debug")).
+				Assert("Server should keep its environment across restarts")
+		}).
+		Run(context.Background())
+
+	if !success {
+		t.Error("Env test should pass but failed")
+	}
+}
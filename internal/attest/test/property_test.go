@@ -0,0 +1,170 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// kvOp is a SET or DEL of one of a few keys.
+type kvOp struct {
+	del   bool
+	key   string
+	value string
+}
+
+func (op kvOp) String() string {
+	if op.del {
+		return "DEL " + op.key
+	}
+	return fmt.Sprintf("SET %s %s", op.key, op.value)
+}
+
+func genKVOp(r *rand.Rand) kvOp {
+	key := string(rune('a' + r.IntN(3)))
+	if r.IntN(3) == 0 {
+		return kvOp{del: true, key: key}
+	}
+	return kvOp{key: key, value: fmt.Sprint(r.IntN(100))}
+}
+
+// kvServer stores values under /{key}. With buggy set, a key once deleted
+// ignores later SETs.
+func kvServer(buggy bool) http.Handler {
+	var mu sync.Mutex
+	values := make(map[string]string)
+	deleted := make(map[string]bool)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /{key}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if !(buggy && deleted[r.PathValue("key")]) {
+			values[r.PathValue("key")] = string(body)
+		}
+	})
+	mux.HandleFunc("DELETE /{key}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delete(values, r.PathValue("key"))
+		deleted[r.PathValue("key")] = true
+	})
+	mux.HandleFunc("GET /{key}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		value, ok := values[r.PathValue("key")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, value)
+	})
+	mux.HandleFunc("POST /flush", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		clear(values)
+		clear(deleted)
+	})
+
+	return mux
+}
+
+// lastValueWins applies ops to the store and a model, then checks every
+// key against the model.
+func lastValueWins(do *Do, ops []kvOp) {
+	model := make(map[string]string)
+	for _, op := range ops {
+		if op.del {
+			do.HTTP("kv", "DELETE", "/"+op.key).T().Status(Is(200)).Assert("DEL should succeed")
+			delete(model, op.key)
+			continue
+		}
+		do.HTTP("kv", "PUT", "/"+op.key, op.value).T().Status(Is(200)).Assert("SET should succeed")
+		model[op.key] = op.value
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		value, ok := model[key]
+		if !ok {
+			do.HTTP("kv", "GET", "/"+key).T().Status(Is(404)).Assert("GET should miss a deleted key")
+			continue
+		}
+		do.HTTP("kv", "GET", "/"+key).T().Body(Is(value)).Assert("GET should return the last value set")
+	}
+}
+
+func TestProperty(t *testing.T) {
+	flush := func(do *Do) {
+		do.HTTP("kv", "POST", "/flush").T().Status(Is(200)).Assert("Store should flush")
+	}
+
+	tests := []struct {
+		name       string
+		buggy      bool
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Holds",
+			testFunc: func(do *Do) {
+				Property(do, "last value wins", genKVOp, lastValueWins).Reset(flush).T().
+					Assert("Store should return the last value set")
+			},
+			shouldPass: true,
+		},
+		{
+			name:  "Shrinks",
+			buggy: true,
+			testFunc: func(do *Do) {
+				Property(do, "last value wins", genKVOp, lastValueWins).Reset(flush).Runs(50).T().
+					Assert("Should fail when a deleted key ignores SET")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(kvServer(tt.buggy))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("kv", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				RunReport(context.Background())
+
+			if report.Passed != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+
+			// DEL then SET of one key is the shortest failing sequence
+			if !tt.shouldPass && !strings.Contains(report.Results[0].Failure, "to 2 operations") {
+				t.Errorf("failure should be shrunk to 2 operations:\n%s", report.Results[0].Failure)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package attest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// repl prompts with "> " and answers each line with "got <line>" until
+// "quit" or the end of input.
+const repl = `while printf '> '; read line; do
+	[ "$line" = quit ] && exit 3
+	[ -t 0 ] || echo "not a terminal"
+	echo "got $line"
+done`
+
+func TestInteractive(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Exchange",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", repl).
+					Expect(`^> $`).
+					Send("hello").
+					Expect(`^got hello$`).
+					Expect(`^> $`).
+					Send("world").
+					Expect(`^got world$`).
+					T().
+					Output(Contains("> hello\ngot hello\n"), Not(Contains("not a terminal"))).
+					Assert("REPL should answer each line on a terminal")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Reply",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", repl).
+					Expect(`^> $`).
+					Send("hello").
+					Expect(`^HELLO$`, 200*time.Millisecond).
+					T().
+					Assert("Should fail when the reply does not match")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Exit Code",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", repl).
+					Expect(`^> $`).
+					Send("quit").
+					T().
+					ExitCode(Is(3)).
+					Assert("REPL should exit on quit")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "End Of Input",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", repl).
+					Expect(`^> $`).
+					SendEOF().
+					T().
+					ExitCode(Is(0)).
+					Assert("REPL should exit at the end of input")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Exits Early",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", "echo bye").
+					Expect(`^> $`).
+					T().
+					Assert("Should fail when the command exits before the prompt")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Never Exits",
+			testFunc: func(do *Do) {
+				do.Interactive("-c", repl).
+					Expect(`^> $`).
+					T().
+					ExitCode(Is(0)).
+					Assert("Should fail when the command keeps running")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Command: "sh", WorkingDir: t.TempDir(), ExecuteTimeout: 500 * time.Millisecond}
+
+			success := New().WithConfig(config).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestInteractiveFailure(t *testing.T) {
+	report := New().WithConfig(&Config{Command: "sh", WorkingDir: t.TempDir(), Quiet: true}).
+		Test("Wrong Reply", func(do *Do) {
+			do.Interactive("-c", repl).
+				Expect(`^> $`).
+				Send("hello").
+				Expect(`^HELLO$`, 200*time.Millisecond).
+				T().
+				Assert("REPL should shout")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", report.Results)
+	}
+
+	want := "Step 3: expect \"^HELLO$\"\n  Actual: no match within 200ms\n  Output since the last match:\n    | hello\n    | got hello\n    | > "
+	if failure := report.Results[0].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
@@ -0,0 +1,130 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// redirectingHandler redirects /old permanently to /a, then /a to /b to
+// /c, and /loop1 and /loop2 to each other.
+func redirectingHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/a", http.StatusMovedPermanently))
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusFound))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusTemporaryRedirect))
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("landed"))
+	})
+	mux.Handle("/loop1", http.RedirectHandler("/loop2", http.StatusFound))
+	mux.Handle("/loop2", http.RedirectHandler("/loop1", http.StatusFound))
+	return mux
+}
+
+func TestRedirects(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Follow Chain",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/old").T().
+					Status(Is(200)).
+					Body(Is("landed")).
+					Redirects(Is(3)).
+					RedirectChain(Is("301 /a -> 302 /b -> 307 /c")).
+					Assert("Server should redirect to the new page")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "No Redirects",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/old").NoRedirects().T().
+					Status(Is(301)).
+					Header("Location", Is("/a")).
+					Redirects(Is(0)).
+					Assert("Server should answer with the redirect")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Within Max",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/a").MaxRedirects(2).T().
+					Status(Is(200)).
+					Assert("Server should redirect twice")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Over Max",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/old").MaxRedirects(2).T().
+					Status(Is(200)).
+					Assert("Should fail with too many redirects")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Loop",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/loop1").T().
+					Status(Is(302)).
+					Assert("Should fail on a redirect loop")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Wrong Chain",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/old").T().
+					RedirectChain(Is("308 /c")).
+					Assert("Should fail when the chain differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Wrong Count",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/c").T().
+					Redirects(AtLeast(1)).
+					Assert("Should fail when nothing redirects")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(redirectingHandler())
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
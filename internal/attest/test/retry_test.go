@@ -0,0 +1,187 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		readyAfter   int64
+		config       RetryPolicy
+		testFunc     func(*Do)
+		shouldPass   bool
+		wantRequests int64
+	}{
+		{
+			name:       "Max Attempts",
+			readyAfter: 5,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().Retry(RetryPolicy{Interval: 10 * time.Millisecond, MaxAttempts: 3}).T().
+					Status(Is(200)).
+					Assert("Should fail when the server is not ready within three attempts")
+			},
+			shouldPass:   false,
+			wantRequests: 3,
+		},
+		{
+			name:       "Ready Within Attempts",
+			readyAfter: 3,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().Retry(RetryPolicy{Interval: 10 * time.Millisecond, MaxAttempts: 3}).T().
+					Status(Is(200)).
+					Assert("Server should be ready by the third attempt")
+			},
+			shouldPass:   true,
+			wantRequests: 3,
+		},
+		{
+			name:       "Exponential Backoff",
+			readyAfter: 100,
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().Within(750 * time.Millisecond).
+					Retry(RetryPolicy{Interval: 10 * time.Millisecond, Multiplier: 2, MaxInterval: 160 * time.Millisecond, Jitter: 0.1}).T().
+					Status(Is(200)).
+					Assert("Should fail when the server never becomes ready")
+			},
+			shouldPass:   false,
+			wantRequests: 8,
+		},
+		{
+			name:       "Config Policy",
+			readyAfter: 5,
+			config:     RetryPolicy{Interval: 10 * time.Millisecond, MaxAttempts: 2},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().T().
+					Status(Is(200)).
+					Assert("Should fail after the configured attempts")
+			},
+			shouldPass:   false,
+			wantRequests: 2,
+		},
+		{
+			name:       "Plan Overrides Config",
+			readyAfter: 4,
+			config:     RetryPolicy{MaxAttempts: 2},
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/").Eventually().Retry(RetryPolicy{Interval: 10 * time.Millisecond}).T().
+					Status(Is(200)).
+					Assert("Server should be ready by the fourth attempt")
+			},
+			shouldPass:   true,
+			wantRequests: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) < tt.readyAfter {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, RetryPolicy: tt.config}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestEvery(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOn      int64
+		every       time.Duration
+		minRequests int64
+		maxRequests int64
+		wantFailure string
+	}{
+		{
+			name:        "Sparse Samples",
+			every:       100 * time.Millisecond,
+			minRequests: 2,
+			maxRequests: 3,
+		},
+		{
+			name:        "Dense Samples",
+			every:       20 * time.Millisecond,
+			minRequests: 10,
+			maxRequests: 15,
+		},
+		{
+			name:        "Failed Sample",
+			failOn:      4,
+			every:       20 * time.Millisecond,
+			minRequests: 4,
+			maxRequests: 4,
+			wantFailure: "Failed on sample 4, checking every 20ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == tt.failOn {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Quiet: true}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					do.HTTP("svc", "GET", "/").Consistently().For(300 * time.Millisecond).Every(tt.every).T().
+						Status(Is(200)).
+						Assert("Server should stay up")
+				}).
+				RunReport(context.Background())
+
+			if report.Passed != (tt.wantFailure == "") {
+				t.Errorf("passed = %v, want %v", report.Passed, tt.wantFailure == "")
+			}
+
+			if got := requests.Load(); got < tt.minRequests || got > tt.maxRequests {
+				t.Errorf("requests = %d, want %d to %d", got, tt.minRequests, tt.maxRequests)
+			}
+
+			if tt.wantFailure != "" && !strings.Contains(report.Results[0].Failure, tt.wantFailure) {
+				t.Errorf("failure should contain %q, got:\n%s", tt.wantFailure, report.Results[0].Failure)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package attest_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+var authorization = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=(\w+)/([^,]+), SignedHeaders=([^,]+), Signature=(\w+)$`)
+
+// verifySigV4 recomputes the signature of r for the secret key.
+func verifySigV4(r *http.Request, body []byte, secret string) bool {
+	m := authorization.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return false
+	}
+	scope, signed := m[2], strings.Split(m[3], ";")
+
+	var headers strings.Builder
+	for _, name := range signed {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		fmt.Fprintf(&headers, "%s:%s\n", name, value)
+	}
+
+	var query []string
+	for key, values := range r.URL.Query() {
+		for _, value := range values {
+			query = append(query, url.QueryEscape(key)+"="+strings.ReplaceAll(url.QueryEscape(value), "+", "%20"))
+		}
+	}
+	slices.Sort(query)
+
+	payload := sha256.Sum256(body)
+	canonical := strings.Join([]string{r.Method, r.URL.EscapedPath(), strings.Join(query, "&"),
+		headers.String(), m[3], hex.EncodeToString(payload[:])}, "\n")
+	hashed := sha256.Sum256([]byte(canonical))
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	parts := strings.Split(scope, "/")
+	key := []byte("AWS4" + secret)
+	for _, part := range parts {
+		key = mac(key, part)
+	}
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	return hex.EncodeToString(mac(key, stringToSign)) == m[4]
+}
+
+// serveS3 runs a minimal path-style S3 API that requires requests signed
+// with the secret "secret". The bucket "sloppy" completes multipart uploads
+// with the ETag of a single-part upload.
+func serveS3(t *testing.T) string {
+	t.Helper()
+
+	var mu sync.Mutex
+	objects := map[string]string{}
+	uploads := map[string][]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !verifySigV4(r, body, "secret") {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		query := r.URL.Query()
+		path := r.URL.Path
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+		switch {
+		case r.Method == "POST" && query.Has("uploads"):
+			uploads[path] = nil
+			io.WriteString(w, "<InitiateMultipartUploadResult><UploadId>up-1</UploadId></InitiateMultipartUploadResult>")
+		case r.Method == "PUT" && query.Has("partNumber"):
+			uploads[path] = append(uploads[path], string(body))
+			sum := md5.Sum(body)
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		case r.Method == "POST" && query.Get("uploadId") == "up-1":
+			parts := uploads[path]
+			objects[path] = strings.Join(parts, "")
+			etag := MultipartETag(parts...)
+			if bucket == "sloppy" {
+				etag = ETagOf(objects[path])
+			}
+			fmt.Fprintf(w, `<CompleteMultipartUploadResult><ETag>"%s"</ETag></CompleteMultipartUploadResult>`, etag)
+		case r.Method == "PUT":
+			objects[path] = string(body)
+			w.Header().Set("ETag", `"`+ETagOf(string(body))+`"`)
+		case r.Method == "GET" && key == "":
+			var keys []string
+			for name := range objects {
+				if strings.HasPrefix(name, "/"+bucket+"/"+query.Get("prefix")) {
+					keys = append(keys, strings.TrimPrefix(name, "/"+bucket+"/"))
+				}
+			}
+			slices.Sort(keys)
+
+			io.WriteString(w, "<ListBucketResult>")
+			for _, name := range keys {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", name)
+			}
+			io.WriteString(w, "</ListBucketResult>")
+		case r.Method == "GET":
+			object, ok := objects[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", `"`+ETagOf(object)+`"`)
+			io.WriteString(w, object)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.Split(server.URL, ":")[2]
+}
+
+func TestS3(t *testing.T) {
+	part := strings.Repeat("a", 1024)
+
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Signed Put and Get",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "PUT", "/photos/cat.txt", "meow", H{"Content-Type": "text/plain"}).
+					SignV4("AKID", "secret", "us-east-1").T().
+					Status(Is(200)).
+					ETag(Is(ETagOf("meow"))).
+					Assert("Server should accept signed uploads")
+
+				do.HTTP("svc", "GET", "/photos/cat.txt").SignV4("AKID", "secret", "us-east-1").T().
+					Status(Is(200)).
+					Body(Is("meow")).
+					ETag(Is(ETagOf("meow"))).
+					Assert("Server should return the object")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Wrong Secret",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "PUT", "/photos/cat.txt", "meow").SignV4("AKID", "wrong", "us-east-1").T().
+					Status(Is(200)).
+					Assert("Should fail when the signature does not match")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Unsigned Request Rejected",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/photos/cat.txt").T().
+					Status(Is(403)).
+					Body(Contains("SignatureDoesNotMatch")).
+					Assert("Server should reject unsigned requests")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "ETag Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "PUT", "/photos/cat.txt", "meow").SignV4("AKID", "secret", "us-east-1").T().
+					ETag(Is(ETagOf("woof"))).
+					Assert("Should fail when the ETag differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "List Objects",
+			testFunc: func(do *Do) {
+				for _, key := range []string{"b.txt", "a.txt", "other/c.txt"} {
+					do.HTTP("svc", "PUT", "/photos/"+key, key).SignV4("AKID", "secret", "us-east-1").T().
+						Status(Is(200)).
+						Assert("Server should accept uploads")
+				}
+
+				do.HTTP("svc", "GET", "/photos?list-type=2&prefix=other%20stuff").SignV4("AKID", "secret", "us-east-1").T().
+					Status(Is(200)).
+					Objects().
+					Assert("Server should list nothing for an unknown prefix")
+
+				do.HTTP("svc", "GET", "/photos?list-type=2").SignV4("AKID", "secret", "us-east-1").T().
+					Status(Is(200)).
+					Objects("a.txt", "b.txt", "other/c.txt").
+					Assert("Server should list keys in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "List Objects Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "PUT", "/photos/a.txt", "a").SignV4("AKID", "secret", "us-east-1").T().
+					Status(Is(200)).
+					Assert("Server should accept uploads")
+
+				do.HTTP("svc", "GET", "/photos?list-type=2").SignV4("AKID", "secret", "us-east-1").T().
+					Objects("a.txt", "b.txt").
+					Assert("Should fail when a key is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Multipart Upload",
+			testFunc: func(do *Do) {
+				do.S3Multipart("svc", "photos", "big.bin").
+					SignV4("AKID", "secret", "us-east-1").
+					Part(part).
+					Part("tail").
+					T().
+					ETag(Is(MultipartETag(part, "tail"))).
+					Object(Is(part + "tail")).
+					Assert("Server should assemble the parts in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Multipart ETag Mismatch",
+			testFunc: func(do *Do) {
+				do.S3Multipart("svc", "sloppy", "big.bin").
+					SignV4("AKID", "secret", "us-east-1").
+					Part(part).
+					Part("tail").
+					T().
+					ETag(Is(MultipartETag(part, "tail"))).
+					Assert("Should fail when the ETag is not a multipart ETag")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Multipart Unsigned",
+			testFunc: func(do *Do) {
+				do.S3Multipart("svc", "photos", "big.bin").
+					Part(part).
+					T().
+					Assert("Should fail when initiating the upload is rejected")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveS3(t)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// itemsHandler stores items created by POST /items under increasing ids.
+// Reading and deleting them requires the cookie set by POST /login.
+func itemsHandler() http.Handler {
+	var mu sync.Mutex
+	items := make(map[string]string)
+	next := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.FormValue("name"), Path: "/"})
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		next++
+		id := fmt.Sprintf("item-%d", next)
+		items[id] = r.FormValue("name")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"item": {"id": %q}}`, id)
+	})
+	mux.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("user"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		name, ok := items[r.PathValue("id")]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			delete(items, r.PathValue("id"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			fmt.Fprint(w, name)
+		}
+	})
+
+	return mux
+}
+
+func TestSession(t *testing.T) {
+	form := H{"Content-Type": "application/x-www-form-urlencoded"}
+
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Create Get Delete",
+			testFunc: func(do *Do) {
+				s := do.Session("svc")
+				s.Set("user", "alice")
+
+				s.HTTP("POST", "/login", "name={{user}}", form).T().
+					Status(Is(200)).
+					Assert("Login should succeed")
+
+				s.HTTP("POST", "/items", "name=widget", form).T().
+					Status(Is(201)).
+					CaptureJSON("id", "$.item.id").
+					Assert("Creating an item should return its id")
+
+				s.HTTP("GET", "/items/{{id}}").T().
+					Body(Is("widget")).
+					Assert("Item should be readable by its id")
+
+				s.HTTP("DELETE", "/items/{{ id }}").T().
+					Status(Is(204)).
+					Assert("Item should be deletable")
+
+				s.HTTP("GET", "/items/"+s.Var("id")).T().
+					Status(Is(404)).
+					Assert("Deleted item should be gone")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Cookies Are Per Session",
+			testFunc: func(do *Do) {
+				do.Session("svc").HTTP("POST", "/login", "name=alice", form).T().
+					Assert("Login should succeed")
+
+				s := do.Session("svc")
+				s.HTTP("POST", "/items", "name=widget", form).T().
+					CaptureJSON("id", "item.id").
+					Assert("Creating an item should return its id")
+
+				s.HTTP("GET", "/items/{{id}}").T().
+					Status(Is(200)).
+					Assert("Should fail without the other session's cookie")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Missing Field",
+			testFunc: func(do *Do) {
+				do.Session("svc").HTTP("POST", "/items", "name=widget", form).T().
+					CaptureJSON("id", "$.id").
+					Assert("Should fail when the captured field is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Undefined Variable",
+			testFunc: func(do *Do) {
+				do.Session("svc").Var("id")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(itemsHandler())
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+// authHandler issues a token on POST /token, creates notes on POST /notes
+// with their URL in Location, and serves them only with the token.
+func authHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/token":
+		fmt.Fprint(w, `{"token": "s3cret"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/echo":
+		io.Copy(w, r.Body)
+	case r.Header.Get("Authorization") != "Bearer s3cret":
+		w.WriteHeader(http.StatusUnauthorized)
+	case r.Method == http.MethodPost && r.URL.Path == "/notes":
+		w.Header().Set("Location", "/notes/7")
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/notes/7":
+		fmt.Fprint(w, "note 7")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Token And Location",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/token").T().
+					CaptureJSON("token", "$.token").
+					Assert("Should issue a token")
+
+				auth := H{"Authorization": "Bearer {{token}}"}
+				do.HTTP("svc", "POST", "/notes", "", auth).T().
+					Status(Is(201)).
+					CaptureHeader("note", "Location").
+					Assert("Should create a note")
+
+				do.HTTP("svc", "GET", "{{note}}", "", auth).T().
+					Body(Is("note 7")).
+					Assert("Note should be at its Location")
+
+				if do.Var("note") != "/notes/7" {
+					panic("captured Location should be /notes/7, got " + do.Var("note"))
+				}
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Session Sees Test Variables",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/token").T().
+					CaptureJSON("token", "token").
+					Assert("Should issue a token")
+
+				do.Session("svc").HTTP("GET", "/notes/7", "", H{"Authorization": "Bearer {{token}}"}).T().
+					Status(Is(200)).
+					Assert("Session should use the test's token")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Missing Header",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/token").T().
+					CaptureHeader("note", "Location").
+					Assert("Should fail when the header is missing")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Literal Braces Without Variables",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/echo", `{"template": "Hello {{name}}"}`).T().
+					Body(Is(`{"template": "Hello {{name}}"}`)).
+					Assert("Body should be sent as it is")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Literal Braces After Capture",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/token").T().
+					CaptureJSON("token", "token").
+					Assert("Should issue a token")
+
+				do.HTTP("svc", "POST", "/echo", "{{token}} for {{name}}").T().
+					Body(Is("s3cret for {{name}}")).
+					Assert("Only set variables should be replaced")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Not Captured After Failure",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "POST", "/token").T().
+					Status(Is(201)).
+					CaptureJSON("token", "token").
+					Assert("Should fail on the status")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(authHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestCaptureScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(authHandler))
+	defer server.Close()
+
+	port := strings.Split(server.URL, ":")[2]
+
+	report := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second, Quiet: true}).
+		Setup(func(do *Do) {
+			do.MockProcess("svc", port)
+		}).
+		Test("Capture", func(do *Do) {
+			do.HTTP("svc", "POST", "/token").T().
+				CaptureJSON("token", "token").
+				Assert("Should issue a token")
+		}).
+		Test("Reuse", func(do *Do) {
+			do.Var("token")
+		}).
+		RunReport(context.Background())
+
+	if len(report.Results) != 2 || !report.Results[0].Passed {
+		t.Fatalf("expected the first of two tests to pass, got %+v", report.Results)
+	}
+
+	want := `Variable "token" is not set`
+	if failure := report.Results[1].Failure; !strings.Contains(failure, want) {
+		t.Errorf("failure should contain %q, got:\n%s", want, failure)
+	}
+}
@@ -0,0 +1,188 @@
+package attest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// sseHandler streams events. /ticks sends three numbered ticks, resuming
+// after Last-Event-ID, then keeps the stream open. /slow waits before its
+// second event. /multi sends a comment and a multi-line event.
+func sseHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher := w.(http.Flusher)
+	send := func(format string, args ...any) {
+		fmt.Fprintf(w, format, args...)
+		flusher.Flush()
+	}
+
+	switch r.URL.Path {
+	case "/ticks":
+		start, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+		for i := start + 1; i <= 3; i++ {
+			send("event: tick\nid: %d\ndata: %d\n\n", i, i)
+		}
+	case "/slow":
+		send("data: first\n\n")
+		select {
+		case <-time.After(500 * time.Millisecond):
+			send("data: second\n\n")
+		case <-r.Context().Done():
+		}
+	case "/multi":
+		send(": keep-alive\n\n")
+		send("retry: 1000\r\ndata: line one\r\ndata: line two\r\n\r\n")
+		send("event: done\ndata:\n\n")
+		return
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	<-r.Context().Done()
+}
+
+func TestSSE(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Event Sequence",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks").SSE().T().
+					Status(Is(200)).
+					Event("tick", Is("1")).EventID(Is("1")).
+					Event("tick", Is("2")).EventID(Is("2")).
+					Event("tick", Is("3")).
+					Assert("Server should stream ticks in order")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Resume From Last Event ID",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks", "", H{"Last-Event-ID": "2"}).SSE().T().
+					Event("tick", Is("3")).EventID(Is("3")).
+					Assert("Server should resume after the last event id")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Data Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks").SSE().T().
+					Event("tick", Is("1")).
+					Event("tick", Is("3")).
+					Assert("Should fail when an event is skipped")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Name Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks").SSE().T().
+					Event("message", Is("1")).
+					Assert("Should fail when the event name differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "ID Mismatch",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks").SSE().T().
+					Event("tick").EventID(Is("7")).
+					Assert("Should fail when the event id differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Open Stream Timeout",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/ticks").SSE().EventTimeout(100 * time.Millisecond).T().
+					Event("tick").
+					Event("tick").
+					Event("tick").
+					Event("tick").
+					Assert("Should fail when no further event arrives")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Inter-Event Timeout",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/slow").SSE().EventTimeout(100*time.Millisecond).T().
+					Event("message", Is("first")).
+					Event("message", Is("second")).
+					Assert("Should fail when the next event is too slow")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Slow Event Within Timeout",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/slow").SSE().T().
+					Event("message", Is("first")).
+					Event("message", Is("second")).
+					Assert("Server should eventually send the second event")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Multi-Line Data",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/multi").SSE().T().
+					Event("message", Is("line one\nline two")).
+					Event("done", Is("")).
+					Assert("Server should join data lines and skip comments")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Stream Ends",
+			testFunc: func(do *Do) {
+				do.HTTP("svc", "GET", "/multi").SSE().T().
+					Event("message").
+					Event("done").
+					Event("message").
+					Assert("Should fail when the stream ends early")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(sseHandler))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
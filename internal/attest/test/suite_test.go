@@ -0,0 +1,236 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+func TestSuiteFilter(t *testing.T) {
+	var ran []string
+	record := func(name string) func(*Do) {
+		return func(do *Do) {
+			ran = append(ran, name)
+		}
+	}
+
+	suite := New().WithConfig(&Config{WorkingDir: t.TempDir()}).
+		Setup(record("setup")).
+		Test("PUT Basic", record("PUT Basic")).
+		Test("GET Basic", record("GET Basic")).
+		Test("GET Edge Cases", record("GET Edge Cases")).
+		Filter(func(name string) bool {
+			return strings.HasPrefix(name, "GET")
+		})
+
+	want := []string{"GET Basic", "GET Edge Cases"}
+	if names := suite.TestNames(); !slices.Equal(names, want) {
+		t.Errorf("TestNames() = %v, want %v", names, want)
+	}
+
+	if !suite.Run(context.Background()) {
+		t.Fatal("filtered suite should pass")
+	}
+
+	want = []string{"setup", "GET Basic", "GET Edge Cases"}
+	if !slices.Equal(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
+
+func TestSuiteSeed(t *testing.T) {
+	run := func(seed uint64) (*Report, string) {
+		var values string
+		report := New().WithConfig(&Config{WorkingDir: t.TempDir(), Seed: seed}).
+			Test("Random", func(do *Do) {
+				values = do.RandString(16)
+			}).
+			RunReport(context.Background())
+
+		return report, values
+	}
+
+	report, first := run(42)
+	if report.Seed != 42 {
+		t.Errorf("Seed = %d, want 42", report.Seed)
+	}
+
+	_, second := run(42)
+	if first != second {
+		t.Errorf("same seed produced %q and %q", first, second)
+	}
+
+	_, other := run(43)
+	if first == other {
+		t.Errorf("different seeds produced the same value %q", first)
+	}
+
+	report, _ = run(0)
+	if report.Seed == 0 {
+		t.Error("zero seed should pick a random seed")
+	}
+}
+
+func TestSuiteParallel(t *testing.T) {
+	tests := []struct {
+		name     string
+		parallel int
+		want     []string
+	}{
+		{"runs parallel tests together", 2, []string{"A", "B", "C"}},
+		{"runs sequentially by default", 0, []string{"A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started atomic.Int32
+
+			// Each parallel test waits until the other has started
+			waitForOther := func(do *Do) {
+				started.Add(1)
+				deadline := time.Now().Add(200 * time.Millisecond)
+				for started.Load() < 2 {
+					if time.Now().After(deadline) {
+						panic("other test did not start")
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), Parallel: tt.parallel}).
+				TestParallel("A", waitForOther).
+				TestParallel("B", waitForOther).
+				Test("C", func(do *Do) {}).
+				RunReport(context.Background())
+
+			var names []string
+			for _, result := range report.Results {
+				names = append(names, result.Name)
+			}
+
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("results %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuiteKeepGoing(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepGoing bool
+		want      []string
+	}{
+		{"stops at the first failure by default", false, []string{"A", "B"}},
+		{"runs every test when keeping going", true, []string{"A", "B", "C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			record := func(name string) func(*Do) {
+				return func(do *Do) {
+					ran = append(ran, name)
+				}
+			}
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), KeepGoing: tt.keepGoing, Quiet: true}).
+				Test("A", record("A")).
+				Test("B", func(do *Do) {
+					ran = append(ran, "B")
+					panic("B failed")
+				}).
+				Test("C", record("C")).
+				RunReport(context.Background())
+
+			if report.Passed {
+				t.Error("suite with a failing test should fail")
+			}
+
+			if !slices.Equal(ran, tt.want) {
+				t.Errorf("ran %v, want %v", ran, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuiteSoft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	port := strings.Split(server.URL, ":")[2]
+
+	tests := []struct {
+		name        string
+		soft        bool
+		wantRan     bool
+		wantFailure []string
+	}{
+		{
+			name:        "collects every failure",
+			soft:        true,
+			wantRan:     true,
+			wantFailure: []string{"2 assertions failed", "[1/2] GET", "Expected status: 201", "[2/2] GET", "Expected response: Created"},
+		},
+		{
+			name:        "stops at the first failure by default",
+			wantFailure: []string{"Expected status: 201"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran bool
+			fn := func(do *Do) {
+				do.HTTP("svc", "GET", "/").T().
+					Status(Is(201)).
+					Assert("Server should create the key")
+				do.HTTP("svc", "GET", "/").T().
+					Body(Is("OK")).
+					Assert("Server should answer OK")
+				do.HTTP("svc", "GET", "/").T().
+					Body(Is("Created")).
+					Assert("Server should say the key was created")
+				ran = true
+			}
+
+			suite := New().WithConfig(&Config{WorkingDir: t.TempDir(), Quiet: true}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				})
+			if tt.soft {
+				suite.TestSoft("Soft", fn)
+			} else {
+				suite.Test("Soft", fn)
+			}
+			report := suite.RunReport(context.Background())
+
+			if report.Passed {
+				t.Fatal("suite with failing assertions should fail")
+			}
+
+			if ran != tt.wantRan {
+				t.Errorf("ran to the end = %v, want %v", ran, tt.wantRan)
+			}
+
+			result := report.Results[0]
+			for _, want := range tt.wantFailure {
+				if !strings.Contains(result.Failure, want) {
+					t.Errorf("failure should contain %q, got:\n%s", want, result.Failure)
+				}
+			}
+
+			if result.HTTP == nil || result.HTTP.Field != "status" {
+				t.Errorf("HTTP failure = %+v, want the status failure", result.HTTP)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// serveTCP accepts connections on a local port and handles each with handler.
+func serveTCP(t *testing.T, handler func(net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// echoLines replies to each line with "+<line>".
+func echoLines(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		conn.Write([]byte("+" + scanner.Text() + "\r\n"))
+	}
+}
+
+func TestTCP(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(net.Conn)
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name:    "Exact Response",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").T().
+					Received(Is("+PING\r\n")).
+					Assert("Server should echo the line")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Prefix and Regex",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("GET key\n").T().
+					Received(HasPrefix("+"), Matches(`^\+GET \w+\r\n$`)).
+					Assert("Server should reply with a simple string")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Split Writes",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PI").Pause(50 * time.Millisecond).Send("NG\n").T().
+					Received(Is("+PING\r\n")).
+					Assert("Server should reassemble a line split across packets")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Multiple Replies",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("A\nB\n").T().
+					Received(Is("+A\r\n+B\r\n")).
+					Assert("Server should reply to each pipelined line")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Response Mismatch",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").ReadDeadline(200 * time.Millisecond).T().
+					Received(Is("+PONG\r\n")).
+					Assert("Should fail when the reply differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Read Deadline",
+			handler: func(conn net.Conn) {
+				time.Sleep(500 * time.Millisecond)
+				conn.Write([]byte("late"))
+			},
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").ReadDeadline(100 * time.Millisecond).T().
+					Received(Is("late")).
+					Assert("Should fail when the reply arrives after the deadline")
+			},
+			shouldPass: false,
+		},
+		{
+			name:    "Responds Within",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").T().
+					Received(Is("+PING\r\n")).
+					RespondsWithin(time.Second).
+					Assert("Server should echo quickly")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Slow Response",
+			handler: func(conn net.Conn) {
+				time.Sleep(200 * time.Millisecond)
+				conn.Write([]byte("+PONG\r\n"))
+			},
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").T().
+					Received(Is("+PONG\r\n")).
+					RespondsWithin(50 * time.Millisecond).
+					Assert("Should fail when the reply is slower than the limit")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Closed by Server",
+			handler: func(conn net.Conn) {
+				conn.Write([]byte("-ERR bye\r\n"))
+			},
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("QUIT\n").T().
+					Received(HasPrefix("-ERR")).
+					Closed().
+					Assert("Server should close the connection after an error")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Left Open",
+			handler: echoLines,
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("QUIT\n").ReadDeadline(200 * time.Millisecond).T().
+					Received(HasPrefix("+")).
+					Closed().
+					Assert("Should fail when the server keeps the connection open")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Eventually OK",
+			handler: func() func(net.Conn) {
+				ready := time.Now().Add(300 * time.Millisecond)
+				return func(conn net.Conn) {
+					if time.Now().Before(ready) {
+						conn.Write([]byte("-LOADING\r\n"))
+						return
+					}
+					conn.Write([]byte("+OK\r\n"))
+				}
+			}(),
+			testFunc: func(do *Do) {
+				do.TCP("svc").Send("PING\n").Eventually().T().
+					Received(Is("+OK\r\n")).
+					Assert("Server should eventually be ready")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveTCP(t, tt.handler)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestTCPConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strings.TrimPrefix(listener.Addr().String(), "127.0.0.1:")
+	listener.Close()
+
+	passed := New().WithConfig(&Config{WorkingDir: t.TempDir(), Quiet: true}).
+		Setup(func(do *Do) {
+			do.MockProcess("svc", port)
+		}).
+		Test("Refused", func(do *Do) {
+			do.TCP("svc").Send("PING\n").T().
+				Assert("Server should accept connections")
+		}).
+		Run(context.Background())
+
+	if passed {
+		t.Error("suite should fail when the connection is refused")
+	}
+}
+
+func TestTCPConnectivity(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(net.Conn)
+		testFunc   func(do *Do, closedPort int)
+		shouldPass bool
+	}{
+		{
+			name:    "Refused",
+			handler: echoLines,
+			testFunc: func(do *Do, closedPort int) {
+				do.TCP("down").Consistently().For(300 * time.Millisecond).T().
+					Refused().
+					Assert("Server should stay down after shutdown")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Still Accepting",
+			handler: echoLines,
+			testFunc: func(do *Do, closedPort int) {
+				do.TCP("svc").T().
+					Refused().
+					Assert("Should fail when the server accepts the connection")
+			},
+			shouldPass: false,
+		},
+		{
+			name:    "Port Not Bound",
+			handler: echoLines,
+			testFunc: func(do *Do, closedPort int) {
+				do.TCPPort(closedPort).T().
+					NotListening().
+					Assert("Server should not bind the port")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Closed Within",
+			handler: func(conn net.Conn) {
+				conn.Write([]byte("-ERR idle\r\n"))
+				time.Sleep(100 * time.Millisecond)
+			},
+			testFunc: func(do *Do, closedPort int) {
+				do.TCP("svc").T().
+					ClosedWithin(500 * time.Millisecond).
+					Assert("Server should close idle connections")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Kept Open",
+			handler: echoLines,
+			testFunc: func(do *Do, closedPort int) {
+				do.TCP("svc").Send("PING\n").T().
+					ClosedWithin(200 * time.Millisecond).
+					Assert("Should fail when the server keeps the connection open")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Closed Too Late",
+			handler: func(conn net.Conn) {
+				time.Sleep(400 * time.Millisecond)
+			},
+			testFunc: func(do *Do, closedPort int) {
+				do.TCP("svc").T().
+					ClosedWithin(100 * time.Millisecond).
+					Assert("Should fail when the server closes after the limit")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveTCP(t, tt.handler)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			closedPort := listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+					do.MockProcess("down", strconv.Itoa(closedPort))
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do, closedPort)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestTCPHexdump(t *testing.T) {
+	tests := []struct {
+		name     string
+		checker  Checker[string]
+		wantText []string
+	}{
+		{
+			name:    "Binary Mismatch",
+			checker: Is("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09"),
+			wantText: []string{
+				"Expected response: 10 bytes\n  Actual response: 10 bytes",
+				"First difference at offset 5 (0x5)",
+				"> 0000    00 01 02 03 04 05 06 07   00 01 02 03 04 ff 06 07",
+				"> 0008    08 09                     08 0a",
+			},
+		},
+		{
+			name:    "Binary Actual",
+			checker: Contains("PONG"),
+			wantText: []string{
+				"Expected response: containing \"PONG\"\n  Actual response: 10 bytes",
+				"    0000  00 01 02 03 04 ff 06 07  |........|",
+			},
+		},
+		{
+			name:     "Text Mismatch",
+			checker:  Is("+PONG\r\n"),
+			wantText: []string{"Actual response: 10 bytes", "First difference at offset 0 (0x0)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveTCP(t, func(conn net.Conn) {
+				conn.Write([]byte("\x00\x01\x02\x03\x04\xff\x06\x07\x08\x0a"))
+			})
+
+			report := New().WithConfig(&Config{WorkingDir: t.TempDir(), Quiet: true}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					do.TCP("svc").Send("PING\n").T().
+						Received(tt.checker).
+						Assert("Server should reply with the frame")
+				}).
+				RunReport(context.Background())
+
+			if report.Passed || len(report.Results) != 1 {
+				t.Fatalf("test should fail with one result, got %+v", report.Results)
+			}
+
+			failure := report.Results[0].Failure
+			for _, want := range tt.wantText {
+				if !strings.Contains(failure, want) {
+					t.Errorf("failure should contain %q, got:\n%s", want, failure)
+				}
+			}
+		})
+	}
+}
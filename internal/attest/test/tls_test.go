@@ -0,0 +1,359 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// testCA signs certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns a certificate for name, chained to the CA.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key}
+}
+
+// writePEM writes the certificate, and its key when key is set.
+func writePEM(t *testing.T, cert tls.Certificate, certPath, keyPath string) {
+	t.Helper()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if keyPath == "" {
+		return
+	}
+
+	der, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveTLS runs an HTTPS server and a TLS echo server that present a
+// localhost certificate, or alt.test when asked for it with SNI. With mtls
+// set, both require a client certificate signed by the CA.
+func serveTLS(t *testing.T, ca *testCA, mtls bool) (string, string) {
+	t.Helper()
+
+	localhost := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	alt := ca.issue(t, "alt.test", x509.ExtKeyUsageServerAuth)
+	config := &tls.Config{
+		Certificates: []tls.Certificate{localhost},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "alt.test" {
+				return &alt, nil
+			}
+			return nil, nil
+		},
+	}
+	if mtls {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(ca.cert)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = config.Clone()
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					conn.Write([]byte(line))
+				}
+			}()
+		}
+	}()
+
+	echoPort := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	return strings.Split(server.URL, ":")[2], echoPort
+}
+
+func TestTLS(t *testing.T) {
+	tests := []struct {
+		name       string
+		mtls       bool
+		testFunc   func(do *Do, dir string)
+		shouldPass bool
+	}{
+		{
+			name: "HTTPS Request",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS().T().
+					Status(Is(200)).
+					Body(Is("HTTP/1.1")).
+					TLSVersion(Is("TLS 1.3")).
+					CertChain(Is("localhost > Test CA")).
+					Assert("Server should terminate TLS")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Verified Root CA",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS(TLSConfig{RootCA: filepath.Join(dir, "ca.pem")}).T().
+					Status(Is(200)).
+					Assert("Server certificate should chain to the CA")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Untrusted Root CA",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS(TLSConfig{RootCA: filepath.Join(dir, "other.pem")}).T().
+					Status(Is(200)).
+					Assert("Should fail when the certificate is not trusted")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Pinned Version And Cipher",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS(TLSConfig{
+					Version:      tls.VersionTLS12,
+					CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+				}).T().
+					TLSVersion(Is("TLS 1.2")).
+					CipherSuite(Is("TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")).
+					Assert("Server should accept TLS 1.2")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Version Mismatch",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS().T().
+					TLSVersion(Is("TLS 1.2")).
+					Assert("Should fail when TLS 1.3 is negotiated")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "SNI",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS(TLSConfig{ServerName: "alt.test"}).T().
+					CertDNSNames(Is("alt.test")).
+					CertChain(HasPrefix("alt.test")).
+					Assert("Server should pick the certificate by SNI")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "SNI Mismatch",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS().T().
+					CertDNSNames(Is("alt.test")).
+					Assert("Should fail without the SNI name")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "ALPN HTTP/2",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").HTTP2().TLS().T().
+					ALPN(Is("h2")).
+					Protocol(Is("HTTP/2.0")).
+					Assert("Server should negotiate h2 with ALPN")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Plain Connection",
+			testFunc: func(do *Do, dir string) {
+				do.TCP("echo").Send("ping\n").T().
+					TLSVersion(Is("TLS 1.3")).
+					Assert("Should fail when TLS was not used")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "TCP Over TLS",
+			testFunc: func(do *Do, dir string) {
+				do.TCP("echo").TLS(TLSConfig{ALPN: []string{"echo"}}).Send("ping\n").T().
+					Received(Is("ping\n")).
+					TLSVersion(Is("TLS 1.3")).
+					ALPN(Is("")).
+					Assert("Server should echo over TLS")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Client Certificate Required",
+			mtls: true,
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS().T().
+					TLSError(Contains("certificate required")).
+					Assert("Server should reject clients without a certificate")
+
+				do.TCP("echo").TLS().Send("ping\n").T().
+					TLSError(Contains("certificate required")).
+					Assert("Server should reject clients without a certificate")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Client Certificate Presented",
+			mtls: true,
+			testFunc: func(do *Do, dir string) {
+				config := TLSConfig{
+					ClientCert: filepath.Join(dir, "client.pem"),
+					ClientKey:  filepath.Join(dir, "client-key.pem"),
+				}
+
+				do.HTTP("web", "GET", "/").TLS(config).T().
+					Status(Is(200)).
+					Assert("Server should accept the client certificate")
+
+				do.TCP("echo").TLS(config).Send("ping\n").T().
+					Received(Is("ping\n")).
+					Assert("Server should accept the client certificate")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Missing Rejection",
+			testFunc: func(do *Do, dir string) {
+				do.HTTP("web", "GET", "/").TLS().T().
+					TLSError().
+					Assert("Should fail when the connection succeeds")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca := newTestCA(t, "Test CA")
+			webPort, echoPort := serveTLS(t, ca, tt.mtls)
+
+			dir := t.TempDir()
+			writePEM(t, tls.Certificate{Certificate: [][]byte{ca.cert.Raw}}, filepath.Join(dir, "ca.pem"), "")
+			other := newTestCA(t, "Other CA")
+			writePEM(t, tls.Certificate{Certificate: [][]byte{other.cert.Raw}}, filepath.Join(dir, "other.pem"), "")
+			client := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+			writePEM(t, client, filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("web", webPort)
+					do.MockProcess("echo", echoPort)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do, dir)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
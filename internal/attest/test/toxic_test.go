@@ -0,0 +1,184 @@
+package attest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// gateway forwards requests to a backend it reaches through a link,
+// answering 504 when the backend is slower than its timeout and 502 when it
+// cannot be reached. Without a timeout it waits for as long as it takes.
+type gateway struct {
+	mu      sync.Mutex
+	backend string
+	timeout time.Duration
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	backend := g.backend
+	g.mu.Unlock()
+
+	client := &http.Client{Timeout: g.timeout, Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + backend + "/")
+	if err != nil {
+		var timeout interface{ Timeout() bool }
+		if errors.As(err, &timeout) && timeout.Timeout() {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	io.Copy(w, resp.Body)
+}
+
+func TestToxic(t *testing.T) {
+	tests := []struct {
+		name       string
+		toxics     []Toxic
+		timeout    time.Duration
+		status     int
+		shouldPass bool
+	}{
+		{name: "Times Out On Latency", toxics: []Toxic{Latency(200 * time.Millisecond)}, timeout: 300 * time.Millisecond, status: 504, shouldPass: true},
+		{name: "Waits Out Latency", toxics: []Toxic{Latency(200 * time.Millisecond)}, status: 504, shouldPass: false},
+		{name: "Tolerates Jitter", toxics: []Toxic{Jitter(50 * time.Millisecond)}, timeout: 300 * time.Millisecond, status: 200, shouldPass: true},
+		{name: "Times Out On Packet Loss", toxics: []Toxic{PacketLoss(1)}, timeout: 300 * time.Millisecond, status: 504, shouldPass: true},
+		{name: "Reports Reset", toxics: []Toxic{ResetConnection(1)}, timeout: 300 * time.Millisecond, status: 502, shouldPass: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "ok")
+			}))
+			defer backend.Close()
+
+			g := &gateway{timeout: tt.timeout}
+			front := httptest.NewServer(g)
+			defer front.Close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: 2 * time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("gateway", strings.Split(front.URL, ":")[2])
+					do.MockProcess("backend", strings.Split(backend.URL, ":")[2])
+
+					g.mu.Lock()
+					g.backend = do.Link("gateway", "backend")
+					g.mu.Unlock()
+				}).
+				Test(tt.name, func(do *Do) {
+					do.HTTP("gateway", "GET", "/").T().
+						Status(Is(200)).
+						Assert("Gateway should reach its backend")
+
+					do.InjectToxic("gateway", "backend", tt.toxics...)
+					do.HTTP("gateway", "GET", "/").T().
+						Status(Is(tt.status)).
+						Assert(fmt.Sprintf("Gateway should answer %d", tt.status))
+
+					do.ClearToxics("gateway", "backend")
+					do.HTTP("gateway", "GET", "/").T().
+						Status(Is(200)).
+						Assert("Gateway should recover once the toxics are cleared")
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: 2 * time.Second}).
+		Setup(func(do *Do) {
+			do.MockProcess("api", strings.Split(backend.URL, ":")[2])
+			do.Proxy("slow-api", "api")
+		}).
+		Test("Proxy Adds Latency", func(do *Do) {
+			do.InjectToxic("slow-api", "api", Latency(100*time.Millisecond))
+
+			start := time.Now()
+			do.HTTP("slow-api", "GET", "/").T().
+				Body(Is("ok")).
+				Assert("Proxy should forward the request")
+
+			if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+				panic(fmt.Sprintf("request took %s through a 100ms link", elapsed))
+			}
+		}).
+		Run(context.Background())
+
+	if !success {
+		t.Error("Proxy test should pass but failed")
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	payload := strings.Repeat("x", 20000)
+
+	tests := []struct {
+		name       string
+		bandwidth  int
+		deadline   time.Duration
+		shouldPass bool
+	}{
+		{name: "Within Deadline", bandwidth: 200000, deadline: time.Second, shouldPass: true},
+		{name: "Too Slow For Deadline", bandwidth: 50000, deadline: 200 * time.Millisecond, shouldPass: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, payload)
+			}))
+			defer backend.Close()
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: 2 * time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("files", strings.Split(backend.URL, ":")[2])
+					do.Proxy("slow-files", "files")
+				}).
+				Test(tt.name, func(do *Do) {
+					do.InjectToxic("slow-files", "files", Bandwidth(tt.bandwidth))
+
+					do.HTTP("slow-files", "GET", "/").Deadline(tt.deadline).T().
+						Body(Is(payload)).
+						Assert("Transfer should complete intact in time")
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package attest_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// serveUDP answers each datagram with the reply from handler, or drops it
+// when handler returns an empty string.
+func serveUDP(t *testing.T, handler func(payload string) string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if reply := handler(string(buf[:n])); reply != "" {
+				conn.WriteTo([]byte(reply), addr)
+			}
+		}
+	}()
+
+	return strconv.Itoa(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+func TestUDP(t *testing.T) {
+	// pong replies to pings and drops everything else.
+	pong := func(payload string) string {
+		if strings.HasPrefix(payload, "ping") {
+			return "pong" + strings.TrimPrefix(payload, "ping")
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name       string
+		handler    func(string) string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name:    "Response",
+			handler: pong,
+			testFunc: func(do *Do) {
+				do.UDP("svc", "ping 1").T().
+					Response(Is("pong 1")).
+					Assert("Server should answer pings")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Response Mismatch",
+			handler: pong,
+			testFunc: func(do *Do) {
+				do.UDP("svc", "ping 1").T().
+					Response(Is("pong 2")).
+					Assert("Should fail when the reply differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name:    "Missing Response",
+			handler: pong,
+			testFunc: func(do *Do) {
+				do.UDP("svc", "junk").Timeout(100 * time.Millisecond).T().
+					Response(HasPrefix("pong")).
+					Assert("Should fail when the server drops the datagram")
+			},
+			shouldPass: false,
+		},
+		{
+			name:    "No Response",
+			handler: pong,
+			testFunc: func(do *Do) {
+				do.UDP("svc", "junk").Timeout(100 * time.Millisecond).T().
+					NoResponse().
+					Assert("Server should drop malformed datagrams")
+			},
+			shouldPass: true,
+		},
+		{
+			name:    "Unexpected Response",
+			handler: pong,
+			testFunc: func(do *Do) {
+				do.UDP("svc", "ping").Timeout(100 * time.Millisecond).T().
+					NoResponse().
+					Assert("Should fail when the server replies")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Eventually OK",
+			handler: func() func(string) string {
+				var received atomic.Int32
+				return func(payload string) string {
+					// Drop the first few datagrams like a lossy network
+					if received.Add(1) < 3 {
+						return ""
+					}
+					return "pong"
+				}
+			}(),
+			testFunc: func(do *Do) {
+				do.UDP("svc", "ping").Timeout(50 * time.Millisecond).Eventually().T().
+					Response(Is("pong")).
+					Assert("Server should answer a retransmitted ping")
+			},
+			shouldPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := serveUDP(t, tt.handler)
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir()}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package attest_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// upstreamSuite runs a suite with a recovering HTTP backend and a TCP cache
+// upstream. The suite's process-less tests reach the backend as "direct".
+func upstreamSuite(config *Config) *Suite {
+	return New().WithConfig(config).
+		Upstream("backend-1", HTTPUpstream().
+			Route("GET /health", Reply(http.StatusServiceUnavailable, ""), Reply(http.StatusOK, "ok")).
+			Route("GET /items/{id}", Reply(http.StatusOK, `{"id":1}`).WithHeader("Content-Type", "application/json")).
+			Route("GET /slow", Reply(http.StatusOK, "late").After(200*time.Millisecond))).
+		Upstream("cache", TCPUpstream().
+			Answer("PING\r\n", "+PONG\r\n").
+			Echo())
+}
+
+// exchange writes request to addr and reads one line back.
+func exchange(addr, request string) string {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err.Error()
+	}
+	defer conn.Close()
+
+	conn.Write([]byte(request))
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	line, _ := bufio.NewReader(conn).ReadString('\n')
+	return line
+}
+
+func TestUpstream(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Scripted Routes",
+			testFunc: func(do *Do) {
+				do.MockProcess("direct", strings.Split(do.UpstreamAddr("backend-1"), ":")[1])
+
+				do.HTTP("direct", "GET", "/health").T().
+					Status(Is(503)).
+					Assert("Backend should start unhealthy")
+
+				do.HTTP("direct", "GET", "/health").T().
+					Status(Is(200)).
+					Body(Is("ok")).
+					Assert("Backend should recover")
+
+				do.HTTP("direct", "GET", "/health").T().
+					Status(Is(200)).
+					Assert("Backend should stay healthy")
+
+				do.HTTP("direct", "GET", "/items/1").T().
+					Header("Content-Type", Is("application/json")).
+					JSON("id", Is("1")).
+					Assert("Backend should serve the item")
+
+				do.HTTP("direct", "GET", "/missing").T().
+					Status(Is(404)).
+					Assert("Backend should not serve unknown routes")
+
+				do.Outbound("backend-1").Path("/health").T().
+					Count(Is(3)).
+					Assert("Backend should record its requests")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Delayed Response",
+			testFunc: func(do *Do) {
+				do.MockProcess("direct", strings.Split(do.UpstreamAddr("backend-1"), ":")[1])
+
+				do.HTTP("direct", "GET", "/slow").T().
+					Latency(AtLeast(200 * time.Millisecond)).
+					Body(Is("late")).
+					Assert("Backend should delay the response")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "TCP",
+			testFunc: func(do *Do) {
+				if line := exchange(do.UpstreamAddr("cache"), "PING\r\n"); line != "+PONG\r\n" {
+					panic("PING answered with " + line)
+				}
+				if line := exchange(do.UpstreamAddr("cache"), "hello\n"); line != "hello\n" {
+					panic("hello echoed as " + line)
+				}
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Unknown Upstream",
+			testFunc: func(do *Do) {
+				do.UpstreamAddr("backend-2")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success := upstreamSuite(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestUpstreamEnv(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(shutdownEnv, "graceful")
+
+	config := &Config{
+		Command:                executable,
+		WorkingDir:             t.TempDir(),
+		ExecuteTimeout:         time.Second,
+		ProcessShutdownTimeout: time.Second,
+	}
+
+	success := upstreamSuite(config).
+		Setup(func(do *Do) {
+			do.Start("server")
+		}).
+		Test("Env", func(do *Do) {
+			do.HTTP("server", "GET", "/env?name=UPSTREAM_BACKEND_1").T().
+				Body(Is(do.UpstreamAddr("backend-1"))).
+				Assert("Server should be given the backend's address")
+
+			do.HTTP("server", "GET", "/env?name=UPSTREAM_CACHE").T().
+				Body(Is(do.UpstreamAddr("cache"))).
+				Assert("Server should be given the cache's address")
+		}).
+		Run(context.Background())
+
+	if !success {
+		t.Error("Env test should pass but failed")
+	}
+}
@@ -0,0 +1,168 @@
+package attest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	. "github.com/littleclusters/lc/internal/attest"
+)
+
+// pubsub is a WebSocket handler that acknowledges subscriptions, echoes
+// other messages, and closes the connection with code 4000 on "bye".
+func pubsub(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "Bearer invalid" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx := r.Context()
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		msg := string(data)
+		switch {
+		case msg == "bye":
+			conn.Close(4000, "bye")
+			return
+		case msg == "silence":
+		case strings.HasPrefix(msg, "subscribe "):
+			topic := strings.TrimPrefix(msg, "subscribe ")
+			conn.Write(ctx, websocket.MessageText, []byte("subscribed "+topic))
+			conn.Write(ctx, websocket.MessageText, []byte(topic+": hello"))
+		default:
+			conn.Write(ctx, typ, data)
+		}
+	}
+}
+
+func TestWS(t *testing.T) {
+	tests := []struct {
+		name       string
+		testFunc   func(*Do)
+		shouldPass bool
+	}{
+		{
+			name: "Echo",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("hello").T().
+					Text(Is("hello")).
+					Assert("Server should echo text messages")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Messages in Order",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("subscribe news").T().
+					Text(Is("subscribed news")).
+					Text(HasPrefix("news:")).
+					Assert("Server should acknowledge then deliver")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Message Mismatch",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("subscribe news").T().
+					Text(Is("subscribed news")).
+					Text(Is("news: goodbye")).
+					Assert("Should fail when the second message differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Binary",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendBinary([]byte{0x01, 0x02}).T().
+					Binary(Is("\x01\x02")).
+					Assert("Server should echo binary messages")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Message Type Mismatch",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendBinary([]byte("hello")).T().
+					Text(Is("hello")).
+					Assert("Should fail when a binary message arrives instead of text")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Message Timeout",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("silence").MessageTimeout(100 * time.Millisecond).T().
+					Text(Is("anything")).
+					Assert("Should fail when no message arrives in time")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Close Code",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("bye").T().
+					CloseCode(Is(4000)).
+					Assert("Server should close with the application code")
+			},
+			shouldPass: true,
+		},
+		{
+			name: "Close Code Mismatch",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws").SendText("bye").T().
+					CloseCode(Is(1000)).
+					Assert("Should fail when the close code differs")
+			},
+			shouldPass: false,
+		},
+		{
+			name: "Upgrade Rejected",
+			testFunc: func(do *Do) {
+				do.WS("svc", "/ws", H{"Authorization": "Bearer invalid"}).SendText("hello").T().
+					Text(Is("hello")).
+					Assert("Should fail when the upgrade is refused")
+			},
+			shouldPass: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(pubsub))
+			defer server.Close()
+
+			port := strings.Split(server.URL, ":")[2]
+
+			success := New().WithConfig(&Config{WorkingDir: t.TempDir(), ExecuteTimeout: time.Second}).
+				Setup(func(do *Do) {
+					do.MockProcess("svc", port)
+				}).
+				Test(tt.name, func(do *Do) {
+					tt.testFunc(do)
+				}).
+				Run(context.Background())
+
+			if success != tt.shouldPass {
+				if tt.shouldPass {
+					t.Errorf("%s test should pass but failed", tt.name)
+				} else {
+					t.Errorf("%s test should fail but passed", tt.name)
+				}
+			}
+		})
+	}
+}
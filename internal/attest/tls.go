@@ -0,0 +1,312 @@
+package attest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// TLSConfig configures the client side of a TLS connection. The zero value
+// negotiates any version and cipher, and skips verifying the server
+// certificate.
+type TLSConfig struct {
+	// Version pins the TLS version, e.g. tls.VersionTLS12.
+	Version uint16
+	// CipherSuites restricts the TLS 1.2 cipher suites offered. TLS 1.3
+	// suites are not configurable.
+	CipherSuites []uint16
+	// ServerName is sent as SNI and verified against the certificate.
+	// Empty uses the host being dialed.
+	ServerName string
+	// ALPN lists the application protocols offered, e.g. "h2".
+	ALPN []string
+	// RootCA is a PEM file the server certificate must chain to.
+	// Empty skips verification.
+	RootCA string
+	// ClientCert and ClientKey are PEM files presented for mutual TLS.
+	ClientCert string
+	ClientKey  string
+}
+
+// client returns the tls.Config for connecting to host.
+func (c *TLSConfig) client(host string) *tls.Config {
+	if c == nil {
+		return nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:   c.ServerName,
+		MinVersion:   c.Version,
+		MaxVersion:   c.Version,
+		CipherSuites: c.CipherSuites,
+		NextProtos:   c.ALPN,
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+
+	if c.RootCA == "" {
+		cfg.InsecureSkipVerify = true
+	} else {
+		pem, err := os.ReadFile(c.RootCA)
+		if err != nil {
+			panic(fmt.Sprintf("An error occurred: %v", err))
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			panic(fmt.Sprintf("An error occurred: no certificates in %s", c.RootCA))
+		}
+	}
+
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			panic(fmt.Sprintf("An error occurred: %v", err))
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg
+}
+
+// TLS connects over TLS, e.g. to test a TLS-terminating server. An optional
+// config pins the version, ciphers and SNI, or presents a client certificate.
+func (p *HTTPPlan) TLS(config ...TLSConfig) *HTTPPlan {
+	p.tls = newTLSConfig(config)
+	return p
+}
+
+// TLS wraps the connection in TLS before any data is sent. An optional
+// config pins the version, ciphers and SNI, or presents a client certificate.
+func (p *TCPPlan) TLS(config ...TLSConfig) *TCPPlan {
+	p.tls = newTLSConfig(config)
+	return p
+}
+
+func newTLSConfig(config []TLSConfig) *TLSConfig {
+	if len(config) == 0 {
+		return &TLSConfig{}
+	}
+
+	return &config[0]
+}
+
+// tlsAssert holds the TLS expectations shared by the HTTP and TCP asserts.
+type tlsAssert struct {
+	state *tls.ConnectionState
+	err   error
+
+	versionCheckers []Checker[string]
+	cipherCheckers  []Checker[string]
+	alpnCheckers    []Checker[string]
+	chainCheckers   []Checker[string]
+	dnsCheckers     []Checker[string]
+	errorCheckers   []Checker[string]
+}
+
+// TLSVersion adds checkers for the negotiated TLS version, e.g. "TLS 1.3".
+// All checkers must pass.
+func (a *HTTPAssert) TLSVersion(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.versionCheckers = append(a.tls.versionCheckers, checkers...)
+	return a
+}
+
+// CipherSuite adds checkers for the negotiated cipher suite, e.g.
+// "TLS_AES_128_GCM_SHA256". All checkers must pass.
+func (a *HTTPAssert) CipherSuite(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.cipherCheckers = append(a.tls.cipherCheckers, checkers...)
+	return a
+}
+
+// ALPN adds checkers for the protocol negotiated with ALPN, e.g. "h2".
+// All checkers must pass.
+func (a *HTTPAssert) ALPN(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.alpnCheckers = append(a.tls.alpnCheckers, checkers...)
+	return a
+}
+
+// CertChain adds checkers for the subject common names of the server's
+// certificate chain, leaf first, joined by " > ". All checkers must pass.
+func (a *HTTPAssert) CertChain(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.chainCheckers = append(a.tls.chainCheckers, checkers...)
+	return a
+}
+
+// CertDNSNames adds checkers for the DNS names of the server's leaf
+// certificate, joined by ", ". All checkers must pass.
+func (a *HTTPAssert) CertDNSNames(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.dnsCheckers = append(a.tls.dnsCheckers, checkers...)
+	return a
+}
+
+// TLSError expects the TLS connection to fail, e.g. when the server requires
+// a client certificate, with an error passing all checkers.
+func (a *HTTPAssert) TLSError(checkers ...Checker[string]) *HTTPAssert {
+	a.tls.errorCheckers = append(a.tls.errorCheckers, checkers...)
+	if len(a.tls.errorCheckers) == 0 {
+		a.tls.errorCheckers = []Checker[string]{Not(Is(""))}
+	}
+	return a
+}
+
+// TLSVersion adds checkers for the negotiated TLS version, e.g. "TLS 1.3".
+// All checkers must pass.
+func (a *TCPAssert) TLSVersion(checkers ...Checker[string]) *TCPAssert {
+	a.tls.versionCheckers = append(a.tls.versionCheckers, checkers...)
+	return a
+}
+
+// CipherSuite adds checkers for the negotiated cipher suite, e.g.
+// "TLS_AES_128_GCM_SHA256". All checkers must pass.
+func (a *TCPAssert) CipherSuite(checkers ...Checker[string]) *TCPAssert {
+	a.tls.cipherCheckers = append(a.tls.cipherCheckers, checkers...)
+	return a
+}
+
+// ALPN adds checkers for the protocol negotiated with ALPN.
+// All checkers must pass.
+func (a *TCPAssert) ALPN(checkers ...Checker[string]) *TCPAssert {
+	a.tls.alpnCheckers = append(a.tls.alpnCheckers, checkers...)
+	return a
+}
+
+// CertChain adds checkers for the subject common names of the server's
+// certificate chain, leaf first, joined by " > ". All checkers must pass.
+func (a *TCPAssert) CertChain(checkers ...Checker[string]) *TCPAssert {
+	a.tls.chainCheckers = append(a.tls.chainCheckers, checkers...)
+	return a
+}
+
+// CertDNSNames adds checkers for the DNS names of the server's leaf
+// certificate, joined by ", ". All checkers must pass.
+func (a *TCPAssert) CertDNSNames(checkers ...Checker[string]) *TCPAssert {
+	a.tls.dnsCheckers = append(a.tls.dnsCheckers, checkers...)
+	return a
+}
+
+// TLSError expects the TLS connection to fail, e.g. when the server requires
+// a client certificate, with an error passing all checkers. With TLS 1.3 the
+// server rejects a client certificate after the handshake, so errors on the
+// first read count too.
+func (a *TCPAssert) TLSError(checkers ...Checker[string]) *TCPAssert {
+	a.tls.errorCheckers = append(a.tls.errorCheckers, checkers...)
+	if len(a.tls.errorCheckers) == 0 {
+		a.tls.errorCheckers = []Checker[string]{Not(Is(""))}
+	}
+	return a
+}
+
+// version returns the negotiated TLS version name.
+func (t *tlsAssert) version() string {
+	return tls.VersionName(t.state.Version)
+}
+
+// cipher returns the negotiated cipher suite name.
+func (t *tlsAssert) cipher() string {
+	return tls.CipherSuiteName(t.state.CipherSuite)
+}
+
+// chain returns the subject common names of the peer certificates.
+func (t *tlsAssert) chain() string {
+	names := make([]string, len(t.state.PeerCertificates))
+	for i, cert := range t.state.PeerCertificates {
+		names[i] = cert.Subject.CommonName
+	}
+
+	return strings.Join(names, " > ")
+}
+
+// dnsNames returns the DNS names of the leaf certificate.
+func (t *tlsAssert) dnsNames() string {
+	if len(t.state.PeerCertificates) == 0 {
+		return ""
+	}
+
+	return strings.Join(t.state.PeerCertificates[0].DNSNames, ", ")
+}
+
+// expectsState reports whether any checker needs a connection state.
+func (t *tlsAssert) expectsState() bool {
+	return len(t.versionCheckers) > 0 || len(t.cipherCheckers) > 0 || len(t.alpnCheckers) > 0 ||
+		len(t.chainCheckers) > 0 || len(t.dnsCheckers) > 0
+}
+
+// failed reports whether the connection failed or was expected to.
+func (t *tlsAssert) failed() bool {
+	return t.err != nil || len(t.errorCheckers) > 0
+}
+
+// passes reports whether the connection meets the TLS expectations.
+func (t *tlsAssert) passes() bool {
+	if t.failed() {
+		return t.err != nil && len(t.errorCheckers) > 0 && checkAll(t.err.Error(), t.errorCheckers, nil)
+	}
+
+	if t.state == nil {
+		return !t.expectsState()
+	}
+
+	return checkAll(t.version(), t.versionCheckers, nil) &&
+		checkAll(t.cipher(), t.cipherCheckers, nil) &&
+		checkAll(t.state.NegotiatedProtocol, t.alpnCheckers, nil) &&
+		checkAll(t.chain(), t.chainCheckers, nil) &&
+		checkAll(t.dnsNames(), t.dnsCheckers, nil)
+}
+
+// check calls fail with the details of the first TLS expectation that
+// failed. It reports whether the connection failed, leaving nothing else to
+// check.
+func (t *tlsAssert) check(fail func(detail, field, expected string)) bool {
+	if t.failed() {
+		if len(t.errorCheckers) == 0 {
+			fail(fmt.Sprintf("  Expected a TLS connection\n  Error: %v", t.err), "tls", "a connection")
+		}
+		if t.err == nil {
+			fail("  Expected the TLS connection to fail\n  Actual: connected", "tls", "an error")
+		}
+
+		checkAll(t.err.Error(), t.errorCheckers, func(m Checker[string], actual string) {
+			fail(fmt.Sprintf("  Expected TLS error: %s\n  Actual TLS error: %s", m.Expected(), actual), "tls", m.Expected())
+		})
+
+		return true
+	}
+
+	if t.state == nil {
+		if t.expectsState() {
+			fail("  Expected a TLS connection\n  Actual: plain connection", "tls", "a connection")
+		}
+
+		return false
+	}
+
+	checks := []struct {
+		name     string
+		actual   string
+		checkers []Checker[string]
+	}{
+		{"TLS version", t.version(), t.versionCheckers},
+		{"cipher suite", t.cipher(), t.cipherCheckers},
+		{"ALPN protocol", t.state.NegotiatedProtocol, t.alpnCheckers},
+		{"certificate chain", t.chain(), t.chainCheckers},
+		{"certificate DNS names", t.dnsNames(), t.dnsCheckers},
+	}
+	for _, c := range checks {
+		checkAll(c.actual, c.checkers, func(m Checker[string], actual string) {
+			fail(fmt.Sprintf("  Expected %s: %s\n  Actual %s: %q", c.name, m.Expected(), c.name, actual), "tls", m.Expected())
+		})
+	}
+
+	return false
+}
+
+// isTLSAlert reports whether err is an alert sent by the TLS peer, which is
+// how TLS 1.3 servers reject a client certificate after the handshake.
+func isTLSAlert(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error"
+}
@@ -0,0 +1,187 @@
+package attest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// retransmitDelay is how long a lost packet holds up a TCP stream before
+// it is sent again.
+const retransmitDelay = 200 * time.Millisecond
+
+// Toxic degrades the traffic through a link, e.g. to check that the
+// implementation times out, retries or hedges. It applies to the data
+// sent each way.
+type Toxic struct {
+	latency time.Duration
+	jitter  time.Duration
+	loss    float64
+	reset   float64
+	// bandwidth is the bytes per second let through, or 0 for no cap.
+	bandwidth int
+}
+
+// Latency holds the data sent each way for d, so a round trip takes 2d
+// longer.
+func Latency(d time.Duration) Toxic {
+	return Toxic{latency: d}
+}
+
+// Jitter holds the data sent each way for a random time up to d, e.g.
+// after Latency for a varying delay.
+func Jitter(d time.Duration) Toxic {
+	return Toxic{jitter: d}
+}
+
+// PacketLoss loses the given fraction of the data sent, from 0 to 1. As
+// TCP sends it again, each loss holds up the stream for 200ms rather than
+// corrupting it.
+func PacketLoss(rate float64) Toxic {
+	return Toxic{loss: rate}
+}
+
+// ResetConnection resets the connection when data is sent, with the given
+// chance from 0 to 1, e.g. 1 to reset every connection on its first
+// request.
+func ResetConnection(rate float64) Toxic {
+	return Toxic{reset: rate}
+}
+
+// Bandwidth caps the data sent each way at bytesPerSecond, e.g. to check
+// backpressure or progress reporting on a slow transfer. It is sent in
+// steps of a tenth of a second, so reads see it trickle in.
+func Bandwidth(bytesPerSecond int) Toxic {
+	if bytesPerSecond < 1 {
+		panic("Bandwidth() requires at least one byte per second")
+	}
+
+	return Toxic{bandwidth: bytesPerSecond}
+}
+
+// InjectToxic adds toxics to the link from one node to another from now
+// on, until ClearToxics.
+func (do *Do) InjectToxic(from, to string, toxics ...Toxic) {
+	do.network.getLink(from, to).toxics.add(toxics)
+}
+
+// ClearToxics removes the toxics injected into the link from one node to
+// another, so its traffic flows freely again.
+func (do *Do) ClearToxics(from, to string) {
+	do.network.getLink(from, to).toxics.clear()
+}
+
+// toxics holds the toxics active on a link.
+type toxics struct {
+	mu     sync.Mutex
+	active []Toxic
+	rand   *rand.Rand
+}
+
+func (t *toxics) add(toxics []Toxic) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active = append(t.active, toxics...)
+}
+
+func (t *toxics) clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active = nil
+}
+
+// take returns what the active toxics do to the next data sent, with the
+// lowest bandwidth cap, or 0 if there is none.
+func (t *toxics) take() (delay time.Duration, reset bool, bandwidth int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, toxic := range t.active {
+		delay += toxic.latency
+		if toxic.jitter > 0 {
+			delay += time.Duration(t.rand.Int64N(int64(toxic.jitter)))
+		}
+		if toxic.loss > 0 && t.rand.Float64() < toxic.loss {
+			delay += retransmitDelay
+		}
+		reset = reset || (toxic.reset > 0 && t.rand.Float64() < toxic.reset)
+		if toxic.bandwidth > 0 && (bandwidth == 0 || toxic.bandwidth < bandwidth) {
+			bandwidth = toxic.bandwidth
+		}
+	}
+
+	return delay, reset, bandwidth
+}
+
+// pipe copies src to dst through the link's toxics until either closes or
+// a toxic resets them.
+func (l *link) pipe(dst, src net.Conn) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			delay, reset, bandwidth := l.toxics.take()
+			if reset {
+				resetConn(src)
+				resetConn(dst)
+				return
+			}
+			time.Sleep(delay)
+
+			if err := throttledWrite(dst, buf[:n], bandwidth); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// throttledWrite writes data to dst at bandwidth bytes per second, in steps
+// of a tenth of a second, or at once if bandwidth is 0.
+func throttledWrite(dst net.Conn, data []byte, bandwidth int) error {
+	if bandwidth == 0 {
+		_, err := dst.Write(data)
+		return err
+	}
+
+	step := max(bandwidth/10, 1)
+	for len(data) > 0 {
+		n := min(step, len(data))
+		start := time.Now()
+		if _, err := dst.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+
+		time.Sleep(time.Duration(n)*time.Second/time.Duration(bandwidth) - time.Since(start))
+	}
+
+	return nil
+}
+
+// resetConn closes conn with a TCP reset rather than a graceful close.
+func resetConn(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// getLink retrieves the link from one node to another or panics if Link
+// never created it.
+func (n *network) getLink(from, to string) *link {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if l, exists := n.links[from+"->"+to]; exists {
+		return l
+	}
+
+	panic(fmt.Sprintf("no link from %q to %q", from, to))
+}
@@ -0,0 +1,205 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+var _ Plan[*UDPPlan, *UDPAssert] = (*UDPPlan)(nil)
+var _ Assert = (*UDPAssert)(nil)
+
+// defaultDatagramTimeout is how long a UDP plan waits for a reply.
+const defaultDatagramTimeout = time.Second
+
+// maxDatagramSize is the largest UDP payload.
+const maxDatagramSize = 65535
+
+// UDPPlan represents a test plan that sends a single datagram and waits for
+// the reply. Each execution uses a new socket.
+type UDPPlan struct {
+	PlanBase
+
+	process string
+	addr    string
+	payload []byte
+	wait    time.Duration
+}
+
+// Timeout sets how long to wait for the reply, one second by default.
+func (p *UDPPlan) Timeout(d time.Duration) *UDPPlan {
+	p.wait = d
+	return p
+}
+
+func (p *UDPPlan) Eventually() *UDPPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *UDPPlan) Within(timeout time.Duration) *UDPPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *UDPPlan) Retry(policy RetryPolicy) *UDPPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *UDPPlan) Consistently() *UDPPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *UDPPlan) For(timeout time.Duration) *UDPPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *UDPPlan) Every(interval time.Duration) *UDPPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *UDPPlan) Deadline(d time.Duration) *UDPPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *UDPPlan) T() *UDPAssert {
+	return &UDPAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// UDPAssert provides assertions on the reply to a datagram.
+type UDPAssert struct {
+	AssertBase
+
+	plan     *UDPPlan
+	response string
+	replied  bool
+	err      error
+
+	responseCheckers []Checker[string]
+	noResponse       bool
+}
+
+// Response adds checkers for the reply payload. A reply is required.
+// All checkers must pass.
+func (a *UDPAssert) Response(checkers ...Checker[string]) *UDPAssert {
+	a.responseCheckers = append(a.responseCheckers, checkers...)
+	return a
+}
+
+// NoResponse expects no reply before the timeout, e.g. for malformed packets
+// a server should drop.
+func (a *UDPAssert) NoResponse() *UDPAssert {
+	a.noResponse = true
+	return a
+}
+
+func (a *UDPAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *UDPAssert) execute() bool {
+	p := a.plan
+	p.config.Recorder.record(Entry{Kind: EntryUDP, Process: p.process, Body: string(p.payload)})
+
+	a.response, a.replied, a.err = exchangeDatagram(p.ctx, p.addr, p.payload, p.replyTimeout())
+
+	if a.noResponse {
+		return !a.replied
+	}
+
+	return a.replied && checkAll(a.response, a.responseCheckers, nil)
+}
+
+// replyTimeout returns how long to wait for a reply.
+func (p *UDPPlan) replyTimeout() time.Duration {
+	if p.wait == 0 {
+		return defaultDatagramTimeout
+	}
+
+	return p.wait
+}
+
+// exchangeDatagram sends payload to addr and waits up to timeout for a reply.
+// A port nobody listens on counts as no reply, with the error kept for context.
+func exchangeDatagram(ctx context.Context, addr string, payload []byte, timeout time.Duration) (string, bool, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return "", false, err
+	}
+	defer conn.Close()
+	defer closeWhenDone(ctx, conn)()
+
+	_, err = conn.Write(payload)
+	if err != nil {
+		return "", false, err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, maxDatagramSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return string(buf[:n]), true, nil
+}
+
+func (a *UDPAssert) check() {
+	p := a.plan
+	title := fmt.Sprintf("UDP %s\n  Sent: %q", p.addr, p.payload)
+
+	if a.noResponse {
+		if a.replied {
+			msg := fmt.Sprintf("%s\n  Expected no response within %s\n  Actual response:%s%s",
+				title, p.replyTimeout(), formatPayload(a.response), a.formatHelp())
+			panic(msg)
+		}
+
+		return
+	}
+
+	if !a.replied {
+		actual := fmt.Sprintf("none within %s", p.replyTimeout())
+		if a.err != nil {
+			actual = a.err.Error()
+		}
+
+		msg := fmt.Sprintf("%s\n  Expected a response\n  Actual response: %s%s", title, actual, a.formatHelp())
+		panic(msg)
+	}
+
+	checkAll(a.response, a.responseCheckers, func(m Checker[string], actual string) {
+		msg := fmt.Sprintf("%s\n%s%s%s",
+			title, payloadMismatch(m, actual), explain(m, actual), a.formatHelp())
+		panic(msg)
+	})
+}
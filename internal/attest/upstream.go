@@ -0,0 +1,300 @@
+package attest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upstream is a backend the harness runs for the implementation, e.g. one
+// of the servers a proxy or load balancer forwards to. Every process is
+// started with its address in UPSTREAM_<NAME>, e.g. UPSTREAM_BACKEND_1 for
+// backend-1.
+type Upstream struct {
+	tcp bool
+
+	routes []upstreamRoute
+
+	answers []tcpAnswer
+	echo    bool
+}
+
+// upstreamRoute scripts the responses to an HTTP upstream route.
+type upstreamRoute struct {
+	pattern   string
+	responses []UpstreamResponse
+}
+
+// tcpAnswer is what a TCP upstream writes back for a request.
+type tcpAnswer struct {
+	request  string
+	response string
+}
+
+// UpstreamResponse is a scripted response from an HTTP upstream.
+type UpstreamResponse struct {
+	status  int
+	body    string
+	headers H
+	delay   time.Duration
+	times   int
+}
+
+// Reply creates a scripted response.
+func Reply(status int, body string) UpstreamResponse {
+	return UpstreamResponse{status: status, body: body}
+}
+
+// WithHeader adds a header to the response.
+func (r UpstreamResponse) WithHeader(key, value string) UpstreamResponse {
+	headers := make(H, len(r.headers)+1)
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	headers[key] = value
+
+	r.headers = headers
+	return r
+}
+
+// After delays the response by d, e.g. to check a proxy's timeout.
+func (r UpstreamResponse) After(d time.Duration) UpstreamResponse {
+	r.delay = d
+	return r
+}
+
+// Times sends the response for n requests in a row, e.g. Reply(500, "")
+// .Times(3) before the route recovers.
+func (r UpstreamResponse) Times(n int) UpstreamResponse {
+	r.times = n
+	return r
+}
+
+// HTTPUpstream creates an HTTP upstream. Requests matching no route get
+// 404 Not Found. Its requests can be checked with Outbound.
+func HTTPUpstream() *Upstream {
+	return &Upstream{}
+}
+
+// TCPUpstream creates a TCP upstream that answers requests it knows and
+// ignores the rest.
+func TCPUpstream() *Upstream {
+	return &Upstream{tcp: true}
+}
+
+// Route scripts the responses to requests matching pattern, in
+// http.ServeMux syntax, e.g. "GET /items/{id}". The responses are sent in
+// turn and the last repeats, e.g. Reply(503, "") then Reply(200, "ok") for
+// a backend that recovers.
+func (u *Upstream) Route(pattern string, responses ...UpstreamResponse) *Upstream {
+	if len(responses) == 0 {
+		panic(fmt.Sprintf("Route(%q) needs at least one response", pattern))
+	}
+
+	var script []UpstreamResponse
+	for _, resp := range responses {
+		for range max(resp.times, 1) {
+			script = append(script, resp)
+		}
+	}
+
+	u.routes = append(u.routes, upstreamRoute{pattern: pattern, responses: script})
+	return u
+}
+
+// Answer makes a TCP upstream write response whenever it reads request,
+// e.g. "PING\r\n" and "+PONG\r\n".
+func (u *Upstream) Answer(request, response string) *Upstream {
+	u.answers = append(u.answers, tcpAnswer{request: request, response: response})
+	return u
+}
+
+// Echo makes a TCP upstream write back whatever it reads that no Answer
+// matches.
+func (u *Upstream) Echo() *Upstream {
+	u.echo = true
+	return u
+}
+
+// Upstream declares an upstream the suite runs for the whole run.
+func (s *Suite) Upstream(name string, upstream *Upstream) *Suite {
+	s.upstreams = append(s.upstreams, namedUpstream{name: name, upstream: upstream})
+	return s
+}
+
+// namedUpstream is an upstream declared on a suite.
+type namedUpstream struct {
+	name     string
+	upstream *Upstream
+}
+
+// runningUpstream is an upstream started for a run.
+type runningUpstream struct {
+	addr   string
+	faults *faults
+}
+
+// UpstreamAddr returns the host:port an upstream listens on, e.g. to pass
+// it as a flag.
+func (do *Do) UpstreamAddr(name string) string {
+	return do.getUpstream(name).addr
+}
+
+// getUpstream retrieves a running upstream by name or panics if not found.
+func (do *Do) getUpstream(name string) *runningUpstream {
+	if u, exists := do.upstreams.Get(name); exists {
+		return u
+	}
+
+	panic(fmt.Sprintf("upstream %q not found", name))
+}
+
+// startUpstreams starts the suite's upstreams.
+func (do *Do) startUpstreams(upstreams []namedUpstream) {
+	for _, u := range upstreams {
+		running := &runningUpstream{faults: &faults{}}
+		if u.upstream.tcp {
+			running.addr = do.startTCPUpstream(u.upstream, running.faults)
+		} else {
+			running.addr = do.intercept(u.name, running.faults.wrap(u.upstream.handler())).Addr()
+		}
+
+		do.upstreams.Set(u.name, running)
+	}
+}
+
+// upstreamEnv returns UPSTREAM_<NAME>=host:port for every upstream.
+func (do *Do) upstreamEnv() []string {
+	var env []string
+	do.upstreams.Range(func(name string, u *runningUpstream) bool {
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		env = append(env, fmt.Sprintf("UPSTREAM_%s=%s", key, u.addr))
+		return true
+	})
+
+	slices.Sort(env)
+	return env
+}
+
+// handler serves the upstream's routes.
+func (u *Upstream) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, route := range u.routes {
+		var mu sync.Mutex
+		next := 0
+
+		mux.HandleFunc(route.pattern, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			resp := route.responses[next]
+			if next < len(route.responses)-1 {
+				next++
+			}
+			mu.Unlock()
+
+			if resp.delay > 0 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(resp.delay):
+				}
+			}
+
+			for key, value := range resp.headers {
+				w.Header().Set(key, value)
+			}
+			w.WriteHeader(resp.status)
+			io.WriteString(w, resp.body)
+		})
+	}
+
+	return mux
+}
+
+// startTCPUpstream starts a TCP upstream until the run is done and returns
+// its address.
+func (do *Do) startTCPUpstream(u *Upstream, faults *faults) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to start upstream: %v", err))
+	}
+
+	go func() {
+		<-do.ctx.Done()
+		listener.Close()
+	}()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go u.serveTCP(conn, faults)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// serveTCP answers the requests read from conn until it closes, or until
+// a fault drops it.
+func (u *Upstream) serveTCP(conn net.Conn, faults *faults) {
+	defer conn.Close()
+
+	var pending []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		pending = append(pending, buf[:n]...)
+
+		delay, _, drop := faults.take()
+		if drop {
+			return
+		}
+		time.Sleep(delay)
+
+		for len(pending) > 0 {
+			answered := false
+			for _, a := range u.answers {
+				if bytes.HasPrefix(pending, []byte(a.request)) {
+					conn.Write([]byte(a.response))
+					pending = pending[len(a.request):]
+					answered = true
+					break
+				}
+			}
+			if answered {
+				continue
+			}
+
+			if !u.awaitsMore(pending) {
+				if u.echo {
+					conn.Write(pending)
+				}
+				pending = nil
+			}
+			break
+		}
+	}
+}
+
+// awaitsMore reports whether pending may still become a known request.
+func (u *Upstream) awaitsMore(pending []byte) bool {
+	for _, a := range u.answers {
+		if strings.HasPrefix(a.request, string(pending)) {
+			return true
+		}
+	}
+
+	return false
+}
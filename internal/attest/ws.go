@@ -0,0 +1,295 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+var _ Plan[*WSPlan, *WSAssert] = (*WSPlan)(nil)
+var _ Assert = (*WSAssert)(nil)
+
+// wsMessage is a message sent or expected on a WebSocket.
+type wsMessage struct {
+	binary bool
+	data   string
+}
+
+func (m wsMessage) String() string {
+	if m.binary {
+		return fmt.Sprintf("binary %q", m.data)
+	}
+
+	return fmt.Sprintf("text %q", m.data)
+}
+
+// WSPlan represents a test plan for a WebSocket session: upgrade the
+// connection, send messages, then read the expected messages in order.
+type WSPlan struct {
+	PlanBase
+
+	process string
+	path    string
+	url     string
+	headers H
+	sends   []wsMessage
+	wait    time.Duration
+}
+
+// SendText sends a text message.
+func (p *WSPlan) SendText(data string) *WSPlan {
+	p.sends = append(p.sends, wsMessage{data: data})
+	return p
+}
+
+// SendBinary sends a binary message.
+func (p *WSPlan) SendBinary(data []byte) *WSPlan {
+	p.sends = append(p.sends, wsMessage{binary: true, data: string(data)})
+	return p
+}
+
+// MessageTimeout sets how long to wait for each expected message.
+// The default is Config.ExecuteTimeout.
+func (p *WSPlan) MessageTimeout(d time.Duration) *WSPlan {
+	p.wait = d
+	return p
+}
+
+func (p *WSPlan) Eventually() *WSPlan {
+	p.setEventually()
+	return p
+}
+
+func (p *WSPlan) Within(timeout time.Duration) *WSPlan {
+	p.setWithin(timeout)
+	return p
+}
+
+func (p *WSPlan) Retry(policy RetryPolicy) *WSPlan {
+	p.setRetry(policy)
+	return p
+}
+
+func (p *WSPlan) Consistently() *WSPlan {
+	p.setConsistently()
+	return p
+}
+
+func (p *WSPlan) For(timeout time.Duration) *WSPlan {
+	p.setFor(timeout)
+	return p
+}
+
+func (p *WSPlan) Every(interval time.Duration) *WSPlan {
+	p.setEvery(interval)
+	return p
+}
+
+func (p *WSPlan) Deadline(d time.Duration) *WSPlan {
+	p.setDeadline(d)
+	return p
+}
+
+func (p *WSPlan) T() *WSAssert {
+	return &WSAssert{
+		AssertBase: AssertBase{config: p.config},
+		plan:       p,
+	}
+}
+
+// wsExpectation is an expected message, in order.
+type wsExpectation struct {
+	binary   bool
+	checkers []Checker[string]
+}
+
+// WSAssert provides assertions on the messages received over a WebSocket.
+type WSAssert struct {
+	AssertBase
+
+	plan     *WSPlan
+	received []wsMessage
+	// failed is the index of the expectation that was not met.
+	failed    int
+	closeCode int
+	err       error
+
+	expected      []wsExpectation
+	closeCheckers []Checker[int]
+}
+
+// Text expects the next message to be a text message passing all checkers.
+func (a *WSAssert) Text(checkers ...Checker[string]) *WSAssert {
+	a.expected = append(a.expected, wsExpectation{checkers: checkers})
+	return a
+}
+
+// Binary expects the next message to be a binary message passing all checkers.
+func (a *WSAssert) Binary(checkers ...Checker[string]) *WSAssert {
+	a.expected = append(a.expected, wsExpectation{binary: true, checkers: checkers})
+	return a
+}
+
+// CloseCode expects the server to close the connection after the expected
+// messages, with a status code passing all checkers.
+func (a *WSAssert) CloseCode(checkers ...Checker[int]) *WSAssert {
+	a.closeCheckers = append(a.closeCheckers, checkers...)
+	return a
+}
+
+func (a *WSAssert) Assert(help string) {
+	a.help = help
+
+	p := a.plan
+	defer p.startDeadline(&a.AssertBase)()
+
+	switch p.timing {
+	case TimingEventually:
+		eventually(p.ctx, a.execute, p.timeout, p.retryPolicy())
+	case TimingConsistently:
+		a.consistently(p.ctx, a.execute, p.timeout, p.pollInterval())
+	default:
+		a.execute()
+	}
+
+	a.verify(p.ctx, a.check)
+}
+
+func (a *WSAssert) execute() bool {
+	p := a.plan
+	a.received, a.failed, a.closeCode, a.err = nil, -1, 0, nil
+
+	wait := p.wait
+	if wait == 0 {
+		wait = a.config.ExecuteTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, a.config.ExecuteTimeout)
+	defer cancel()
+
+	header := http.Header{}
+	for key, value := range p.headers {
+		header.Set(key, value)
+	}
+
+	conn, _, err := websocket.Dial(ctx, p.url, &websocket.DialOptions{HTTPHeader: header})
+	if err != nil {
+		a.err = err
+		return false
+	}
+	defer conn.CloseNow()
+
+	for _, msg := range p.sends {
+		typ := websocket.MessageText
+		if msg.binary {
+			typ = websocket.MessageBinary
+		}
+
+		err := conn.Write(ctx, typ, []byte(msg.data))
+		if err != nil {
+			a.err = err
+			return false
+		}
+	}
+
+	for i, want := range a.expected {
+		msg, err := readMessage(p.ctx, conn, wait)
+		if err != nil {
+			a.failed, a.err = i, err
+			return false
+		}
+
+		a.received = append(a.received, msg)
+		if msg.binary != want.binary || !checkAll(msg.data, want.checkers, nil) {
+			a.failed = i
+			return false
+		}
+	}
+
+	if len(a.closeCheckers) > 0 {
+		_, err := readMessage(p.ctx, conn, wait)
+		a.closeCode = int(websocket.CloseStatus(err))
+		if a.closeCode == -1 {
+			a.err = err
+			return false
+		}
+
+		return checkAll(a.closeCode, a.closeCheckers, nil)
+	}
+
+	conn.Close(websocket.StatusNormalClosure, "")
+	return true
+}
+
+// readMessage reads the next message, waiting at most wait.
+func readMessage(ctx context.Context, conn *websocket.Conn, wait time.Duration) (wsMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	typ, data, err := conn.Read(ctx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return wsMessage{}, fmt.Errorf("no message within %s", wait)
+		}
+
+		return wsMessage{}, err
+	}
+
+	return wsMessage{binary: typ == websocket.MessageBinary, data: string(data)}, nil
+}
+
+func (a *WSAssert) check() {
+	p := a.plan
+
+	sent := make([]string, 0, len(p.sends))
+	for _, msg := range p.sends {
+		sent = append(sent, msg.String())
+	}
+	title := fmt.Sprintf("WebSocket %s\n  Sent: [%s]", p.url, strings.Join(sent, ", "))
+
+	if a.failed >= 0 {
+		want := a.expected[a.failed]
+		kind := "text"
+		if want.binary {
+			kind = "binary"
+		}
+
+		if a.failed >= len(a.received) {
+			msg := fmt.Sprintf("%s\n  Expected message %d: %s\n  Error: %v%s",
+				title, a.failed+1, kind, a.err, a.formatHelp())
+			panic(msg)
+		}
+
+		actual := a.received[a.failed]
+		if actual.binary != want.binary {
+			msg := fmt.Sprintf("%s\n  Expected message %d: %s\n  Actual message %d: %s%s",
+				title, a.failed+1, kind, a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		}
+
+		checkAll(actual.data, want.checkers, func(m Checker[string], actual string) {
+			msg := fmt.Sprintf("%s\n  Expected message %d: %s\n  Actual message %d: %q%s",
+				title, a.failed+1, m.Expected(), a.failed+1, actual, a.formatHelp())
+			panic(msg)
+		})
+	}
+
+	if a.err != nil {
+		msg := fmt.Sprintf("%s\n  Error: %v%s", title, a.err, a.formatHelp())
+		if len(a.closeCheckers) > 0 {
+			msg = fmt.Sprintf("%s\n  Expected the server to close the connection\n  Error: %v%s", title, a.err, a.formatHelp())
+		}
+		panic(msg)
+	}
+
+	checkAll(a.closeCode, a.closeCheckers, func(m Checker[int], actual int) {
+		msg := fmt.Sprintf("%s\n  Expected close code: %s\n  Actual close code: %d%s",
+			title, m.Expected(), actual, a.formatHelp())
+		panic(msg)
+	})
+}
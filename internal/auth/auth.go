@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const credentialsFile = "credentials.json"
+
+// ErrNotLoggedIn is returned when no credentials are stored.
+var ErrNotLoggedIn = errors.New("Not logged in.\nRun 'lc login' to authenticate.")
+
+// Credentials holds an authenticated session.
+type Credentials struct {
+	Token   string    `json:"token"`
+	User    string    `json:"user"`
+	Created time.Time `json:"created"`
+}
+
+// Load reads the credentials stored in dir.
+func Load(dir string) (*Credentials, error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, credentialsFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNotLoggedIn
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read credentials: %w", err)
+	}
+
+	var creds Credentials
+	err = json.Unmarshal(bytes, &creds)
+	if err != nil || creds.Token == "" {
+		return nil, fmt.Errorf("Invalid credentials file. Run 'lc login' again.")
+	}
+
+	return &creds, nil
+}
+
+// Save writes credentials to dir, readable only by the current user.
+func Save(dir string, creds *Credentials) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create credentials directory: %w", err)
+	}
+
+	bytes, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode credentials: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, credentialsFile), bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write credentials: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the credentials stored in dir.
+// It returns ErrNotLoggedIn if there were none.
+func Delete(dir string) error {
+	err := os.Remove(filepath.Join(dir, credentialsFile))
+	if os.IsNotExist(err) {
+		return ErrNotLoggedIn
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to remove credentials: %w", err)
+	}
+
+	return nil
+}
+
+// Client talks to the littleclusters authentication API.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// DeviceCode is the response to a device authorization request (RFC 8628).
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestDeviceCode starts a device authorization flow.
+func (c *Client) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	var code DeviceCode
+	err := c.post(ctx, "/auth/device", nil, &code)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start login: %w", err)
+	}
+
+	return &code, nil
+}
+
+// PollToken waits until the user approves the device code and returns the access token.
+func (c *Client) PollToken(ctx context.Context, code *DeviceCode) (string, error) {
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+		}
+		// Pending and denied device codes are answered with a 400
+		err := c.post(ctx, "/auth/token", map[string]string{"device_code": code.DeviceCode}, &resp, http.StatusBadRequest)
+		if err != nil {
+			return "", fmt.Errorf("Failed to complete login: %w", err)
+		}
+
+		switch resp.Error {
+		case "":
+			return resp.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return "", fmt.Errorf("Login was denied.")
+		case "expired_token":
+			return "", fmt.Errorf("Login code expired. Run 'lc login' again.")
+		default:
+			return "", fmt.Errorf("Login failed: %s", resp.Error)
+		}
+	}
+
+	return "", fmt.Errorf("Login code expired. Run 'lc login' again.")
+}
+
+// User returns the account name the token belongs to.
+func (c *Client) User(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/user", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	err = c.do(req, &user)
+	if err != nil {
+		return "", fmt.Errorf("Failed to verify token: %w", err)
+	}
+
+	return user.Login, nil
+}
+
+// post sends a JSON request and decodes the JSON response into out, as do.
+func (c *Client) post(ctx context.Context, path string, body any, out any, accept ...int) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, out, accept...)
+}
+
+// do executes a request and decodes the JSON response into out. Responses
+// with an error status are decoded too if it is one of accept.
+func (c *Client) do(req *http.Request, out any, accept ...int) error {
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 && !slices.Contains(accept, resp.StatusCode) {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return fmt.Errorf("unexpected response (%s): %w", resp.Status, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	commands "github.com/urfave/cli/v3"
+)
+
+// badge holds the text and color of a progress badge.
+type badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// badgeColors maps shields.io color names to the hex values used in SVG badges.
+var badgeColors = map[string]string{
+	"lightgrey":   "#9f9f9f",
+	"yellow":      "#dfb317",
+	"brightgreen": "#4c1",
+}
+
+const badgeSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">
+<title>%[3]s: %[4]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[6]d" height="20" fill="%[5]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[7]d" y="14">%[3]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[8]d" y="14">%[4]s</text>
+</g>
+</svg>
+`
+
+// buildBadge describes the challenge progress recorded in the state and history.
+func buildBadge(cfg *state.State, challenge *registry.Challenge) (*badge, error) {
+	events, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	completed := completedStages(cfg, challenge, events)
+	total := challenge.Len()
+
+	b := &badge{
+		Label:   cfg.Challenge,
+		Message: fmt.Sprintf("%d/%d stages", completed, total),
+		Color:   "yellow",
+	}
+
+	switch completed {
+	case 0:
+		b.Color = "lightgrey"
+	case total:
+		b.Color = "brightgreen"
+	}
+
+	return b, nil
+}
+
+// completedStages returns the number of stages completed in a challenge.
+func completedStages(cfg *state.State, challenge *registry.Challenge, events []history.Event) int {
+	completed := challenge.StageIndex(cfg.Stage)
+	for _, stageKey := range challenge.StageOrder[:max(completed, 0)] {
+		if cfg.IsSkipped(stageKey) {
+			completed--
+		}
+	}
+
+	// The last stage has no next stage to advance to, so count it once it passes
+	if cfg.Stage == challenge.StageOrder[challenge.Len()-1] {
+		if s, ok := history.Summarize(events, cfg.Challenge)[cfg.Stage]; ok && s.LastPassed {
+			completed++
+		}
+	}
+
+	return max(completed, 0)
+}
+
+// svg renders the badge in the flat shields.io style.
+func (b *badge) svg() string {
+	labelWidth := textWidth(b.Label) + 10
+	messageWidth := textWidth(b.Message) + 10
+
+	return fmt.Sprintf(badgeSVG,
+		labelWidth+messageWidth, labelWidth,
+		html.EscapeString(b.Label), html.EscapeString(b.Message),
+		badgeColors[b.Color], messageWidth,
+		labelWidth/2, labelWidth+messageWidth/2,
+	)
+}
+
+// json renders the badge as a shields.io endpoint response.
+func (b *badge) json() (string, error) {
+	bytes, err := json.MarshalIndent(struct {
+		SchemaVersion int    `json:"schemaVersion"`
+		Label         string `json:"label"`
+		Message       string `json:"message"`
+		Color         string `json:"color"`
+	}{1, b.Label, b.Message, b.Color}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes) + "\n", nil
+}
+
+// textWidth approximates the rendered width of s in 11px Verdana.
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("il.:|!' ", r):
+			width += 4
+		case strings.ContainsRune("mwMW", r):
+			width += 10
+		default:
+			width += 7
+		}
+	}
+
+	return width
+}
+
+// Badge writes an SVG or shields.io JSON badge showing completed stages.
+func Badge(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	output := cmd.String("output")
+	format := cmd.String("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".json":
+			format = "json"
+		default:
+			format = "svg"
+		}
+	}
+
+	b, err := buildBadge(cfg, challenge)
+	if err != nil {
+		return err
+	}
+
+	var content string
+	switch format {
+	case "svg":
+		content = b.svg()
+	case "json":
+		content, err = b.json()
+	default:
+		return usageError("Unknown format %q.\nUsage: lc badge --format svg|json", format)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to render badge: %w", err)
+	}
+
+	if output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	err = os.WriteFile(output, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write badge: %w", err)
+	}
+
+	fmt.Printf("Wrote %s badge to %s\n", b.Message, output)
+
+	return nil
+}
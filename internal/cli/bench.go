@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/registry"
+	commands "github.com/urfave/cli/v3"
+)
+
+// Bench runs the benchmark suite for the specified stage.
+func Bench(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := validateEnvironment()
+	if err != nil {
+		return err
+	}
+
+	var stageKey string
+	switch cmd.NArg() {
+	case 0:
+		stageKey = cfg.Stage
+	case 1:
+		stageKey = cmd.Args().First()
+	default:
+		return usageError("Too many arguments.\nUsage: lc bench [stage]")
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	stage, err := challenge.GetStage(stageKey)
+	if err != nil {
+		return err
+	}
+
+	if stage.Bench == nil {
+		msg := "\nStages with benchmarks:\n"
+		for _, key := range challenge.StageOrder {
+			if challenge.Stages[key].Bench != nil {
+				msg += fmt.Sprintf("- %s\n", key)
+			}
+		}
+
+		return usageError("Stage %s has no benchmarks.\n%s", stageKey, msg)
+	}
+
+	suite := stage.Bench().WithConfig(&attest.Config{
+		DefaultRetryTimeout: settings.RetryTimeout(),
+		Verbose:             settings.Verbose(),
+	})
+
+	fmt.Printf("Benchmarking %s: %s\n\n", stageKey, stage.Name)
+	if !suite.Run(ctx) {
+		return testFailure("\nBenchmark thresholds for %s not met.", stageKey)
+	}
+
+	return nil
+}
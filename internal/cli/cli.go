@@ -5,24 +5,35 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/fatih/color"
 	_ "github.com/littleclusters/lc/challenges"
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/history"
 	"github.com/littleclusters/lc/internal/registry"
 	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
 	commands "github.com/urfave/cli/v3"
 )
 
-const (
-	DocsBaseURL = "https://littleclusters.com"
-)
-
-var (
-	yellow = color.New(color.FgYellow).SprintFunc()
-)
+// runCommands maps languages to the run.sh command that starts an implementation.
+var runCommands = map[string]string{
+	"go":         `exec go run . "$@"`,
+	"python":     `exec python3 main.py "$@"`,
+	"rust":       `exec cargo run --release --quiet -- "$@"`,
+	"javascript": `exec node index.js "$@"`,
+	"typescript": `exec npx tsx index.ts "$@"`,
+	"java":       `exec java Main.java "$@"`,
+}
 
 // createChallengeFiles creates the initial project files for a new challenge.
-func createChallengeFiles(challenge *registry.Challenge, targetPath string) error {
+// runCommand starts the implementation; if empty, the language's default
+// command or a placeholder is used.
+func createChallengeFiles(challenge *registry.Challenge, targetPath, language, runCommand string) error {
 	// run.sh
 	scriptPath := filepath.Join(targetPath, "run.sh")
 	scriptTemplate := `#!/bin/bash -e
@@ -32,14 +43,21 @@ func createChallengeFiles(challenge *registry.Challenge, targetPath string) erro
 # "$@" passes command-line arguments from lc to your program, e.g.:
 #   --working-dir=<path>: Directory where your program should write files
 
-echo "Replace this line with the command that runs your implementation."
+%s
+`
+
+	if runCommand == "" {
+		runCommand = `echo "Replace this line with the command that runs your implementation."
 # Examples:
 #   exec go run ./cmd/server "$@"
 #   exec python main.py "$@"
-#   exec ./my-program "$@"
-`
+#   exec ./my-program "$@"`
+		if command, ok := runCommands[language]; ok {
+			runCommand = command
+		}
+	}
 
-	err := os.WriteFile(scriptPath, []byte(scriptTemplate), 0755)
+	err := os.WriteFile(scriptPath, []byte(fmt.Sprintf(scriptTemplate, runCommand)), 0755)
 	if err != nil {
 		return fmt.Errorf("Failed to create run.sh: %w", err)
 	}
@@ -78,7 +96,7 @@ func InitChallenge(ctx context.Context, cmd *commands.Command) error {
 	// Get Challenge
 	args := cmd.Args().Slice()
 	if len(args) == 0 {
-		return fmt.Errorf("Challenge name is required.\nUsage: lc init <challenge> [path]")
+		return usageError("Challenge name is required.\nUsage: lc init <challenge> [path]")
 	}
 
 	challengeKey := args[0]
@@ -99,7 +117,10 @@ func InitChallenge(ctx context.Context, cmd *commands.Command) error {
 		targetPath = "."
 	}
 
-	err = createChallengeFiles(challenge, targetPath)
+	// Existing code decides how run.sh starts the implementation
+	language, runCommand := detectRunCommand(targetPath)
+
+	err = createChallengeFiles(challenge, targetPath, settings.Language(), runCommand)
 	if err != nil {
 		return err
 	}
@@ -115,20 +136,39 @@ func InitChallenge(ctx context.Context, cmd *commands.Command) error {
 	fmt.Println("  lc.state     - Tracks your progress")
 	fmt.Printf("  .gitignore   - Ignores .lc/ working directory (server files and logs)\n\n")
 
+	if runCommand != "" {
+		fmt.Printf("Detected existing %s code; run.sh is set up to run it. Check it before testing.\n\n", language)
+	}
+
+	if cmd.Bool("git") {
+		err := initGitRepo(targetPath, challengeKey)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Initialized git repository with the challenge files committed.\n\n")
+	}
+
 	firstStageKey := challenge.StageOrder[0]
 	if targetPath == "." {
-		fmt.Printf("Implement %s stage, then run %s.\n", firstStageKey, yellow("'lc test'"))
+		fmt.Printf("Implement %s stage, then run %s.\n", firstStageKey, style.Yellow("'lc test'"))
 	} else {
-		fmt.Printf("cd %s and implement %s stage, then run %s.\n", targetPath, firstStageKey, yellow("'lc test'"))
+		fmt.Printf("cd %s and implement %s stage, then run %s.\n", targetPath, firstStageKey, style.Yellow("'lc test'"))
 	}
 
 	return nil
 }
 
-// validateEnvironment checks if run.sh exists and loads the state.
+// guideURL returns the guide URL for a challenge stage.
+func guideURL(challengeKey, stageKey string) string {
+	return fmt.Sprintf("%s/%s/%s", settings.DocsBaseURL(), challengeKey, stageKey)
+}
+
+// validateEnvironment checks that run.sh is set up and loads the state.
 func validateEnvironment() (*state.State, error) {
-	if _, err := os.Stat("run.sh"); os.IsNotExist(err) {
-		return nil, fmt.Errorf("run.sh not found\nCreate an executable run.sh script that starts your implementation.")
+	err := checkRunScript(".")
+	if err != nil {
+		return nil, err
 	}
 
 	cfg, err := state.Load()
@@ -139,11 +179,64 @@ func validateEnvironment() (*state.State, error) {
 	return cfg, nil
 }
 
+// testOptions holds flags that customize how stage suites run.
+type testOptions struct {
+	// filter selects the tests to run; nil runs the whole suite.
+	filter func(name string) bool
+	// seed fixes the suite's random generator; zero picks a random seed.
+	seed uint64
+	// quiet prints one line per test and a summary.
+	quiet bool
+	// parallel is the maximum number of independent tests run at once.
+	parallel int
+	// keepGoing runs a stage's remaining tests after a failing one.
+	keepGoing bool
+	// timeoutScale multiplies retry timeouts; zero leaves them unchanged.
+	timeoutScale float64
+	// dir is the project directory to test; empty tests the current one.
+	// Runs against another directory are not recorded.
+	dir string
+	// recorder captures the traffic sent during the run, if set.
+	recorder *attest.Recorder
+	// env holds KEY=VALUE pairs added to the implementation's environment.
+	env []string
+	// goldenDir receives the actual values of golden assertions, if set.
+	goldenDir string
+}
+
+// globPrefix marks a --run pattern as a glob rather than a regex.
+const globPrefix = "glob:"
+
+// testFilter returns a filter selecting tests whose name matches pattern,
+// case-insensitively. A pattern is a regular expression matching part of the
+// name unless it starts with glob:, e.g. glob:GET*, which makes the rest a
+// glob matching the whole name.
+func testFilter(pattern string) (func(name string) bool, error) {
+	if glob, ok := strings.CutPrefix(pattern, globPrefix); ok {
+		glob = strings.ToLower(glob)
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, usageError("Invalid --run glob %q: %v", glob, err)
+		}
+
+		return func(name string) bool {
+			matched, _ := filepath.Match(glob, strings.ToLower(name))
+			return matched
+		}, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(pattern))
+	}
+
+	return re.MatchString, nil
+}
+
 // runStageTests runs tests for a specific stage and returns success/failure.
-func runStageTests(ctx context.Context, challengeKey, stageKey string) (bool, error) {
+func runStageTests(ctx context.Context, challengeKey, stageKey string, opts testOptions) (*attest.Report, error) {
 	challenge, err := registry.GetChallenge(challengeKey)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	stage, err := challenge.GetStage(stageKey)
@@ -153,84 +246,531 @@ func runStageTests(ctx context.Context, challengeKey, stageKey string) (bool, er
 			msg += fmt.Sprintf("- %s\n", stage)
 		}
 
-		return false, fmt.Errorf("%w\n%s", err, msg)
+		return nil, fmt.Errorf("%w\n%s", err, msg)
+	}
+
+	suite := stage.Fn().WithConfig(&attest.Config{
+		DefaultRetryTimeout: settings.RetryTimeout(),
+		Verbose:             settings.Verbose(),
+		Seed:                opts.seed,
+		Quiet:               opts.quiet,
+		Parallel:            opts.parallel,
+		KeepGoing:           opts.keepGoing,
+		TimeoutScale:        opts.timeoutScale,
+		Dir:                 opts.dir,
+		Env:                 opts.env,
+		Recorder:            opts.recorder,
+		Golden:              challenge.Golden,
+		GoldenDir:           opts.goldenDir,
+		UpdateGolden:        opts.goldenDir != "",
+	})
+
+	if opts.filter != nil {
+		allTests := suite.TestNames()
+		suite.Filter(opts.filter)
+
+		if len(suite.TestNames()) == 0 {
+			msg := "\nAvailable tests:\n"
+			for _, name := range allTests {
+				msg += fmt.Sprintf("- %s\n", name)
+			}
+
+			return nil, usageError("No tests in %s match the pattern.\n%s", stageKey, msg)
+		}
+	}
+
+	if opts.dir == "" {
+		noteChanges(challenge, stageKey)
+	}
+
+	if opts.quiet {
+		fmt.Printf("Testing %s: %s\n", stageKey, stage.Name)
+	} else {
+		fmt.Printf("Testing %s: %s\n\n", stageKey, stage.Name)
+	}
+	start := time.Now()
+	report := suite.RunReport(ctx)
+	passed := report.Passed
+
+	// Interrupted and partial runs don't count towards the stage history
+	if ctx.Err() == nil && opts.filter == nil && opts.dir == "" {
+		err = history.Record(history.Event{
+			Time:      start,
+			Kind:      history.KindTest,
+			Challenge: challengeKey,
+			Stage:     stageKey,
+			Passed:    passed,
+			Duration:  time.Since(start),
+			Version:   LCVersion(),
+			Revision:  challenge.Revision(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		err = recordResult(challengeKey, stageKey, passed, start)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		recordTelemetry(ctx, challengeKey, stageKey, report, time.Since(start))
+
+		if passed {
+			err = clearSkipped(challengeKey, stageKey)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
 	}
 
-	suite := stage.Fn()
-	fmt.Printf("Testing %s: %s\n\n", stageKey, stage.Name)
-	passed := suite.Run(ctx)
-	return passed, nil
+	if ctx.Err() == nil && !passed && opts.dir == "" {
+		err = saveLastFailure(challengeKey, stageKey, report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	return report, nil
 }
 
-// Test runs tests for the specified stage(s).
-func Test(ctx context.Context, cmd *commands.Command) error {
-	cfg, err := validateEnvironment()
-	if err != nil {
-		return err
+// listStageTests prints the tests of each stage without running them.
+func listStageTests(challenge *registry.Challenge, stageKeys []string, opts testOptions) error {
+	for i, stageKey := range stageKeys {
+		stage, err := challenge.GetStage(stageKey)
+		if err != nil {
+			return err
+		}
+
+		suite := stage.Fn()
+		if opts.filter != nil {
+			suite.Filter(opts.filter)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+
+		fmt.Printf("%s: %s\n", stageKey, stage.Name)
+		for j, name := range suite.TestNames() {
+			fmt.Printf("  %2d. %s\n", j+1, name)
+		}
 	}
 
-	var challengeKey string
-	var stageKey string
+	return nil
+}
 
-	switch cmd.NArg() {
-	case 0:
-		challengeKey = cfg.Challenge
-		stageKey = cfg.Stage
-	case 1:
-		challengeKey = cfg.Challenge
-		stageKey = cmd.Args().Slice()[0]
-	default:
-		return fmt.Errorf("Too many arguments.\nUsage: lc test [stage]")
+// TestFlags returns the flags of lc test.
+func TestFlags() []commands.Flag {
+	return []commands.Flag{
+		&commands.StringFlag{
+			Name:  "project",
+			Usage: "Run in the `name`d project of lc.workspace",
+		},
+		&commands.BoolFlag{
+			Name:  "so-far",
+			Usage: "Test all stages up to the specified stage",
+		},
+		&commands.StringFlag{
+			Name:  "run",
+			Usage: "Only run tests whose name matches the regex `pattern`, or the glob after glob:, e.g. glob:GET*",
+		},
+		&commands.IntFlag{
+			Name:  "repeat",
+			Usage: "Run the suite `N` times and report flaky tests",
+			Value: 1,
+		},
+		&commands.BoolFlag{
+			Name:  "until-fail",
+			Usage: "Run the suite repeatedly until it fails",
+		},
+		&commands.IntFlag{
+			Name:  "max-runs",
+			Usage: "Maximum number of runs for --until-fail",
+			Value: 100,
+		},
+		&commands.BoolFlag{
+			Name:  "list",
+			Usage: "List the tests in the stage without running them",
+		},
+		&commands.Uint64Flag{
+			Name:  "seed",
+			Usage: "Seed randomized test inputs to reproduce a run",
+		},
+		&commands.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "Print one line per test and a summary",
+		},
+		&commands.BoolFlag{
+			Name:  "fail-fast",
+			Usage: "Stop a stage at its first failing test (the default)",
+		},
+		&commands.BoolFlag{
+			Name:  "keep-going",
+			Usage: "Run the remaining tests after a failing one instead of stopping",
+		},
+		&commands.StringFlag{
+			Name:  "timeout",
+			Usage: "Scale retry timeouts by a multiplier like `2x`, or set the default like 10s",
+		},
+		&commands.IntFlag{
+			Name:  "parallel",
+			Usage: "Run up to `N` independent tests at once",
+			Value: 1,
+		},
+		&commands.StringSliceFlag{
+			Name:  "path",
+			Usage: "Test the project in `dir` instead of the current directory (repeatable)",
+		},
+		&commands.StringSliceFlag{
+			Name:  "env",
+			Usage: "Set `KEY=VALUE` in the environment of your implementation (repeatable)",
+		},
+		&commands.StringFlag{
+			Name:   "update-golden",
+			Usage:  "Write golden assertion values to the challenge testdata `dir`",
+			Hidden: true,
+		},
 	}
+}
 
-	challenge, err := registry.GetChallenge(challengeKey)
+// parseTestOptions reads the flags shared by every stage run of lc test.
+func parseTestOptions(cmd *commands.Command) (testOptions, error) {
+	var opts testOptions
+	if pattern := cmd.String("run"); pattern != "" {
+		filter, err := testFilter(pattern)
+		if err != nil {
+			return opts, err
+		}
+		opts.filter = filter
+	}
+	opts.seed = cmd.Uint64("seed")
+	opts.quiet = cmd.Bool("quiet") || settings.Quiet()
+	opts.keepGoing = cmd.Bool("keep-going")
+	if opts.keepGoing && cmd.Bool("fail-fast") {
+		// A flag on the command line overrides the other's variable
+		switch keepEnv, failEnv := fromEnv(cmd, "keep-going"), fromEnv(cmd, "fail-fast"); {
+		case keepEnv && !failEnv:
+			opts.keepGoing = false
+		case failEnv && !keepEnv:
+		default:
+			return opts, usageError("--fail-fast and --keep-going cannot be used together.")
+		}
+	}
+	opts.goldenDir = cmd.String("update-golden")
+
+	for _, pair := range cmd.StringSlice("env") {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return opts, usageError("Invalid --env %q: expected KEY=VALUE", pair)
+		}
+		opts.env = append(opts.env, pair)
+	}
+
+	if timeout := cmd.String("timeout"); timeout != "" {
+		scale, err := parseTimeoutScale(timeout)
+		if err != nil {
+			return opts, err
+		}
+		opts.timeoutScale = scale
+	}
+
+	opts.parallel = cmd.Int("parallel")
+	if opts.parallel < 1 {
+		return opts, usageError("--parallel must be at least 1")
+	}
+
+	repeat := cmd.Int("repeat")
+	if repeat < 1 {
+		return opts, usageError("--repeat must be at least 1")
+	}
+
+	if cmd.Bool("until-fail") && repeat > 1 {
+		return opts, usageError("--until-fail and --repeat cannot be used together.\nUse --max-runs to limit --until-fail.")
+	}
+
+	// LC_TEST_MAX_RUNS may be exported for every run, not just --until-fail
+	if cmd.IsSet("max-runs") && !cmd.Bool("until-fail") && !fromEnv(cmd, "max-runs") {
+		return opts, usageError("--max-runs requires --until-fail.\nUse --repeat to run the suite a fixed number of times.")
+	}
+
+	if cmd.Int("max-runs") < 1 {
+		return opts, usageError("--max-runs must be at least 1")
+	}
+
+	return opts, nil
+}
+
+// parseTimeoutScale converts a --timeout value into a retry timeout scale.
+// A multiplier such as "2x" scales every retry timeout; a duration such as
+// "10s" replaces the default retry timeout and scales explicit ones to match.
+func parseTimeoutScale(value string) (float64, error) {
+	if multiplier, ok := strings.CutSuffix(value, "x"); ok {
+		scale, err := strconv.ParseFloat(multiplier, 64)
+		if err != nil || scale <= 0 {
+			return 0, usageError("Invalid --timeout %q: expected a positive multiplier such as 2x or a duration such as 10s", value)
+		}
+
+		return scale, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, usageError("Invalid --timeout %q: expected a positive multiplier such as 2x or a duration such as 10s", value)
+	}
+
+	base := settings.RetryTimeout()
+	if base == 0 {
+		base = attest.DefaultConfig().DefaultRetryTimeout
+	}
+
+	return float64(timeout) / float64(base), nil
+}
+
+// resolveTestTarget returns the challenge and stage to test in the project at dir.
+// The target is empty, a stage key, or a challenge:stage pair; only the
+// pair works without an lc.state file.
+func resolveTestTarget(target, dir string) (string, string, error) {
+	if challengeKey, stageKey, ok := strings.Cut(target, ":"); ok {
+		return challengeKey, stageKey, nil
+	}
+
+	cfg, err := state.LoadFrom(filepath.Join(dir, "lc.state"))
 	if err != nil {
-		return err
+		return "", "", err
+	}
+
+	if target == "" {
+		return cfg.Challenge, cfg.Stage, nil
 	}
 
-	// Determine which stages to test
-	var stagesToTest []string
+	return cfg.Challenge, target, nil
+}
+
+// stagesToTest returns the stages selected by the target stage and --so-far.
+// It fails if --run selects none of their tests.
+func stagesToTest(cmd *commands.Command, challenge *registry.Challenge, stageKey string, opts testOptions) ([]string, error) {
+	stages := []string{stageKey}
 	if cmd.Bool("so-far") {
 		targetIndex := challenge.StageIndex(stageKey)
 		if targetIndex == -1 {
-			return fmt.Errorf("Stage '%s' not found in challenge", stageKey)
+			return nil, usageError("Stage '%s' not found in challenge", stageKey)
 		}
+		stages = challenge.StageOrder[:targetIndex+1]
+	}
 
-		stagesToTest = challenge.StageOrder[:targetIndex+1]
-	} else {
-		stagesToTest = []string{stageKey}
+	if opts.filter == nil {
+		return stages, nil
 	}
 
-	// Run tests for all stages
-	for _, currentStage := range stagesToTest {
-		passed, err := runStageTests(ctx, challengeKey, currentStage)
+	for _, key := range stages {
+		stage, err := challenge.GetStage(key)
 		if err != nil {
-			return err
+			// Reported with the available stages when the stage runs
+			return stages, nil
+		}
+		if len(stage.Fn().Filter(opts.filter).TestNames()) > 0 {
+			return stages, nil
+		}
+	}
+
+	return nil, usageError("No tests match --run %q\nRun 'lc test --list' to see the test names.", cmd.String("run"))
+}
+
+// testStages runs the selected stages in order and returns the first stage
+// that failed, or an empty string if all passed.
+func testStages(ctx context.Context, cmd *commands.Command, challenge *registry.Challenge, stages []string, opts testOptions) (string, error) {
+	repeat := cmd.Int("repeat")
+
+	for _, currentStage := range stages {
+		var passed bool
+		var err error
+		if cmd.Bool("until-fail") {
+			passed, err = untilFailStageTests(ctx, challenge.Key, currentStage, opts, cmd.Int("max-runs"))
+		} else if repeat > 1 {
+			passed, err = repeatStageTests(ctx, challenge.Key, currentStage, opts, repeat)
+		} else {
+			var report *attest.Report
+			report, err = runStageTests(ctx, challenge.Key, currentStage, opts)
+			passed = report != nil && report.Passed
+		}
+		if err != nil {
+			return "", err
 		}
 
 		if !passed {
-			guideURL := fmt.Sprintf("%s/%s/%s", DocsBaseURL, challengeKey, currentStage)
-			return fmt.Errorf("\nRead the guide: \033]8;;%s\033\\%s/%s/%s\033]8;;\033\\\n", guideURL, DocsBaseURL, challengeKey, currentStage)
+			return currentStage, nil
 		}
 
-		if len(stagesToTest) > 1 {
+		if len(stages) > 1 {
 			fmt.Println()
 		}
 	}
 
+	return "", nil
+}
+
+// Test runs tests for the specified stage(s).
+func Test(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() > 1 {
+		return usageError("Too many arguments.\nUsage: lc test [[challenge:]stage]")
+	}
+	target := cmd.Args().First()
+
+	opts, err := parseTestOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	paths := cmd.StringSlice("path")
+	if len(paths) > 0 && !cmd.Bool("list") {
+		return testPaths(ctx, cmd, target, paths, opts)
+	}
+
+	dir := "."
+	if len(paths) > 0 {
+		dir = paths[0]
+	}
+
+	challengeKey, stageKey, err := resolveTestTarget(target, dir)
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(challengeKey)
+	if err != nil {
+		return err
+	}
+
+	stages, err := stagesToTest(cmd, challenge, stageKey, opts)
+	if err != nil {
+		return err
+	}
+
+	// Listing needs no implementation, so it works before run.sh is set up
+	if cmd.Bool("list") {
+		return listStageTests(challenge, stages, opts)
+	}
+
+	err = checkRunScript(dir)
+	if err != nil {
+		return err
+	}
+
+	failedStage, err := testStages(ctx, cmd, challenge, stages, opts)
+	if err != nil {
+		return err
+	}
+
+	if failedStage != "" {
+		guideURL := guideURL(challengeKey, failedStage)
+		return testFailure("\nRead the guide: %s\n", style.Link(guideURL, guideURL))
+	}
+
 	// Success message
-	if len(stagesToTest) > 1 {
-		fmt.Printf("All stages up to %s passed! ✓\n", stageKey)
+	if len(stages) > 1 {
+		fmt.Printf("All stages up to %s passed! %s\n", stageKey, style.CheckMark())
 	}
 
 	targetIndex := challenge.StageIndex(stageKey)
-	if targetIndex < challenge.Len()-1 {
-		fmt.Printf("\nRun %s to advance to the next stage.\n", yellow("'lc next'"))
+	if targetIndex < challenge.Len()-1 && opts.filter == nil {
+		fmt.Printf("\nRun %s to advance to the next stage.\n", style.Yellow("'lc next'"))
+	}
+
+	return nil
+}
+
+// testPaths runs the selected stages against each project directory and
+// summarizes which projects passed. Results are not recorded in the projects.
+func testPaths(ctx context.Context, cmd *commands.Command, target string, paths []string, opts testOptions) error {
+	summary := make([]string, 0, len(paths))
+	failed := 0
+
+	for i, path := range paths {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s %s\n\n", style.Bold("Project"), path)
+
+		outcome, err := testPath(ctx, cmd, target, path, opts)
+		if err != nil {
+			outcome = firstLine(err.Error())
+			fmt.Printf("%s\n", err)
+		}
+
+		if outcome == "" {
+			summary = append(summary, fmt.Sprintf("  %s %s", style.CheckMark(), path))
+		} else {
+			failed++
+			summary = append(summary, fmt.Sprintf("  %s %s: %s", style.CrossMark(), path, outcome))
+		}
+	}
+
+	fmt.Printf("\nResults:\n%s\n", strings.Join(summary, "\n"))
+
+	if failed > 0 {
+		return testFailure("\n%d of %d projects failed.", failed, len(paths))
 	}
 
 	return nil
 }
 
+// testPath runs the selected stages against one project directory.
+// It returns a description of the failure, or an empty string if it passed.
+func testPath(ctx context.Context, cmd *commands.Command, target, path string, opts testOptions) (string, error) {
+	err := checkRunScript(path)
+	if err != nil {
+		return "", err
+	}
+
+	challengeKey, stageKey, err := resolveTestTarget(target, path)
+	if err != nil {
+		return "", err
+	}
+
+	challenge, err := registry.GetChallenge(challengeKey)
+	if err != nil {
+		return "", err
+	}
+
+	stages, err := stagesToTest(cmd, challenge, stageKey, opts)
+	if err != nil {
+		return "", err
+	}
+
+	opts.dir, err = filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to resolve %s: %w", path, err)
+	}
+
+	failedStage, err := testStages(ctx, cmd, challenge, stages, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if failedStage != "" {
+		return fmt.Sprintf("%s:%s failed", challengeKey, failedStage), nil
+	}
+
+	return "", nil
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for line := range strings.Lines(s) {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
 // NextStage advances to the next stage after verifying current stage is complete.
 func NextStage(ctx context.Context, cmd *commands.Command) error {
 	// Get Challenge
@@ -247,25 +787,35 @@ func NextStage(ctx context.Context, cmd *commands.Command) error {
 	// Check if current stage is completed
 	currentIndex := challenge.StageIndex(cfg.Stage)
 	if currentIndex == -1 {
-		return fmt.Errorf("Current stage '%s' not found in challenge", cfg.Stage)
+		return environmentError("Current stage '%s' not found in challenge", cfg.Stage)
 	}
 
 	// Run tests for current stage
-	passed, err := runStageTests(ctx, cfg.Challenge, cfg.Stage)
+	report, err := runStageTests(ctx, cfg.Challenge, cfg.Stage, testOptions{})
 	if err != nil {
 		return err
 	}
 
 	fmt.Println()
 
-	if !passed {
-		return fmt.Errorf("Complete %s before advancing.", cfg.Stage)
+	if !report.Passed {
+		return testFailure("Complete %s before advancing.", cfg.Stage)
+	}
+
+	if cmd.Bool("tag") {
+		tag, err := tagStage(cfg.Challenge, cfg.Stage)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tagged %s as %s.\n\n", cfg.Stage, tag)
 	}
 
 	// Check if already at final stage
 	if currentIndex == challenge.Len()-1 {
-		fmt.Printf("You've completed all stages for %s! 🎉\n\n", cfg.Challenge)
-		fmt.Printf("Try another challenge at \033]8;;%s/\033\\%s\033]8;;\033\\\n", DocsBaseURL, DocsBaseURL)
+		fmt.Printf("You've completed all stages for %s!%s\n\n", cfg.Challenge, style.Emoji(" 🎉"))
+		docsURL := settings.DocsBaseURL()
+		fmt.Printf("Try another challenge at %s\n", style.Link(docsURL+"/", docsURL))
 
 		return state.Save(cfg)
 	}
@@ -284,31 +834,141 @@ func NextStage(ctx context.Context, cmd *commands.Command) error {
 	}
 
 	fmt.Printf("Advanced to %s: %s\n\n", nextStageKey, nextStage.Name)
-	guideURL := fmt.Sprintf("%s/%s/%s", DocsBaseURL, cfg.Challenge, nextStageKey)
-	fmt.Printf("Read the guide: \033]8;;%s\033\\%s/%s/%s\033]8;;\033\\\n\n", guideURL, DocsBaseURL, cfg.Challenge, nextStageKey)
-	fmt.Printf("Run %s when ready.\n", yellow("'lc test'"))
+	guideURL := guideURL(cfg.Challenge, nextStageKey)
+	fmt.Printf("Read the guide: %s\n\n", style.Link(guideURL, guideURL))
+	fmt.Printf("Run %s when ready.\n", style.Yellow("'lc test'"))
 
 	return nil
 }
 
+// clearSkipped marks a skipped stage as complete once it passes.
+func clearSkipped(challengeKey, stageKey string) error {
+	cfg, err := state.Load()
+	if err != nil || cfg.Challenge != challengeKey || !cfg.IsSkipped(stageKey) {
+		return nil
+	}
+
+	cfg.Skipped = slices.DeleteFunc(cfg.Skipped, func(s string) bool { return s == stageKey })
+	fmt.Printf("\nSkipped stage %s is now complete.\n", stageKey)
+
+	return state.Save(cfg)
+}
+
+// SkipStage advances to the next stage without passing the current one.
+// The skipped stage stays incomplete until its tests pass.
+func SkipStage(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	currentIndex := challenge.StageIndex(cfg.Stage)
+	if currentIndex == -1 {
+		return environmentError("Current stage '%s' not found in challenge", cfg.Stage)
+	}
+
+	if currentIndex == challenge.Len()-1 {
+		return usageError("%s is the final stage and cannot be skipped.", cfg.Stage)
+	}
+
+	skippedKey := cfg.Stage
+	if !cfg.IsSkipped(skippedKey) {
+		cfg.Skipped = append(cfg.Skipped, skippedKey)
+	}
+
+	nextStageKey := challenge.StageOrder[currentIndex+1]
+	cfg.Stage = nextStageKey
+	err = state.Save(cfg)
+	if err != nil {
+		return err
+	}
+
+	nextStage, err := challenge.GetStage(nextStageKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Skipped %s. It stays incomplete until %s passes.\n\n", skippedKey, style.Yellow(fmt.Sprintf("'lc test %s'", skippedKey)))
+	fmt.Printf("Advanced to %s: %s\n\n", nextStageKey, nextStage.Name)
+	guideURL := guideURL(cfg.Challenge, nextStageKey)
+	fmt.Printf("Read the guide: %s\n", style.Link(guideURL, guideURL))
+
+	return nil
+}
+
+// statusInfo is the JSON representation of the challenge progress.
+type statusInfo struct {
+	Challenge       string   `json:"challenge"`
+	Name            string   `json:"name"`
+	Stage           string   `json:"stage"`
+	StageName       string   `json:"stageName"`
+	StageIndex      int      `json:"stageIndex"`
+	CompletedStages []string `json:"completedStages"`
+	SkippedStages   []string `json:"skippedStages"`
+	TotalStages     int      `json:"totalStages"`
+	GuideURL        string   `json:"guideUrl"`
+}
+
+// newStatusInfo builds the JSON status for the current challenge.
+func newStatusInfo(cfg *state.State, challenge *registry.Challenge) statusInfo {
+	currentIndex := challenge.StageIndex(cfg.Stage)
+
+	completed := []string{}
+	skipped := []string{}
+	for _, stageKey := range challenge.StageOrder[:currentIndex] {
+		if cfg.IsSkipped(stageKey) {
+			skipped = append(skipped, stageKey)
+		} else {
+			completed = append(completed, stageKey)
+		}
+	}
+
+	return statusInfo{
+		Challenge:       cfg.Challenge,
+		Name:            challenge.Name,
+		Stage:           cfg.Stage,
+		StageName:       challenge.Stages[cfg.Stage].Name,
+		StageIndex:      currentIndex,
+		CompletedStages: completed,
+		SkippedStages:   skipped,
+		TotalStages:     challenge.Len(),
+		GuideURL:        guideURL(cfg.Challenge, cfg.Stage),
+	}
+}
+
 // ShowStatus displays the current challenge progress and next steps.
+// It exits with exitEnvironment when not in a challenge directory or the
+// state is invalid.
 func ShowStatus(ctx context.Context, cmd *commands.Command) error {
 	// Summary
 	cfg, err := state.Load()
 	if err != nil {
-		return err
+		return commands.Exit(err, exitEnvironment)
 	}
 
 	challenge, err := registry.GetChallenge(cfg.Challenge)
 	if err != nil {
-		return err
+		return commands.Exit(err, exitEnvironment)
+	}
+
+	currentIndex := challenge.StageIndex(cfg.Stage)
+	if currentIndex == -1 {
+		return commands.Exit(fmt.Sprintf("Stage %q in lc.state not found for challenge %s.", cfg.Stage, cfg.Challenge), exitEnvironment)
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(newStatusInfo(cfg, challenge))
 	}
 
 	fmt.Printf("%s\n\n%s\n\n", challenge.Name, challenge.Summary)
 
 	// Progress
 	fmt.Println("Progress:")
-	currentIndex := challenge.StageIndex(cfg.Stage)
 	for i, stageKey := range challenge.StageOrder {
 		stage, err := challenge.GetStage(stageKey)
 		if err != nil {
@@ -316,7 +976,9 @@ func ShowStatus(ctx context.Context, cmd *commands.Command) error {
 		}
 
 		isCompleted := i < currentIndex
-		if isCompleted {
+		if isCompleted && cfg.IsSkipped(stageKey) {
+			fmt.Printf("%s %-18s - %s %s\n", style.Yellow("↷"), stageKey, stage.Name, style.Yellow("(skipped)"))
+		} else if isCompleted {
 			fmt.Printf("✓ %-18s - %s\n", stageKey, stage.Name)
 		} else if stageKey == cfg.Stage {
 			fmt.Printf("→ %-18s - %s\n", stageKey, stage.Name)
@@ -326,23 +988,9 @@ func ShowStatus(ctx context.Context, cmd *commands.Command) error {
 	}
 
 	// Next steps
-	guideURL := fmt.Sprintf("%s/%s/%s", DocsBaseURL, cfg.Challenge, cfg.Stage)
-	fmt.Printf("\nRead the guide: \033]8;;%s\033\\%s/%s/%s\033]8;;\033\\\n\n", guideURL, DocsBaseURL, cfg.Challenge, cfg.Stage)
-	fmt.Printf("Implement %s, then run %s.\n", cfg.Stage, yellow("'lc test'"))
-
-	return nil
-}
-
-// ListChallenges displays all available challenges.
-func ListChallenges(ctx context.Context, cmd *commands.Command) error {
-	fmt.Printf("Available challenges:\n\n")
-
-	challenges := registry.GetAllChallenges()
-	for key, challenge := range challenges {
-		fmt.Printf("  %-20s - %s (%d stages)\n", key, challenge.Name, challenge.Len())
-	}
-
-	fmt.Printf("\nStart with: lc init <challenge-name>\n")
+	guideURL := guideURL(cfg.Challenge, cfg.Stage)
+	fmt.Printf("\nRead the guide: %s\n\n", style.Link(guideURL, guideURL))
+	fmt.Printf("Implement %s, then run %s.\n", cfg.Stage, style.Yellow("'lc test'"))
 
 	return nil
 }
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/littleclusters/lc/internal/registry"
+	commands "github.com/urfave/cli/v3"
+)
+
+// runLC runs lc with args, with only the commands the tests use, and
+// returns the error it would exit with.
+func runLC(args ...string) error {
+	cmd := &commands.Command{
+		Name:           "lc",
+		ExitErrHandler: func(context.Context, *commands.Command, error) {},
+		Commands: []*commands.Command{
+			{Name: "test", Flags: TestFlags(), Action: Test},
+		},
+	}
+	BindEnv(cmd)
+
+	return cmd.Run(context.Background(), append([]string{"lc"}, args...))
+}
+
+// newProject creates a project as lc init does, with the placeholder run.sh.
+func newProject(t *testing.T) string {
+	t.Helper()
+
+	challenge, err := registry.GetChallenge("kv-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := createChallengeFiles(challenge, dir, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestListBeforeImplementing(t *testing.T) {
+	dir := newProject(t)
+
+	if err := runLC("test", "--list", "--path", dir); err != nil {
+		t.Errorf("lc test --list --path should work with the placeholder run.sh: %v", err)
+	}
+
+	t.Chdir(dir)
+	if err := runLC("test", "--list"); err != nil {
+		t.Errorf("lc test --list should work with the placeholder run.sh: %v", err)
+	}
+
+	err := runLC("test")
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitEnvironment {
+		t.Errorf("lc test should fail on the placeholder run.sh, got %v", err)
+	}
+}
+
+func TestRunMatchesNoTests(t *testing.T) {
+	dir := newProject(t)
+
+	err := runLC("test", "--list", "--run", "glob:PUT [0-9]+", "--path", dir)
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitUsage {
+		t.Errorf("a --run matching no tests should be a usage error, got %v", err)
+	}
+
+	if err := runLC("test", "--list", "--run", "glob:*", "--path", dir); err != nil {
+		t.Errorf("a --run matching tests should list them: %v", err)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	dir := newProject(t)
+
+	if err := runLC("test", "--list", "--fail-fast", "--path", dir); err != nil {
+		t.Errorf("--fail-fast should be accepted: %v", err)
+	}
+
+	err := runLC("test", "--list", "--fail-fast", "--keep-going", "--path", dir)
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitUsage {
+		t.Errorf("--fail-fast with --keep-going should be a usage error, got %v", err)
+	}
+}
+
+func TestFailFastOverridesKeepGoingVariable(t *testing.T) {
+	t.Setenv("LC_TEST_KEEP_GOING", "true")
+
+	var opts testOptions
+	cmd := &commands.Command{
+		Name:           "lc",
+		ExitErrHandler: func(context.Context, *commands.Command, error) {},
+		Commands: []*commands.Command{
+			{
+				Name:  "test",
+				Flags: TestFlags(),
+				Action: func(ctx context.Context, cmd *commands.Command) error {
+					var err error
+					opts, err = parseTestOptions(cmd)
+					return err
+				},
+			},
+		},
+	}
+	BindEnv(cmd)
+
+	if err := cmd.Run(context.Background(), []string{"lc", "test", "--fail-fast"}); err != nil {
+		t.Fatalf("--fail-fast should override LC_TEST_KEEP_GOING: %v", err)
+	}
+	if opts.keepGoing {
+		t.Error("--fail-fast should override LC_TEST_KEEP_GOING")
+	}
+}
+
+func TestMaxRuns(t *testing.T) {
+	dir := newProject(t)
+
+	err := runLC("test", "--list", "--max-runs", "3", "--path", dir)
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitUsage {
+		t.Errorf("--max-runs without --until-fail should be a usage error, got %v", err)
+	}
+
+	t.Setenv("LC_TEST_MAX_RUNS", "3")
+	if err := runLC("test", "--list", "--path", dir); err != nil {
+		t.Errorf("LC_TEST_MAX_RUNS should be ignored without --until-fail: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/littleclusters/lc/internal/config"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// settings holds the global user preferences loaded by Setup.
+var settings = &config.Config{}
+
+// Setup loads the global config file and applies it before any command runs.
+// Invalid settings are warned about and left at their defaults, so lc config
+// and lc purge can still fix them.
+func Setup(ctx context.Context, cmd *commands.Command) (context.Context, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\nFix it with 'lc config set', or reset all settings with 'lc purge --config'.\n", err)
+	}
+	if cfg == nil {
+		cfg, err = config.Defaults()
+		if err != nil {
+			return ctx, asEnvironment(err)
+		}
+	}
+
+	settings = cfg
+	registry.DocsBaseURL = cfg.DocsBaseURL()
+
+	// NO_COLOR and non-terminal output are already handled by the color package
+	if cmd.Bool("no-color") || !cfg.Color() {
+		style.Disable()
+	}
+
+	return ctx, nil
+}
+
+// ConfigGet prints the value of a config key.
+func ConfigGet(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() != 1 {
+		return usageError("Config key is required.\nUsage: lc config get <key>")
+	}
+
+	value, err := settings.Get(cmd.Args().First())
+	if err != nil {
+		return asUsage(err)
+	}
+
+	fmt.Println(value)
+
+	return nil
+}
+
+// ConfigSet stores the value of a config key.
+func ConfigSet(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() != 2 {
+		return usageError("Config key and value are required.\nUsage: lc config set <key> <value>")
+	}
+
+	key, value := cmd.Args().Get(0), cmd.Args().Get(1)
+	err := settings.Set(key, value)
+	if err != nil {
+		return asUsage(err)
+	}
+
+	err = settings.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Set %s = %s\n", key, value)
+
+	return nil
+}
+
+// ConfigUnset restores the default value of a config key.
+func ConfigUnset(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() != 1 {
+		return usageError("Config key is required.\nUsage: lc config unset <key>")
+	}
+
+	key := cmd.Args().First()
+	err := settings.Unset(key)
+	if err != nil {
+		return asUsage(err)
+	}
+
+	err = settings.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unset %s\n", key)
+
+	return nil
+}
+
+// ConfigList displays all config keys with their current values.
+func ConfigList(ctx context.Context, cmd *commands.Command) error {
+	fmt.Printf("Config file: %s\n\n", settings.Path())
+
+	for _, key := range config.Keys {
+		value, _ := settings.Get(key.Name)
+		if value == "" {
+			value = "(not set)"
+		} else if !settings.IsSet(key.Name) {
+			value = fmt.Sprintf("%s (default)", value)
+		}
+
+		fmt.Printf("  %-14s = %s\n", key.Name, value)
+		fmt.Printf("  %-14s   %s\n", "", key.Usage)
+	}
+
+	fmt.Printf("\nChange with: lc config set <key> <value>\n")
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// detectRunCommand guesses the language of existing code in dir and returns
+// it with a run.sh command that starts it. It returns empty strings when no
+// known project files are found.
+func detectRunCommand(dir string) (string, string) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("go.mod"):
+		// Prefer a single command under cmd/ when there is no main package at the root
+		if !exists("main.go") {
+			mains, _ := filepath.Glob(filepath.Join(dir, "cmd", "*", "main.go"))
+			if len(mains) == 1 {
+				pkg := filepath.Base(filepath.Dir(mains[0]))
+				return "go", fmt.Sprintf(`exec go run ./cmd/%s "$@"`, pkg)
+			}
+		}
+		return "go", runCommands["go"]
+	case exists("Cargo.toml"):
+		return "rust", runCommands["rust"]
+	case exists("package.json"):
+		return detectNodeCommand(dir)
+	case exists("pyproject.toml"), exists("requirements.txt"), exists("main.py"):
+		for _, entry := range []string{"main.py", "app.py", "server.py"} {
+			if exists(entry) {
+				return "python", fmt.Sprintf(`exec python3 %s "$@"`, entry)
+			}
+		}
+		return "python", runCommands["python"]
+	case exists("pom.xml"):
+		return "java", `mvn -q package -DskipTests
+exec java -jar target/*.jar "$@"`
+	case exists("build.gradle"), exists("build.gradle.kts"):
+		return "java", `exec ./gradlew --quiet run --args="$*"`
+	case exists("Main.java"):
+		return "java", runCommands["java"]
+	}
+
+	return "", ""
+}
+
+// detectNodeCommand picks the run.sh command for a package.json project,
+// preferring its start script over its main file.
+func detectNodeCommand(dir string) (string, string) {
+	language := "javascript"
+	if _, err := os.Stat(filepath.Join(dir, "tsconfig.json")); err == nil {
+		language = "typescript"
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return language, runCommands[language]
+	}
+
+	var pkg struct {
+		Main    string            `json:"main"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(bytes, &pkg) != nil {
+		return language, runCommands[language]
+	}
+
+	switch {
+	case pkg.Scripts["start"] != "":
+		return language, `exec npm start --silent -- "$@"`
+	case pkg.Main != "":
+		return language, fmt.Sprintf(`exec node %s "$@"`, pkg.Main)
+	}
+
+	return language, runCommands[language]
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/style"
+	"github.com/tidwall/gjson"
+	commands "github.com/urfave/cli/v3"
+)
+
+const lastFailurePath = ".lc/last-failure.json"
+
+// lastFailure is the most recent failed HTTP assertion, kept for lc diff.
+type lastFailure struct {
+	Challenge string              `json:"challenge"`
+	Stage     string              `json:"stage"`
+	Test      string              `json:"test"`
+	Time      time.Time           `json:"time"`
+	HTTP      *attest.HTTPFailure `json:"http"`
+}
+
+// saveLastFailure records the failed HTTP assertion of a run for lc diff.
+// Runs that failed some other way remove the previous record.
+func saveLastFailure(challengeKey, stageKey string, report *attest.Report) error {
+	for _, result := range report.Results {
+		if result.Passed {
+			continue
+		}
+
+		if result.HTTP == nil {
+			break
+		}
+
+		bytes, err := json.MarshalIndent(lastFailure{
+			Challenge: challengeKey,
+			Stage:     stageKey,
+			Test:      result.Name,
+			Time:      time.Now(),
+			HTTP:      result.HTTP,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Failed to encode last failure: %w", err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(lastFailurePath), 0755)
+		if err != nil {
+			return fmt.Errorf("Failed to create .lc directory: %w", err)
+		}
+
+		err = os.WriteFile(lastFailurePath, bytes, 0644)
+		if err != nil {
+			return fmt.Errorf("Failed to write last failure: %w", err)
+		}
+
+		return nil
+	}
+
+	err := os.Remove(lastFailurePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove last failure: %w", err)
+	}
+
+	return nil
+}
+
+// loadLastFailure reads the most recent failed HTTP assertion.
+func loadLastFailure() (*lastFailure, error) {
+	bytes, err := os.ReadFile(lastFailurePath)
+	if os.IsNotExist(err) {
+		return nil, environmentError("No failed HTTP request to compare.\nRun %s and come back when a request fails.", style.Yellow("'lc test'"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read last failure: %w", err)
+	}
+
+	var failure lastFailure
+	err = json.Unmarshal(bytes, &failure)
+	if err != nil || failure.HTTP == nil {
+		return nil, fmt.Errorf("Invalid last failure file %s", lastFailurePath)
+	}
+
+	return &failure, nil
+}
+
+// actualValue returns the part of a response the failed assertion checked.
+func actualValue(f *attest.HTTPFailure, status int, body string) string {
+	switch f.Field {
+	case "status":
+		return strconv.Itoa(status)
+	case "json":
+		return gjson.Get(body, f.JSONPath).String()
+	default:
+		return body
+	}
+}
+
+// lineDiff returns a line-by-line diff of expected and actual, marking
+// removed lines with - and added lines with +.
+func lineDiff(expected, actual string) []string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, style.Red("- "+a[i]))
+			i++
+		default:
+			lines = append(lines, style.Green("+ "+b[j]))
+			j++
+		}
+	}
+
+	return lines
+}
+
+// Diff replays the last failed HTTP request and diffs the expected and actual responses.
+func Diff(ctx context.Context, cmd *commands.Command) error {
+	last, err := loadLastFailure()
+	if err != nil {
+		return err
+	}
+	f := last.HTTP
+
+	fmt.Printf("Last failure: %s › %s (%s)\n", last.Stage, last.Test, last.Time.Format("2006-01-02 15:04"))
+	fmt.Printf("%s %s\n", f.Method, f.Path)
+	if f.Body != "" {
+		fmt.Printf("Request body: %q\n", f.Body)
+	}
+
+	status, body := f.Status, f.Response
+	source := "recorded during the test run"
+
+	switch addr := cmd.String("addr"); {
+	case cmd.Bool("recorded"):
+	case addr != "":
+		if !strings.Contains(addr, "://") {
+			addr = "http://" + addr
+		}
+
+		status, body, err = f.Send(ctx, strings.TrimSuffix(addr, "/"), settings.RetryTimeout())
+		if err != nil {
+			return fmt.Errorf("Failed to replay request: %w", err)
+		}
+		source = "replayed against " + addr
+	default:
+		_, err := os.Stat("run.sh")
+		if os.IsNotExist(err) {
+			return environmentError("run.sh not found\nUse --recorded to show the response from the test run.")
+		}
+
+		status, body, err = attest.Replay(ctx, &attest.Config{}, f)
+		if err != nil {
+			return fmt.Errorf("Failed to replay request: %w", err)
+		}
+		source = "replayed against a fresh instance"
+	}
+
+	field := f.Field
+	if field == "json" {
+		field = "JSON " + f.JSONPath
+	}
+
+	fmt.Printf("\n%s expected, %s actual %s (%s):\n\n", style.Red("-"), style.Green("+"), field, source)
+	for _, line := range lineDiff(f.Expected, actualValue(f, status, body)) {
+		fmt.Println(line)
+	}
+
+	if f.Field != "body" {
+		fmt.Printf("\nResponse: %d %q\n", status, body)
+	}
+
+	return nil
+}
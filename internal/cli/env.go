@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	commands "github.com/urfave/cli/v3"
+)
+
+// envPrefix starts the environment variables that set flags.
+const envPrefix = "LC_"
+
+// envVar returns the environment variable for a flag of the command at
+// path, the names of the commands below lc, e.g. LC_TEST_KEEP_GOING for
+// lc test --keep-going. The command is part of the name because commands
+// share flag names such as --output, and it keeps flags such as --all from
+// naming a locale variable.
+func envVar(path []string, flag string) string {
+	var b strings.Builder
+	b.WriteString(envPrefix)
+	for _, command := range path {
+		b.WriteString(envName(command) + "_")
+	}
+	b.WriteString(envName(flag))
+
+	return b.String()
+}
+
+// envName turns a flag or command name into its part of a variable name.
+func envName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// BindEnv lets every flag of cmd and its subcommands be set with an LC_
+// environment variable. Flags given on the command line take precedence.
+func BindEnv(cmd *commands.Command) {
+	bindEnv(cmd, nil)
+}
+
+// bindEnv binds the flags of cmd, found at path, and of its subcommands.
+func bindEnv(cmd *commands.Command, path []string) {
+	for _, flag := range cmd.Flags {
+		name := flag.Names()[0]
+		sources := commands.EnvVars(envVar(path, name))
+
+		switch f := flag.(type) {
+		case *commands.BoolFlag:
+			f.Sources = sources
+		case *commands.StringFlag:
+			f.Sources = sources
+		case *commands.IntFlag:
+			f.Sources = sources
+		case *commands.Uint64Flag:
+			f.Sources = sources
+		case *commands.StringSliceFlag:
+			f.Sources = sources
+		}
+	}
+
+	for _, sub := range cmd.Commands {
+		bindEnv(sub, slices.Concat(path, []string{sub.Name}))
+	}
+}
+
+// fromEnv reports whether flag of cmd has its LC_ variable set, so that a
+// value it has may not have been given on the command line.
+func fromEnv(cmd *commands.Command, flag string) bool {
+	var path []string
+	for _, c := range cmd.Lineage() {
+		if c.Root() != c {
+			path = append([]string{c.Name}, path...)
+		}
+	}
+
+	_, ok := os.LookupEnv(envVar(path, flag))
+	return ok
+}
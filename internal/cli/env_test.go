@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	commands "github.com/urfave/cli/v3"
+)
+
+func TestEnvVar(t *testing.T) {
+	tests := []struct {
+		path     []string
+		flag     string
+		expected string
+	}{
+		{path: nil, flag: "no-color", expected: "LC_NO_COLOR"},
+		{path: []string{"test"}, flag: "keep-going", expected: "LC_TEST_KEEP_GOING"},
+		{path: []string{"purge"}, flag: "all", expected: "LC_PURGE_ALL"},
+		{path: []string{"progress"}, flag: "all", expected: "LC_PROGRESS_ALL"},
+		{path: []string{"config", "list"}, flag: "json", expected: "LC_CONFIG_LIST_JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if actual := envVar(tt.path, tt.flag); actual != tt.expected {
+				t.Errorf("envVar(%q, %q) = %q, expected %q", tt.path, tt.flag, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBindEnvIgnoresLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "C.UTF-8")
+
+	var all bool
+	cmd := &commands.Command{
+		Name: "lc",
+		Commands: []*commands.Command{
+			{
+				Name:  "purge",
+				Flags: []commands.Flag{&commands.BoolFlag{Name: "all"}},
+				Action: func(ctx context.Context, cmd *commands.Command) error {
+					all = cmd.Bool("all")
+					return nil
+				},
+			},
+		},
+	}
+	BindEnv(cmd)
+
+	if err := cmd.Run(context.Background(), []string{"lc", "purge"}); err != nil {
+		t.Fatalf("LC_ALL should not set --all: %v", err)
+	}
+	if all {
+		t.Error("LC_ALL should not set --all")
+	}
+
+	t.Setenv("LC_PURGE_ALL", "true")
+	if err := cmd.Run(context.Background(), []string{"lc", "purge"}); err != nil {
+		t.Fatal(err)
+	}
+	if !all {
+		t.Error("LC_PURGE_ALL should set --all")
+	}
+}
+
+func TestBindEnvPerCommand(t *testing.T) {
+	t.Setenv("LC_BADGE_OUTPUT", "badge.svg")
+	t.Setenv("LC_OUTPUT", "ignored")
+
+	outputs := make(map[string]string)
+	command := func(name string) *commands.Command {
+		return &commands.Command{
+			Name:  name,
+			Flags: []commands.Flag{&commands.StringFlag{Name: "output"}},
+			Action: func(ctx context.Context, cmd *commands.Command) error {
+				outputs[name] = cmd.String("output")
+				return nil
+			},
+		}
+	}
+
+	cmd := &commands.Command{
+		Name:     "lc",
+		Commands: []*commands.Command{command("badge"), command("submit")},
+	}
+	BindEnv(cmd)
+
+	for _, name := range []string{"badge", "submit"} {
+		if err := cmd.Run(context.Background(), []string{"lc", name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if outputs["badge"] != "badge.svg" {
+		t.Errorf("LC_BADGE_OUTPUT should set lc badge --output, got %q", outputs["badge"])
+	}
+	if outputs["submit"] != "" {
+		t.Errorf("LC_BADGE_OUTPUT should not set lc submit --output, got %q", outputs["submit"])
+	}
+}
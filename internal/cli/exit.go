@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/littleclusters/lc/internal/auth"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/tui"
+	commands "github.com/urfave/cli/v3"
+)
+
+// Exit codes returned by lc, so scripts and graders can tell a failing
+// implementation apart from a broken setup or harness.
+const (
+	// exitTestFailure means tests or benchmarks ran and did not pass.
+	exitTestFailure = 1
+	// exitEnvironment means the project is not set up to run: no lc.state,
+	// an invalid run.sh, missing credentials, and similar.
+	exitEnvironment = 2
+	// exitUsage means the command line was invalid.
+	exitUsage = 3
+	// exitInternal means lc itself failed.
+	exitInternal = 4
+)
+
+// ExitCodes documents the exit codes for command help.
+const ExitCodes = `Exit codes:
+   0  success
+   1  tests failed
+   2  environment error, such as a missing run.sh or lc.state
+   3  usage error
+   4  internal error`
+
+// exitError attaches an exit code to an error.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+// testFailure returns an error for tests that ran and failed.
+func testFailure(format string, a ...any) error {
+	return &exitError{fmt.Errorf(format, a...), exitTestFailure}
+}
+
+// usageError returns an error for an invalid command line.
+func usageError(format string, a ...any) error {
+	return &exitError{fmt.Errorf(format, a...), exitUsage}
+}
+
+// environmentError returns an error for a project that is not set up to run.
+func environmentError(format string, a ...any) error {
+	return &exitError{fmt.Errorf(format, a...), exitEnvironment}
+}
+
+// asEnvironment marks err as a problem with the project setup.
+func asEnvironment(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &exitError{err, exitEnvironment}
+}
+
+// asUsage marks err as caused by invalid user input.
+func asUsage(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &exitError{err, exitUsage}
+}
+
+// UsageError marks flag and argument parsing errors as usage errors.
+func UsageError(ctx context.Context, cmd *commands.Command, err error, isSubcommand bool) error {
+	return &exitError{fmt.Errorf("%w\nRun '%s --help' for usage.", err, cmd.FullName()), exitUsage}
+}
+
+// ExitCode returns the exit code for an error returned by a command.
+// Errors without a known cause are internal errors.
+func ExitCode(err error) int {
+	var coder commands.ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	switch {
+	case errors.Is(err, state.ErrNotInChallenge), errors.Is(err, state.ErrInvalidState),
+		errors.Is(err, auth.ErrNotLoggedIn), errors.Is(err, tui.ErrNoTerminal):
+		return exitEnvironment
+	case errors.Is(err, registry.ErrNotFound):
+		return exitUsage
+	}
+
+	return exitInternal
+}
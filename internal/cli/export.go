@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	commands "github.com/urfave/cli/v3"
+)
+
+// report holds the data rendered by the export templates.
+type report struct {
+	Challenge string
+	Summary   string
+	Current   string
+	Completed int
+	Total     int
+	Generated string
+	Stages    []reportStage
+}
+
+// reportStage holds the progress and history of a single stage.
+type reportStage struct {
+	Index      int
+	Key        string
+	Name       string
+	GuideURL   string
+	Status     string
+	Runs       int
+	Attempts   int
+	TimeToPass string
+	LastResult string
+}
+
+const markdownReport = `# {{.Challenge}} Progress Report
+
+{{.Summary}}
+
+**Progress:** {{.Completed}}/{{.Total}} stages completed
+**Current stage:** {{.Current}}
+
+| # | Stage | Status | Last result | Runs | Attempts | Time to pass |
+|---|-------|--------|-------------|-----:|---------:|-------------:|
+{{range .Stages -}}
+| {{.Index}} | [{{.Key}}]({{.GuideURL}}) - {{.Name}} | {{.Status}} | {{.LastResult}} | {{.Runs}} | {{.Attempts}} | {{.TimeToPass}} |
+{{end}}
+_Generated by lc on {{.Generated}}._
+`
+
+const htmlReport = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Challenge}} Progress Report</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4rem 0.6rem; text-align: left; }
+th { background: #f5f5f5; }
+td.num { text-align: right; }
+footer { margin-top: 2rem; color: #777; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>{{.Challenge}} Progress Report</h1>
+<p>{{.Summary}}</p>
+<p><strong>Progress:</strong> {{.Completed}}/{{.Total}} stages completed<br>
+<strong>Current stage:</strong> {{.Current}}</p>
+<table>
+<tr><th>#</th><th>Stage</th><th>Status</th><th>Last result</th><th>Runs</th><th>Attempts</th><th>Time to pass</th></tr>
+{{range .Stages -}}
+<tr><td>{{.Index}}</td><td><a href="{{.GuideURL}}">{{.Key}}</a> - {{.Name}}</td><td>{{.Status}}</td><td>{{.LastResult}}</td><td class="num">{{.Runs}}</td><td class="num">{{.Attempts}}</td><td class="num">{{.TimeToPass}}</td></tr>
+{{end -}}
+</table>
+<footer>Generated by lc on {{.Generated}}.</footer>
+</body>
+</html>
+`
+
+// buildReport collects the progress report for the current challenge.
+func buildReport(cfg *state.State, challenge *registry.Challenge) (*report, error) {
+	events, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := history.Summarize(events, cfg.Challenge)
+	currentIndex := challenge.StageIndex(cfg.Stage)
+
+	r := &report{
+		Challenge: challenge.Name,
+		Summary:   challenge.Summary,
+		Current:   cfg.Stage,
+		Completed: completedStages(cfg, challenge, events),
+		Total:     challenge.Len(),
+		Generated: time.Now().Format("2006-01-02 15:04"),
+	}
+
+	for i, stageKey := range challenge.StageOrder {
+		stage := challenge.Stages[stageKey]
+
+		rs := reportStage{
+			Index:      i + 1,
+			Key:        stageKey,
+			Name:       stage.Name,
+			GuideURL:   guideURL(cfg.Challenge, stageKey),
+			Status:     "Pending",
+			LastResult: "-",
+			TimeToPass: "-",
+		}
+
+		if i < currentIndex && cfg.IsSkipped(stageKey) {
+			rs.Status = "Skipped"
+		} else if i < currentIndex {
+			rs.Status = "Completed"
+		} else if i == currentIndex {
+			rs.Status = "In progress"
+		}
+
+		if s, ok := stats[stageKey]; ok && s.Runs > 0 {
+			rs.Runs = s.Runs
+			rs.Attempts = s.Attempts
+			rs.LastResult = "Failed"
+			if s.LastPassed {
+				rs.LastResult = "Passed"
+			}
+			if s.Passed() {
+				rs.TimeToPass = formatDuration(s.TimeToPass())
+			}
+		}
+
+		r.Stages = append(r.Stages, rs)
+	}
+
+	return r, nil
+}
+
+// ExportReport renders the challenge progress as a Markdown or HTML report.
+func ExportReport(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	output := cmd.String("output")
+	format := cmd.String("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".html", ".htm":
+			format = "html"
+		default:
+			format = "markdown"
+		}
+	}
+
+	r, err := buildReport(cfg, challenge)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "markdown", "md":
+		err = template.Must(template.New("report").Parse(markdownReport)).Execute(&buf, r)
+	case "html":
+		err = htmltemplate.Must(htmltemplate.New("report").Parse(htmlReport)).Execute(&buf, r)
+	default:
+		return usageError("Unknown format %q.\nUsage: lc export --format markdown|html", format)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to render report: %w", err)
+	}
+
+	if output == "" {
+		fmt.Print(buf.String())
+		return nil
+	}
+
+	err = os.WriteFile(output, buf.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write report: %w", err)
+	}
+
+	fmt.Printf("Exported progress report to %s\n", output)
+
+	return nil
+}
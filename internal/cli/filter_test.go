@@ -0,0 +1,47 @@
+package cli
+
+import "testing"
+
+func TestTestFilter(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "glob:GET*", name: "GET returns value", expected: true},
+		{pattern: "glob:GET*", name: "get missing key", expected: true},
+		{pattern: "glob:GET*", name: "Large values", expected: false},
+		{pattern: "glob:GET*", name: "PUT then GET", expected: false},
+		{pattern: "glob:*value*", name: "Large values", expected: true},
+		{pattern: "value", name: "Large values", expected: true},
+		{pattern: "value", name: "PUT then GET", expected: false},
+		{pattern: "VALUE", name: "Large values", expected: true},
+		{pattern: "GET*", name: "Get everything", expected: true},
+		{pattern: "GET.*Edge", name: "GET edge cases", expected: true},
+		{pattern: "GET.*Edge", name: "GET returns value", expected: false},
+		{pattern: "PUT [0-9]+", name: "PUT 100 keys", expected: true},
+		{pattern: "PUT [0-9]+", name: "PUT then GET", expected: false},
+		{pattern: "^PUT|DELETE$", name: "PUT then GET", expected: true},
+		{pattern: "^PUT|DELETE$", name: "GET after DELETE", expected: true},
+		{pattern: "^PUT|DELETE$", name: "GET after PUT", expected: false},
+		{pattern: "(unclosed", name: "Group (unclosed", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			filter, err := testFilter(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := filter(tt.name); actual != tt.expected {
+				t.Errorf("testFilter(%q)(%q) = %v, expected %v", tt.pattern, tt.name, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTestFilterInvalidGlob(t *testing.T) {
+	if _, err := testFilter("glob:[unclosed"); err == nil {
+		t.Error("expected an error for an invalid glob")
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runGit runs a git command in dir, including its output in any error.
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("git %s failed: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+// isGitRepo reports whether dir is inside a git work tree.
+func isGitRepo(dir string) bool {
+	return runGit(dir, "rev-parse", "--is-inside-work-tree") == nil
+}
+
+// initGitRepo initializes a git repository in dir and commits the challenge files.
+func initGitRepo(dir, challengeKey string) error {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		return environmentError("git not found\nInstall git or run 'lc init' without --git.")
+	}
+
+	if !isGitRepo(dir) {
+		err := runGit(dir, "init", "--quiet")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = runGit(dir, "add", "run.sh", "README.md", "lc.state", ".gitignore")
+	if err != nil {
+		return err
+	}
+
+	return runGit(dir, "commit", "--quiet", "-m", fmt.Sprintf("Initialize %s challenge", challengeKey))
+}
+
+// tagStage tags the current commit as the completion of a stage.
+func tagStage(challengeKey, stageKey string) (string, error) {
+	if !isGitRepo(".") {
+		return "", environmentError("Not a git repository\nRun 'git init' or 'lc init --git' to track your progress with git.")
+	}
+
+	tag := fmt.Sprintf("%s/%s", challengeKey, stageKey)
+	err := runGit(".", "tag", tag)
+	if err != nil {
+		return "", err
+	}
+
+	return tag, nil
+}
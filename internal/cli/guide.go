@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/littleclusters/lc/internal/markdown"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+// maxGuideWidth caps the line length of rendered guides for readability.
+const maxGuideWidth = 100
+
+// Guide renders the bundled guide for a stage in the terminal.
+func Guide(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	var stageKey string
+	switch cmd.NArg() {
+	case 0:
+		stageKey = cfg.Stage
+	case 1:
+		stageKey = cmd.Args().First()
+	default:
+		return usageError("Too many arguments.\nUsage: lc guide [stage]")
+	}
+
+	stage, err := challenge.GetStage(stageKey)
+	if err != nil {
+		return err
+	}
+
+	guideURL := guideURL(cfg.Challenge, stageKey)
+	if stage.Guide == "" {
+		fmt.Printf("No offline guide is bundled for %s.\n", stageKey)
+		fmt.Printf("Read the guide: %s\n", style.Link(guideURL, guideURL))
+		return nil
+	}
+
+	width := maxGuideWidth
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w < width {
+		width = w
+	}
+
+	fmt.Print(markdown.Render(stage.Guide, width))
+	fmt.Printf("\nOnline version: %s\n", style.Link(guideURL, guideURL))
+
+	return nil
+}
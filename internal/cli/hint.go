@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// Hint reveals the next hint for a stage, repeating the hints already revealed.
+func Hint(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	var stageKey string
+	switch cmd.NArg() {
+	case 0:
+		stageKey = cfg.Stage
+	case 1:
+		stageKey = cmd.Args().First()
+	default:
+		return usageError("Too many arguments.\nUsage: lc hint [stage]")
+	}
+
+	stage, err := challenge.GetStage(stageKey)
+	if err != nil {
+		return err
+	}
+
+	guideURL := guideURL(cfg.Challenge, stageKey)
+	if len(stage.Hints) == 0 {
+		fmt.Printf("No hints for %s yet.\n", stageKey)
+		fmt.Printf("Read the guide: %s\n", style.Link(guideURL, guideURL))
+		return nil
+	}
+
+	events, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	revealed := 0
+	if s, ok := history.Summarize(events, cfg.Challenge)[stageKey]; ok {
+		revealed = s.Hints
+	}
+
+	if revealed < len(stage.Hints) {
+		revealed++
+
+		err := history.Record(history.Event{
+			Kind:      history.KindHint,
+			Challenge: cfg.Challenge,
+			Stage:     stageKey,
+			Hint:      revealed,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Hints for %s: %s\n\n", stageKey, stage.Name)
+	for i, hint := range stage.Hints[:revealed] {
+		fmt.Printf("%d/%d. %s\n", i+1, len(stage.Hints), hint)
+	}
+
+	if revealed < len(stage.Hints) {
+		fmt.Printf("\nRun %s again for the next hint.\n", style.Yellow("'lc hint'"))
+	} else {
+		fmt.Printf("\nThat was the last hint. Read the guide: %s\n", style.Link(guideURL, guideURL))
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/registry"
+	commands "github.com/urfave/cli/v3"
+)
+
+// challengeInfo is the JSON representation of a registered challenge.
+type challengeInfo struct {
+	Key        string      `json:"key"`
+	Name       string      `json:"name"`
+	Summary    string      `json:"summary"`
+	Difficulty string      `json:"difficulty,omitempty"`
+	Tags       []string    `json:"tags"`
+	GuideURL   string      `json:"guideUrl"`
+	Stages     []stageInfo `json:"stages"`
+}
+
+// stageInfo is the JSON representation of a challenge stage.
+type stageInfo struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	GuideURL string `json:"guideUrl"`
+}
+
+// newChallengeInfo converts a registered challenge for JSON output.
+func newChallengeInfo(challenge *registry.Challenge) challengeInfo {
+	info := challengeInfo{
+		Key:        challenge.Key,
+		Name:       challenge.Name,
+		Summary:    challenge.Summary,
+		Difficulty: challenge.Difficulty,
+		Tags:       challenge.Tags,
+		GuideURL:   fmt.Sprintf("%s/%s/", settings.DocsBaseURL(), challenge.Key),
+	}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+
+	for _, key := range challenge.StageOrder {
+		info.Stages = append(info.Stages, stageInfo{
+			Key:      key,
+			Name:     challenge.Stages[key].Name,
+			GuideURL: guideURL(challenge.Key, key),
+		})
+	}
+
+	return info
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("Failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// hasAllTags reports whether the challenge is tagged with every tag.
+func hasAllTags(challenge *registry.Challenge, tags []string) bool {
+	for _, tag := range tags {
+		if !challenge.HasTag(strings.ToLower(tag)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListChallenges lists available challenges, optionally filtered by tag and difficulty.
+func ListChallenges(ctx context.Context, cmd *commands.Command) error {
+	tags := cmd.StringSlice("tag")
+	difficulty := strings.ToLower(cmd.String("difficulty"))
+
+	if difficulty != "" && !slices.Contains(registry.Difficulties, difficulty) {
+		return usageError("Unknown difficulty %q.\nUse one of: %s", difficulty, strings.Join(registry.Difficulties, ", "))
+	}
+
+	var matches []*registry.Challenge
+	for _, challenge := range registry.SortedChallenges() {
+		if difficulty != "" && challenge.Difficulty != difficulty {
+			continue
+		}
+
+		if hasAllTags(challenge, tags) {
+			matches = append(matches, challenge)
+		}
+	}
+
+	if cmd.Bool("json") {
+		infos := make([]challengeInfo, 0, len(matches))
+		for _, challenge := range matches {
+			infos = append(infos, newChallengeInfo(challenge))
+		}
+
+		return printJSON(infos)
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No challenges match the given filters.\n")
+		return nil
+	}
+
+	fmt.Printf("Available challenges:\n\n")
+
+	for _, challenge := range matches {
+		fmt.Printf("  %-20s - %s (%d stages)\n", challenge.Key, challenge.Name, challenge.Len())
+
+		var details []string
+		if challenge.Difficulty != "" {
+			details = append(details, challenge.Difficulty)
+		}
+		if len(challenge.Tags) > 0 {
+			details = append(details, strings.Join(challenge.Tags, ", "))
+		}
+		if len(details) > 0 {
+			fmt.Printf("  %-20s   %s\n", "", strings.Join(details, " · "))
+		}
+	}
+
+	fmt.Printf("\nStart with: lc init <challenge-name>\n")
+
+	return nil
+}
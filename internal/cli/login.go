@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/littleclusters/lc/internal/auth"
+	"github.com/littleclusters/lc/internal/config"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// tokenEnv is the environment variable lc login reads an API token from.
+const tokenEnv = "LC_TOKEN"
+
+// authClient returns a client for the configured authentication API.
+func authClient() *auth.Client {
+	return &auth.Client{BaseURL: settings.APIURL()}
+}
+
+// loadCredentials returns the stored credentials for the current user.
+func loadCredentials() (*auth.Credentials, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.Load(dir)
+}
+
+// Login authenticates with littleclusters.com and stores the session.
+func Login(ctx context.Context, cmd *commands.Command) error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+
+	client := authClient()
+
+	token := os.Getenv(tokenEnv)
+	if cmd.Bool("with-token") {
+		token, err = readToken(os.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+
+	if token == "" {
+		code, err := client.RequestDeviceCode(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Open %s and enter the code: %s\n\n", code.VerificationURI, style.Yellow(code.UserCode))
+		browserCommand(code.VerificationURI).Start()
+		fmt.Println("Waiting for approval...")
+
+		token, err = client.PollToken(ctx, code)
+		if err != nil {
+			return err
+		}
+	}
+
+	user, err := client.User(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	err = auth.Save(dir, &auth.Credentials{Token: token, User: user, Created: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in as %s.\n", user)
+
+	return nil
+}
+
+// readToken reads an API token from the first line of r. Tokens are never
+// taken as arguments, which would show them in ps and the shell history.
+func readToken(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("Failed to read token: %w", err)
+	}
+
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", usageError("No token on standard input\nPipe it in, e.g. lc login --with-token < token.txt")
+	}
+
+	return token, nil
+}
+
+// Logout removes the stored session.
+func Logout(ctx context.Context, cmd *commands.Command) error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+
+	creds, err := auth.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	err = auth.Delete(dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged out %s.\n", creds.User)
+
+	return nil
+}
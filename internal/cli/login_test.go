@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadToken(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		shouldErr bool
+	}{
+		{input: "s3cret\n", expected: "s3cret"},
+		{input: "  s3cret  ", expected: "s3cret"},
+		{input: "s3cret\nignored\n", expected: "s3cret"},
+		{input: "", shouldErr: true},
+		{input: "\n", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			token, err := readToken(strings.NewReader(tt.input))
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", token)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tt.expected {
+				t.Errorf("got %q, expected %q", token, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	commands "github.com/urfave/cli/v3"
+)
+
+// browserCommand returns the command that opens a URL in the system browser.
+func browserCommand(url string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command("xdg-open", url)
+	}
+}
+
+// OpenGuide opens the guide for the current (or specified) stage in a browser.
+func OpenGuide(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	var stageKey string
+	switch cmd.NArg() {
+	case 0:
+		stageKey = cfg.Stage
+	case 1:
+		stageKey = cmd.Args().First()
+	default:
+		return usageError("Too many arguments.\nUsage: lc open [stage]")
+	}
+
+	_, err = challenge.GetStage(stageKey)
+	if err != nil {
+		return err
+	}
+
+	url := guideURL(cfg.Challenge, stageKey)
+	err = browserCommand(url).Start()
+	if err != nil {
+		return fmt.Errorf("Failed to open browser: %w\nOpen the guide manually: %s", err, url)
+	}
+
+	fmt.Printf("Opened %s\n", url)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/state"
+	commands "github.com/urfave/cli/v3"
+)
+
+// pluginPrefix starts the names of executables that extend lc, so that
+// 'lc foo' runs lc-foo from PATH when foo is not a built-in command.
+const pluginPrefix = "lc-"
+
+// FindPlugin returns the executable on PATH for an unknown subcommand in
+// args, along with the arguments to pass it.
+func FindPlugin(cmd *commands.Command, args []string) (string, []string, bool) {
+	// Skip global flags, which are all booleans
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		if args[i] == "--" {
+			return "", nil, false
+		}
+		i++
+	}
+
+	if i == len(args) {
+		return "", nil, false
+	}
+
+	name := args[i]
+	if name == "help" || name == "h" || cmd.Command(name) != nil || strings.ContainsRune(name, os.PathSeparator) {
+		return "", nil, false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return "", nil, false
+	}
+
+	return path, args[i+1:], true
+}
+
+// RunPlugin runs a plugin executable with the terminal attached and returns
+// its exit code. The plugin learns about lc and the current challenge from
+// LC_BIN, LC_VERSION, LC_PROJECT_DIR, LC_STATE_FILE, LC_CHALLENGE and LC_STAGE.
+func RunPlugin(path string, args []string) (int, error) {
+	plugin := exec.Command(path, args...)
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+	plugin.Env = append(os.Environ(), pluginEnv()...)
+
+	err := plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Failed to run %s: %w", filepath.Base(path), err)
+	}
+
+	return 0, nil
+}
+
+// pluginEnv describes lc and the challenge in the working directory, or the
+// workspace project containing it, as environment variables.
+func pluginEnv() []string {
+	env := []string{"LC_VERSION=" + LCVersion()}
+
+	bin, err := os.Executable()
+	if err == nil {
+		env = append(env, "LC_BIN="+bin)
+	}
+
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return env
+	}
+
+	if _, err := os.Stat("lc.state"); err != nil {
+		ws, _ := state.FindWorkspace(".")
+		if ws == nil {
+			return env
+		}
+
+		dir = ws.Containing(".")
+		if dir == "" {
+			return env
+		}
+	}
+
+	statePath := filepath.Join(dir, "lc.state")
+	st, err := state.LoadFrom(statePath)
+	if err != nil {
+		return env
+	}
+
+	return append(env,
+		"LC_PROJECT_DIR="+dir,
+		"LC_STATE_FILE="+statePath,
+		"LC_CHALLENGE="+st.Challenge,
+		"LC_STAGE="+st.Stage,
+	)
+}
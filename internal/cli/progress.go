@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// maxScanDepth bounds how deep lc progress --all looks for projects.
+const maxScanDepth = 3
+
+// projectProgress summarizes the progress of one challenge directory.
+type projectProgress struct {
+	Path      string
+	Challenge string
+	Stage     string
+	Completed int
+	Total     int
+	LastRun   time.Time
+	Err       error
+}
+
+// findProjects returns the challenge directories of a workspace.
+// Directories listed in lc.workspace take precedence over scanning.
+func findProjects(root string) ([]string, error) {
+	ws, err := state.LoadWorkspace(root)
+	if err == nil {
+		dirs := make([]string, 0, len(ws.Projects))
+		for _, dir := range ws.Projects {
+			rel, err := filepath.Rel(ws.Root, dir)
+			if err != nil {
+				rel = dir
+			}
+			dirs = append(dirs, rel)
+		}
+
+		return dirs, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			return fs.SkipDir
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "target") {
+			return fs.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, "lc.state")); err == nil {
+			dirs = append(dirs, path)
+			return fs.SkipDir
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if rel != "." && strings.Count(rel, string(filepath.Separator)) >= maxScanDepth-1 {
+			return fs.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to scan for projects: %w", err)
+	}
+
+	return dirs, nil
+}
+
+// loadProgress reads the state and history of the challenge in dir.
+func loadProgress(dir string) projectProgress {
+	p := projectProgress{Path: dir}
+
+	cfg, err := state.LoadFrom(filepath.Join(dir, "lc.state"))
+	if err != nil {
+		p.Err = err
+		return p
+	}
+	p.Challenge = cfg.Challenge
+	p.Stage = cfg.Stage
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		p.Err = err
+		return p
+	}
+
+	events, err := history.LoadFrom(filepath.Join(dir, ".lc", "history"))
+	if err != nil {
+		p.Err = err
+		return p
+	}
+
+	p.Completed = completedStages(cfg, challenge, events)
+	p.Total = challenge.Len()
+	for _, s := range history.Summarize(events, cfg.Challenge) {
+		if s.LastRun.After(p.LastRun) {
+			p.LastRun = s.LastRun
+		}
+	}
+
+	return p
+}
+
+// progressBar renders completed out of total as a fixed-width bar.
+func progressBar(completed, total, width int) string {
+	if total == 0 {
+		return strings.Repeat("░", width)
+	}
+
+	filled := completed * width / total
+	return style.Green(strings.Repeat("█", filled)) + strings.Repeat("░", width-filled)
+}
+
+// formatAge formats the time since t, or "never" for a zero time.
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// ShowProgress prints a progress table for the current challenge or, with
+// --all, every challenge directory in the workspace.
+func ShowProgress(ctx context.Context, cmd *commands.Command) error {
+	dirs := []string{"."}
+	if cmd.Bool("all") {
+		found, err := findProjects(".")
+		if err != nil {
+			return err
+		}
+
+		if len(found) == 0 {
+			fmt.Println("No challenge directories found.")
+			fmt.Printf("Run %s in a workspace containing projects created with 'lc init', or list them in %s.\n",
+				style.Yellow("'lc progress --all'"), state.WorkspaceFile)
+			return nil
+		}
+		dirs = found
+	} else if _, err := state.Load(); err != nil {
+		return err
+	}
+
+	fmt.Printf("  %-24s  %-14s  %-18s  %-18s  %s\n", "Project", "Challenge", "Stage", "Progress", "Last run")
+
+	var completed, total int
+	for _, dir := range dirs {
+		p := loadProgress(dir)
+		if p.Err != nil {
+			fmt.Printf("  %-24s  %s\n", dir, style.Red(firstLine(p.Err.Error())))
+			continue
+		}
+
+		fmt.Printf("  %-24s  %-14s  %-18s  %s %5s  %s\n",
+			dir, p.Challenge, p.Stage, progressBar(p.Completed, p.Total, 12),
+			fmt.Sprintf("%d/%d", p.Completed, p.Total), formatAge(p.LastRun))
+
+		completed += p.Completed
+		total += p.Total
+	}
+
+	if len(dirs) > 1 {
+		fmt.Printf("\nTotal: %d/%d stages completed across %d projects\n", completed, total, len(dirs))
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/config"
+	"github.com/littleclusters/lc/internal/style"
+	"github.com/littleclusters/lc/internal/telemetry"
+	commands "github.com/urfave/cli/v3"
+)
+
+// purgeCategory is a kind of global lc data that lc purge can remove.
+type purgeCategory struct {
+	Name        string
+	Description string
+	// Files are relative to the global config directory.
+	Files []string
+}
+
+// purgeCategories lists all global data lc keeps outside challenge directories.
+// Project data in .lc/ is not included; delete it with the project.
+var purgeCategories = []purgeCategory{
+	{
+		Name:        "credentials",
+		Description: "login token",
+		Files:       []string{"credentials.json"},
+	},
+	{
+		Name:        "config",
+		Description: "preferences set with lc config",
+		Files:       []string{"config.toml"},
+	},
+	{
+		Name:        "telemetry",
+		Description: "unsent telemetry events and installation ID",
+		Files:       []string{telemetry.QueueFile, telemetry.IDFile},
+	},
+}
+
+// PurgeFlags returns a flag for each purge category.
+func PurgeFlags() []commands.Flag {
+	flags := []commands.Flag{
+		&commands.BoolFlag{
+			Name:  "all",
+			Usage: "Remove all global lc data",
+		},
+		&commands.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show what would be removed without removing it",
+		},
+	}
+
+	for _, category := range purgeCategories {
+		flags = append(flags, &commands.BoolFlag{
+			Name:  category.Name,
+			Usage: "Remove the " + category.Description,
+		})
+	}
+
+	return flags
+}
+
+// Purge removes global lc data by category.
+func Purge(ctx context.Context, cmd *commands.Command) error {
+	all := cmd.Bool("all")
+
+	var selected []purgeCategory
+	for _, category := range purgeCategories {
+		if all || cmd.Bool(category.Name) {
+			selected = append(selected, category)
+		}
+	}
+
+	if len(selected) == 0 {
+		names := make([]string, 0, len(purgeCategories))
+		for _, category := range purgeCategories {
+			names = append(names, "--"+category.Name)
+		}
+
+		return usageError("Nothing to purge.\nUsage: lc purge --all | %s", strings.Join(names, " | "))
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+
+	dryRun := cmd.Bool("dry-run")
+	removed := 0
+	for _, category := range selected {
+		for _, file := range category.Files {
+			path := filepath.Join(dir, file)
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				continue
+			}
+
+			if !dryRun {
+				err := os.Remove(path)
+				if err != nil {
+					return fmt.Errorf("Failed to remove %s: %w", path, err)
+				}
+			}
+
+			fmt.Printf("%s %s (%s)\n", style.Red("-"), path, category.Name)
+			removed++
+		}
+	}
+
+	// Leave no trace of lc once everything is gone
+	if all && !dryRun {
+		os.Remove(dir)
+	}
+
+	files := "files"
+	if removed == 1 {
+		files = "file"
+	}
+
+	switch {
+	case removed == 0:
+		fmt.Println("No global lc data to remove.")
+	case dryRun:
+		fmt.Printf("\nWould remove %d %s. Run without --dry-run to remove them.\n", removed, files)
+	default:
+		fmt.Printf("\nRemoved %d %s.\n", removed, files)
+	}
+
+	return nil
+}
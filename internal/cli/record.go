@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+const recordingsDir = ".lc/recordings"
+
+// recording is the traffic captured from one stage run, saved by lc record.
+type recording struct {
+	Challenge string         `json:"challenge"`
+	Stage     string         `json:"stage"`
+	Time      time.Time      `json:"time"`
+	Seed      uint64         `json:"seed"`
+	Passed    bool           `json:"passed"`
+	Entries   []attest.Entry `json:"entries"`
+}
+
+// Record runs a stage suite and saves every request it sends for later replay.
+func Record(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := validateEnvironment()
+	if err != nil {
+		return err
+	}
+
+	stageKey := cfg.Stage
+	switch cmd.NArg() {
+	case 0:
+	case 1:
+		stageKey = cmd.Args().First()
+	default:
+		return usageError("Too many arguments.\nUsage: lc record [stage]")
+	}
+
+	recorder := attest.NewRecorder()
+	start := time.Now()
+	report, err := runStageTests(ctx, cfg.Challenge, stageKey, testOptions{
+		seed:     cmd.Uint64("seed"),
+		parallel: 1,
+		recorder: recorder,
+	})
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	output := cmd.String("output")
+	if output == "" {
+		output = filepath.Join(recordingsDir, fmt.Sprintf("%s-%s.json", stageKey, start.Format("20060102-150405")))
+	}
+
+	bytes, err := json.MarshalIndent(recording{
+		Challenge: cfg.Challenge,
+		Stage:     stageKey,
+		Time:      start,
+		Seed:      report.Seed,
+		Passed:    report.Passed,
+		Entries:   recorder.Entries(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode recording: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(output), 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create recordings directory: %w", err)
+	}
+
+	err = os.WriteFile(output, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write recording: %w", err)
+	}
+
+	fmt.Printf("\nRecorded %d actions to %s\n", len(recorder.Entries()), output)
+	fmt.Printf("Run %s to send them again.\n", style.Yellow(fmt.Sprintf("'lc replay %s'", output)))
+
+	return nil
+}
+
+// Replay sends the actions of a recording to fresh instances of the
+// implementation without checking any expectations.
+func Replay(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() != 1 {
+		return usageError("Recording file is required.\nUsage: lc replay <file>")
+	}
+
+	err := checkRunScript(".")
+	if err != nil {
+		return err
+	}
+
+	path := cmd.Args().First()
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read recording: %w", err)
+	}
+
+	var rec recording
+	err = json.Unmarshal(bytes, &rec)
+	if err != nil {
+		return fmt.Errorf("Invalid recording %s: %w", path, err)
+	}
+
+	fmt.Printf("Replaying %d actions from %s:%s (%s)\n", len(rec.Entries), rec.Challenge, rec.Stage, rec.Time.Format("2006-01-02 15:04"))
+
+	failed := 0
+	attest.ReplayEntries(ctx, &attest.Config{}, rec.Entries, cmd.Bool("realtime"), func(entry attest.Entry, result attest.ReplayResult) {
+		if result.Err != nil {
+			failed++
+		}
+
+		fmt.Println(formatReplay(entry, result))
+	})
+
+	if failed > 0 {
+		fmt.Printf("\n%d actions failed to replay.\n", failed)
+	}
+
+	return nil
+}
+
+// formatReplay describes a replayed action and its outcome on one line.
+func formatReplay(entry attest.Entry, result attest.ReplayResult) string {
+	var line string
+	switch entry.Kind {
+	case attest.EntryTest:
+		return "\n" + style.Bold(entry.Test)
+	case attest.EntryHTTP:
+		line = fmt.Sprintf("  %s %s", entry.Method, entry.Path)
+		if result.Err == nil {
+			line += fmt.Sprintf(" → %d %s", result.Status, truncate(result.Output, 60))
+		}
+	case attest.EntryTCP, attest.EntryUDP:
+		line = fmt.Sprintf("  %s %s %s", entry.Kind, entry.Process, truncate(entry.Body, 40))
+		if result.Err == nil {
+			line += fmt.Sprintf(" → %s", truncate(result.Output, 60))
+		}
+	case attest.EntryExec:
+		line = fmt.Sprintf("  exec %s", strings.Join(entry.Args, " "))
+		if result.Err == nil {
+			line += fmt.Sprintf(" → exit %d %s", result.Status, truncate(result.Output, 60))
+		}
+	default:
+		line = fmt.Sprintf("  %s %s", entry.Kind, entry.Process)
+	}
+
+	if result.Err != nil {
+		return fmt.Sprintf("%s %s %s", line, style.CrossMark(), firstLine(result.Err.Error()))
+	}
+
+	return fmt.Sprintf("%s (%s)", line, formatDuration(result.Duration))
+}
+
+// truncate quotes s, shortening it to at most n characters.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return fmt.Sprintf("%q…", string(runes[:n]))
+	}
+
+	return fmt.Sprintf("%q", s)
+}
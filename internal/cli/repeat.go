@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/style"
+)
+
+// testTally counts the outcomes of a test across repeated runs.
+type testTally struct {
+	name   string
+	passed int
+	failed int
+}
+
+// tallyReports counts passes and failures per test, in suite order.
+func tallyReports(reports []*attest.Report) []*testTally {
+	var tallies []*testTally
+	byName := make(map[string]*testTally)
+
+	for _, report := range reports {
+		for _, result := range report.Results {
+			t, ok := byName[result.Name]
+			if !ok {
+				t = &testTally{name: result.Name}
+				byName[result.Name] = t
+				tallies = append(tallies, t)
+			}
+
+			if result.Passed {
+				t.passed++
+			} else {
+				t.failed++
+			}
+		}
+	}
+
+	return tallies
+}
+
+// repeatStageTests runs a stage suite n times and summarizes flaky tests.
+// It returns true only if every run passed.
+func repeatStageTests(ctx context.Context, challengeKey, stageKey string, opts testOptions, n int) (bool, error) {
+	var reports []*attest.Report
+	failedRuns := 0
+
+	for i := 1; i <= n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fmt.Printf("Run %d/%d\n", i, n)
+		report, err := runStageTests(ctx, challengeKey, stageKey, opts)
+		if err != nil {
+			return false, err
+		}
+		fmt.Println()
+
+		reports = append(reports, report)
+		if !report.Passed {
+			failedRuns++
+		}
+	}
+
+	fmt.Printf("Ran %s %d times: %d passed, %d failed\n", stageKey, len(reports), len(reports)-failedRuns, failedRuns)
+
+	var flaky, failing []*testTally
+	for _, t := range tallyReports(reports) {
+		switch {
+		case t.failed > 0 && t.passed > 0:
+			flaky = append(flaky, t)
+		case t.failed > 0:
+			failing = append(failing, t)
+		}
+	}
+
+	if len(flaky) > 0 {
+		fmt.Printf("\nFlaky tests:\n")
+		for _, t := range flaky {
+			fmt.Printf("  ~ %-40s passed %d/%d\n", t.name, t.passed, t.passed+t.failed)
+		}
+	}
+
+	if len(failing) > 0 {
+		fmt.Printf("\nFailing tests:\n")
+		for _, t := range failing {
+			fmt.Printf("  %s %-40s failed %d/%d\n", style.CrossMark(), t.name, t.failed, t.failed)
+		}
+	}
+
+	return failedRuns == 0 && len(reports) == n, nil
+}
+
+// untilFailStageTests runs a stage suite until it fails or maxRuns is reached.
+// It returns true if every run passed.
+func untilFailStageTests(ctx context.Context, challengeKey, stageKey string, opts testOptions, maxRuns int) (bool, error) {
+	for i := 1; i <= maxRuns; i++ {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
+		fmt.Printf("Run %d/%d\n", i, maxRuns)
+		report, err := runStageTests(ctx, challengeKey, stageKey, opts)
+		if err != nil {
+			return false, err
+		}
+		fmt.Println()
+
+		if !report.Passed {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+
+			fmt.Printf("%s failed on run %d after %d passing runs.\n", stageKey, i, i-1)
+			for _, result := range report.Results {
+				if !result.Passed {
+					fmt.Printf("Failing test: %s\n", result.Name)
+				}
+			}
+			fmt.Printf("Logs: %s\n", report.WorkingDir)
+			fmt.Printf("Reproduce with: lc test %s --seed %d\n", stageKey, report.Seed)
+
+			return false, nil
+		}
+	}
+
+	fmt.Printf("%s passed all %d runs without failing.\n", stageKey, maxRuns)
+	return true, nil
+}
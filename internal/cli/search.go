@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// searchResult is a challenge matching a search query.
+type searchResult struct {
+	challenge *registry.Challenge
+	score     int
+	// stages holds the keys of stages whose names matched.
+	stages []string
+}
+
+// matchTerm scores how well term matches text.
+// Whole words score highest, then substrings, then in-order subsequences
+// within a single word (so "raf" or "rft" still find "raft").
+func matchTerm(term, text string) int {
+	text = strings.ToLower(text)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+
+	for _, word := range words {
+		if word == term {
+			return 3
+		}
+	}
+
+	if strings.Contains(text, term) {
+		return 2
+	}
+
+	if len(term) < 3 {
+		return 0
+	}
+
+	for _, word := range words {
+		if isSubsequence(term, word) && len(word) <= 2*len(term) {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// isSubsequence reports whether the letters of s appear in order in t.
+func isSubsequence(s, t string) bool {
+	i := 0
+	for j := 0; i < len(s) && j < len(t); j++ {
+		if s[i] == t[j] {
+			i++
+		}
+	}
+
+	return i == len(s)
+}
+
+// searchChallenge scores a challenge against the query terms.
+// Every term must match the challenge or one of its stages.
+func searchChallenge(challenge *registry.Challenge, terms []string) (searchResult, bool) {
+	result := searchResult{challenge: challenge}
+	matchedStages := make(map[string]bool)
+
+	for _, term := range terms {
+		best := 0
+
+		fields := []string{challenge.Key, challenge.Name, challenge.Difficulty}
+		fields = append(fields, challenge.Tags...)
+		for _, field := range fields {
+			best = max(best, 2*matchTerm(term, field))
+		}
+		best = max(best, matchTerm(term, challenge.Summary))
+
+		for _, key := range challenge.StageOrder {
+			score := max(matchTerm(term, key), matchTerm(term, challenge.Stages[key].Name))
+			if score > 0 {
+				matchedStages[key] = true
+				best = max(best, score)
+			}
+		}
+
+		if best == 0 {
+			return result, false
+		}
+
+		result.score += best
+	}
+
+	for _, key := range challenge.StageOrder {
+		if matchedStages[key] {
+			result.stages = append(result.stages, key)
+		}
+	}
+
+	return result, true
+}
+
+// Search finds challenges whose names, summaries, tags, or stages match the query.
+func Search(ctx context.Context, cmd *commands.Command) error {
+	if cmd.NArg() == 0 {
+		return usageError("Missing search query.\nUsage: lc search <query>")
+	}
+
+	terms := strings.Fields(strings.ToLower(strings.Join(cmd.Args().Slice(), " ")))
+
+	var results []searchResult
+	for _, challenge := range registry.SortedChallenges() {
+		result, ok := searchChallenge(challenge, terms)
+		if ok {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No challenges match %q.\n", strings.Join(terms, " "))
+		fmt.Printf("Run %s to see all challenges.\n", style.Yellow("'lc list'"))
+		return nil
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	for _, result := range results {
+		challenge := result.challenge
+		fmt.Printf("%-20s - %s (%d stages)\n", challenge.Key, challenge.Name, challenge.Len())
+
+		for _, key := range result.stages {
+			index := challenge.StageIndex(key)
+			fmt.Printf("  %d. %-20s - %s\n", index+1, key, challenge.Stages[key].Name)
+		}
+	}
+
+	fmt.Printf("\nStart with: lc init <challenge-name>\n")
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// formatDuration formats a duration for display, rounded to the second.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	return d.Round(time.Second).String()
+}
+
+// ShowStats displays per-stage timing and test run history.
+func ShowStats(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	events, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	stats := history.Summarize(events, cfg.Challenge)
+	if len(stats) == 0 {
+		fmt.Printf("No test runs recorded for %s yet.\n\n", challenge.Name)
+		fmt.Printf("Run %s to get started.\n", style.Yellow("'lc test'"))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", challenge.Name)
+	fmt.Printf("  %-18s  %6s  %8s  %12s  %12s  %5s\n", "Stage", "Runs", "Attempts", "Time to pass", "Test time", "Hints")
+
+	var totalRuns int
+	var totalTestTime time.Duration
+	for _, stageKey := range challenge.StageOrder {
+		s, ok := stats[stageKey]
+		if !ok {
+			fmt.Printf("  %-18s  %6s  %8s  %12s  %12s  %5s\n", stageKey, "-", "-", "-", "-", "-")
+			continue
+		}
+
+		timeToPass := "not passed"
+		if s.Passed() {
+			timeToPass = formatDuration(s.TimeToPass())
+		}
+
+		fmt.Printf("  %-18s  %6d  %8d  %12s  %12s  %5d\n",
+			stageKey, s.Runs, s.Attempts, timeToPass, formatDuration(s.TestTime), s.Hints)
+
+		totalRuns += s.Runs
+		totalTestTime += s.TestTime
+	}
+
+	fmt.Printf("\nTotal: %d test runs, %s spent testing\n", totalRuns, formatDuration(totalTestTime))
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/littleclusters/lc/internal/results"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// artifactName is the name of the results file inside a submission archive.
+const artifactName = "lc-results.json"
+
+// recordResult saves the outcome of a test run for later submission,
+// signed with the login token if there is one.
+func recordResult(challengeKey, stageKey string, passed bool, start time.Time) error {
+	digest, err := results.Digest(".")
+	if err != nil {
+		return err
+	}
+
+	result := results.Result{
+		Challenge: challengeKey,
+		Stage:     stageKey,
+		Passed:    passed,
+		Time:      start,
+		Duration:  time.Since(start),
+		Digest:    digest,
+		Version:   LCVersion(),
+	}
+
+	// Unsigned results are still shown by lc progress, but cannot be submitted
+	creds, err := loadCredentials()
+	if err == nil {
+		result, err = results.Sign(result, creds.Token)
+		if err != nil {
+			return err
+		}
+	}
+
+	return results.Save(result)
+}
+
+// createArchive packages the project source and results artifact as a tar.gz.
+func createArchive(root string, artifact *results.Artifact) ([]byte, error) {
+	files, err := results.SourceFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	artifactBytes, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode results artifact: %w", err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    artifactName,
+		Mode:    0644,
+		Size:    int64(len(artifactBytes)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write archive: %w", err)
+	}
+
+	_, err = tw.Write(artifactBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write archive: %w", err)
+	}
+
+	for _, rel := range files {
+		err := addToArchive(tw, filepath.Join(root, rel), rel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write archive: %w", err)
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to write archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addToArchive writes a single file to the archive under the given name.
+func addToArchive(tw *tar.Writer, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %w", name, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %w", name, err)
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("Failed to archive %s: %w", name, err)
+	}
+	header.Name = name
+
+	err = tw.WriteHeader(header)
+	if err != nil {
+		return fmt.Errorf("Failed to archive %s: %w", name, err)
+	}
+
+	_, err = io.Copy(tw, file)
+	if err != nil {
+		return fmt.Errorf("Failed to archive %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Submit packages the project with its signed test results and uploads it.
+func Submit(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := validateEnvironment()
+	if err != nil {
+		return err
+	}
+
+	all, err := results.Load()
+	if err != nil {
+		return err
+	}
+
+	var challengeResults []results.Result
+	var latest *results.Result
+	for i, r := range all {
+		if r.Challenge != cfg.Challenge {
+			continue
+		}
+
+		challengeResults = append(challengeResults, r)
+		if latest == nil || r.Time.After(latest.Time) {
+			latest = &all[i]
+		}
+	}
+
+	if latest == nil || !hasPassed(challengeResults) {
+		return environmentError("No passing test results to submit.\nRun %s and pass a stage before submitting.", style.Yellow("'lc test'"))
+	}
+
+	// Results are only trustworthy for the exact source they were produced from
+	digest, err := results.Digest(".")
+	if err != nil {
+		return err
+	}
+
+	if digest != latest.Digest {
+		return environmentError("Your code changed since the last test run.\nRun %s again before submitting.", style.Yellow("'lc test'"))
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range challengeResults {
+		if r.Verify(creds.Token) != nil {
+			return environmentError("The result for %s was not recorded under your current login.\nRun %s again before submitting.", r.Stage, style.Yellow("'lc test'"))
+		}
+	}
+
+	archive, err := createArchive(".", &results.Artifact{Results: challengeResults})
+	if err != nil {
+		return err
+	}
+
+	output := cmd.String("output")
+	if output != "" {
+		err := os.WriteFile(output, archive, 0644)
+		if err != nil {
+			return fmt.Errorf("Failed to write submission: %w", err)
+		}
+
+		fmt.Printf("Wrote submission to %s (%d bytes)\n", output, len(archive))
+		return nil
+	}
+
+	url, err := uploadSubmission(ctx, cfg, creds.Token, archive)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Submitted %s (%d stage results).\n", cfg.Challenge, len(challengeResults))
+	if url != "" {
+		fmt.Printf("View your submission: %s\n", url)
+	}
+
+	return nil
+}
+
+// hasPassed reports whether any of the results passed.
+func hasPassed(rs []results.Result) bool {
+	for _, r := range rs {
+		if r.Passed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// uploadSubmission posts the archive to the submissions API and returns the submission URL.
+func uploadSubmission(ctx context.Context, cfg *state.State, token string, archive []byte) (string, error) {
+	endpoint := fmt.Sprintf("%s/challenges/%s/submissions", settings.APIURL(), cfg.Challenge)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(archive))
+	if err != nil {
+		return "", fmt.Errorf("Failed to create submission request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("User-Agent", "lc/"+LCVersion())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Failed to upload submission: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("Submission rejected (%s): %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var response struct {
+		URL string `json:"url"`
+	}
+	json.Unmarshal(body, &response)
+
+	return response.URL, nil
+}
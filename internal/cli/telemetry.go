@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/config"
+	"github.com/littleclusters/lc/internal/style"
+	"github.com/littleclusters/lc/internal/telemetry"
+	commands "github.com/urfave/cli/v3"
+)
+
+// telemetryFlushTimeout bounds how long a test run waits to send telemetry.
+const telemetryFlushTimeout = 2 * time.Second
+
+// TelemetryOn enables anonymous usage statistics.
+func TelemetryOn(ctx context.Context, cmd *commands.Command) error {
+	err := setTelemetry(true)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Telemetry enabled. Thank you for helping improve the challenges!")
+	printCollected()
+
+	return nil
+}
+
+// TelemetryOff disables anonymous usage statistics and discards unsent events.
+func TelemetryOff(ctx context.Context, cmd *commands.Command) error {
+	err := setTelemetry(false)
+	if err != nil {
+		return err
+	}
+
+	queue, err := telemetryQueue()
+	if err != nil {
+		return err
+	}
+
+	err = queue.Clear()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Telemetry disabled. Unsent events were deleted.")
+
+	return nil
+}
+
+// TelemetryStatus shows whether telemetry is enabled and what is waiting to be sent.
+func TelemetryStatus(ctx context.Context, cmd *commands.Command) error {
+	queue, err := telemetryQueue()
+	if err != nil {
+		return err
+	}
+
+	events, err := queue.Events()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case os.Getenv("DO_NOT_TRACK") != "" && settings.Telemetry():
+		fmt.Printf("Telemetry: %s (DO_NOT_TRACK is set)\n", style.Yellow("paused"))
+	case settings.Telemetry():
+		fmt.Printf("Telemetry: %s\n", style.Green("on"))
+	default:
+		fmt.Printf("Telemetry: %s\n", style.Bold("off"))
+		fmt.Printf("Run %s to share anonymous usage statistics.\n", style.Yellow("'lc telemetry on'"))
+	}
+
+	switch {
+	case len(events) == 1:
+		fmt.Printf("Queued: 1 event in %s\n", queue.Path())
+	case len(events) > 1:
+		fmt.Printf("Queued: %d events in %s\n", len(events), queue.Path())
+	}
+
+	fmt.Println()
+	printCollected()
+
+	return nil
+}
+
+// printCollected explains what telemetry collects.
+func printCollected() {
+	fmt.Println("Collected: challenge and stage, pass or fail, test duration, names and")
+	fmt.Println("categories of failed tests, lc version, OS, and a random installation ID.")
+	fmt.Println("Never collected: your code, file paths, program output, or failure messages.")
+}
+
+// setTelemetry saves the telemetry preference.
+func setTelemetry(enabled bool) error {
+	err := settings.Set("telemetry", fmt.Sprint(enabled))
+	if err != nil {
+		return err
+	}
+
+	return settings.Save()
+}
+
+// telemetryQueue returns the queue in the global config directory.
+func telemetryQueue() (*telemetry.Queue, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return telemetry.NewQueue(dir), nil
+}
+
+// recordTelemetry queues an event for a test run when telemetry is enabled,
+// and sends the queue once it is due. Failures never affect the run.
+func recordTelemetry(ctx context.Context, challengeKey, stageKey string, report *attest.Report, duration time.Duration) {
+	if !settings.Telemetry() || os.Getenv("DO_NOT_TRACK") != "" {
+		return
+	}
+
+	queue, err := telemetryQueue()
+	if err != nil {
+		return
+	}
+
+	event := telemetry.Event{
+		Version:    LCVersion(),
+		Challenge:  challengeKey,
+		Stage:      stageKey,
+		Passed:     report.Passed,
+		DurationMS: duration.Milliseconds(),
+	}
+
+	for _, result := range report.Results {
+		if result.Passed {
+			continue
+		}
+
+		field := ""
+		if result.HTTP != nil {
+			field = result.HTTP.Field
+		}
+
+		event.Failures = append(event.Failures, telemetry.Failure{
+			Test:     result.Name,
+			Category: telemetry.Categorize(result.Failure, field),
+		})
+	}
+
+	if queue.Add(event) != nil {
+		return
+	}
+
+	events, err := queue.Events()
+	if err != nil || !queue.Due(events) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, telemetryFlushTimeout)
+	defer cancel()
+
+	queue.Flush(ctx, settings.APIURL()+"/telemetry", "lc/"+LCVersion())
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/tui"
+	commands "github.com/urfave/cli/v3"
+)
+
+// TUI opens the interactive full-screen mode for the current challenge.
+func TUI(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := validateEnvironment()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Failed to locate lc executable: %w", err)
+	}
+
+	return tui.New(challenge, cfg, exe, ".lc").Run(ctx)
+}
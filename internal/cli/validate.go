@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/littleclusters/lc/internal/attest"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// placeholderLine is part of the run.sh command written by lc init.
+const placeholderLine = "Replace this line with the command that runs your implementation."
+
+// minAliveTime is how long run.sh must keep running after it starts listening.
+const minAliveTime = time.Second
+
+// checkRunScript checks that run.sh in dir exists, is executable, starts
+// with a shebang, and no longer runs the lc init placeholder.
+func checkRunScript(dir string) error {
+	where := ""
+	if dir != "." {
+		where = " in " + dir
+	}
+
+	path := filepath.Join(dir, "run.sh")
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return environmentError("run.sh not found%s\nCreate an executable run.sh script that starts your implementation.", where)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read run.sh: %w", err)
+	}
+
+	if info.Mode()&0111 == 0 {
+		return environmentError("run.sh%s is not executable\nRun: chmod +x %s", where, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read run.sh: %w", err)
+	}
+
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return environmentError("run.sh%s has no shebang line\nStart it with a line such as: #!/bin/bash -e", where)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") && strings.Contains(line, placeholderLine) {
+			return environmentError("run.sh%s still contains the placeholder command\nReplace the echo line with the command that starts your implementation.", where)
+		}
+	}
+
+	return nil
+}
+
+// checkRunScriptStarts runs run.sh in dir and checks that it accepts
+// connections on its port and keeps running for minAliveTime.
+func checkRunScriptStarts(ctx context.Context, dir string) error {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return fmt.Errorf("Failed to get OS-assigned port: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	workingDir, err := os.MkdirTemp("", "lc-validate-")
+	if err != nil {
+		return fmt.Errorf("Failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workingDir)
+
+	var output syncBuffer
+	cmd := exec.Command("./run.sh", fmt.Sprintf("--port=%d", port), fmt.Sprintf("--working-dir=%s", workingDir))
+	cmd.Dir = dir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("Failed to start run.sh: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	defer func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}()
+
+	startTimeout := attest.DefaultConfig().ProcessStartTimeout
+	deadline := time.After(startTimeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for listening := false; !listening; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-exited:
+			return environmentError("run.sh exited before accepting connections (%v)%s", err, formatOutput(output.String()))
+		case <-deadline:
+			return environmentError("run.sh did not accept connections on --port=%d within %s\nCheck that your program listens on the port passed with --port.%s",
+				port, startTimeout, formatOutput(output.String()))
+		case <-ticker.C:
+			conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), 100*time.Millisecond)
+			if err == nil {
+				conn.Close()
+				listening = true
+			}
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-exited:
+		return environmentError("run.sh exited shortly after starting (%v)%s", err, formatOutput(output.String()))
+	case <-time.After(minAliveTime):
+	}
+
+	return nil
+}
+
+// formatOutput shows the last lines a program printed, if any.
+func formatOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return ""
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+
+	return "\n\nOutput:\n  " + strings.Join(lines, "\n  ")
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// Validate checks that run.sh is set up correctly and starts the implementation.
+func Validate(ctx context.Context, cmd *commands.Command) error {
+	dir := cmd.String("path")
+	if dir == "" {
+		dir = "."
+	}
+
+	err := checkRunScript(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s run.sh is executable and has a shebang\n", style.CheckMark())
+
+	fmt.Println("  Starting run.sh...")
+	err = checkRunScriptStarts(ctx, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s run.sh starts a process that listens on its port\n", style.CheckMark())
+
+	fmt.Printf("\nReady to run %s.\n", style.Yellow("'lc test'"))
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package cli
+
+import "runtime/debug"
+
+// Version is the lc version, set at build time with -ldflags "-X".
+var Version = ""
+
+// LCVersion returns the lc version, falling back to the module build info.
+func LCVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "dev"
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/littleclusters/lc/internal/history"
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"github.com/littleclusters/lc/internal/style"
+	commands "github.com/urfave/cli/v3"
+)
+
+// WhatsNew prints the test suite changes affecting the current challenge
+// since the last test run.
+func WhatsNew(ctx context.Context, cmd *commands.Command) error {
+	cfg, err := state.Load()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := registry.GetChallenge(cfg.Challenge)
+	if err != nil {
+		return err
+	}
+
+	events, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	last := history.LastTest(events, cfg.Challenge)
+	if last == nil {
+		fmt.Printf("No test runs yet. Run %s to get started.\n", style.Yellow("'lc test'"))
+		return nil
+	}
+
+	fmt.Printf("Last test run: %s (lc %s)\n", last.Time.Format("2006-01-02 15:04"), orUnknown(last.Version))
+	if version := LCVersion(); last.Version != "" && last.Version != version {
+		fmt.Printf("lc has been updated to %s.\n", version)
+	}
+
+	changes, later := relevantChanges(challenge, cfg.Stage, last.Revision)
+	if len(changes) == 0 {
+		fmt.Printf("\nNo changes to the %s tests you have reached.\n", cfg.Challenge)
+	} else {
+		fmt.Printf("\n%s\n", style.Bold(fmt.Sprintf("Changes to %s since then:", challenge.Name)))
+		for _, change := range changes {
+			scope := "all stages"
+			if len(change.Stages) > 0 {
+				scope = strings.Join(change.Stages, ", ")
+			}
+
+			fmt.Printf("  r%d %s: %s\n", change.Revision, scope, change.Summary)
+		}
+	}
+
+	switch {
+	case later == 1:
+		fmt.Println("\n1 more change to later stages.")
+	case later > 1:
+		fmt.Printf("\n%d more changes to later stages.\n", later)
+	}
+
+	return nil
+}
+
+// relevantChanges returns the changes after revision that affect stageKey or
+// an earlier stage, and the number of other changes.
+func relevantChanges(challenge *registry.Challenge, stageKey string, revision int) ([]registry.Change, int) {
+	current := challenge.StageIndex(stageKey)
+
+	var relevant []registry.Change
+	later := 0
+	for _, change := range challenge.ChangesSince(revision) {
+		affected := len(change.Stages) == 0
+		for _, key := range change.Stages {
+			if challenge.StageIndex(key) <= current {
+				affected = true
+			}
+		}
+
+		if affected {
+			relevant = append(relevant, change)
+		} else {
+			later++
+		}
+	}
+
+	return relevant, later
+}
+
+// noteChanges points to lc whatsnew when the tests of reached stages changed
+// since the last recorded run.
+func noteChanges(challenge *registry.Challenge, stageKey string) {
+	events, err := history.Load()
+	if err != nil {
+		return
+	}
+
+	last := history.LastTest(events, challenge.Key)
+	if last == nil {
+		return
+	}
+
+	changes, _ := relevantChanges(challenge, stageKey, last.Revision)
+	if len(changes) > 0 {
+		fmt.Printf("The %s tests changed since your last run. Run %s for details.\n\n", challenge.Key, style.Yellow("'lc whatsnew'"))
+	}
+}
+
+// orUnknown returns s, or "unknown" if s is empty.
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return s
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/littleclusters/lc/internal/state"
+	commands "github.com/urfave/cli/v3"
+)
+
+// SelectProject switches to the workspace project a command should run in.
+// The project is chosen with --project, or inferred when the working
+// directory is inside a project listed in lc.workspace.
+func SelectProject(ctx context.Context, cmd *commands.Command) (context.Context, error) {
+	name := cmd.String("project")
+	if name == "" {
+		// A project root needs no inference
+		if _, err := os.Stat("lc.state"); err == nil {
+			return ctx, nil
+		}
+	}
+
+	ws, err := state.FindWorkspace(".")
+	if err != nil {
+		return ctx, err
+	}
+
+	if ws == nil {
+		if name != "" {
+			return ctx, usageError("--project needs an %s file\nList your challenge directories in %s at the root of your repository, one per line.",
+				state.WorkspaceFile, state.WorkspaceFile)
+		}
+
+		return ctx, nil
+	}
+
+	var dir string
+	if name != "" {
+		dir, err = ws.Project(name)
+		if err != nil {
+			return ctx, asUsage(err)
+		}
+	} else {
+		dir = ws.Containing(".")
+		if dir == "" {
+			return ctx, nil
+		}
+	}
+
+	err = os.Chdir(dir)
+	if err != nil {
+		return ctx, fmt.Errorf("Failed to enter project %s: %w", dir, err)
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,335 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fileName = "config.toml"
+
+// Key describes a supported configuration key.
+type Key struct {
+	Name    string
+	Usage   string
+	Default string
+
+	validate func(string) error
+}
+
+// Keys lists all supported configuration keys.
+var Keys = []Key{
+	{
+		Name:     "retry_timeout",
+		Usage:    "Timeout for Eventually and Consistently assertions (e.g. 10s)",
+		validate: validateDuration,
+	},
+	{
+		Name:     "color",
+		Usage:    "Colorize output (true or false)",
+		Default:  "true",
+		validate: validateBool,
+	},
+	{
+		Name:     "verbosity",
+		Usage:    "Output verbosity (quiet, normal, or verbose)",
+		Default:  "normal",
+		validate: validateOneOf("quiet", "normal", "verbose"),
+	},
+	{
+		Name:     "docs_base_url",
+		Usage:    "Base URL for stage guides",
+		Default:  "https://littleclusters.com",
+		validate: validateURL,
+	},
+	{
+		Name:     "api_url",
+		Usage:    "Base URL for the littleclusters API",
+		Default:  "https://littleclusters.com/api",
+		validate: validateURL,
+	},
+	{
+		Name:     "telemetry",
+		Usage:    "Send anonymous usage statistics (set with lc telemetry on|off)",
+		Default:  "false",
+		validate: validateBool,
+	},
+	{
+		Name:     "language",
+		Usage:    "Default language for run.sh created by lc init",
+		validate: validateOneOf("go", "python", "rust", "javascript", "typescript", "java"),
+	},
+}
+
+// Config holds persistent user preferences.
+type Config struct {
+	values map[string]string
+	path   string
+}
+
+// Dir returns the directory holding the global lc configuration.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("Failed to locate home directory: %w", err)
+		}
+
+		base = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(base, "lc"), nil
+}
+
+// Load reads the global config file, returning defaults if it does not
+// exist. Invalid lines are handled as by LoadFrom.
+func Load() (*Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFrom(filepath.Join(dir, fileName))
+}
+
+// Defaults returns a config with every key at its default, without reading
+// or writing the global config file. Save writes it there, e.g. to replace
+// one that cannot be loaded.
+func Defaults() (*Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{values: make(map[string]string), path: filepath.Join(dir, fileName)}, nil
+}
+
+// LoadFrom reads the config file at the specified path. If some lines are
+// invalid, it returns the config of the others along with the first error.
+func LoadFrom(path string) (*Config, error) {
+	cfg := &Config{values: make(map[string]string), path: path}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	// Invalid lines are skipped, so the rest still apply
+	var invalid error
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, err := parseTOMLLine(line)
+		if err == nil {
+			err = cfg.Set(key, value)
+		}
+		if err != nil && invalid == nil {
+			invalid = fmt.Errorf("Invalid config at %s:%d: %w", path, n, err)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config file: %w", err)
+	}
+
+	return cfg, invalid
+}
+
+// Path returns the path of the config file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Get returns the value for a key, falling back to its default.
+func (c *Config) Get(name string) (string, error) {
+	key, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	if value, ok := c.values[name]; ok {
+		return value, nil
+	}
+
+	return key.Default, nil
+}
+
+// IsSet reports whether a key has been explicitly set.
+func (c *Config) IsSet(name string) bool {
+	_, ok := c.values[name]
+	return ok
+}
+
+// Set validates and stores the value for a key.
+func (c *Config) Set(name, value string) error {
+	key, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if key.validate != nil && value != "" {
+		err := key.validate(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for %s: %w", name, err)
+		}
+	}
+
+	c.values[name] = value
+	return nil
+}
+
+// Unset removes an explicitly set value, restoring the default.
+func (c *Config) Unset(name string) error {
+	_, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	delete(c.values, name)
+	return nil
+}
+
+// Save writes the config to its file, creating the directory if needed.
+func (c *Config) Save() error {
+	err := os.MkdirAll(filepath.Dir(c.path), 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create config directory: %w", err)
+	}
+
+	names := make([]string, 0, len(c.values))
+	for name := range c.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s = %s\n", name, quoteTOML(c.values[name]))
+	}
+
+	err = os.WriteFile(c.path, []byte(b.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// RetryTimeout returns the configured retry timeout, or 0 if unset.
+func (c *Config) RetryTimeout() time.Duration {
+	value, _ := c.Get("retry_timeout")
+	d, _ := time.ParseDuration(value)
+	return d
+}
+
+// Color reports whether colored output is enabled.
+func (c *Config) Color() bool {
+	value, _ := c.Get("color")
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
+
+// Verbose reports whether verbose output is enabled.
+func (c *Config) Verbose() bool {
+	value, _ := c.Get("verbosity")
+	return value == "verbose"
+}
+
+// Quiet reports whether quiet output is enabled.
+func (c *Config) Quiet() bool {
+	value, _ := c.Get("verbosity")
+	return value == "quiet"
+}
+
+// DocsBaseURL returns the base URL for stage guides.
+func (c *Config) DocsBaseURL() string {
+	value, _ := c.Get("docs_base_url")
+	return strings.TrimSuffix(value, "/")
+}
+
+// APIURL returns the base URL for the littleclusters API.
+func (c *Config) APIURL() string {
+	value, _ := c.Get("api_url")
+	return strings.TrimSuffix(value, "/")
+}
+
+// Telemetry reports whether anonymous usage statistics are enabled.
+func (c *Config) Telemetry() bool {
+	value, _ := c.Get("telemetry")
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// Language returns the default language for new challenges.
+func (c *Config) Language() string {
+	value, _ := c.Get("language")
+	return value
+}
+
+// lookup returns the key with the given name.
+func lookup(name string) (Key, error) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("Unknown config key %q.\nRun 'lc config list' to see available keys.", name)
+}
+
+func validateDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("expected a duration like 10s or 1m, got %q", value)
+	}
+
+	if d <= 0 {
+		return fmt.Errorf("duration must be positive, got %q", value)
+	}
+
+	return nil
+}
+
+func validateBool(value string) error {
+	_, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("expected true or false, got %q", value)
+	}
+
+	return nil
+}
+
+func validateURL(value string) error {
+	if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+		return fmt.Errorf("expected an http(s) URL, got %q", value)
+	}
+
+	return nil
+}
+
+func validateOneOf(values ...string) func(string) error {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("expected one of %s, got %q", strings.Join(values, ", "), value)
+	}
+}
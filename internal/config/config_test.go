@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTOMLLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		key       string
+		value     string
+		shouldErr bool
+	}{
+		{line: `color = "true"`, key: "color", value: "true"},
+		{line: `color = true`, key: "color", value: "true"},
+		{line: `verbosity="quiet" # trailing comment`, key: "verbosity", value: "quiet"},
+		{line: `language = 'go'`, key: "language", value: "go"},
+		{line: `"api_url" = "http://localhost:8080"`, key: "api_url", value: "http://localhost:8080"},
+		{line: `docs_base_url = "a\"b\\c\u00e9"`, key: "docs_base_url", value: "a\"b\\cé"},
+		{line: `color = "tru`, shouldErr: true},
+		{line: `color`, shouldErr: true},
+		{line: `color = yes`, shouldErr: true},
+		{line: `color = "true" extra`, shouldErr: true},
+		{line: `color = "\x41"`, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			key, value, err := parseTOMLLine(tt.line)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q = %q", key, value)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != tt.key || value != tt.value {
+				t.Errorf("got %q = %q, expected %q = %q", key, value, tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	values := map[string]string{
+		"docs_base_url": "https://example.com/\"quoted\"\\path\t\x01é",
+		"verbosity":     "verbose",
+		"retry_timeout": "10s",
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range values {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range values {
+		if actual, _ := loaded.Get(key); actual != value {
+			t.Errorf("%s: got %q, expected %q", key, actual, value)
+		}
+	}
+}
+
+func TestLoadSkipsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "color = \"tru\nverbosity = \"quiet\"\nunknown = \"x\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err == nil {
+		t.Error("expected an error for the invalid lines")
+	}
+	if cfg == nil {
+		t.Fatal("expected the valid lines to be loaded")
+	}
+
+	if !cfg.Quiet() {
+		t.Error("verbosity should be loaded from the valid line")
+	}
+	if cfg.IsSet("color") {
+		t.Error("color should be left at its default")
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The config file is a flat TOML table: one key = value pair per line,
+// values being strings, booleans or numbers. Tables and arrays are not
+// supported.
+
+// quoteTOML returns s as a TOML basic string.
+func quoteTOML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// parseTOMLLine parses a line of the form key = value, a trailing comment
+// allowed, and returns the key and the value as a string.
+func parseTOMLLine(line string) (string, string, error) {
+	key, rest, err := parseTOMLKey(line)
+	if err != nil {
+		return "", "", err
+	}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", fmt.Errorf("expected '=' after key %q", key)
+	}
+	rest = strings.TrimSpace(rest[1:])
+
+	value, rest, err := parseTOMLValue(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", fmt.Errorf("unexpected %q after value", rest)
+	}
+
+	return key, value, nil
+}
+
+// parseTOMLKey parses a bare or quoted key and returns what follows it.
+func parseTOMLKey(s string) (string, string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, `'`) {
+		return parseTOMLString(s)
+	}
+
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+	})
+	if end == -1 {
+		end = len(s)
+	}
+	if end == 0 {
+		return "", "", fmt.Errorf("expected a key")
+	}
+
+	return s[:end], s[end:], nil
+}
+
+// parseTOMLValue parses a string, boolean or number and returns it as a
+// string with what follows it.
+func parseTOMLValue(s string) (string, string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, `'`) {
+		return parseTOMLString(s)
+	}
+
+	end := strings.IndexAny(s, " \t#")
+	if end == -1 {
+		end = len(s)
+	}
+	value := s[:end]
+
+	if value == "true" || value == "false" {
+		return value, s[end:], nil
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err == nil {
+		return value, s[end:], nil
+	}
+
+	return "", "", fmt.Errorf("expected a string, boolean or number, got %q", value)
+}
+
+// parseTOMLString parses a basic or literal single-line string and returns
+// its value with what follows it.
+func parseTOMLString(s string) (string, string, error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end == -1 {
+			return "", "", fmt.Errorf("unterminated string %s", s)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), s[i+1:], nil
+		case c == '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated string %s", s)
+			}
+
+			n, err := unescapeTOML(&b, s[i+1:])
+			if err != nil {
+				return "", "", err
+			}
+			i += 1 + n
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteRune(r)
+			i += size
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated string %s", s)
+}
+
+// unescapeTOML writes the character escaped at the start of s, after the
+// backslash, and returns how many bytes the escape took.
+func unescapeTOML(b *strings.Builder, s string) (int, error) {
+	simple := map[byte]byte{'"': '"', '\\': '\\', 'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r'}
+	if c, ok := simple[s[0]]; ok {
+		b.WriteByte(c)
+		return 1, nil
+	}
+
+	digits := map[byte]int{'u': 4, 'U': 8}[s[0]]
+	if digits == 0 || len(s) < 1+digits {
+		return 0, fmt.Errorf("invalid escape \\%s", s[:min(len(s), 1+digits)])
+	}
+
+	code, err := strconv.ParseUint(s[1:1+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Errorf("invalid escape \\%s", s[:1+digits])
+	}
+
+	b.WriteRune(rune(code))
+	return 1 + digits, nil
+}
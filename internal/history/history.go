@@ -0,0 +1,187 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const historyPath = ".lc/history"
+
+// Kinds of recorded events.
+const (
+	KindTest = "test"
+	KindHint = "hint"
+)
+
+// Event represents a single recorded action in a challenge directory.
+type Event struct {
+	Time      time.Time     `json:"time"`
+	Kind      string        `json:"kind"`
+	Challenge string        `json:"challenge"`
+	Stage     string        `json:"stage"`
+	Passed    bool          `json:"passed,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	// Hint is the 1-based number of a revealed hint.
+	Hint int `json:"hint,omitempty"`
+	// Version and Revision identify the lc version and test suite revision of a test run.
+	Version  string `json:"version,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// Record appends an event to the history file.
+func Record(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	err := os.MkdirAll(filepath.Dir(historyPath), 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create history directory: %w", err)
+	}
+
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Failed to encode history event: %w", err)
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("Failed to write history file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads all recorded events, oldest first.
+// A missing history file yields no events.
+func Load() ([]Event, error) {
+	return LoadFrom(historyPath)
+}
+
+// LoadFrom reads the events recorded in the history file at path.
+func LoadFrom(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			// Skip lines corrupted by an interrupted write
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read history file: %w", err)
+	}
+
+	return events, nil
+}
+
+// LastTest returns the most recent test run of a challenge, or nil if
+// there is none.
+func LastTest(events []Event, challenge string) *Event {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Kind == KindTest && events[i].Challenge == challenge {
+			return &events[i]
+		}
+	}
+
+	return nil
+}
+
+// StageStats summarizes the test history of a single stage.
+type StageStats struct {
+	// Runs is the total number of test runs.
+	Runs int
+	// Attempts is the number of test runs up to and including the first pass.
+	Attempts int
+	// TestTime is the total time spent running tests.
+	TestTime time.Duration
+
+	FirstRun  time.Time
+	FirstPass time.Time
+
+	// LastPassed reports whether the most recent test run passed.
+	LastPassed bool
+	LastRun    time.Time
+
+	// Hints is the number of hints revealed.
+	Hints int
+}
+
+// Passed reports whether the stage has passed at least once.
+func (s *StageStats) Passed() bool {
+	return !s.FirstPass.IsZero()
+}
+
+// TimeToPass returns the time between the first test run and the first pass.
+func (s *StageStats) TimeToPass() time.Duration {
+	if !s.Passed() {
+		return 0
+	}
+
+	return s.FirstPass.Sub(s.FirstRun)
+}
+
+// Summarize aggregates test and hint events for a challenge by stage key.
+func Summarize(events []Event, challenge string) map[string]*StageStats {
+	stats := make(map[string]*StageStats)
+
+	for _, event := range events {
+		if event.Challenge != challenge || (event.Kind != KindTest && event.Kind != KindHint) {
+			continue
+		}
+
+		s, ok := stats[event.Stage]
+		if !ok {
+			s = &StageStats{}
+			stats[event.Stage] = s
+		}
+
+		if event.Kind == KindHint {
+			s.Hints = max(s.Hints, event.Hint)
+			continue
+		}
+
+		if s.Runs == 0 {
+			s.FirstRun = event.Time
+		}
+
+		s.Runs++
+		s.TestTime += event.Duration
+		s.LastRun = event.Time
+		s.LastPassed = event.Passed
+
+		if !s.Passed() {
+			s.Attempts++
+			if event.Passed {
+				s.FirstPass = event.Time
+			}
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,325 @@
+// Package markdown renders the small Markdown subset used by stage guides
+// for display in a terminal.
+//
+// Supported: ATX headings, paragraphs, bullet and numbered lists, fenced
+// code blocks, pipe tables, and inline code, bold, and links. Anything else
+// is shown as plain text.
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/littleclusters/lc/internal/style"
+)
+
+// listItem matches a bullet or numbered list item and captures its marker.
+var listItem = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+`)
+
+// Render formats Markdown source for a terminal, wrapping text at width.
+func Render(src string, width int) string {
+	var b strings.Builder
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				b.WriteString("    " + style.Yellow(lines[i]) + "\n")
+				i++
+			}
+			i++
+			b.WriteString("\n")
+
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			title := renderInline(strings.TrimSpace(trimmed[level:]))
+			b.WriteString(style.Bold(title) + "\n")
+			if level == 1 {
+				b.WriteString(strings.Repeat("═", min(visibleWidth(title), width)) + "\n")
+			}
+			b.WriteString("\n")
+			i++
+
+		case strings.HasPrefix(trimmed, "|"):
+			var rows []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|") {
+				rows = append(rows, strings.TrimSpace(lines[i]))
+				i++
+			}
+			b.WriteString(renderTable(rows) + "\n")
+
+		case listItem.MatchString(line):
+			for i < len(lines) && listItem.MatchString(lines[i]) {
+				m := listItem.FindStringSubmatch(lines[i])
+				indent := len(m[1])
+				marker := "•"
+				if m[2] != "-" && m[2] != "*" && m[2] != "+" {
+					marker = m[2]
+				}
+
+				text := lines[i][len(m[0]):]
+				i++
+				// Lazy continuation lines belong to the item
+				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !listItem.MatchString(lines[i]) && !isBlockStart(lines[i]) {
+					text += " " + strings.TrimSpace(lines[i])
+					i++
+				}
+
+				prefix := strings.Repeat(" ", indent+2) + marker + " "
+				hang := strings.Repeat(" ", visibleWidth(prefix))
+				for j, wrapped := range wrap(text, width-len(hang)) {
+					if j == 0 {
+						b.WriteString(prefix + wrapped + "\n")
+					} else {
+						b.WriteString(hang + wrapped + "\n")
+					}
+				}
+			}
+			b.WriteString("\n")
+
+		default:
+			paragraph := trimmed
+			i++
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !isBlockStart(lines[i]) {
+				paragraph += " " + strings.TrimSpace(lines[i])
+				i++
+			}
+
+			for _, wrapped := range wrap(paragraph, width) {
+				b.WriteString(wrapped + "\n")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// isBlockStart reports whether line starts a block other than a paragraph.
+func isBlockStart(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "```") ||
+		strings.HasPrefix(trimmed, "|") || listItem.MatchString(line)
+}
+
+// span is a run of inline text with a single style.
+type span struct {
+	text string
+	code bool
+	bold bool
+}
+
+// parseInline splits text into styled spans, turning links into their text
+// followed by the URL.
+func parseInline(text string) []span {
+	var spans []span
+	var current strings.Builder
+	bold := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			spans = append(spans, span{text: current.String(), bold: bold})
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '`':
+			end := strings.IndexByte(text[i+1:], '`')
+			if end < 0 {
+				current.WriteByte(text[i])
+				i++
+				continue
+			}
+			flush()
+			spans = append(spans, span{text: text[i+1 : i+1+end], code: true})
+			i += end + 2
+
+		case strings.HasPrefix(text[i:], "**"):
+			flush()
+			bold = !bold
+			i += 2
+
+		case text[i] == '[':
+			close := strings.Index(text[i:], "](")
+			end := strings.IndexByte(text[i:], ')')
+			if close < 0 || end < close {
+				current.WriteByte(text[i])
+				i++
+				continue
+			}
+			label := text[i+1 : i+close]
+			url := text[i+close+2 : i+end]
+			if label == url {
+				current.WriteString(url)
+			} else {
+				current.WriteString(fmt.Sprintf("%s (%s)", label, url))
+			}
+			i += end + 1
+
+		default:
+			current.WriteByte(text[i])
+			i++
+		}
+	}
+	flush()
+
+	return spans
+}
+
+// renderSpan applies the span's style.
+func renderSpan(s span) string {
+	switch {
+	case s.code:
+		return style.Yellow(s.text)
+	case s.bold:
+		return style.Bold(s.text)
+	}
+
+	return s.text
+}
+
+// renderInline renders inline formatting without wrapping.
+func renderInline(text string) string {
+	var b strings.Builder
+	for _, s := range parseInline(text) {
+		b.WriteString(renderSpan(s))
+	}
+
+	return b.String()
+}
+
+// wrap renders inline formatting and breaks text into lines of at most
+// width visible characters. Words longer than width get a line of their own.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	space := false
+
+	for _, s := range parseInline(text) {
+		for j, word := range strings.Split(s.text, " ") {
+			// Spaces may separate words within a span or across spans
+			if j > 0 {
+				space = true
+			}
+			if word == "" {
+				continue
+			}
+
+			wordWidth := utf8.RuneCountInString(word)
+			if lineWidth > 0 && lineWidth+1+wordWidth > width {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineWidth = 0
+			}
+
+			if space && lineWidth > 0 {
+				line.WriteString(" ")
+				lineWidth++
+			}
+			space = false
+
+			line.WriteString(renderSpan(span{text: word, code: s.code, bold: s.bold}))
+			lineWidth += wordWidth
+		}
+	}
+
+	if lineWidth > 0 {
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+// renderTable aligns the cells of a pipe table, skipping its separator row.
+func renderTable(rows []string) string {
+	var cells [][]string
+	for _, row := range rows {
+		row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+		parts := strings.Split(row, "|")
+
+		separator := true
+		for j, part := range parts {
+			parts[j] = strings.TrimSpace(part)
+			if strings.Trim(parts[j], "-: ") != "" {
+				separator = false
+			}
+		}
+
+		if !separator {
+			cells = append(cells, parts)
+		}
+	}
+
+	var widths []int
+	for _, row := range cells {
+		for j, cell := range row {
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], visibleWidth(renderInline(cell)))
+		}
+	}
+
+	var b strings.Builder
+	for r, row := range cells {
+		b.WriteString("  ")
+		for j, cell := range row {
+			rendered := renderInline(cell)
+			if r == 0 {
+				rendered = style.Bold(cell)
+			}
+
+			b.WriteString(rendered)
+			if j < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[j]-visibleWidth(rendered)+2))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// visibleWidth counts the runes of s that are not part of escape sequences.
+func visibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			// SGR sequences end with 'm'; OSC-8 hyperlinks end with ST
+			if strings.HasPrefix(s[i:], "\033]") {
+				end := strings.Index(s[i:], "\033\\")
+				if end < 0 {
+					break
+				}
+				i += end + 2
+				continue
+			}
+
+			end := strings.IndexByte(s[i:], 'm')
+			if end < 0 {
+				break
+			}
+			i += end + 1
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		width++
+		i += size
+	}
+
+	return width
+}
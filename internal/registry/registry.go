@@ -1,15 +1,21 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"slices"
+	"sort"
 
 	"github.com/littleclusters/lc/internal/attest"
 )
 
-const (
-	DocsBaseURL = "https://littleclusters.com"
-)
+// ErrNotFound is returned when a challenge or stage does not exist.
+var ErrNotFound = errors.New("not found")
+
+// DocsBaseURL is the base URL for challenge guides.
+var DocsBaseURL = "https://littleclusters.com"
 
 func init() {
 	log.SetFlags(0)
@@ -17,19 +23,41 @@ func init() {
 
 var challenges = make(map[string]*Challenge)
 
+// Difficulty levels a challenge can declare.
+var Difficulties = []string{"beginner", "intermediate", "advanced"}
+
 // Challenge represents a coding challenge.
 type Challenge struct {
 	Key        string
 	Name       string
 	Summary    string
+	Difficulty string
+	Tags       []string
 	Stages     map[string]*Stage
 	StageOrder []string
+	// Changes lists revisions of the test suites, oldest first.
+	Changes []Change
+	// Golden holds the golden files compared by Golden assertions.
+	Golden fs.FS
+}
+
+// Change describes a revision of a challenge's test suites.
+type Change struct {
+	Revision int
+	Summary  string
+	// Stages lists the affected stages; empty means every stage.
+	Stages []string
 }
 
 // Stage represents a single stage within a challenge.
 type Stage struct {
-	Name string
-	Fn   StageFunc
+	Name  string
+	Fn    StageFunc
+	Bench StageFunc
+	// Hints are revealed one at a time by lc hint, from gentle to specific.
+	Hints []string
+	// Guide is the Markdown stage guide shown offline by lc guide.
+	Guide string
 }
 
 // StageFunc is a function that returns a test suite for a stage.
@@ -45,11 +73,81 @@ func (c *Challenge) AddStage(key, name string, fn StageFunc) {
 	c.StageOrder = append(c.StageOrder, key)
 }
 
+// AddBenchmark attaches a benchmark suite to an existing stage.
+func (c *Challenge) AddBenchmark(key string, fn StageFunc) {
+	stage, exists := c.Stages[key]
+	if !exists {
+		log.Fatalf("Cannot add benchmark to unknown stage %s.", key)
+	}
+
+	stage.Bench = fn
+}
+
+// AddHints appends progressive hints to an existing stage.
+func (c *Challenge) AddHints(key string, hints ...string) {
+	stage, exists := c.Stages[key]
+	if !exists {
+		log.Fatalf("Cannot add hints to unknown stage %s.", key)
+	}
+
+	stage.Hints = append(stage.Hints, hints...)
+}
+
+// AddGuides attaches stage guides from fsys, read from "<stage>.md" files.
+// Stages without a file keep no offline guide.
+func (c *Challenge) AddGuides(fsys fs.FS) {
+	for key, stage := range c.Stages {
+		bytes, err := fs.ReadFile(fsys, key+".md")
+		if err != nil {
+			continue
+		}
+
+		stage.Guide = string(bytes)
+	}
+}
+
+// AddGolden attaches the golden files the stages compare responses to,
+// usually the challenge's embedded testdata.
+func (c *Challenge) AddGolden(fsys fs.FS) {
+	c.Golden = fsys
+}
+
+// AddChange records a revision of the test suites for lc whatsnew.
+// Revisions must be added in increasing order.
+func (c *Challenge) AddChange(revision int, summary string, stages ...string) {
+	if revision <= c.Revision() {
+		log.Fatalf("Change %d of %s is out of order.", revision, c.Name)
+	}
+
+	c.Changes = append(c.Changes, Change{Revision: revision, Summary: summary, Stages: stages})
+}
+
+// Revision returns the latest revision of the test suites, or zero if the
+// challenge has no recorded changes.
+func (c *Challenge) Revision() int {
+	if len(c.Changes) == 0 {
+		return 0
+	}
+
+	return c.Changes[len(c.Changes)-1].Revision
+}
+
+// ChangesSince returns the changes made after revision, oldest first.
+func (c *Challenge) ChangesSince(revision int) []Change {
+	for i, change := range c.Changes {
+		if change.Revision > revision {
+			return c.Changes[i:]
+		}
+	}
+
+	return nil
+}
+
 // GetStage retrieves a stage by key.
 func (c *Challenge) GetStage(key string) (*Stage, error) {
 	stage, exists := c.Stages[key]
 	if !exists {
-		return nil, fmt.Errorf("Stage %q not found for challenge %s.", key, c.Key)
+		return nil, fmt.Errorf("Stage %q %w for challenge %s.", key, ErrNotFound, c.Key)
 	}
 
 	return stage, nil
@@ -66,6 +164,11 @@ func (c *Challenge) StageIndex(key string) int {
 	return -1
 }
 
+// HasTag reports whether the challenge is tagged with tag.
+func (c *Challenge) HasTag(tag string) bool {
+	return slices.Contains(c.Tags, tag)
+}
+
 // Len returns the number of stages in the challenge.
 func (c *Challenge) Len() int {
 	return len(c.StageOrder)
@@ -110,6 +213,10 @@ func RegisterChallenge(key string, challenge *Challenge) {
 		log.Fatalf("Cannot register empty challenge %s.", key)
 	}
 
+	if challenge.Difficulty != "" && !slices.Contains(Difficulties, challenge.Difficulty) {
+		log.Fatalf("Challenge %s has unknown difficulty %q.", key, challenge.Difficulty)
+	}
+
 	challenge.Key = key
 	challenges[key] = challenge
 }
@@ -118,7 +225,7 @@ func RegisterChallenge(key string, challenge *Challenge) {
 func GetChallenge(key string) (*Challenge, error) {
 	challenge, exists := challenges[key]
 	if !exists {
-		return nil, fmt.Errorf("Challenge %s not found", key)
+		return nil, fmt.Errorf("Challenge %s %w", key, ErrNotFound)
 	}
 
 	return challenge, nil
@@ -128,3 +235,17 @@ func GetChallenge(key string) (*Challenge, error) {
 func GetAllChallenges() map[string]*Challenge {
 	return challenges
 }
+
+// SortedChallenges returns all registered challenges ordered by key.
+func SortedChallenges() []*Challenge {
+	sorted := make([]*Challenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		sorted = append(sorted, challenge)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
@@ -0,0 +1,214 @@
+package results
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const resultsPath = ".lc/results.json"
+
+// Result records the outcome of the latest test run for a stage.
+type Result struct {
+	Challenge string        `json:"challenge"`
+	Stage     string        `json:"stage"`
+	Passed    bool          `json:"passed"`
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	// Digest is the SHA-256 digest of the project source at test time.
+	Digest  string `json:"digest"`
+	Version string `json:"version"`
+	// Signature is set by Sign, when the test ran.
+	Signature string `json:"signature,omitempty"`
+}
+
+// Load reads the latest results for all stages.
+// A missing results file yields no results.
+func Load() ([]Result, error) {
+	bytes, err := os.ReadFile(resultsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read results file: %w", err)
+	}
+
+	var results []Result
+	err = json.Unmarshal(bytes, &results)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse results file: %w", err)
+	}
+
+	return results, nil
+}
+
+// Save records a result, replacing any previous result for the same stage.
+func Save(result Result) error {
+	results, err := Load()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, r := range results {
+		if r.Challenge == result.Challenge && r.Stage == result.Stage {
+			results[i] = result
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		results = append(results, result)
+	}
+
+	bytes, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode results: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(resultsPath), 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create results directory: %w", err)
+	}
+
+	err = os.WriteFile(resultsPath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write results file: %w", err)
+	}
+
+	return nil
+}
+
+// IsExcluded reports whether a project path is excluded from digests and archives.
+func IsExcluded(path string) bool {
+	name := filepath.Base(path)
+	return name == ".lc" || name == ".git"
+}
+
+// SourceFiles returns the project files under root in a stable order, relative to root.
+func SourceFiles(root string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != root && IsExcluded(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list project files: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// Digest returns the SHA-256 digest of the project source under root.
+// The lc.state file is left out since advancing stages changes it.
+func Digest(root string) (string, error) {
+	files, err := SourceFiles(root)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, rel := range files {
+		if rel == "lc.state" {
+			continue
+		}
+
+		file, err := os.Open(filepath.Join(root, rel))
+		if err != nil {
+			return "", fmt.Errorf("Failed to read %s: %w", rel, err)
+		}
+
+		fmt.Fprintf(h, "%s\x00", rel)
+		_, err = io.Copy(h, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("Failed to read %s: %w", rel, err)
+		}
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Artifact is the bundle of test results attached to a submission.
+type Artifact struct {
+	Results []Result `json:"results"`
+}
+
+// Sign signs a result with the account token it is recorded under, so the
+// server, which knows the token, can tell it was not edited afterwards. It
+// does not stop someone holding the token from signing a result of their
+// own.
+func Sign(result Result, token string) (Result, error) {
+	signature, err := result.signature(token)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result.Signature = signature
+	return result, nil
+}
+
+// Verify checks that the result was signed with token and not changed since.
+func (r Result) Verify(token string) error {
+	if r.Signature == "" {
+		return fmt.Errorf("Result for %s is not signed", r.Stage)
+	}
+
+	signature, err := r.signature(token)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(r.Signature)) {
+		return fmt.Errorf("Result for %s does not match its signature", r.Stage)
+	}
+
+	return nil
+}
+
+// signature returns the HMAC-SHA256 of the result, its signature left out,
+// keyed with token.
+func (r Result) signature(token string) (string, error) {
+	r.Signature = ""
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode result: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(payload)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -1,41 +1,77 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
 const statePath = "lc.state"
 
+// ErrInvalidState is returned when lc.state cannot be parsed.
+var ErrInvalidState = errors.New("Invalid state format")
+
+// ErrNotInChallenge is returned when the working directory has no lc.state file.
+var ErrNotInChallenge = errors.New("Not in a challenge directory\nRun this command from a directory created with 'lc init <challenge>'")
+
+// skippedPrefix starts the optional lc.state line listing skipped stages.
+const skippedPrefix = "skipped:"
+
 // State represents the challenge progress.
 type State struct {
 	Challenge string
 	Stage     string
+	// Skipped lists stages advanced past with lc skip that haven't passed since.
+	Skipped []string
+}
+
+// IsSkipped reports whether stage was skipped and is still incomplete.
+func (st *State) IsSkipped(stage string) bool {
+	return slices.Contains(st.Skipped, stage)
 }
 
 // Load reads and parses the lc.state file.
 func Load() (*State, error) {
-	_, err := os.Stat(statePath)
+	return LoadFrom(statePath)
+}
+
+// LoadFrom reads and parses the state file at path.
+func LoadFrom(path string) (*State, error) {
+	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("Not in a challenge directory\nRun this command from a directory created with 'lc init <challenge>'")
+		return nil, ErrNotInChallenge
 	}
 
-	bytes, err := os.ReadFile(statePath)
+	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read state file: %w", err)
 	}
 
 	content := strings.TrimSpace(string(bytes))
-	parts := strings.SplitN(content, ":", 2)
+	lines := strings.Split(content, "\n")
+	parts := strings.SplitN(lines[0], ":", 2)
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("Invalid state format. Expected '<challenge>:<stage>', got: %s", content)
+		return nil, fmt.Errorf("%w. Expected '<challenge>:<stage>', got: %s", ErrInvalidState, content)
 	}
 
-	return &State{
+	st := &State{
 		Challenge: strings.TrimSpace(parts[0]),
 		Stage:     strings.TrimSpace(parts[1]),
-	}, nil
+	}
+
+	for _, line := range lines[1:] {
+		if skipped, ok := strings.CutPrefix(strings.TrimSpace(line), skippedPrefix); ok {
+			for _, stage := range strings.Split(skipped, ",") {
+				if stage = strings.TrimSpace(stage); stage != "" {
+					st.Skipped = append(st.Skipped, stage)
+				}
+			}
+		}
+	}
+
+	return st, nil
 }
 
 // Save writes the state to the default lc.state file.
@@ -46,6 +82,9 @@ func Save(st *State) error {
 // SaveTo writes the state to the specified path.
 func SaveTo(st *State, path string) error {
 	content := fmt.Sprintf("%s:%s\n", st.Challenge, st.Stage)
+	if len(st.Skipped) > 0 {
+		content += fmt.Sprintf("%s %s\n", skippedPrefix, strings.Join(st.Skipped, ","))
+	}
 	err := os.WriteFile(path, []byte(content), 0644)
 	if err != nil {
 		return fmt.Errorf("Failed to write state file: %w", err)
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceFile lists the challenge directories of a multi-challenge
+// repository, one path per line relative to the file.
+const WorkspaceFile = "lc.workspace"
+
+// Workspace is a directory holding several challenge projects.
+type Workspace struct {
+	// Root is the directory containing the workspace file.
+	Root string
+	// Projects are the absolute paths of the listed challenge directories.
+	Projects []string
+}
+
+// LoadWorkspace reads the workspace file in root.
+// It returns os.ErrNotExist when root has no workspace file.
+func LoadWorkspace(root string) (*Workspace, error) {
+	file, err := os.Open(filepath.Join(root, WorkspaceFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to resolve workspace path: %w", err)
+	}
+
+	ws := &Workspace{Root: root}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(root, line)
+		}
+		ws.Projects = append(ws.Projects, filepath.Clean(line))
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", WorkspaceFile, err)
+	}
+
+	return ws, nil
+}
+
+// FindWorkspace returns the nearest workspace containing dir, or nil if
+// there is none.
+func FindWorkspace(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to resolve path: %w", err)
+	}
+
+	for {
+		ws, err := LoadWorkspace(dir)
+		if err == nil {
+			return ws, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Project returns the directory of the project matching name, which is
+// either its path as listed or its directory name.
+func (ws *Workspace) Project(name string) (string, error) {
+	for _, dir := range ws.Projects {
+		rel, _ := filepath.Rel(ws.Root, dir)
+		if name == rel || name == filepath.Base(dir) || filepath.Join(ws.Root, name) == dir {
+			return dir, nil
+		}
+	}
+
+	names := make([]string, 0, len(ws.Projects))
+	for _, dir := range ws.Projects {
+		rel, _ := filepath.Rel(ws.Root, dir)
+		names = append(names, "- "+rel)
+	}
+
+	return "", fmt.Errorf("Project %q not found in %s\n\nAvailable projects:\n%s",
+		name, filepath.Join(ws.Root, WorkspaceFile), strings.Join(names, "\n"))
+}
+
+// Containing returns the project directory that contains dir, or an empty
+// string if dir is outside every project.
+func (ws *Workspace) Containing(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for _, project := range ws.Projects {
+		rel, err := filepath.Rel(project, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return project
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+// Package style renders colors, emoji, and hyperlinks for terminal output.
+//
+// Styling is disabled by the --no-color flag, the color config key, the
+// NO_COLOR environment variable, or when stdout is not a terminal. Disabled
+// output is plain text that reads well in CI logs.
+package style
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// Disable turns off all styling.
+func Disable() {
+	color.NoColor = true
+}
+
+// Enabled reports whether styling is on.
+func Enabled() bool {
+	return !color.NoColor
+}
+
+// Green renders its arguments in green.
+func Green(a ...any) string {
+	return color.New(color.FgGreen).Sprint(a...)
+}
+
+// Red renders its arguments in red.
+func Red(a ...any) string {
+	return color.New(color.FgRed).Sprint(a...)
+}
+
+// Yellow renders its arguments in yellow.
+func Yellow(a ...any) string {
+	return color.New(color.FgYellow).Sprint(a...)
+}
+
+// Bold renders its arguments in bold.
+func Bold(a ...any) string {
+	return color.New(color.Bold).Sprint(a...)
+}
+
+// CheckMark returns a green check mark.
+func CheckMark() string {
+	return Green("✓")
+}
+
+// CrossMark returns a red cross mark.
+func CrossMark() string {
+	return Red("✗")
+}
+
+// Emoji returns e, or an empty string when styling is disabled.
+func Emoji(e string) string {
+	if !Enabled() {
+		return ""
+	}
+
+	return e
+}
+
+// Link returns an OSC-8 terminal hyperlink to url showing text.
+// When styling is disabled it returns the text followed by the URL.
+func Link(url, text string) string {
+	if !Enabled() {
+		if text == url {
+			return url
+		}
+
+		return fmt.Sprintf("%s (%s)", text, url)
+	}
+
+	return fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, text)
+}
@@ -0,0 +1,258 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Files kept in the global config directory.
+const (
+	QueueFile = "telemetry.jsonl"
+	IDFile    = "telemetry.id"
+)
+
+const (
+	// maxQueued bounds the queue while events cannot be sent; the oldest are dropped.
+	maxQueued = 1000
+	// flushSize and flushInterval decide when queued events are sent.
+	flushSize     = 20
+	flushInterval = time.Hour
+)
+
+// Event is an anonymous record of a test run.
+// It holds no project contents: no source, paths, program output, or failure messages.
+type Event struct {
+	Time time.Time `json:"time"`
+	// ID identifies the installation, not the user.
+	ID         string    `json:"id"`
+	Version    string    `json:"version"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	Challenge  string    `json:"challenge"`
+	Stage      string    `json:"stage"`
+	Passed     bool      `json:"passed"`
+	DurationMS int64     `json:"durationMs"`
+	Failures   []Failure `json:"failures,omitempty"`
+}
+
+// Failure names a failed test of the challenge suite and what kind of check failed.
+type Failure struct {
+	Test     string `json:"test"`
+	Category string `json:"category"`
+}
+
+// Categorize reduces a failure to a fixed category so the message itself,
+// which may quote program output, is never collected. field is the failed
+// HTTP response field, if the failure was an HTTP assertion.
+func Categorize(message, field string) string {
+	if field != "" {
+		return "http_" + field
+	}
+
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "timed out") || strings.Contains(message, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "connection reset"):
+		return "connection"
+	case strings.Contains(message, "exit code"):
+		return "exit_code"
+	case strings.Contains(message, "output"):
+		return "output"
+	}
+
+	return "other"
+}
+
+// Queue holds events waiting to be sent.
+type Queue struct {
+	dir string
+}
+
+// NewQueue returns the queue stored in dir.
+func NewQueue(dir string) *Queue {
+	return &Queue{dir: dir}
+}
+
+// Path returns the path of the queue file.
+func (q *Queue) Path() string {
+	return filepath.Join(q.dir, QueueFile)
+}
+
+// ID returns the anonymous installation ID, creating it on first use.
+func (q *Queue) ID() (string, error) {
+	path := filepath.Join(q.dir, IDFile)
+	content, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("Failed to read telemetry ID: %w", err)
+	}
+
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	id := hex.EncodeToString(raw)
+
+	err = os.MkdirAll(q.dir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create config directory: %w", err)
+	}
+
+	err = os.WriteFile(path, []byte(id+"\n"), 0600)
+	if err != nil {
+		return "", fmt.Errorf("Failed to write telemetry ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// Add queues an event, filling in its installation and platform details.
+func (q *Queue) Add(event Event) error {
+	id, err := q.ID()
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+	event.OS = runtime.GOOS
+	event.Arch = runtime.GOARCH
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	events, err := q.Events()
+	if err != nil {
+		return err
+	}
+
+	events = append(events, event)
+	if len(events) > maxQueued {
+		events = events[len(events)-maxQueued:]
+	}
+
+	return q.write(events)
+}
+
+// Events returns the queued events, oldest first.
+func (q *Queue) Events() ([]Event, error) {
+	file, err := os.Open(q.Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read telemetry queue: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			events = append(events, event)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read telemetry queue: %w", err)
+	}
+
+	return events, nil
+}
+
+// Due reports whether enough events have queued up, or waited long enough, to send.
+func (q *Queue) Due(events []Event) bool {
+	return len(events) >= flushSize || (len(events) > 0 && time.Since(events[0].Time) >= flushInterval)
+}
+
+// Flush sends the queued events to endpoint and clears them once accepted.
+// Events stay queued when sending fails.
+func (q *Queue) Flush(ctx context.Context, endpoint, userAgent string) error {
+	events, err := q.Events()
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("Failed to encode telemetry: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to send telemetry: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Telemetry rejected (%s)", resp.Status)
+	}
+
+	return q.write(nil)
+}
+
+// Clear removes the queue and the installation ID.
+func (q *Queue) Clear() error {
+	for _, name := range []string{QueueFile, IDFile} {
+		err := os.Remove(filepath.Join(q.dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// write replaces the queue file with events.
+func (q *Queue) write(events []Event) error {
+	if len(events) == 0 {
+		err := os.Remove(q.Path())
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to clear telemetry queue: %w", err)
+		}
+
+		return nil
+	}
+
+	var b bytes.Buffer
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("Failed to encode telemetry: %w", err)
+		}
+
+		b.Write(append(line, '\n'))
+	}
+
+	err := os.MkdirAll(q.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create config directory: %w", err)
+	}
+
+	err = os.WriteFile(q.Path(), b.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write telemetry queue: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,423 @@
+// Package tui implements the full-screen interactive mode of lc.
+//
+// The screen shows the stage list on the left, streaming test output on the
+// right, and the tail of the latest program log below it. Tests run as a
+// child `lc test` process so their output can be streamed without touching
+// the attest printers.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/littleclusters/lc/internal/registry"
+	"github.com/littleclusters/lc/internal/state"
+	"golang.org/x/term"
+)
+
+const (
+	// stagePaneWidth is the width of the stage list column.
+	stagePaneWidth = 36
+	// maxOutputLines bounds the test output kept in memory.
+	maxOutputLines = 2000
+	// maxLogBytes bounds how much of the program log is read per refresh.
+	maxLogBytes = 64 << 10
+	// refreshInterval is how often the screen and log tail are redrawn.
+	refreshInterval = 250 * time.Millisecond
+)
+
+// ErrNoTerminal is returned when stdin or stdout is not a terminal.
+var ErrNoTerminal = errors.New("lc tui needs an interactive terminal")
+
+// App holds the state of the interactive session.
+type App struct {
+	challenge *registry.Challenge
+	exe       string
+	logDir    string
+
+	mu      sync.Mutex
+	current string
+	skipped []string
+	cursor  int
+	output  []string
+	status  string
+	running bool
+	cancel  context.CancelFunc
+}
+
+// New creates an interactive session for the challenge in the working directory.
+// exe is the lc binary used to run tests and logDir holds the test run directories.
+func New(challenge *registry.Challenge, cfg *state.State, exe, logDir string) *App {
+	return &App{
+		challenge: challenge,
+		exe:       exe,
+		logDir:    logDir,
+		current:   cfg.Stage,
+		skipped:   cfg.Skipped,
+		cursor:    max(challenge.StageIndex(cfg.Stage), 0),
+		status:    "enter: test  n: next stage  j/k: move  q: quit",
+	}
+}
+
+// Run takes over the terminal until the user quits or ctx is cancelled.
+func (a *App) Run(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return ErrNoTerminal
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("Failed to configure terminal: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// Alternate screen, hidden cursor
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		a.draw()
+
+		select {
+		case <-ctx.Done():
+			a.stop()
+			return nil
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok || !a.handleKey(ctx, key) {
+				a.stop()
+				return nil
+			}
+		}
+	}
+}
+
+// handleKey applies a key press and returns false when the session should end.
+func (a *App) handleKey(ctx context.Context, key string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch key {
+	case "q":
+		return false
+	case "ctrl-c":
+		if !a.running {
+			return false
+		}
+		a.cancel()
+	case "up", "k":
+		a.cursor = max(a.cursor-1, 0)
+	case "down", "j":
+		a.cursor = min(a.cursor+1, a.challenge.Len()-1)
+	case "enter", "t":
+		if !a.running {
+			stage := a.challenge.StageOrder[a.cursor]
+			a.start(ctx, fmt.Sprintf("Testing %s", stage), "test", stage)
+		}
+	case "n":
+		if !a.running {
+			a.start(ctx, "Advancing to the next stage", "next")
+		}
+	}
+
+	return true
+}
+
+// start runs an lc subcommand in the background, streaming its output.
+// The caller must hold a.mu.
+func (a *App) start(ctx context.Context, title string, args ...string) {
+	runCtx, cancel := context.WithCancel(ctx)
+	a.running = true
+	a.cancel = cancel
+	a.output = nil
+	a.status = title + "...  ctrl-c: stop"
+
+	cmd := exec.CommandContext(runCtx, a.exe, append([]string{"--no-color"}, args...)...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = 5 * time.Second
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			a.appendOutput(scanner.Text())
+		}
+	}()
+
+	go func() {
+		err := cmd.Run()
+		stopped := runCtx.Err() != nil
+		writer.Close()
+		cancel()
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.running = false
+		switch {
+		case stopped:
+			a.status = title + ": stopped"
+		case err != nil:
+			a.status = title + ": failed"
+		default:
+			a.status = title + ": done"
+		}
+
+		cfg, err := state.Load()
+		if err == nil {
+			a.current = cfg.Stage
+			a.skipped = cfg.Skipped
+		}
+	}()
+}
+
+// stop cancels a running command, if any.
+func (a *App) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		a.cancel()
+	}
+}
+
+// appendOutput adds a line of command output, dropping the oldest lines.
+func (a *App) appendOutput(line string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.output = append(a.output, strings.ReplaceAll(line, "\t", "    "))
+	if len(a.output) > maxOutputLines {
+		a.output = a.output[len(a.output)-maxOutputLines:]
+	}
+}
+
+// draw renders the whole screen.
+func (a *App) draw() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < stagePaneWidth+20 || height < 10 {
+		fmt.Print("\033[H\033[2JTerminal too small for lc tui.")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rightWidth := width - stagePaneWidth - 3
+	bodyHeight := height - 3
+	outputHeight := bodyHeight * 3 / 5
+	logHeight := bodyHeight - outputHeight - 1
+
+	left := a.stageLines()
+	right := []string{rule("Output", rightWidth)}
+	right = append(right, tail(a.output, outputHeight-1)...)
+	for len(right) < outputHeight {
+		right = append(right, "")
+	}
+
+	logPath, logLines := latestLog(a.logDir, logHeight)
+	right = append(right, rule("Log "+logPath, rightWidth))
+	right = append(right, logLines...)
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "\033[1m%s\033[0m\r\n", fit(fmt.Sprintf("lc · %s", a.challenge.Name), width))
+	b.WriteString(strings.Repeat("─", width) + "\r\n")
+
+	for i := range bodyHeight {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+
+		fmt.Fprintf(&b, "%s │ %s\r\n", pad(l, stagePaneWidth), fit(r, rightWidth))
+	}
+
+	b.WriteString("\033[7m" + pad(a.status, width) + "\033[0m")
+	fmt.Print(b.String())
+}
+
+// stageLines renders the stage list with progress markers and the cursor.
+func (a *App) stageLines() []string {
+	currentIndex := a.challenge.StageIndex(a.current)
+	lines := []string{"Stages", ""}
+
+	for i, key := range a.challenge.StageOrder {
+		marker := " "
+		if i < currentIndex && slices.Contains(a.skipped, key) {
+			marker = "↷"
+		} else if i < currentIndex {
+			marker = "✓"
+		} else if i == currentIndex {
+			marker = "→"
+		}
+
+		line := fit(fmt.Sprintf("%s %d. %s", marker, i+1, key), stagePaneWidth)
+		if i == a.cursor {
+			line = "\033[7m" + pad(line, stagePaneWidth) + "\033[0m"
+		}
+
+		lines = append(lines, line)
+	}
+
+	if a.cursor < a.challenge.Len() {
+		stage := a.challenge.Stages[a.challenge.StageOrder[a.cursor]]
+		lines = append(lines, "", fit(stage.Name, stagePaneWidth))
+	}
+
+	return lines
+}
+
+// latestLog returns the newest program log and its last n lines.
+func latestLog(dir string, n int) (string, []string) {
+	runs, _ := filepath.Glob(filepath.Join(dir, "run-*"))
+	sort.Strings(runs)
+	if len(runs) == 0 {
+		return "", nil
+	}
+
+	logs, _ := filepath.Glob(filepath.Join(runs[len(runs)-1], "*.log"))
+
+	var newest string
+	var newestTime time.Time
+	for _, path := range logs {
+		info, err := os.Stat(path)
+		if err == nil && info.ModTime().After(newestTime) {
+			newest, newestTime = path, info.ModTime()
+		}
+	}
+
+	if newest == "" {
+		return "", nil
+	}
+
+	file, err := os.Open(newest)
+	if err != nil {
+		return newest, nil
+	}
+	defer file.Close()
+
+	// Only the end of the log is shown, so skip the rest of large files
+	info, err := file.Stat()
+	if err == nil && info.Size() > maxLogBytes {
+		file.Seek(-maxLogBytes, io.SeekEnd)
+	}
+
+	bytes, err := io.ReadAll(file)
+	if err != nil {
+		return newest, nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(bytes), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "\t", "    ")
+	}
+
+	return newest, tail(lines, n)
+}
+
+// readKeys decodes key presses from r until it fails.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+
+		switch s := string(buf[:n]); s {
+		case "\x03":
+			keys <- "ctrl-c"
+		case "\r", "\n":
+			keys <- "enter"
+		case "\x1b[A", "\x1bOA":
+			keys <- "up"
+		case "\x1b[B", "\x1bOB":
+			keys <- "down"
+		default:
+			keys <- s
+		}
+	}
+}
+
+// rule renders a pane title as a horizontal rule.
+func rule(title string, width int) string {
+	return fit("── "+title+" "+strings.Repeat("─", width), width)
+}
+
+// tail returns the last n lines.
+func tail(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+
+	return lines[len(lines)-n:]
+}
+
+// fit truncates s to at most width runes.
+func fit(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// pad truncates or right-pads s to exactly width runes.
+func pad(s string, width int) string {
+	visible := utf8.RuneCountInString(stripANSI(s))
+	if visible > width {
+		return fit(s, width)
+	}
+
+	return s + strings.Repeat(" ", width-visible)
+}
+
+// stripANSI removes SGR escape sequences.
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\033' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}